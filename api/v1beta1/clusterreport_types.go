@@ -20,6 +20,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	ClusterReportKind = "ClusterReport"
+)
+
 // HelmAction represents the type of action on a give resource or helm release
 type HelmAction string
 
@@ -52,7 +52,7 @@ const (
 	FeatureKustomize = FeatureID("Kustomize")
 )
 
-// +kubebuilder:validation:Enum:=Provisioning;Provisioned;Failed;FailedNonRetriable;Removing;Removed
+// +kubebuilder:validation:Enum:=Provisioning;Provisioned;Degraded;Failed;FailedNonRetriable;Removing;Removed
 type FeatureStatus string
 
 const (
@@ -64,6 +64,10 @@ const (
 	// provisioned in the workload cluster
 	FeatureStatusProvisioned = FeatureStatus("Provisioned")
 
+	// FeatureStatusDegraded indicates that feature was provisioned
+	// in the workload cluster but health checks are currently failing
+	FeatureStatusDegraded = FeatureStatus("Degraded")
+
 	// FeatureStatusFailed indicates that configuring the feature
 	// in the workload cluster failed
 	FeatureStatusFailed = FeatureStatus("Failed")
@@ -117,6 +121,11 @@ type FeatureSummary struct {
 	// LastAppliedTime is the time feature was last reconciled
 	// +optional
 	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// DriftDetectedTime is the time a configuration drift was detected in the
+	// workload cluster for this feature. It is cleared once the drift is remediated.
+	// +optional
+	DriftDetectedTime *metav1.Time `json:"driftDetectedTime,omitempty"`
 }
 
 type FeatureDeploymentInfo struct {
@@ -182,6 +191,11 @@ type ClusterSummarySpec struct {
 	// ClusterProfileSpec represent the configuration that will be applied to
 	// the workload cluster.
 	ClusterProfileSpec Spec `json:"clusterProfileSpec,omitempty"`
+
+	// PlanHash is the hash of the approved ClusterProfile/Profile plan ClusterProfileSpec
+	// belongs to. Only set when ClusterProfile/Profile requires approval.
+	// +optional
+	PlanHash string `json:"planHash,omitempty"`
 }
 
 // ClusterSummaryStatus defines the observed state of ClusterSummary
@@ -255,6 +269,35 @@ func GetClusterSummary(ctx context.Context, c client.Client, namespace, name str
 	return clusterSummary, nil
 }
 
+// GetClusterSummaryStatus summarizes the status of all features of a ClusterSummary, returning
+// the least advanced one. An empty status is returned if no feature has reported a status yet.
+func GetClusterSummaryStatus(clusterSummary *ClusterSummary) FeatureStatus {
+	priority := map[FeatureStatus]int{
+		"":                              0,
+		FeatureStatusFailedNonRetriable: 1,
+		FeatureStatusFailed:             2,
+		FeatureStatusDegraded:           3,
+		FeatureStatusRemoving:           4,
+		FeatureStatusProvisioning:       5,
+		FeatureStatusRemoved:            6,
+		FeatureStatusProvisioned:        7,
+	}
+
+	if len(clusterSummary.Status.FeatureSummaries) == 0 {
+		return ""
+	}
+
+	status := clusterSummary.Status.FeatureSummaries[0].Status
+	for i := range clusterSummary.Status.FeatureSummaries {
+		current := clusterSummary.Status.FeatureSummaries[i].Status
+		if priority[current] < priority[status] {
+			status = current
+		}
+	}
+
+	return status
+}
+
 // GetProfileOwnerReference returns the ClusterProfile/Profile owning a given ClusterSummary
 func GetProfileOwnerReference(clusterSummary *ClusterSummary) (*metav1.OwnerReference, error) {
 	for _, ref := range clusterSummary.OwnerReferences {
@@ -0,0 +1,102 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	DeploymentWindowKind = "DeploymentWindow"
+
+	// WaitingForWindowReason is the FeatureSummary FailureReason set when a deployment
+	// is deferred because of a DeploymentWindow
+	WaitingForWindowReason = "WaitingForWindow"
+)
+
+// Window is a recurring time window
+type Window struct {
+	// Schedule is a cron expression (minute hour day-of-month month day-of-week)
+	// defining when the window starts. For instance "0 22 * * 6" starts every
+	// Saturday at 22:00.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open once started
+	Duration metav1.Duration `json:"duration"`
+}
+
+// DeploymentWindowSpec defines the desired state of DeploymentWindow
+type DeploymentWindowSpec struct {
+	// ClusterSelector identifies clusters this DeploymentWindow applies to.
+	// If not set, all clusters are selected.
+	// +optional
+	ClusterSelector libsveltosv1beta1.Selector `json:"clusterSelector,omitempty"`
+
+	// ProfileSelector identifies ClusterProfiles/Profiles this DeploymentWindow
+	// applies to. If not set, all ClusterProfiles/Profiles are selected.
+	// +optional
+	ProfileSelector libsveltosv1beta1.Selector `json:"profileSelector,omitempty"`
+
+	// TimeZone is the IANA time zone (for instance "Europe/Rome") schedules are
+	// evaluated in. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// AllowedWindows, when set, are the only windows add-on changes can be deployed in.
+	// +optional
+	AllowedWindows []Window `json:"allowedWindows,omitempty"`
+
+	// BlockedWindows are windows (freezes) add-on changes cannot be deployed in.
+	// BlockedWindows take precedence over AllowedWindows.
+	// +optional
+	BlockedWindows []Window `json:"blockedWindows,omitempty"`
+
+	// DeferDriftRemediation indicates whether remediating configuration drifts
+	// must also wait for a window. By default drifts are always remediated.
+	// +kubebuilder:default:=false
+	// +optional
+	DeferDriftRemediation bool `json:"deferDriftRemediation,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=deploymentwindows,scope=Cluster
+// +kubebuilder:storageversion
+
+// DeploymentWindow is the Schema for the deploymentwindows API.
+// It restricts when add-on changes are deployed to the selected clusters.
+type DeploymentWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeploymentWindowSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DeploymentWindowList contains a list of DeploymentWindow
+type DeploymentWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeploymentWindow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeploymentWindow{}, &DeploymentWindowList{})
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	NotifierKind = "Notifier"
+)
+
+// NotificationTransition is a profile or cluster state transition a Notifier can subscribe to
+// +kubebuilder:validation:Enum:=Failed;FailedNonRetriable;Provisioned;RolloutHalted;DriftDetected
+type NotificationTransition string
+
+const (
+	// NotificationTransitionFailed is sent when a feature deployment fails in a cluster
+	// (for instance when moving from Provisioned to Failed)
+	NotificationTransitionFailed = NotificationTransition("Failed")
+
+	// NotificationTransitionFailedNonRetriable is sent when a feature deployment fails in a cluster
+	// and Sveltos stops retrying
+	NotificationTransitionFailedNonRetriable = NotificationTransition("FailedNonRetriable")
+
+	// NotificationTransitionProvisioned is sent when a feature, previously not provisioned,
+	// is successfully deployed in a cluster
+	NotificationTransitionProvisioned = NotificationTransition("Provisioned")
+
+	// NotificationTransitionRolloutHalted is sent when a ClusterProfile/Profile stops rolling out
+	// (circuit breaker tripped)
+	NotificationTransitionRolloutHalted = NotificationTransition("RolloutHalted")
+
+	// NotificationTransitionDriftDetected is sent when a configuration drift is detected in a cluster
+	NotificationTransitionDriftDetected = NotificationTransition("DriftDetected")
+)
+
+// NotifierSinkType is where notifications are delivered
+// +kubebuilder:validation:Enum:=Webhook;Slack;ConfigMap
+type NotifierSinkType string
+
+const (
+	// NotifierSinkWebhook posts the notification, as JSON, to an HTTP endpoint
+	NotifierSinkWebhook = NotifierSinkType("Webhook")
+
+	// NotifierSinkSlack posts the notification, as Slack-compatible JSON ({"text": ...}),
+	// to an HTTP endpoint (a Slack incoming webhook for instance)
+	NotifierSinkSlack = NotifierSinkType("Slack")
+
+	// NotifierSinkConfigMap stores the latest notification, per cluster/profile and transition,
+	// in a ConfigMap in the management cluster
+	NotifierSinkConfigMap = NotifierSinkType("ConfigMap")
+)
+
+// NotifierSink defines where notifications are delivered
+type NotifierSink struct {
+	// Type of the sink
+	Type NotifierSinkType `json:"type"`
+
+	// URL of the HTTP endpoint. Used by Webhook and Slack sinks.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// URLSecretRef references a Secret containing the URL of the HTTP endpoint in its "url" key.
+	// Used by Webhook and Slack sinks when URL is not set.
+	// Secret must be in the addon-controller namespace. If namespace is not set, such namespace is used.
+	// +optional
+	URLSecretRef *corev1.SecretReference `json:"urlSecretRef,omitempty"`
+
+	// ConfigMapRef references the ConfigMap notifications are stored in. Used by the ConfigMap sink.
+	// ConfigMap is created if it does not exist.
+	// ConfigMap must be in the addon-controller namespace. If namespace is not set, such namespace is used.
+	// +optional
+	ConfigMapRef *corev1.ObjectReference `json:"configMapRef,omitempty"`
+}
+
+// NotifierSpec defines the desired state of Notifier
+type NotifierSpec struct {
+	// ClusterSelector identifies clusters whose notifications are delivered.
+	// If not set, all clusters are selected. Ignored for profile notifications (RolloutHalted).
+	// +optional
+	ClusterSelector libsveltosv1beta1.Selector `json:"clusterSelector,omitempty"`
+
+	// ProfileSelector identifies ClusterProfiles/Profiles whose notifications are delivered.
+	// If not set, all ClusterProfiles/Profiles are selected.
+	// +optional
+	ProfileSelector libsveltosv1beta1.Selector `json:"profileSelector,omitempty"`
+
+	// Transitions this Notifier subscribes to. If not set, all transitions are delivered.
+	// +listType=set
+	// +optional
+	Transitions []NotificationTransition `json:"transitions,omitempty"`
+
+	// Sink is where notifications are delivered
+	Sink NotifierSink `json:"sink"`
+
+	// Template is a Go template used to render the payload. Available fields are
+	// .Transition, .ProfileKind, .ProfileNamespace, .ProfileName, .ClusterType,
+	// .ClusterNamespace, .ClusterName, .FeatureID, .Message and .Time.
+	// For Slack sink, the rendered template is the message text.
+	// If not set, Webhook and ConfigMap sinks use the notification as JSON, Slack
+	// sink uses a one line summary.
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// MaxRetries is the number of times delivering a notification is retried on failure.
+	// Retries are spaced with exponential backoff, starting at one second.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default:=3
+	// +optional
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+
+	// DeduplicationWindow is the time window during which identical notifications
+	// are delivered only once. Defaults to 10 minutes.
+	// +optional
+	DeduplicationWindow *metav1.Duration `json:"deduplicationWindow,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=notifiers,scope=Cluster
+// +kubebuilder:storageversion
+
+// Notifier is the Schema for the notifiers API.
+// It delivers profile and cluster state transitions to a webhook, Slack or a ConfigMap.
+type Notifier struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NotifierSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NotifierList contains a list of Notifier
+type NotifierList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Notifier `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Notifier{}, &NotifierList{})
+}
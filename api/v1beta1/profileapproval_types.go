@@ -0,0 +1,85 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ProfileApprovalKind = "ProfileApproval"
+
+	// ApprovedPlanAnnotation can be set on a ClusterProfile/Profile with ApprovalRequired set.
+	// Its value is the hash of the approved plan (Status.Plan.Hash).
+	ApprovedPlanAnnotation = "projectsveltos.io/approved-plan"
+
+	// PlanPendingApprovalReason is the FeatureSummary FailureReason set when a ClusterSummary
+	// does not deploy because the ClusterProfile/Profile plan changed and has not been approved yet
+	PlanPendingApprovalReason = "PlanPendingApproval"
+)
+
+// ProfileReference identifies a ClusterProfile or a Profile
+type ProfileReference struct {
+	// Kind of the profile. Either ClusterProfile or Profile
+	// +kubebuilder:validation:Enum=ClusterProfile;Profile
+	Kind string `json:"kind"`
+
+	// Namespace of the profile. Only set for Profiles
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the profile
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ProfileApprovalSpec defines the desired state of ProfileApproval
+type ProfileApprovalSpec struct {
+	// ProfileRef references the ClusterProfile/Profile whose plan is approved
+	ProfileRef ProfileReference `json:"profileRef"`
+
+	// PlanHash is the hash of the approved plan, as reported in the profile Status.Plan.Hash.
+	// If the profile Spec changes, its plan hash changes and this approval does not apply anymore.
+	// +kubebuilder:validation:MinLength=1
+	PlanHash string `json:"planHash"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=profileapprovals,scope=Cluster
+// +kubebuilder:storageversion
+
+// ProfileApproval is the Schema for the profileapprovals API.
+// It approves the plan computed for a ClusterProfile/Profile with ApprovalRequired set.
+type ProfileApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProfileApprovalSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ProfileApprovalList contains a list of ProfileApproval
+type ProfileApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProfileApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProfileApproval{}, &ProfileApprovalList{})
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	ProfileSimulationKind = "ProfileSimulation"
+)
+
+// ProfileSimulationSpec defines the desired state of ProfileSimulation.
+// Each field, when set, replaces the corresponding field of the referenced ClusterProfile/Profile.
+// Fields not set keep the profile current value.
+type ProfileSimulationSpec struct {
+	// ProfileRef references the ClusterProfile/Profile whose changes are simulated
+	ProfileRef ProfileReference `json:"profileRef"`
+
+	// ClusterSelector is the simulated ClusterSelector
+	// +optional
+	ClusterSelector *libsveltosv1beta1.Selector `json:"clusterSelector,omitempty"`
+
+	// ClusterRefs is the simulated list of ClusterRefs
+	// +optional
+	ClusterRefs []corev1.ObjectReference `json:"clusterRefs,omitempty"`
+
+	// SetRefs is the simulated list of (cluster)Sets
+	// +optional
+	SetRefs []string `json:"setRefs,omitempty"`
+
+	// StopMatchingBehavior is the simulated StopMatchingBehavior
+	// +kubebuilder:validation:Enum:=WithdrawPolicies;LeavePolicies
+	// +optional
+	StopMatchingBehavior *StopMatchingBehavior `json:"stopMatchingBehavior,omitempty"`
+
+	// Tier is the simulated Tier
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Tier *int32 `json:"tier,omitempty"`
+}
+
+// OwnershipChange reports a Helm release or a resource that would change owner in a cluster
+type OwnershipChange struct {
+	// Cluster where the Helm release/resource is deployed
+	Cluster corev1.ObjectReference `json:"cluster"`
+
+	// Kind is either HelmRelease or the Kind of the deployed resource
+	Kind string `json:"kind"`
+
+	// Group of the deployed resource. Empty for Helm releases and core resources
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Namespace of the Helm release/resource
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the Helm release/resource
+	Name string `json:"name"`
+
+	// CurrentOwner is the ClusterProfile/Profile currently managing the Helm release/resource
+	// +optional
+	CurrentOwner *ProfileReference `json:"currentOwner,omitempty"`
+
+	// NewOwner is the ClusterProfile/Profile that would manage the Helm release/resource.
+	// Not set when nothing would manage it anymore.
+	// +optional
+	NewOwner *ProfileReference `json:"newOwner,omitempty"`
+}
+
+// ProfileSimulationStatus defines the observed state of ProfileSimulation
+type ProfileSimulationStatus struct {
+	// ObservedGeneration is the generation of the ProfileSimulation the status refers to
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// NewlyMatchingClusters are the clusters which would start matching
+	// +optional
+	NewlyMatchingClusters []corev1.ObjectReference `json:"newlyMatchingClusters,omitempty"`
+
+	// StopMatchingClusters are the clusters which would stop matching
+	// +optional
+	StopMatchingClusters []corev1.ObjectReference `json:"stopMatchingClusters,omitempty"`
+
+	// WithdrawnFromClusters indicates whether add-ons would be withdrawn from the
+	// clusters which would stop matching
+	// +optional
+	WithdrawnFromClusters bool `json:"withdrawnFromClusters,omitempty"`
+
+	// OwnershipChanges lists the Helm releases and resources which would change owner
+	// +optional
+	OwnershipChanges []OwnershipChange `json:"ownershipChanges,omitempty"`
+
+	// FailureMessage provides more information if an error occurs.
+	// +optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=profilesimulations,scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+
+// ProfileSimulation is the Schema for the profilesimulations API.
+// It reports the impact changing a ClusterProfile/Profile would have, without changing it.
+type ProfileSimulation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProfileSimulationSpec   `json:"spec,omitempty"`
+	Status ProfileSimulationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ProfileSimulationList contains a list of ProfileSimulation
+type ProfileSimulationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProfileSimulation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProfileSimulation{}, &ProfileSimulationList{})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	RollbackKind = "Rollback"
+)
+
+// RollbackSpec defines the desired state of Rollback
+type RollbackSpec struct {
+	// ProfileRef references the ClusterProfile/Profile whose deployments are rolled back.
+	// Only Kind, Namespace (for Profiles) and Name are considered.
+	ProfileRef corev1.ObjectReference `json:"profileRef"`
+
+	// ClusterRef, when set, restricts the rollback to the cluster it references.
+	// Otherwise deployments on all clusters matching the ClusterProfile/Profile are
+	// rolled back.
+	// +optional
+	ClusterRef *corev1.ObjectReference `json:"clusterRef,omitempty"`
+
+	// FeatureIDs, when set, restricts the rollback to those features (Resources, Helm,
+	// Kustomize). Otherwise all features are rolled back.
+	// +optional
+	FeatureIDs []FeatureID `json:"featureIDs,omitempty"`
+
+	// Revision is the revision deployments are pinned to. Revisions are numbered per
+	// cluster and feature.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+
+	// DeployedBefore, when Revision is not set, pins deployments to the latest revision
+	// deployed before this time. This is useful to roll back all clusters matching a
+	// ClusterProfile/Profile since revision numbers differ between clusters.
+	// If neither Revision nor DeployedBefore is set, deployments are pinned to the
+	// revision preceding the latest one.
+	// +optional
+	DeployedBefore *metav1.Time `json:"deployedBefore,omitempty"`
+}
+
+// PinnedRevision reports the revision a feature is pinned to in a cluster
+type PinnedRevision struct {
+	// ClusterNamespace is the namespace of the cluster
+	ClusterNamespace string `json:"clusterNamespace"`
+
+	// ClusterName is the name of the cluster
+	ClusterName string `json:"clusterName"`
+
+	// ClusterType is the type of the cluster
+	ClusterType libsveltosv1beta1.ClusterType `json:"clusterType"`
+
+	// FeatureID is the feature rolled back
+	FeatureID FeatureID `json:"featureID"`
+
+	// Revision is the revision the feature is pinned to. Not set when no stored
+	// revision matches the Rollback. In such case the current configuration is deployed.
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+
+	// Message provides more information, for instance why no revision is pinned
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RollbackStatus defines the observed state of Rollback
+type RollbackStatus struct {
+	// PinnedRevisions reports, for each cluster and feature the Rollback applies to,
+	// the revision deployments are pinned to
+	// +optional
+	PinnedRevisions []PinnedRevision `json:"pinnedRevisions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=rollbacks,scope=Cluster
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+
+// Rollback is the Schema for the rollbacks API.
+// It pins deployments of a ClusterProfile/Profile to a prior revision till it is deleted.
+type Rollback struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RollbackSpec   `json:"spec,omitempty"`
+	Status RollbackStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RollbackList contains a list of Rollback
+type RollbackList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Rollback `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Rollback{}, &RollbackList{})
+}
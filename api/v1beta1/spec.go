@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -44,6 +46,17 @@ func (m *DryRunReconciliationError) Error() string {
 	return "mode is DryRun. Nothing is reconciled"
 }
 
+// GetDependsOnProfile returns kind and name of the profile a DependsOn entry refers to.
+// An entry is the name of a profile of the same kind as the dependent (dependentKind), unless
+// it has the form "ClusterProfile/<name>", which lets a Profile depend on a ClusterProfile.
+func GetDependsOnProfile(dependentKind, entry string) (kind, name string) {
+	if name, ok := strings.CutPrefix(entry, ClusterProfileKind+"/"); ok {
+		return ClusterProfileKind, name
+	}
+
+	return dependentKind, entry
+}
+
 type ValidateHealth struct {
 	// Name is the name of this check
 	Name string `json:"name"`
@@ -462,6 +475,18 @@ type HelmChart struct {
 	// including information to connect to private registries.
 	// +optional
 	RegistryCredentialsConfig *RegistryCredentialsConfig `json:"registryCredentialsConfig,omitempty"`
+
+	// Identifier uniquely identifies this entry among the PolicyRefs, HelmCharts and KustomizationRefs
+	// of the profile. Other entries use it in DependsOn.
+	// +optional
+	Identifier string `json:"identifier,omitempty"`
+
+	// DependsOn lists the Identifiers of other PolicyRefs, HelmCharts or KustomizationRefs of this
+	// profile which must be deployed and ready before this entry is deployed. When withdrawn, this
+	// entry is removed before the entries it depends on.
+	// +listType=atomic
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 type KustomizationRef struct {
@@ -547,6 +572,32 @@ type KustomizationRef struct {
 	// the actual region retrieved earlier.
 	// +optional
 	ValuesFrom []ValueFrom `json:"valuesFrom,omitempty"`
+
+	// Wait, when set, makes Sveltos wait for all resources deployed because of this reference
+	// to be ready before marking the feature as Provisioned. Readiness is evaluated with built-in
+	// rules (Deployments/StatefulSets/DaemonSets rolled out, Jobs completed, CRDs established,
+	// APIServices available, PVCs bound, etc.).
+	// While waiting, the feature stays Provisioning and resources not ready yet are reported.
+	// +kubebuilder:default:=false
+	// +optional
+	Wait bool `json:"wait,omitempty"`
+
+	// Timeout is the maximum time to wait for resources to be ready. Used only when Wait is set.
+	// If resources are not ready by then, the deployment fails. Defaults to 5m0s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Identifier uniquely identifies this entry among the PolicyRefs, HelmCharts and KustomizationRefs
+	// of the profile. Other entries use it in DependsOn.
+	// +optional
+	Identifier string `json:"identifier,omitempty"`
+
+	// DependsOn lists the Identifiers of other PolicyRefs, HelmCharts or KustomizationRefs of this
+	// profile which must be deployed and ready before this entry is deployed. When withdrawn, this
+	// entry is removed before the entries it depends on.
+	// +listType=atomic
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // StopMatchingBehavior indicates what will happen when Cluster stops matching
@@ -562,6 +613,79 @@ const (
 	LeavePolicies    StopMatchingBehavior = "LeavePolicies"
 )
 
+const (
+	// ConfirmWithdrawalAnnotation confirms a withdrawal blocked by the WithdrawalGuard.
+	// Its value must be the withdrawal hash reported in the WithdrawalBlocked condition.
+	ConfirmWithdrawalAnnotation = "projectsveltos.io/confirm-withdrawal"
+
+	// ForceWithdrawalAnnotation, set on a ClusterProfile/Profile, withdraws its add-ons and applications
+	// without waiting for profiles depending on it to be withdrawn first.
+	ForceWithdrawalAnnotation = "projectsveltos.io/force-withdrawal"
+)
+
+const (
+	// ResetCircuitBreakerAnnotation resets a tripped circuit breaker. It is removed once
+	// the circuit breaker is reset.
+	ResetCircuitBreakerAnnotation = "projectsveltos.io/reset-circuit-breaker"
+)
+
+const (
+	// ClusterAPIQPSAnnotation, set on a SveltosCluster, overrides the maximum queries per second
+	// sent to the managed cluster API server. The limit is shared by all Sveltos clients of the cluster.
+	ClusterAPIQPSAnnotation = "projectsveltos.io/api-qps"
+
+	// ClusterAPIBurstAnnotation, set on a SveltosCluster, overrides the maximum burst of queries
+	// sent to the managed cluster API server.
+	ClusterAPIBurstAnnotation = "projectsveltos.io/api-burst"
+
+	// MaxConcurrentDeploymentsAnnotation, set on a SveltosCluster, overrides the maximum number
+	// of deployments running in parallel on the managed cluster, across all ClusterSummaries.
+	MaxConcurrentDeploymentsAnnotation = "projectsveltos.io/max-concurrent-deployments"
+)
+
+// CircuitBreaker stops deployments of a ClusterProfile/Profile when too many of its matching
+// clusters fail. When tripped, the ClusterProfile/Profile Tripped condition is set, clusters not
+// updated yet are left untouched and failing clusters are not retried anymore, till the circuit
+// breaker is reset either with the ResetCircuitBreakerAnnotation or after CoolDown.
+type CircuitBreaker struct {
+	// FailureThreshold is the percentage of clusters which, when failing within Window, trips
+	// the circuit breaker. It is computed over the matching clusters where deployment completed
+	// (either successfully or not), once at least a minimum sample of those is available. So the
+	// circuit breaker can trip before every matching cluster has been updated, even when
+	// MaxUpdate is not set. Zero disables the circuit breaker.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	FailureThreshold int32 `json:"failureThreshold"`
+
+	// Window is the time window failures are counted in. Defaults to the addon-controller one.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// CoolDown is how long the circuit breaker stays tripped before being automatically reset.
+	// Defaults to the addon-controller one.
+	// +optional
+	CoolDown *metav1.Duration `json:"coolDown,omitempty"`
+}
+
+// WithdrawalGuard limits how many clusters add-ons can be withdrawn from in one go because
+// clusters stopped matching. When a limit is exceeded, nothing is withdrawn and the
+// WithdrawalBlocked condition is set till the withdrawal is confirmed with the
+// ConfirmWithdrawalAnnotation.
+type WithdrawalGuard struct {
+	// MaxClusters is the maximum number of clusters add-ons can be withdrawn from at once.
+	// Zero disables this limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxClusters *int32 `json:"maxClusters,omitempty"`
+
+	// MaxPercentage is the maximum percentage of the clusters with deployed add-ons
+	// they can be withdrawn from at once. Zero disables this limit.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxPercentage *int32 `json:"maxPercentage,omitempty"`
+}
+
 type TemplateResourceRef struct {
 	// Resource references a Kubernetes instance in the management
 	// cluster to fetch and use during template instantiation.
@@ -620,6 +744,32 @@ type PolicyRef struct {
 	// +kubebuilder:default:=false
 	// +optional
 	Optional bool `json:"optional,omitempty"`
+
+	// Wait, when set, makes Sveltos wait for all resources deployed because of this reference
+	// to be ready before marking the feature as Provisioned. Readiness is evaluated with built-in
+	// rules (Deployments/StatefulSets/DaemonSets rolled out, Jobs completed, CRDs established,
+	// APIServices available, PVCs bound, etc.).
+	// While waiting, the feature stays Provisioning and resources not ready yet are reported.
+	// +kubebuilder:default:=false
+	// +optional
+	Wait bool `json:"wait,omitempty"`
+
+	// Timeout is the maximum time to wait for resources to be ready. Used only when Wait is set.
+	// If resources are not ready by then, the deployment fails. Defaults to 5m0s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Identifier uniquely identifies this entry among the PolicyRefs, HelmCharts and KustomizationRefs
+	// of the profile. Other entries use it in DependsOn.
+	// +optional
+	Identifier string `json:"identifier,omitempty"`
+
+	// DependsOn lists the Identifiers of other PolicyRefs, HelmCharts or KustomizationRefs of this
+	// profile which must be deployed and ready before this entry is deployed. When withdrawn, this
+	// entry is removed before the entries it depends on.
+	// +listType=atomic
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 type DriftExclusion struct {
@@ -632,6 +782,29 @@ type DriftExclusion struct {
 	Target *libsveltosv1beta1.PatchSelector `json:"target,omitempty"`
 }
 
+// HealthMonitoring configures periodic re-evaluation of the health of deployed features.
+type HealthMonitoring struct {
+	// Interval is the minimum time between two consecutive health evaluations
+	// in a matching managed cluster.
+	// +kubebuilder:default:="5m"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// CheckDeployedResources indicates whether, on top of ValidateHealths, the built-in
+	// readiness of all deployed resources (Deployments available, Jobs completed, etc.)
+	// must be evaluated. Readiness is evaluated for resources deployed because of PolicyRefs and
+	// KustomizationRefs. For Helm charts, use ValidateHealths.
+	// +kubebuilder:default:=false
+	// +optional
+	CheckDeployedResources bool `json:"checkDeployedResources,omitempty"`
+
+	// RedeployOnDegraded indicates whether a feature moving to Degraded must be redeployed.
+	// By default a Degraded feature is only reported and never redeployed.
+	// +kubebuilder:default:=false
+	// +optional
+	RedeployOnDegraded bool `json:"redeployOnDegraded,omitempty"`
+}
+
 type Clusters struct {
 	// Hash represents of a unique value for ClusterProfile Spec at
 	// a fixed point in time
@@ -676,6 +849,16 @@ type Spec struct {
 	// +optional
 	SyncMode SyncMode `json:"syncMode,omitempty"`
 
+	// ApprovalRequired, when set, holds any change to this profile till it is approved.
+	// Till the current Spec is approved, matching clusters are processed in DryRun mode: what
+	// would change is reported in ClusterReports and the plan is summarized in Status.Plan.
+	// A plan is approved by a ProfileApproval, or by the ApprovedPlanAnnotation set on the profile,
+	// carrying the plan hash. Once approved, the plan is applied following SyncMode.
+	// Any Spec change made after approval produces a new plan which needs a new approval.
+	// +kubebuilder:default:=false
+	// +optional
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
+
 	// Tier controls the order of deployment for ClusterProfile or Profile resources targeting
 	// the same cluster resources.
 	// Imagine two configurations (ClusterProfiles or Profiles) trying to deploy the same resource (a Kubernetes
@@ -690,6 +873,18 @@ type Spec struct {
 	// +optional
 	Tier int32 `json:"tier,omitempty"`
 
+	// Priority controls the order in which deployments are scheduled when more deployments are
+	// queued than there are deployer workers. Deployments of ClusterProfiles or Profiles with a
+	// **higher** Priority are processed first. A queued deployment gains priority the longer it waits,
+	// so deployments with a low Priority are delayed but never starved.
+	// Priority is unrelated to Tier: Tier decides which ClusterProfile or Profile deploys a resource
+	// when conflicts arise, Priority only decides which deployment runs first.
+	// +kubebuilder:default:=0
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
 	// By default (when ContinueOnConflict is unset or set to false), Sveltos stops deployment after
 	// encountering the first conflict (e.g., another ClusterProfile already deployed the resource).
 	// If set to true, Sveltos will attempt to deploy remaining resources in the ClusterProfile even
@@ -725,6 +920,17 @@ type Spec struct {
 	// +optional
 	StopMatchingBehavior StopMatchingBehavior `json:"stopMatchingBehavior,omitempty"`
 
+	// WithdrawalGuard limits how many clusters add-ons can be withdrawn from at once when
+	// clusters stop matching. Limits not set here default to the ones the addon-controller
+	// is started with.
+	// +optional
+	WithdrawalGuard *WithdrawalGuard `json:"withdrawalGuard,omitempty"`
+
+	// CircuitBreaker stops deployments when the failure ratio across matching clusters
+	// exceeds a threshold. When not set, the addon-controller one (if any) is used.
+	// +optional
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
+
 	// Reloader indicates whether Deployment/StatefulSet/DaemonSet instances deployed
 	// by Sveltos and part of this ClusterProfile need to be restarted via rolling upgrade
 	// when a ConfigMap/Secret instance mounted as volume is modified.
@@ -747,6 +953,8 @@ type Spec struct {
 	// In any managed cluster that matches this ClusterProfile, the add-ons and applications
 	// defined in this instance will not be deployed until all add-ons and applications in the
 	// ClusterProfiles listed as dependencies are deployed.
+	// A Profile can depend on a ClusterProfile using the form "ClusterProfile/<name>". Such a
+	// ClusterProfile is never automatically deployed to the Profile's matching clusters.
 	DependsOn []string `json:"dependsOn,omitempty"`
 
 	// PolicyRefs references all the ConfigMaps/Secrets/Flux Sources containing kubernetes resources
@@ -763,6 +971,14 @@ type Spec struct {
 	// +optional
 	HelmCharts []HelmChart `json:"helmCharts,omitempty"`
 
+	// MaxConcurrentHelmCharts is the maximum number of helm charts installed, upgraded or
+	// uninstalled at the same time in a managed cluster. Charts with no declared ordering
+	// between them are then deployed in parallel.
+	// When not set (or set to 1) charts are deployed sequentially, in the order they are listed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentHelmCharts *int32 `json:"maxConcurrentHelmCharts,omitempty"`
+
 	// Kustomization refs is a list of kustomization paths. Kustomization will
 	// be run on those paths and the outcome will be deployed.
 	// +listType=atomic
@@ -776,6 +992,12 @@ type Spec struct {
 	// +optional
 	ValidateHealths []ValidateHealth `json:"validateHealths,omitempty"`
 
+	// HealthMonitoring, when set, makes Sveltos periodically re-evaluate ValidateHealths (and
+	// optionally the readiness of deployed resources) after features are provisioned.
+	// Features failing those checks are moved to Degraded.
+	// +optional
+	HealthMonitoring *HealthMonitoring `json:"healthMonitoring,omitempty"`
+
 	// Define additional Kustomize inline Patches applied for all resources on this profile
 	// Within the Patch Spec you can use templating
 	// +listType=atomic
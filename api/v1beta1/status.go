@@ -18,8 +18,47 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// DegradedCondition is set to true when at least one feature in one matching
+	// cluster is Degraded.
+	DegradedCondition = "Degraded"
+
+	// WithdrawalBlockedCondition is set to true when add-ons are not withdrawn from clusters
+	// which stopped matching because the WithdrawalGuard limits were exceeded.
+	WithdrawalBlockedCondition = "WithdrawalBlocked"
+
+	// TrippedCondition is set to true when the circuit breaker is tripped and deployments
+	// are stopped. It is set to false when the circuit breaker is reset.
+	TrippedCondition = "Tripped"
+
+	// WaitingForDependentsCondition is set to true when add-ons are not withdrawn from at least one
+	// cluster because profiles depending on this ClusterProfile/Profile are still deployed there.
+	WaitingForDependentsCondition = "WaitingForDependents"
+
+	// WaitingForDependentsReason is the FailureReason of features not withdrawn yet because
+	// profiles depending on the ClusterProfile/Profile are still deployed in the cluster.
+	WaitingForDependentsReason = "WaitingForDependents"
+)
+
+// Plan is the snapshot of a ClusterProfile/Profile change waiting for approval
+type Plan struct {
+	// Hash of the ClusterProfile/Profile Spec this plan was computed for.
+	// An approval must carry this hash.
+	Hash string `json:"hash"`
+
+	// Approved indicates whether this plan has been approved
+	Approved bool `json:"approved"`
+
+	// ClusterReports contains, for each matching cluster not running an approved plan yet,
+	// the ClusterReport listing what this plan would change. Clusters running an approved plan
+	// keep it till this plan is approved. It is only set till the plan is approved.
+	// +optional
+	ClusterReports []corev1.ObjectReference `json:"clusterReports,omitempty"`
+}
+
 // Status defines the observed state of ClusterProfile/Profile
 type Status struct {
 	// MatchingClusterRefs reference all the clusters currently matching
@@ -38,7 +77,18 @@ type Status struct {
 	// +optional
 	UpdatedClusters Clusters `json:"updatedClusters,omitempty"`
 
+	// Plan is set when ApprovalRequired is true. It contains the plan computed for
+	// the current Spec and whether such plan has been approved
+	// +optional
+	Plan *Plan `json:"plan,omitempty"`
+
 	// DependenciesHash is a hash representing the set of clusters where this ClusterProfile
 	// must be deployed, based on the combined configuration of its dependencies.
 	DependenciesHash []byte `json:"dependenciesHash,omitempty"`
+
+	// Conditions contains the latest available observations of the ClusterProfile/Profile state
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
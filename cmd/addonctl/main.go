@@ -0,0 +1,349 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// addonctl renders ClusterProfiles/Profiles offline, using local fixtures in place of
+// the management cluster.
+//
+//	addonctl render --profile profile.yaml --cluster cluster.yaml [--objects fixtures.yaml] \
+//	  [--source GitRepository/flux-system/repo=./repo] [--chart kyverno/kyverno=./charts/kyverno]
+//
+// render prints the rendered manifests of each feature. plan accepts the same flags and prints
+// the list of resources each feature would deploy. With --previous, pointing to the output of
+// a previous render, plan reports resources added, changed or removed.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+const (
+	renderCommand = "render"
+	planCommand   = "plan"
+
+	logsVerbosity = 5
+)
+
+type options struct {
+	profile  string
+	cluster  string
+	objects  []string
+	sources  map[string]string
+	charts   map[string]string
+	previous string
+	verbose  bool
+}
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != renderCommand && os.Args[1] != planCommand) {
+		fmt.Fprintf(os.Stderr, "usage: %s render|plan [flags]\n", filepath.Base(os.Args[0]))
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+	o := &options{}
+	fs := pflag.NewFlagSet(command, pflag.ExitOnError)
+	initFlags(fs, o, command)
+	_ = fs.Parse(os.Args[2:])
+
+	if err := run(context.Background(), command, o, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func initFlags(fs *pflag.FlagSet, o *options, command string) {
+	fs.StringVar(&o.profile, "profile", "",
+		"File containing the ClusterProfile or Profile to render")
+	fs.StringVar(&o.cluster, "cluster", "",
+		"File containing the Cluster or SveltosCluster the profile is rendered for")
+	fs.StringArrayVar(&o.objects, "objects", nil,
+		"File (or directory) with resources standing in for the management cluster: ConfigMaps, Secrets, "+
+			"TemplateResourceRefs, ... Can be repeated")
+	fs.StringToStringVar(&o.sources, "source", nil,
+		"Local directory standing in for a Flux Source, in the form Kind/namespace/name=directory. Can be repeated")
+	fs.StringToStringVar(&o.charts, "chart", nil,
+		"Local chart to use for a helm release, in the form releaseNamespace/releaseName=path. Can be repeated. "+
+			"Charts not specified are pulled from their repository")
+	fs.BoolVar(&o.verbose, "verbose", false, "Log rendering steps to stderr")
+	if command == planCommand {
+		fs.StringVar(&o.previous, "previous", "",
+			"Output of a previous render to compare against")
+	}
+}
+
+func run(ctx context.Context, command string, o *options, w io.Writer) error {
+	if o.profile == "" || o.cluster == "" {
+		return errors.New("--profile and --cluster are required")
+	}
+
+	s, err := controllers.InitScheme()
+	if err != nil {
+		return err
+	}
+
+	profile, err := loadSingleObject(s, o.profile)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := loadSingleObject(s, o.cluster)
+	if err != nil {
+		return err
+	}
+
+	objects := make([]client.Object, 0)
+	for i := range o.objects {
+		current, err := loadObjects(s, o.objects[i])
+		if err != nil {
+			return err
+		}
+		objects = append(objects, current...)
+	}
+
+	logger := logr.Discard()
+	if o.verbose {
+		logger = textlogger.NewLogger(textlogger.NewConfig(textlogger.Verbosity(logsVerbosity),
+			textlogger.Output(os.Stderr)))
+	}
+
+	features, err := controllers.Render(ctx, &controllers.RenderOptions{
+		Profile: profile,
+		Cluster: cluster,
+		Objects: objects,
+		Sources: o.sources,
+		Charts:  o.charts,
+	}, logger)
+	if err != nil {
+		return err
+	}
+
+	if command == renderCommand {
+		return printRendered(features, w)
+	}
+
+	var previous []*unstructured.Unstructured
+	if o.previous != "" {
+		previous, err = readUnstructured(o.previous)
+		if err != nil {
+			return err
+		}
+	}
+	return printPlan(features, previous, o.previous != "", w)
+}
+
+// printRendered prints all rendered resources as a multi-document YAML. Each feature is preceded
+// by a comment identifying it.
+func printRendered(features []controllers.RenderedFeature, w io.Writer) error {
+	for i := range features {
+		f := &features[i]
+		fmt.Fprintf(w, "# Feature: %s\n# Source: %s\n# DeploymentType: %s\n",
+			f.FeatureID, f.Source, getDeploymentType(f))
+		for j := range f.Objects {
+			data, err := yaml.Marshal(f.Objects[j].Object)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "---\n%s", data)
+		}
+	}
+	return nil
+}
+
+// printPlan prints, for each feature, the resources it would deploy. When previous is provided,
+// each resource is flagged as added (+), changed (~) or unchanged, and resources not rendered
+// anymore are reported as removed (-).
+func printPlan(features []controllers.RenderedFeature, previous []*unstructured.Unstructured,
+	compare bool, w io.Writer) error {
+
+	previousByKey := map[string]*unstructured.Unstructured{}
+	for i := range previous {
+		previousByKey[getObjectKey(previous[i])] = previous[i]
+	}
+
+	seen := map[string]bool{}
+	added, changed := 0, 0
+	for i := range features {
+		f := &features[i]
+		fmt.Fprintf(w, "%s %s (%s)\n", f.FeatureID, f.Source, getDeploymentType(f))
+		for j := range f.Objects {
+			key := getObjectKey(f.Objects[j])
+			seen[key] = true
+
+			marker := "+"
+			if compare {
+				if old, ok := previousByKey[key]; !ok {
+					added++
+				} else if reflect.DeepEqual(old.Object, f.Objects[j].Object) {
+					marker = " "
+				} else {
+					marker = "~"
+					changed++
+				}
+			}
+			fmt.Fprintf(w, "  %s %s\n", marker, key)
+		}
+	}
+
+	if !compare {
+		return nil
+	}
+
+	removed := make([]string, 0)
+	for key := range previousByKey {
+		if !seen[key] {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	if len(removed) > 0 {
+		fmt.Fprintln(w, "Removed")
+		for i := range removed {
+			fmt.Fprintf(w, "  - %s\n", removed[i])
+		}
+	}
+
+	fmt.Fprintf(w, "Plan: %d to add, %d to change, %d to remove\n", added, changed, len(removed))
+	return nil
+}
+
+func getDeploymentType(f *controllers.RenderedFeature) string {
+	if f.DeploymentType == "" {
+		return "Remote"
+	}
+	return string(f.DeploymentType)
+}
+
+func getObjectKey(u *unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", u.GroupVersionKind().GroupKind(), u.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", u.GroupVersionKind().GroupKind(), u.GetNamespace(), u.GetName())
+}
+
+func loadSingleObject(s *runtime.Scheme, fileName string) (client.Object, error) {
+	objects, err := loadObjects(s, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) != 1 {
+		return nil, fmt.Errorf("%s: expected exactly one resource, found %d", fileName, len(objects))
+	}
+	return objects[0], nil
+}
+
+// loadObjects reads all resources contained in a file, or in all YAML files of a directory.
+// Resources of kinds known to the addon-controller scheme are converted to typed objects.
+func loadObjects(s *runtime.Scheme, fileName string) ([]client.Object, error) {
+	fileNames := []string{fileName}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		fileNames = make([]string, 0)
+		entries, err := os.ReadDir(fileName)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			ext := filepath.Ext(entries[i].Name())
+			if !entries[i].IsDir() && (ext == ".yaml" || ext == ".yml" || ext == ".json") {
+				fileNames = append(fileNames, filepath.Join(fileName, entries[i].Name()))
+			}
+		}
+	}
+
+	result := make([]client.Object, 0)
+	for i := range fileNames {
+		resources, err := readUnstructured(fileNames[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := range resources {
+			obj, err := toTyped(s, resources[j])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fileNames[i], err)
+			}
+			result = append(result, obj)
+		}
+	}
+
+	return result, nil
+}
+
+func readUnstructured(fileName string) ([]*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*unstructured.Unstructured, 0)
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data))
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		if strings.TrimSpace(u.GetKind()) == "" {
+			return nil, fmt.Errorf("%s: resource without kind", fileName)
+		}
+		result = append(result, u)
+	}
+
+	return result, nil
+}
+
+func toTyped(s *runtime.Scheme, u *unstructured.Unstructured) (client.Object, error) {
+	if !s.Recognizes(u.GroupVersionKind()) {
+		return u, nil
+	}
+
+	obj, err := s.New(u.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return nil, err
+	}
+
+	return obj.(client.Object), nil
+}
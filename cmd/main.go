@@ -59,7 +59,9 @@ import (
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/api/v1beta1/index"
 	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	"github.com/projectsveltos/addon-controller/controllers/dependencymanager"
+	"github.com/projectsveltos/addon-controller/internal/stateapi"
 	"github.com/projectsveltos/addon-controller/internal/telemetry"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/crd"
@@ -77,6 +79,9 @@ var (
 	workers                 int
 	concurrentReconciles    int
 	agentInMgmtCluster      bool
+	driftDetectionPolling   bool
+	pollingInterval         time.Duration
+	pollingConcurrency      int
 	reportMode              controllers.ReportMode
 	tmpReportMode           int
 	restConfigQPS           float32
@@ -94,6 +99,21 @@ var (
 	disableTelemetry        bool
 	autoDeployDependencies  bool
 	registry                string
+	dependencyGraphConfig   string
+	maxWithdrawalClusters   int
+	maxWithdrawalPercentage int
+	circuitBreakerThreshold int
+	circuitBreakerWindow    time.Duration
+	circuitBreakerCoolDown  time.Duration
+	auditLog                string
+	revisionHistoryLimit    int
+	autoShardingEnabled     bool
+	execPluginAllowlist     []string
+	remoteQPS               float32
+	remoteBurst             int
+	maxClusterDeployments   int
+	maxInFlightDeployments  int
+	priorityAgingInterval   time.Duration
 )
 
 const (
@@ -103,6 +123,8 @@ const (
 	defaulReportMode     = int(controllers.CollectFromManagementCluster)
 	mebibytes_bytes      = 1 << 20
 	gibibytes_per_bytes  = 1 << 30
+
+	projectsveltosNamespace = "projectsveltos"
 )
 
 // Add RBAC for the authorized diagnostics endpoint.
@@ -123,6 +145,11 @@ func main() {
 	pflag.Parse()
 
 	reportMode = controllers.ReportMode(tmpReportMode)
+	if autoShardingEnabled && shardKey != "" {
+		setupLog.Error(nil, "--auto-sharding and --shard-key are mutually exclusive")
+		os.Exit(1)
+	}
+
 	disableFor := []client.Object{}
 	byObject := map[client.Object]cache.ByObject{}
 	if disableCaching {
@@ -161,6 +188,15 @@ func main() {
 		PprofBindAddress: profilerAddress,
 	}
 
+	if autoShardingEnabled {
+		// All replicas run the ClusterSummary controller on the clusters assigned to them.
+		// Every other controller runs only on the elected leader.
+		ctrlOptions.LeaderElection = true
+		ctrlOptions.LeaderElectionID = "addon-controller.projectsveltos.io"
+		ctrlOptions.LeaderElectionNamespace = getPodNamespace()
+		controllers.SetAutoSharding(getPodName(), getPodNamespace())
+	}
+
 	restConfig := ctrl.GetConfigOrDie()
 	restConfig.QPS = restConfigQPS
 	restConfig.Burst = restConfigBurst
@@ -173,6 +209,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	// As the diagnostics endpoint is protected when not insecure, the read-only state API and the
+	// profile dependency graph are served there as well. All reads come from the manager cache.
+	if !insecureDiagnostics {
+		if err := mgr.AddMetricsServerExtraHandler(stateapi.BasePath, stateapi.Handler(mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to add state API handler")
+			os.Exit(1)
+		}
+		if err := mgr.AddMetricsServerExtraHandler(dependencymanager.GraphPath, dependencymanager.GraphHandler()); err != nil {
+			setupLog.Error(err, "unable to add dependency graph handler")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info(fmt.Sprintf("diagnostics endpoint is insecure: state API (%s) and dependency graph (%s) are not served",
+			stateapi.BasePath, dependencymanager.GraphPath))
+	}
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 	controllers.SetManagementClusterAccess(mgr.GetClient(), mgr.GetConfig())
@@ -181,9 +233,28 @@ func main() {
 	controllers.SetCAPIOnboardAnnotation(capiOnboardAnnotation)
 	controllers.SetDriftDetectionRegistry(registry)
 	controllers.SetAgentInMgmtCluster(agentInMgmtCluster)
+	controllers.SetDriftDetectionPolling(driftDetectionPolling)
+	controllers.SetWithdrawalGuard(maxWithdrawalClusters, maxWithdrawalPercentage)
+	controllers.SetCircuitBreaker(circuitBreakerThreshold, circuitBreakerWindow, circuitBreakerCoolDown)
+	controllers.SetRevisionHistoryLimit(revisionHistoryLimit)
+	controllers.SetNotifierNamespace(getPodNamespace())
+	clustercache.SetExecPluginAllowlist(execPluginAllowlist)
+	clustercache.SetRemoteRateLimits(remoteQPS, remoteBurst)
+	controllers.SetDeploymentConcurrency(maxClusterDeployments, maxInFlightDeployments)
+	go clustercache.GetManager().RefreshExpiringConfigs(ctx, clustercache.DefaultCredentialRefreshInterval,
+		ctrl.Log.WithName("clustercache"))
+	if err := controllers.SetAuditLog(auditLog); err != nil {
+		setupLog.Error(err, "unable to set up audit log")
+		os.Exit(1)
+	}
 
 	// Start dependency manager
 	dependencymanager.InitializeManagerInstance(ctx, mgr.GetClient(), autoDeployDependencies, ctrl.Log.WithName("dependency_manager"))
+	if dependencyGraphConfig != "" {
+		const dumpInterval = time.Minute
+		go dependencymanager.StartGraphDump(ctx, getPodNamespace(), dependencyGraphConfig, dumpInterval,
+			ctrl.Log.WithName("dependency_graph"))
+	}
 
 	logsettings.RegisterForLogSettings(ctx,
 		libsveltosv1beta1.ComponentAddonManager, ctrl.Log.WithName("log-setter"),
@@ -220,6 +291,16 @@ func initFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&agentInMgmtCluster, "agent-in-mgmt-cluster", false,
 		"When set, indicates drift-detection-manager needs to be started in the management cluster")
 
+	fs.BoolVar(&driftDetectionPolling, "drift-detection-polling", false,
+		"When set, no drift-detection-manager is deployed. The addon-controller periodically compares resources "+
+			"deployed in managed clusters with their recorded hash to detect configuration drifts")
+
+	fs.DurationVar(&pollingInterval, "drift-detection-polling-interval", controllers.DefaultDriftDetectionPollingInterval,
+		"The interval at which configuration drifts are evaluated when --drift-detection-polling is set")
+
+	fs.IntVar(&pollingConcurrency, "drift-detection-polling-concurrency", controllers.DefaultDriftDetectionPollingConcurrency,
+		"The maximum number of resources fetched in parallel from each managed cluster when --drift-detection-polling is set")
+
 	fs.BoolVar(&disableCaching, "disable-secret-caching", false,
 		"When set, disable caching secrets and configmaps")
 
@@ -232,7 +313,9 @@ func initFlags(fs *pflag.FlagSet) {
 			"If --insecure-diagnostics is not set the diagnostics endpoint also serves pprof endpoints")
 
 	fs.BoolVar(&insecureDiagnostics, "insecure-diagnostics", false,
-		"Enable insecure diagnostics serving. For more details see the description of --diagnostics-address.")
+		"Enable insecure diagnostics serving. For more details see the description of --diagnostics-address. "+
+			"When set, the read-only state API and the profile dependency graph are not served, as those expose "+
+			"the state of all profiles and clusters.")
 
 	fs.StringVar(&shardKey, "shard-key", "",
 		"If set, only clusters will annotation matching this shard key will be reconciled by this deployment.")
@@ -308,6 +391,65 @@ func initFlags(fs *pflag.FlagSet) {
 	// configuration inconsistencies.
 	fs.BoolVar(&autoDeployDependencies, "auto-deploy-dependencies", true,
 		" When AutoDeployDependencies is set to true, Sveltos will automatically resolve and deploy the prerequisite profiles specified in the DependsOn field")
+
+	fs.StringVar(&dependencyGraphConfig, "dependency-graph-config", "",
+		"The name of the ConfigMap, in the addon-controller namespace, the profile dependency graph is periodically stored in (JSON and DOT format). "+
+			"If not set, the graph is only served by the diagnostics endpoint")
+
+	fs.IntVar(&maxWithdrawalClusters, "max-withdrawal-clusters", 0,
+		"The maximum number of clusters a ClusterProfile/Profile can withdraw add-ons from at once because "+
+			"clusters stopped matching. Can be overridden per profile. Zero disables this limit")
+
+	fs.IntVar(&maxWithdrawalPercentage, "max-withdrawal-percentage", 0,
+		"The maximum percentage of its clusters a ClusterProfile/Profile can withdraw add-ons from at once because "+
+			"clusters stopped matching. Can be overridden per profile. Zero disables this limit")
+
+	fs.IntVar(&circuitBreakerThreshold, "circuit-breaker-threshold", 0,
+		"The percentage of matching clusters which, when failing within the circuit breaker window, stops "+
+			"deployments of a ClusterProfile/Profile. Can be overridden per profile. Zero disables the circuit breaker")
+
+	fs.DurationVar(&circuitBreakerWindow, "circuit-breaker-window", controllers.DefaultCircuitBreakerWindow,
+		"The time window deployment failures are counted in by the circuit breaker")
+
+	fs.DurationVar(&circuitBreakerCoolDown, "circuit-breaker-cool-down", controllers.DefaultCircuitBreakerCoolDown,
+		"How long a tripped circuit breaker stays tripped before being automatically reset")
+
+	fs.StringVar(&auditLog, "audit-log", "",
+		"The file an audit record, one JSON line, is appended to for every change applied to managed clusters. "+
+			"Use - for standard output. If not set, no audit record is written")
+
+	fs.IntVar(&revisionHistoryLimit, "revision-history-limit", controllers.DefaultRevisionHistoryLimit,
+		"The number of deployed revisions kept per cluster and feature, which deployments can be rolled back to "+
+			"with a Rollback. Zero disables the revision history")
+
+	fs.BoolVar(&autoShardingEnabled, "auto-sharding", false,
+		"When set, replicas of this deployment register themselves with Leases and split managed clusters "+
+			"among themselves using consistent hashing. Cannot be used together with --shard-key")
+
+	fs.StringSliceVar(&execPluginAllowlist, "exec-plugin-allowlist", nil,
+		"Comma separated list of exec credential plugins managed cluster kubeconfigs can use (for instance "+
+			"aws,gke-gcloud-auth-plugin). Kubeconfigs using any other exec plugin are rejected")
+
+	fs.Float32Var(&remoteQPS, "remote-api-qps", 0,
+		"Maximum queries per second to each managed cluster API server, shared by all clients of the cluster. "+
+			"Can be overridden per SveltosCluster with the projectsveltos.io/api-qps annotation. "+
+			"If not set, clients use the managed cluster kubeconfig settings")
+
+	fs.IntVar(&remoteBurst, "remote-api-burst", 0,
+		"Maximum burst of queries to each managed cluster API server. Can be overridden per SveltosCluster "+
+			"with the projectsveltos.io/api-burst annotation")
+
+	fs.IntVar(&maxClusterDeployments, "max-concurrent-deployments-per-cluster", 0,
+		"Maximum number of deployments running in parallel on each managed cluster, across all ClusterSummaries. "+
+			"Can be overridden per SveltosCluster with the projectsveltos.io/max-concurrent-deployments annotation. "+
+			"Zero means no limit")
+
+	fs.IntVar(&maxInFlightDeployments, "max-in-flight-deployments", 0,
+		"Maximum number of deployments running in parallel across all managed clusters. Zero means no limit")
+
+	fs.DurationVar(&priorityAgingInterval, "priority-aging-interval", controllers.DefaultPriorityAgingInterval,
+		"How long a deployment waiting for a deployer worker needs to wait to gain one priority level. "+
+			"Deployments with a higher ClusterProfile/Profile priority are scheduled first")
 }
 
 func setupIndexes(ctx context.Context, mgr ctrl.Manager) {
@@ -468,6 +610,7 @@ func getProfileReconciler(mgr manager.Manager) *controllers.ProfileReconciler {
 		Mux:                  sync.Mutex{},
 		ConcurrentReconciles: concurrentReconciles,
 		Logger:               ctrl.Log.WithName("profilereconciler"),
+		EventRecorder:        mgr.GetEventRecorderFor("profile-controller"),
 	}
 }
 
@@ -482,11 +625,14 @@ func getClusterProfileReconciler(mgr manager.Manager) *controllers.ClusterProfil
 		Mux:                  sync.Mutex{},
 		ConcurrentReconciles: concurrentReconciles,
 		Logger:               ctrl.Log.WithName("clusterprofilereconciler"),
+		EventRecorder:        mgr.GetEventRecorderFor("clusterprofile-controller"),
 	}
 }
 
 func getClusterSummaryReconciler(ctx context.Context, mgr manager.Manager) *controllers.ClusterSummaryReconciler {
-	d := deployer.GetClient(ctx, ctrl.Log.WithName("deployer"), mgr.GetClient(), workers)
+	d := controllers.NewPriorityDeployer(ctx,
+		deployer.GetClient(ctx, ctrl.Log.WithName("deployer"), mgr.GetClient(), workers),
+		workers, priorityAgingInterval, ctrl.Log.WithName("prioritydeployer"))
 	controllers.RegisterFeatures(d, setupLog)
 
 	return &controllers.ClusterSummaryReconciler{
@@ -503,6 +649,10 @@ func getClusterSummaryReconciler(ctx context.Context, mgr manager.Manager) *cont
 		ConcurrentReconciles: concurrentReconciles,
 		ConflictRetryTime:    conflictRetryTime,
 		Logger:               ctrl.Log.WithName("clustersummaryreconciler"),
+		EventRecorder:        mgr.GetEventRecorderFor("clustersummary-controller"),
+
+		DriftDetectionPollingInterval:    pollingInterval,
+		DriftDetectionPollingConcurrency: pollingConcurrency,
 	}
 }
 
@@ -520,6 +670,14 @@ func getSetReconciler(mgr manager.Manager) *controllers.SetReconciler {
 	}
 }
 
+func getProfileSimulationReconciler(mgr manager.Manager) *controllers.ProfileSimulationReconciler {
+	return &controllers.ProfileSimulationReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		ConcurrentReconciles: concurrentReconciles,
+	}
+}
+
 func getClusterSetReconciler(mgr manager.Manager) *controllers.ClusterSetReconciler {
 	return &controllers.ClusterSetReconciler{
 		Client:               mgr.GetClient(),
@@ -534,6 +692,29 @@ func getClusterSetReconciler(mgr manager.Manager) *controllers.ClusterSetReconci
 	}
 }
 
+// getPodName returns the name of this pod, used to identify the replica with automatic sharding
+func getPodName() string {
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		setupLog.Error(err, "unable to get hostname")
+		os.Exit(1)
+	}
+	return hostname
+}
+
+// getPodNamespace returns the namespace of this pod. Leases used by automatic sharding and the
+// profile dependency graph ConfigMap are created there.
+func getPodNamespace() string {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return projectsveltosNamespace
+}
+
 // getDiagnosticsOptions returns metrics options which can be used to configure a Manager.
 func getDiagnosticsOptions() metricsserver.Options {
 	// If "--insecure-diagnostics" is set, serve metrics via http
@@ -620,6 +801,12 @@ func startControllersAndWatchers(ctx context.Context, mgr manager.Manager) {
 			os.Exit(1)
 		}
 		watchersForCAPI = append(watchersForCAPI, setReconciler)
+
+		err = getProfileSimulationReconciler(mgr).SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", configv1beta1.ProfileSimulationKind)
+			os.Exit(1)
+		}
 	}
 
 	clusterSummaryReconciler := getClusterSummaryReconciler(ctx, mgr)
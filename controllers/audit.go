@@ -0,0 +1,350 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Audit log
+// Every time a feature is deployed to (or withdrawn from) a managed cluster, an audit record is
+// appended to the audit log as a JSON line. A record contains:
+// - the ClusterProfile/Profile and the ClusterSummary;
+// - why the deployment was triggered (ClusterSummary spec change, change in a referenced resource,
+// configuration drift, dependencies becoming deployed, ...);
+// - the feature hash before and after the deployment;
+// - the Kubernetes resources and helm releases created, updated or deleted, taken from the same
+// reports used to generate ClusterReports in DryRun mode.
+// Trigger is evaluated by the reconciler when a deployment is queued. Record is written by the
+// worker once deployment completes.
+
+type auditReason string
+
+const (
+	// auditReasonInitial is used the first time a feature is deployed
+	auditReasonInitial = auditReason("InitialDeployment")
+	// auditReasonSpecChange is used when ClusterSummary spec changed
+	auditReasonSpecChange = auditReason("SpecChange")
+	// auditReasonReferencedResourceChange is used when a resource referenced by the ClusterSummary
+	// (ConfigMap, Secret, ...) changed
+	auditReasonReferencedResourceChange = auditReason("ReferencedResourceChange")
+	// auditReasonConfigurationChange is used when the configuration changed but the origin
+	// of the change is not known (for instance after a restart)
+	auditReasonConfigurationChange = auditReason("ConfigurationChange")
+	// auditReasonDrift is used when a configuration drift is remediated
+	auditReasonDrift = auditReason("Drift")
+	// auditReasonDependency is used when deployment proceeds because dependencies got deployed
+	auditReasonDependency = auditReason("Dependency")
+	// auditReasonRetry is used when a deployment is retried with unchanged configuration
+	auditReasonRetry = auditReason("Retry")
+	// auditReasonWithdrawal is used when a feature is withdrawn from a managed cluster
+	auditReasonWithdrawal = auditReason("Withdrawal")
+)
+
+type auditResource struct {
+	Action            string `json:"action"`
+	Group             string `json:"group,omitempty"`
+	Version           string `json:"version,omitempty"`
+	Kind              string `json:"kind"`
+	Namespace         string `json:"namespace,omitempty"`
+	Name              string `json:"name"`
+	ManagementCluster bool   `json:"managementCluster,omitempty"`
+}
+
+type auditRelease struct {
+	Action       string `json:"action"`
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	ChartVersion string `json:"chartVersion,omitempty"`
+}
+
+type auditRecord struct {
+	Time                    time.Time       `json:"time"`
+	ProfileKind             string          `json:"profileKind,omitempty"`
+	ProfileNamespace        string          `json:"profileNamespace,omitempty"`
+	ProfileName             string          `json:"profileName,omitempty"`
+	ClusterSummaryNamespace string          `json:"clusterSummaryNamespace"`
+	ClusterSummaryName      string          `json:"clusterSummaryName"`
+	ClusterType             string          `json:"clusterType"`
+	ClusterNamespace        string          `json:"clusterNamespace"`
+	ClusterName             string          `json:"clusterName"`
+	FeatureID               string          `json:"featureID"`
+	Reason                  auditReason     `json:"reason"`
+	HashBefore              string          `json:"hashBefore,omitempty"`
+	HashAfter               string          `json:"hashAfter,omitempty"`
+	Resources               []auditResource `json:"resources,omitempty"`
+	Releases                []auditRelease  `json:"releases,omitempty"`
+	Error                   string          `json:"error,omitempty"`
+}
+
+// auditTrigger contains why a feature deployment was queued
+type auditTrigger struct {
+	reason     auditReason
+	hashBefore []byte
+	hashAfter  []byte
+	generation int64
+}
+
+var (
+	auditMux    sync.Mutex
+	auditWriter io.Writer
+
+	// key: ClusterSummary namespace/name/featureID
+	auditTriggers = map[string]*auditTrigger{}
+)
+
+// SetAuditLog sets where audit records are appended to. Empty path disables the audit log.
+// "-" means standard output. Any other value is a file audit records are appended to.
+func SetAuditLog(path string) error {
+	var w io.Writer
+	switch path {
+	case "":
+	case "-":
+		w = os.Stdout
+	default:
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open audit log %s: %w", path, err)
+		}
+		w = f
+	}
+
+	auditMux.Lock()
+	defer auditMux.Unlock()
+	if c, ok := auditWriter.(io.Closer); ok && auditWriter != os.Stdout {
+		_ = c.Close()
+	}
+	auditWriter = w
+	return nil
+}
+
+func isAuditEnabled() bool {
+	auditMux.Lock()
+	defer auditMux.Unlock()
+	return auditWriter != nil
+}
+
+func getAuditTriggerKey(clusterSummary *configv1beta1.ClusterSummary, featureID string) string {
+	return fmt.Sprintf("%s/%s/%s", clusterSummary.Namespace, clusterSummary.Name, featureID)
+}
+
+// recordAuditTrigger is invoked when a feature deployment is about to be queued. It stores why
+// the deployment is happening so the record written once deployment completes contains it.
+// A retry of the deployment of the same configuration keeps the original trigger.
+func recordAuditTrigger(clusterSummaryScope *scope.ClusterSummaryScope, featureID configv1beta1.FeatureID,
+	hashBefore, hashAfter []byte, isConfigSame bool) {
+
+	if !isAuditEnabled() {
+		return
+	}
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	key := getAuditTriggerKey(clusterSummary, string(featureID))
+
+	auditMux.Lock()
+	defer auditMux.Unlock()
+
+	previous := auditTriggers[key]
+	if isConfigSame && previous != nil && string(previous.hashAfter) == string(hashAfter) {
+		return
+	}
+
+	var reason auditReason
+	switch {
+	case isDriftPending(clusterSummary.Namespace, clusterSummary.Name, string(featureID)):
+		reason = auditReasonDrift
+	case clusterSummaryScope.AreDependenciesUnblocked():
+		reason = auditReasonDependency
+	case isConfigSame:
+		reason = auditReasonRetry
+	case hashBefore == nil && previous == nil:
+		reason = auditReasonInitial
+	case previous == nil:
+		reason = auditReasonConfigurationChange
+	case previous.generation != clusterSummary.Generation:
+		reason = auditReasonSpecChange
+	default:
+		reason = auditReasonReferencedResourceChange
+	}
+
+	auditTriggers[key] = &auditTrigger{
+		reason:     reason,
+		hashBefore: hashBefore,
+		hashAfter:  hashAfter,
+		generation: clusterSummary.Generation,
+	}
+}
+
+// removeAuditTriggers forgets triggers for a ClusterSummary being deleted
+func removeAuditTriggers(clusterSummary *configv1beta1.ClusterSummary) {
+	auditMux.Lock()
+	defer auditMux.Unlock()
+
+	for _, featureID := range []configv1beta1.FeatureID{configv1beta1.FeatureResources,
+		configv1beta1.FeatureHelm, configv1beta1.FeatureKustomize} {
+
+		delete(auditTriggers, getAuditTriggerKey(clusterSummary, string(featureID)))
+	}
+}
+
+// auditDeployment appends an audit record for a feature deployment. No-op in DryRun mode.
+func auditDeployment(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	localReports, remoteReports []configv1beta1.ResourceReport, releaseReports []configv1beta1.ReleaseReport,
+	deployError error, logger logr.Logger) {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return
+	}
+
+	record := newAuditRecord(clusterSummary, featureID, localReports, remoteReports, releaseReports, deployError)
+
+	auditMux.Lock()
+	if trigger, ok := auditTriggers[getAuditTriggerKey(clusterSummary, string(featureID))]; ok {
+		record.Reason = trigger.reason
+		record.HashBefore = fmt.Sprintf("%x", trigger.hashBefore)
+		record.HashAfter = fmt.Sprintf("%x", trigger.hashAfter)
+	} else {
+		record.Reason = auditReasonConfigurationChange
+	}
+	auditMux.Unlock()
+
+	writeAuditRecord(record, logger)
+}
+
+// auditWithdrawal appends an audit record for a feature withdrawn from a managed cluster.
+// No-op in DryRun mode.
+func auditWithdrawal(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	remoteReports []configv1beta1.ResourceReport, releaseReports []configv1beta1.ReleaseReport,
+	logger logr.Logger) {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return
+	}
+
+	record := newAuditRecord(clusterSummary, featureID, nil, remoteReports, releaseReports, nil)
+	record.Reason = auditReasonWithdrawal
+
+	writeAuditRecord(record, logger)
+}
+
+func newAuditRecord(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	localReports, remoteReports []configv1beta1.ResourceReport, releaseReports []configv1beta1.ReleaseReport,
+	deployError error) *auditRecord {
+
+	record := &auditRecord{
+		Time:                    time.Now().UTC(),
+		ClusterSummaryNamespace: clusterSummary.Namespace,
+		ClusterSummaryName:      clusterSummary.Name,
+		ClusterType:             string(clusterSummary.Spec.ClusterType),
+		ClusterNamespace:        clusterSummary.Spec.ClusterNamespace,
+		ClusterName:             clusterSummary.Spec.ClusterName,
+		FeatureID:               string(featureID),
+	}
+
+	if ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary); err == nil && ownerRef != nil {
+		record.ProfileKind = ownerRef.Kind
+		record.ProfileName = ownerRef.Name
+		if ownerRef.Kind == configv1beta1.ProfileKind {
+			record.ProfileNamespace = clusterSummary.Namespace
+		}
+	}
+
+	record.Resources = append(getAuditResources(localReports, true), getAuditResources(remoteReports, false)...)
+	record.Releases = getAuditReleases(releaseReports)
+
+	if deployError != nil {
+		record.Error = deployError.Error()
+	}
+
+	return record
+}
+
+// getAuditResources returns the resources created, updated or deleted
+func getAuditResources(reports []configv1beta1.ResourceReport, managementCluster bool) []auditResource {
+	resources := make([]auditResource, 0)
+	for i := range reports {
+		switch configv1beta1.ResourceAction(reports[i].Action) {
+		case configv1beta1.CreateResourceAction, configv1beta1.UpdateResourceAction,
+			configv1beta1.DeleteResourceAction:
+		default:
+			continue
+		}
+
+		resource := &reports[i].Resource
+		resources = append(resources, auditResource{
+			Action:            reports[i].Action,
+			Group:             resource.Group,
+			Version:           resource.Version,
+			Kind:              resource.Kind,
+			Namespace:         resource.Namespace,
+			Name:              resource.Name,
+			ManagementCluster: managementCluster,
+		})
+	}
+	return resources
+}
+
+// getAuditReleases returns the helm releases installed, upgraded or uninstalled
+func getAuditReleases(reports []configv1beta1.ReleaseReport) []auditRelease {
+	releases := make([]auditRelease, 0)
+	for i := range reports {
+		switch configv1beta1.HelmAction(reports[i].Action) {
+		case configv1beta1.InstallHelmAction, configv1beta1.UpgradeHelmAction,
+			configv1beta1.UninstallHelmAction, configv1beta1.UpdateHelmValuesAction:
+		default:
+			continue
+		}
+
+		releases = append(releases, auditRelease{
+			Action:       reports[i].Action,
+			Namespace:    reports[i].ReleaseNamespace,
+			Name:         reports[i].ReleaseName,
+			ChartVersion: reports[i].ChartVersion,
+		})
+	}
+	return releases
+}
+
+func writeAuditRecord(record *auditRecord, logger logr.Logger) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to marshal audit record: %v", err))
+		return
+	}
+
+	auditMux.Lock()
+	defer auditMux.Unlock()
+
+	if auditWriter == nil {
+		return
+	}
+
+	if _, err := auditWriter.Write(append(data, '\n')); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to write audit record: %v", err))
+	}
+}
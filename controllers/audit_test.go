@@ -0,0 +1,141 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Audit", func() {
+	var auditLog string
+
+	BeforeEach(func() {
+		auditLog = filepath.Join(GinkgoT().TempDir(), "audit.log")
+		Expect(controllers.SetAuditLog(auditLog)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(controllers.SetAuditLog("")).To(Succeed())
+	})
+
+	readRecords := func() []map[string]interface{} {
+		f, err := os.Open(auditLog)
+		Expect(err).To(BeNil())
+		defer f.Close()
+
+		records := make([]map[string]interface{}, 0)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			record := map[string]interface{}{}
+			Expect(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+			records = append(records, record)
+		}
+		return records
+	}
+
+	It("appends a record with trigger, hashes and changed resources", func() {
+		clusterProfileName := randomString()
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  randomString(),
+				Name:       randomString(),
+				Generation: 1,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1beta1.ClusterProfileKind,
+						Name:       clusterProfileName,
+						APIVersion: configv1beta1.GroupVersion.String(),
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).Build()
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		reports := []configv1beta1.ResourceReport{
+			{
+				Resource: configv1beta1.Resource{Kind: "ConfigMap", Namespace: "default", Name: randomString()},
+				Action:   string(configv1beta1.CreateResourceAction),
+			},
+			{
+				Resource: configv1beta1.Resource{Kind: "Secret", Namespace: "default", Name: randomString()},
+				Action:   string(configv1beta1.NoResourceAction),
+			},
+		}
+
+		controllers.RecordAuditTrigger(clusterSummaryScope, configv1beta1.FeatureResources, nil, []byte{0x01}, false)
+		controllers.AuditDeployment(clusterSummary, configv1beta1.FeatureResources, nil, reports, nil, nil, logger)
+
+		// ClusterSummary spec changes
+		clusterSummary.Generation = 2
+		controllers.RecordAuditTrigger(clusterSummaryScope, configv1beta1.FeatureResources,
+			[]byte{0x01}, []byte{0x02}, false)
+		controllers.AuditDeployment(clusterSummary, configv1beta1.FeatureResources, nil, nil, nil, nil, logger)
+
+		// A resource referenced by the ClusterSummary changes
+		controllers.RecordAuditTrigger(clusterSummaryScope, configv1beta1.FeatureResources,
+			[]byte{0x02}, []byte{0x03}, false)
+		controllers.AuditDeployment(clusterSummary, configv1beta1.FeatureResources, nil, nil, nil, nil, logger)
+
+		records := readRecords()
+		Expect(len(records)).To(Equal(3))
+
+		Expect(records[0]["reason"]).To(Equal("InitialDeployment"))
+		Expect(records[0]["profileKind"]).To(Equal(configv1beta1.ClusterProfileKind))
+		Expect(records[0]["profileName"]).To(Equal(clusterProfileName))
+		Expect(records[0]["clusterName"]).To(Equal(clusterSummary.Spec.ClusterName))
+		Expect(records[0]["hashAfter"]).To(Equal("01"))
+		resources, ok := records[0]["resources"].([]interface{})
+		Expect(ok).To(BeTrue())
+		Expect(len(resources)).To(Equal(1))
+		Expect(resources[0].(map[string]interface{})["kind"]).To(Equal("ConfigMap"))
+
+		Expect(records[1]["reason"]).To(Equal("SpecChange"))
+		Expect(records[1]["hashBefore"]).To(Equal("01"))
+		Expect(records[1]["hashAfter"]).To(Equal("02"))
+
+		Expect(records[2]["reason"]).To(Equal("ReferencedResourceChange"))
+	})
+})
@@ -0,0 +1,593 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/chartmanager"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Automatic sharding
+// With static sharding, each addon-controller deployment is started with a shard key and only manages
+// clusters annotated with that key. With automatic sharding, replicas of the same deployment split
+// clusters among themselves:
+// - every replica registers itself with a Lease, renewed periodically. A replica whose Lease is expired
+// is considered gone (and its Lease is removed);
+// - clusters are assigned to live replicas using consistent hashing, so when a replica joins or leaves
+// only the clusters gained or lost by that replica move;
+// - when membership changes, a replica keeps clusters it lost until the deployer has no deployment in
+// progress for them. Only then it acknowledges the new membership on its own Lease;
+// - a replica starts managing clusters it gained only once all live replicas acknowledged the same
+// membership. Till then, ClusterSummaries for those clusters are requeued.
+// Helm chart registrations for a gained cluster are rebuilt from ClusterSummary Status before its
+// ClusterSummaries are requeued (same as static sharding does on restart), while registrations for a
+// lost cluster are dropped.
+// Scaling out is just a matter of raising the number of replicas.
+
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+
+const (
+	shardLeaseLabel       = "projectsveltos.io/addon-controller-shard"
+	shardLeasePrefix      = "addon-controller-shard-"
+	shardViewAnnotation   = "projectsveltos.io/shard-view"
+	shardRingVirtualNodes = 128
+
+	// shardLeaseDuration is how long a replica is considered alive after last renewing its Lease
+	shardLeaseDuration = 30 * time.Second
+	// shardLeaseRenewInterval is how often Leases are renewed and membership is evaluated
+	shardLeaseRenewInterval = 10 * time.Second
+)
+
+var (
+	// autoSharding is nil when automatic sharding is disabled
+	autoSharding *shardMembership
+)
+
+// shardRing assigns keys to members using consistent hashing. Each member is placed
+// shardRingVirtualNodes times on the ring to spread keys evenly.
+type shardRing struct {
+	points []uint32
+	owners map[uint32]string
+}
+
+// shardedCluster is the cluster managed by a ClusterSummary
+type shardedCluster struct {
+	clusterNamespace string
+	clusterName      string
+	clusterType      libsveltosv1beta1.ClusterType
+}
+
+type shardMembership struct {
+	mu sync.Mutex
+
+	identity  string
+	namespace string
+
+	// members are the live replicas, sorted. view identifies this set of members
+	members []string
+	view    string
+	ring    *shardRing
+
+	// acknowledged is the view this replica acknowledged on its Lease
+	acknowledged string
+	// ready is set once all live replicas acknowledged view
+	ready bool
+
+	// owned contains the ClusterSummaries this replica is currently managing
+	owned map[types.NamespacedName]shardedCluster
+
+	// events is used to requeue ClusterSummaries when cluster assignments change
+	events chan event.GenericEvent
+}
+
+// SetAutoSharding enables automatic sharding. Identity uniquely identifies this replica,
+// namespace is where replica Leases are created. An empty identity disables automatic sharding.
+func SetAutoSharding(identity, namespace string) {
+	if identity == "" {
+		autoSharding = nil
+		return
+	}
+
+	const eventBufferSize = 1024
+	autoSharding = &shardMembership{
+		identity:  identity,
+		namespace: namespace,
+		owned:     make(map[types.NamespacedName]shardedCluster),
+		events:    make(chan event.GenericEvent, eventBufferSize),
+	}
+}
+
+func isAutoShardingEnabled() bool {
+	return autoSharding != nil
+}
+
+func newShardRing(members []string) *shardRing {
+	ring := &shardRing{
+		points: make([]uint32, 0, len(members)*shardRingVirtualNodes),
+		owners: make(map[uint32]string),
+	}
+
+	for _, member := range members {
+		for i := 0; i < shardRingVirtualNodes; i++ {
+			point := hashShardKey(fmt.Sprintf("%s#%d", member, i))
+			owner, ok := ring.owners[point]
+			if !ok {
+				ring.points = append(ring.points, point)
+			} else if owner < member {
+				// On collisions lowest member wins, so all replicas build the very same ring
+				continue
+			}
+			ring.owners[point] = member
+		}
+	}
+
+	sort.Slice(ring.points, func(i, j int) bool { return ring.points[i] < ring.points[j] })
+	return ring
+}
+
+// getOwner returns the member the key is assigned to: the first member found on the ring
+// moving clockwise from the key hash
+func (r *shardRing) getOwner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	h := hashShardKey(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+func hashShardKey(key string) uint32 {
+	h := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint32(h[:4])
+}
+
+func getShardClusterKey(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType) string {
+	return fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName)
+}
+
+func getShardView(members []string) string {
+	h := sha256.Sum256([]byte(strings.Join(members, ",")))
+	return hex.EncodeToString(h[:])
+}
+
+func getShardLeaseName(identity string) string {
+	return shardLeasePrefix + identity
+}
+
+// isClusterAssignedToThisReplica returns true if cluster is assigned to this replica.
+// It always returns true when automatic sharding is disabled.
+func isClusterAssignedToThisReplica(clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType) bool {
+
+	m := autoSharding
+	if m == nil {
+		return true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.isAssigned(clusterNamespace, clusterName, clusterType)
+}
+
+// isAssigned must be called with lock held
+func (m *shardMembership) isAssigned(clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType) bool {
+
+	if m.ring == nil {
+		return false
+	}
+	return m.ring.getOwner(getShardClusterKey(clusterNamespace, clusterName, clusterType)) == m.identity
+}
+
+// isClusterAnAutoShardMatch returns true if this replica must manage the ClusterSummary.
+// An error is returned while the ClusterSummary is being handed off between replicas: either
+// this replica gained the cluster and not all replicas acknowledged the new membership yet, or this
+// replica lost the cluster but still has deployments in progress for it.
+func (r *ClusterSummaryReconciler) isClusterAnAutoShardMatch(clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) (bool, error) {
+
+	m := autoSharding
+	key := types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}
+	cluster := shardedCluster{
+		clusterNamespace: clusterSummary.Spec.ClusterNamespace,
+		clusterName:      clusterSummary.Spec.ClusterName,
+		clusterType:      clusterSummary.Spec.ClusterType,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, isOwned := m.owned[key]
+	if m.isAssigned(cluster.clusterNamespace, cluster.clusterName, cluster.clusterType) {
+		if !isOwned && !m.ready {
+			logger.V(logs.LogDebug).Info("cluster assigned to this replica. Waiting for shard membership acknowledgement")
+			return false, fmt.Errorf("shard membership not acknowledged by all replicas yet")
+		}
+		m.owned[key] = cluster
+		return true, nil
+	}
+
+	if isOwned {
+		if r.isAnyDeploymentInProgress(clusterSummary.Name, &cluster) {
+			logger.V(logs.LogDebug).Info("cluster assigned to another replica. Waiting for deployments in progress")
+			return false, fmt.Errorf("deployments still in progress, cannot hand off cluster")
+		}
+		delete(m.owned, key)
+	}
+
+	return false, nil
+}
+
+// forgetAutoShardClusterSummary removes ClusterSummary from the ones managed by this replica
+func forgetAutoShardClusterSummary(clusterSummary *configv1beta1.ClusterSummary) {
+	m := autoSharding
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.owned, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name})
+}
+
+func (r *ClusterSummaryReconciler) isAnyDeploymentInProgress(clusterSummaryName string, cluster *shardedCluster) bool {
+	for _, featureID := range []configv1beta1.FeatureID{configv1beta1.FeatureResources,
+		configv1beta1.FeatureHelm, configv1beta1.FeatureKustomize} {
+
+		for _, cleanup := range []bool{false, true} {
+			if r.Deployer.IsInProgress(cluster.clusterNamespace, cluster.clusterName, clusterSummaryName,
+				string(featureID), cluster.clusterType, cleanup) {
+
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runAutoSharding periodically renews this replica Lease and evaluates shard membership.
+// When context is cancelled, the Lease is deleted so remaining replicas take over right away.
+func (r *ClusterSummaryReconciler) runAutoSharding(ctx context.Context, logger logr.Logger) {
+	m := autoSharding
+	logger = logger.WithValues("replica", m.identity)
+
+	ticker := time.NewTicker(shardLeaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.updateShardMembership(ctx, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update shard membership: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			deleteCtx, cancel := context.WithTimeout(context.Background(), shardLeaseRenewInterval)
+			lease := &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Namespace: m.namespace, Name: getShardLeaseName(m.identity)},
+			}
+			if err := r.Delete(deleteCtx, lease); err != nil && !apierrors.IsNotFound(err) {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to delete shard lease: %v", err))
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ClusterSummaryReconciler) updateShardMembership(ctx context.Context, logger logr.Logger) error {
+	m := autoSharding
+
+	acknowledgements, err := getLiveShardLeases(ctx, r.Client, m.namespace, logger)
+	if err != nil {
+		return err
+	}
+
+	members := []string{m.identity}
+	for identity := range acknowledgements {
+		if identity != m.identity {
+			members = append(members, identity)
+		}
+	}
+	sort.Strings(members)
+	view := getShardView(members)
+
+	m.mu.Lock()
+	if view != m.view {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("shard membership changed: %s", strings.Join(members, ",")))
+		m.members = members
+		m.view = view
+		m.ring = newShardRing(members)
+		m.ready = false
+	}
+	released, lost, drained := r.releaseClusterSummaries(m)
+	if drained {
+		m.acknowledged = view
+	}
+	acknowledged := m.acknowledged
+	m.mu.Unlock()
+
+	if len(lost) > 0 {
+		chartManager, err := chartmanager.GetChartManagerInstance(ctx, r.Client)
+		if err != nil {
+			return err
+		}
+		for i := range lost {
+			chartManager.RemoveClusterRegistrations(lost[i].clusterNamespace, lost[i].clusterName, lost[i].clusterType)
+		}
+	}
+
+	// ClusterSummaries released by this replica are requeued so internal state gets refreshed
+	requeueShardClusterSummaries(m, released)
+
+	if err := renewShardLease(ctx, r.Client, m, acknowledged); err != nil {
+		return err
+	}
+	acknowledgements[m.identity] = acknowledged
+
+	ready := true
+	for i := range members {
+		if acknowledgements[members[i]] != view {
+			ready = false
+			break
+		}
+	}
+
+	m.mu.Lock()
+	becameReady := ready && !m.ready && m.view == view
+	if !ready && m.view == view {
+		m.ready = false
+	}
+	m.mu.Unlock()
+
+	if !becameReady {
+		return nil
+	}
+
+	logger.V(logs.LogDebug).Info("shard membership acknowledged by all replicas")
+	// ready is set only once assigned ClusterSummaries have been requeued. On failure, requeue
+	// is retried on next tick.
+	if err := r.requeueAssignedClusterSummaries(ctx, m); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if m.view == view {
+		m.ready = true
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+// releaseClusterSummaries stops managing ClusterSummaries whose cluster is not assigned to this
+// replica anymore, unless deployments are still in progress for those. It returns the released
+// ClusterSummaries, the clusters this replica does not manage any ClusterSummary for anymore and
+// whether all ClusterSummaries not assigned to this replica were released.
+// Must be called with lock held.
+func (r *ClusterSummaryReconciler) releaseClusterSummaries(m *shardMembership) (released []types.NamespacedName,
+	lost []shardedCluster, drained bool) {
+
+	drained = true
+	releasedClusters := make(map[shardedCluster]bool)
+	for key := range m.owned {
+		cluster := m.owned[key]
+		if m.isAssigned(cluster.clusterNamespace, cluster.clusterName, cluster.clusterType) {
+			continue
+		}
+		if r.isAnyDeploymentInProgress(key.Name, &cluster) {
+			drained = false
+			continue
+		}
+		delete(m.owned, key)
+		released = append(released, key)
+		releasedClusters[cluster] = true
+	}
+
+	owned := m.getOwnedClusters()
+	for cluster := range releasedClusters {
+		if !owned[cluster] {
+			lost = append(lost, cluster)
+		}
+	}
+
+	return released, lost, drained
+}
+
+// getOwnedClusters returns the clusters this replica is managing at least one ClusterSummary for.
+// Must be called with lock held.
+func (m *shardMembership) getOwnedClusters() map[shardedCluster]bool {
+	owned := make(map[shardedCluster]bool, len(m.owned))
+	for key := range m.owned {
+		owned[m.owned[key]] = true
+	}
+	return owned
+}
+
+// requeueAssignedClusterSummaries requeues all ClusterSummaries whose cluster is assigned to this replica.
+// For clusters this replica just gained, helm chart registrations are first rebuilt from ClusterSummary
+// Status, same as done at startup, so the ClusterSummary currently managing an helm chart stays the manager
+// regardless of the order ClusterSummaries are reconciled.
+func (r *ClusterSummaryReconciler) requeueAssignedClusterSummaries(ctx context.Context, m *shardMembership) error {
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := r.List(ctx, clusterSummaries); err != nil {
+		return err
+	}
+
+	chartManager, err := chartmanager.GetChartManagerInstance(ctx, r.Client)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	owned := m.getOwnedClusters()
+	m.mu.Unlock()
+
+	assigned := make([]types.NamespacedName, 0)
+	gained := make(map[shardedCluster][]configv1beta1.ClusterSummary)
+	for i := range clusterSummaries.Items {
+		cs := &clusterSummaries.Items[i]
+		if !isClusterAssignedToThisReplica(cs.Spec.ClusterNamespace, cs.Spec.ClusterName, cs.Spec.ClusterType) {
+			continue
+		}
+		assigned = append(assigned, types.NamespacedName{Namespace: cs.Namespace, Name: cs.Name})
+		cluster := shardedCluster{
+			clusterNamespace: cs.Spec.ClusterNamespace,
+			clusterName:      cs.Spec.ClusterName,
+			clusterType:      cs.Spec.ClusterType,
+		}
+		if !owned[cluster] {
+			gained[cluster] = append(gained[cluster], *cs)
+		}
+	}
+
+	for cluster := range gained {
+		chartManager.RebuildClusterRegistrations(cluster.clusterNamespace, cluster.clusterName,
+			cluster.clusterType, gained[cluster])
+	}
+
+	requeueShardClusterSummaries(m, assigned)
+	return nil
+}
+
+// requeueShardClusterSummaries requeues ClusterSummaries. Events are sent from a separate goroutine
+// so that a full events channel never delays Lease renewal.
+func requeueShardClusterSummaries(m *shardMembership, clusterSummaries []types.NamespacedName) {
+	if len(clusterSummaries) == 0 {
+		return
+	}
+
+	go func() {
+		for i := range clusterSummaries {
+			m.events <- event.GenericEvent{
+				Object: &configv1beta1.ClusterSummary{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: clusterSummaries[i].Namespace,
+						Name:      clusterSummaries[i].Name,
+					},
+				},
+			}
+		}
+	}()
+}
+
+// getLiveShardLeases returns, for each live replica, the membership view it acknowledged.
+// Expired Leases are deleted.
+func getLiveShardLeases(ctx context.Context, c client.Client, namespace string,
+	logger logr.Logger) (map[string]string, error) {
+
+	leases := &coordinationv1.LeaseList{}
+	if err := c.List(ctx, leases, client.InNamespace(namespace), client.HasLabels{shardLeaseLabel}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	acknowledgements := make(map[string]string)
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if lease.Spec.HolderIdentity == nil {
+			continue
+		}
+
+		if isShardLeaseExpired(lease, now) {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("removing expired shard lease %s", lease.Name))
+			if err := c.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+				logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to delete lease %s: %v", lease.Name, err))
+			}
+			continue
+		}
+
+		acknowledgements[*lease.Spec.HolderIdentity] = lease.Annotations[shardViewAnnotation]
+	}
+
+	return acknowledgements, nil
+}
+
+func isShardLeaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil {
+		return true
+	}
+
+	duration := shardLeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return lease.Spec.RenewTime.Add(duration).Before(now)
+}
+
+// renewShardLease creates or renews this replica Lease recording the acknowledged membership view
+func renewShardLease(ctx context.Context, c client.Client, m *shardMembership, acknowledged string) error {
+	now := metav1.NewMicroTime(time.Now())
+	duration := int32(shardLeaseDuration.Seconds())
+
+	lease := &coordinationv1.Lease{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: m.namespace, Name: getShardLeaseName(m.identity)}, lease)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   m.namespace,
+				Name:        getShardLeaseName(m.identity),
+				Labels:      map[string]string{shardLeaseLabel: "ok"},
+				Annotations: map[string]string{shardViewAnnotation: acknowledged},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &m.identity,
+				LeaseDurationSeconds: &duration,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		return c.Create(ctx, lease)
+	}
+
+	if lease.Annotations == nil {
+		lease.Annotations = make(map[string]string)
+	}
+	lease.Annotations[shardViewAnnotation] = acknowledged
+	lease.Spec.HolderIdentity = &m.identity
+	lease.Spec.LeaseDurationSeconds = &duration
+	lease.Spec.RenewTime = &now
+	return c.Update(ctx, lease)
+}
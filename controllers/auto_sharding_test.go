@@ -0,0 +1,250 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/controllers/chartmanager"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	fakedeployer "github.com/projectsveltos/libsveltos/lib/deployer/fake"
+)
+
+var _ = Describe("Auto sharding", func() {
+	AfterEach(func() {
+		controllers.SetAutoSharding("", "")
+	})
+
+	It("assigns clusters with consistent hashing, moving only clusters of joining/leaving replicas", func() {
+		const clusters = 1000
+		members := []string{"replica-a", "replica-b", "replica-c"}
+
+		owners := make(map[string]string)
+		count := make(map[string]int)
+		for i := 0; i < clusters; i++ {
+			key := fmt.Sprintf("cluster-%d", i)
+			owners[key] = controllers.GetShardOwner(members, key)
+			Expect(members).To(ContainElement(owners[key]))
+			// Ring is deterministic, so all replicas agree on assignments
+			Expect(controllers.GetShardOwner([]string{"replica-c", "replica-a", "replica-b"}, key)).To(Equal(owners[key]))
+			count[owners[key]]++
+		}
+
+		for i := range members {
+			Expect(count[members[i]]).To(BeNumerically(">", clusters/6))
+		}
+
+		// A replica joins: clusters either stay or move to the new replica
+		joined := append([]string{"replica-d"}, members...)
+		for key, owner := range owners {
+			newOwner := controllers.GetShardOwner(joined, key)
+			Expect(newOwner == owner || newOwner == "replica-d").To(BeTrue())
+		}
+
+		// A replica leaves: only its clusters move
+		left := []string{"replica-a", "replica-c"}
+		for key, owner := range owners {
+			if owner != "replica-b" {
+				Expect(controllers.GetShardOwner(left, key)).To(Equal(owner))
+			}
+		}
+	})
+
+	It("registers replica with a Lease and waits for all replicas to acknowledge membership", func() {
+		namespace := randomString()
+		controllers.SetAutoSharding("replica-a", namespace)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		dep := fakedeployer.GetClient(context.TODO(), textlogger.NewLogger(textlogger.NewConfig()), c)
+		reconciler := getClusterSummaryReconciler(c, dep)
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		Expect(controllers.IsClusterAssignedToThisReplica(randomString(), randomString(),
+			libsveltosv1beta1.ClusterTypeCapi)).To(BeFalse())
+
+		Expect(reconciler.UpdateShardMembership(context.TODO(), logger)).To(Succeed())
+
+		lease := &coordinationv1.Lease{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "addon-controller-shard-replica-a"},
+			lease)).To(Succeed())
+		Expect(*lease.Spec.HolderIdentity).To(Equal("replica-a"))
+		Expect(lease.Annotations["projectsveltos.io/shard-view"]).ToNot(BeEmpty())
+
+		// Single replica: all clusters are assigned to it
+		Expect(controllers.IsClusterAssignedToThisReplica(randomString(), randomString(),
+			libsveltosv1beta1.ClusterTypeCapi)).To(BeTrue())
+
+		// An expired Lease from a gone replica is removed
+		now := metav1.NewMicroTime(time.Now())
+		expired := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+		for _, l := range []struct {
+			identity  string
+			renewTime *metav1.MicroTime
+		}{{"replica-b", &now}, {"replica-c", &expired}} {
+			Expect(c.Create(context.TODO(), &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      "addon-controller-shard-" + l.identity,
+					Labels:    map[string]string{"projectsveltos.io/addon-controller-shard": "ok"},
+				},
+				Spec: coordinationv1.LeaseSpec{
+					HolderIdentity:       ptr.To(l.identity),
+					LeaseDurationSeconds: ptr.To(int32(30)),
+					RenewTime:            l.renewTime,
+				},
+			})).To(Succeed())
+		}
+
+		Expect(reconciler.UpdateShardMembership(context.TODO(), logger)).To(Succeed())
+
+		leases := &coordinationv1.LeaseList{}
+		Expect(c.List(context.TODO(), leases)).To(Succeed())
+		Expect(len(leases.Items)).To(Equal(2))
+
+		// replica-b has not acknowledged new membership yet, still clusters are already assigned
+		// using the new membership.
+		assigned := 0
+		for i := 0; i < 100; i++ {
+			if controllers.IsClusterAssignedToThisReplica(randomString(), randomString(),
+				libsveltosv1beta1.ClusterTypeCapi) {
+
+				assigned++
+			}
+		}
+		Expect(assigned).To(BeNumerically(">", 0))
+		Expect(assigned).To(BeNumerically("<", 100))
+	})
+	It("becomes ready only after assigned ClusterSummaries are requeued", func() {
+		namespace := randomString()
+		controllers.SetAutoSharding("replica-a", namespace)
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+
+		failList := true
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).
+			WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, cl client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if _, ok := list.(*configv1beta1.ClusterSummaryList); ok && failList {
+						return fmt.Errorf("cache not started")
+					}
+					return cl.List(ctx, list, opts...)
+				},
+			}).Build()
+		dep := fakedeployer.GetClient(context.TODO(), textlogger.NewLogger(textlogger.NewConfig()), c)
+		reconciler := getClusterSummaryReconciler(c, dep)
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		Expect(reconciler.UpdateShardMembership(context.TODO(), logger)).ToNot(Succeed())
+		Expect(controllers.IsShardMembershipReady()).To(BeFalse())
+
+		// Requeue is retried on next tick
+		failList = false
+		Expect(reconciler.UpdateShardMembership(context.TODO(), logger)).To(Succeed())
+		Expect(controllers.IsShardMembershipReady()).To(BeTrue())
+
+		var e event.GenericEvent
+		Eventually(controllers.GetShardEvents()).Should(Receive(&e))
+		Expect(e.Object.GetNamespace()).To(Equal(clusterSummary.Namespace))
+		Expect(e.Object.GetName()).To(Equal(clusterSummary.Name))
+	})
+	It("rebuilds helm chart registrations of gained clusters from ClusterSummary Status", func() {
+		controllers.SetAutoSharding("replica-a", randomString())
+
+		clusterNamespace := randomString()
+		clusterName := randomString()
+		chart := configv1beta1.HelmChart{
+			RepositoryURL:    randomString(),
+			RepositoryName:   randomString(),
+			ChartName:        randomString(),
+			ChartVersion:     randomString(),
+			ReleaseName:      randomString(),
+			ReleaseNamespace: randomString(),
+		}
+
+		getClusterSummary := func(status configv1beta1.HelmChartStatus) *configv1beta1.ClusterSummary {
+			return &configv1beta1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{Namespace: clusterNamespace, Name: randomString()},
+				Spec: configv1beta1.ClusterSummarySpec{
+					ClusterNamespace: clusterNamespace,
+					ClusterName:      clusterName,
+					ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+					ClusterProfileSpec: configv1beta1.Spec{
+						HelmCharts: []configv1beta1.HelmChart{chart},
+					},
+				},
+				Status: configv1beta1.ClusterSummaryStatus{
+					HelmReleaseSummaries: []configv1beta1.HelmChartSummary{
+						{
+							ReleaseName:      chart.ReleaseName,
+							ReleaseNamespace: chart.ReleaseNamespace,
+							Status:           status,
+						},
+					},
+				},
+			}
+		}
+		manager := getClusterSummary(configv1beta1.HelmChartStatusManaging)
+		other := getClusterSummary(configv1beta1.HelmChartStatusConflict)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(manager, other).
+			WithStatusSubresource(manager, other).Build()
+		dep := fakedeployer.GetClient(context.TODO(), textlogger.NewLogger(textlogger.NewConfig()), c)
+		reconciler := getClusterSummaryReconciler(c, dep)
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		chartManager, err := chartmanager.GetChartManagerInstance(context.TODO(), c)
+		Expect(err).To(BeNil())
+		// Stale registrations, in reverse order, from when this replica managed the cluster earlier
+		chartManager.RegisterClusterSummaryForCharts(other)
+		chartManager.RegisterClusterSummaryForCharts(manager)
+
+		Expect(reconciler.UpdateShardMembership(context.TODO(), logger)).To(Succeed())
+
+		// ClusterSummaries are reconciled in reverse order
+		chartManager.RegisterClusterSummaryForCharts(other)
+		chartManager.RegisterClusterSummaryForCharts(manager)
+
+		managerName, err := chartManager.GetManagerForChart(clusterNamespace, clusterName,
+			libsveltosv1beta1.ClusterTypeCapi, &chart)
+		Expect(err).To(BeNil())
+		Expect(managerName).To(Equal(manager.Name))
+	})
+})
@@ -525,6 +525,41 @@ func (m *instance) rebuildRegistrations(ctx context.Context, c client.Client) er
 	return nil
 }
 
+// RebuildClusterRegistrations rebuilds internal structures for a managed cluster, relying completely on
+// ClusterSummary.Status, same as done at startup: ClusterSummaries currently managing helm charts are
+// registered first. Any existing registration for the cluster is dropped.
+// clusterSummaries are all the ClusterSummaries for the managed cluster.
+func (m *instance) RebuildClusterRegistrations(clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, clusterSummaries []configv1beta1.ClusterSummary) {
+
+	clusterKey := m.getClusterKey(clusterNamespace, clusterName, clusterType)
+
+	m.chartMux.Lock()
+	defer m.chartMux.Unlock()
+
+	delete(m.perClusterChartMap, clusterKey)
+
+	for i := range clusterSummaries {
+		m.addManagers(&clusterSummaries[i])
+	}
+
+	for i := range clusterSummaries {
+		m.addNonManagers(&clusterSummaries[i])
+	}
+}
+
+// RemoveClusterRegistrations drops all registrations for a managed cluster
+func (m *instance) RemoveClusterRegistrations(clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType) {
+
+	clusterKey := m.getClusterKey(clusterNamespace, clusterName, clusterType)
+
+	m.chartMux.Lock()
+	defer m.chartMux.Unlock()
+
+	delete(m.perClusterChartMap, clusterKey)
+}
+
 // addManagers walks clusterSummary's status and registers it for each helm chart currently managed
 func (m *instance) addManagers(clusterSummary *configv1beta1.ClusterSummary) {
 	clusterKey := m.getClusterKey(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
@@ -0,0 +1,274 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+)
+
+// Circuit breaker
+// When a ClusterProfile/Profile change (a bad chart version for instance) breaks many clusters,
+// the deployer would keep retrying every failing cluster. The circuit breaker counts matching
+// clusters with at least one feature failed within a time window. When their percentage, out of
+// the matching clusters where deployment completed, exceeds the threshold, the circuit breaker trips:
+// - the Tripped condition is set with a sample of the errors;
+// - ClusterSummaries are not created/updated anymore, so clusters not updated yet keep the
+// previous configuration;
+// - the ClusterSummary controller does not deploy (and so does not retry) anymore.
+// The circuit breaker is reset either by the ResetCircuitBreakerAnnotation or once CoolDown elapsed.
+// Tripped condition is then set to false. Only failures after the reset are counted from then on.
+// Failure ratio is evaluated as soon as deployment completed in a minimum sample of clusters, not
+// over all matching clusters. Without MaxUpdate, all matching clusters are updated at once; waiting for
+// all of those to complete would let the circuit breaker trip only once the whole fleet was hit.
+
+const (
+	circuitBreakerTrippedReason   = "FailureThresholdExceeded"
+	circuitBreakerResetReason     = "Reset"
+	circuitBreakerCoolDownReason  = "CoolDownElapsed"
+	maxCircuitBreakerErrorSamples = 3
+
+	// minCircuitBreakerSample is the minimum number of clusters where deployment must have completed
+	// for the failure ratio to be evaluated (all matching clusters, when fewer)
+	minCircuitBreakerSample = 5
+
+	// circuitBreakerRequeueAfter is how often a ClusterProfile/Profile, and its ClusterSummaries,
+	// verify whether the tripped circuit breaker can be reset and deployments resumed
+	circuitBreakerRequeueAfter = time.Minute
+
+	DefaultCircuitBreakerWindow   = 10 * time.Minute
+	DefaultCircuitBreakerCoolDown = 30 * time.Minute
+)
+
+var (
+	// Default circuit breaker. Zero threshold disables it.
+	circuitBreakerThreshold int32
+	circuitBreakerWindow    = DefaultCircuitBreakerWindow
+	circuitBreakerCoolDown  = DefaultCircuitBreakerCoolDown
+)
+
+// SetCircuitBreaker sets the circuit breaker used by ClusterProfiles/Profiles not defining one.
+// A zero threshold disables it.
+func SetCircuitBreaker(threshold int, window, coolDown time.Duration) {
+	circuitBreakerThreshold = int32(threshold)
+	circuitBreakerWindow = window
+	circuitBreakerCoolDown = coolDown
+}
+
+// getCircuitBreakerSettings returns the circuit breaker settings for a ClusterProfile/Profile
+func getCircuitBreakerSettings(spec *configv1beta1.Spec) (threshold int32, window, coolDown time.Duration) {
+	if spec.CircuitBreaker == nil {
+		return circuitBreakerThreshold, circuitBreakerWindow, circuitBreakerCoolDown
+	}
+
+	threshold, window, coolDown = spec.CircuitBreaker.FailureThreshold, circuitBreakerWindow, circuitBreakerCoolDown
+	if spec.CircuitBreaker.Window != nil {
+		window = spec.CircuitBreaker.Window.Duration
+	}
+	if spec.CircuitBreaker.CoolDown != nil {
+		coolDown = spec.CircuitBreaker.CoolDown.Duration
+	}
+	return threshold, window, coolDown
+}
+
+// isCircuitBreakerTripped returns true if the Tripped condition is set
+func isCircuitBreakerTripped(status *configv1beta1.Status) bool {
+	return meta.IsStatusConditionTrue(status.Conditions, configv1beta1.TrippedCondition)
+}
+
+// evaluateCircuitBreaker resets a tripped circuit breaker (when requested via annotation or once
+// CoolDown elapsed) and trips it when the failure ratio exceeds the threshold.
+// Returns true if the circuit breaker is tripped.
+func evaluateCircuitBreaker(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) (bool, error) {
+	status := profileScope.GetStatus()
+	threshold, window, coolDown := getCircuitBreakerSettings(profileScope.GetSpec())
+	if threshold == 0 {
+		meta.RemoveStatusCondition(&status.Conditions, configv1beta1.TrippedCondition)
+		return false, nil
+	}
+
+	resetRequested := false
+	annotations := profileScope.Profile.GetAnnotations()
+	if _, ok := annotations[configv1beta1.ResetCircuitBreakerAnnotation]; ok {
+		resetRequested = true
+		delete(annotations, configv1beta1.ResetCircuitBreakerAnnotation)
+		profileScope.Profile.SetAnnotations(annotations)
+	}
+
+	now := time.Now()
+	if condition := meta.FindStatusCondition(status.Conditions, configv1beta1.TrippedCondition); condition != nil &&
+		condition.Status == metav1.ConditionTrue {
+
+		switch {
+		case resetRequested:
+			resetCircuitBreaker(profileScope, circuitBreakerResetReason, "circuit breaker reset by annotation")
+		case now.After(condition.LastTransitionTime.Add(coolDown)):
+			resetCircuitBreaker(profileScope, circuitBreakerCoolDownReason, "circuit breaker reset after cool-down")
+		default:
+			return true, nil
+		}
+	}
+
+	matchingClusters := len(status.MatchingClusterRefs)
+	if matchingClusters == 0 {
+		return false, nil
+	}
+
+	// Only failures within window and after last reset are counted
+	since := now.Add(-window)
+	if condition := meta.FindStatusCondition(status.Conditions, configv1beta1.TrippedCondition); condition != nil &&
+		condition.LastTransitionTime.After(since) {
+
+		since = condition.LastTransitionTime.Time
+	}
+
+	clusterSummaries, err := getProfileClusterSummaries(ctx, c, profileScope.GetKind(),
+		profileScope.Profile.GetNamespace(), profileScope.Name())
+	if err != nil {
+		return false, err
+	}
+
+	failures := make([]string, 0)
+	completed := 0
+	for i := range clusterSummaries.Items {
+		cs := &clusterSummaries.Items[i]
+		if !isDeploymentCompleted(cs) {
+			continue
+		}
+		completed++
+		if failure := getRecentFailure(cs, since); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+
+	if completed < min(minCircuitBreakerSample, matchingClusters) {
+		return false, nil
+	}
+
+	if len(failures)*100 <= int(threshold)*completed {
+		return false, nil
+	}
+
+	samples := failures
+	if len(samples) > maxCircuitBreakerErrorSamples {
+		samples = samples[:maxCircuitBreakerErrorSamples]
+	}
+	profileScope.Logger.Info(fmt.Sprintf("circuit breaker tripped: %d out of %d clusters failed",
+		len(failures), completed))
+	recordWarningEvent(ctx, profileScope.Profile, eventReasonCircuitBreakerTripped,
+		"%d out of %d clusters failed within %s. Deployments stopped", len(failures), completed, window)
+	queueNotification(newProfileNotification(profileScope.Profile, configv1beta1.NotificationTransitionRolloutHalted,
+		fmt.Sprintf("circuit breaker tripped: %d out of %d clusters failed within %s", len(failures),
+			completed, window)), profileScope.Logger)
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:   configv1beta1.TrippedCondition,
+		Status: metav1.ConditionTrue,
+		Reason: circuitBreakerTrippedReason,
+		Message: fmt.Sprintf("%d out of %d clusters failed within %s. Sample errors: %s",
+			len(failures), completed, window, strings.Join(samples, "; ")),
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	})
+	return true, nil
+}
+
+func resetCircuitBreaker(profileScope *scope.ProfileScope, reason, message string) {
+	profileScope.Logger.Info(message)
+	meta.SetStatusCondition(&profileScope.GetStatus().Conditions, metav1.Condition{
+		Type:               configv1beta1.TrippedCondition,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	})
+}
+
+// isDeploymentCompleted returns true if deployment of all features of the ClusterSummary completed,
+// either successfully or not
+func isDeploymentCompleted(clusterSummary *configv1beta1.ClusterSummary) bool {
+	if !clusterSummary.DeletionTimestamp.IsZero() || len(clusterSummary.Status.FeatureSummaries) == 0 {
+		return false
+	}
+
+	for i := range clusterSummary.Status.FeatureSummaries {
+		switch clusterSummary.Status.FeatureSummaries[i].Status {
+		case configv1beta1.FeatureStatusProvisioned, configv1beta1.FeatureStatusFailed,
+			configv1beta1.FeatureStatusFailedNonRetriable:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// getRecentFailure returns, if any feature of the ClusterSummary failed after since, the cluster
+// along with the failure message. Returns an empty string otherwise.
+func getRecentFailure(clusterSummary *configv1beta1.ClusterSummary, since time.Time) string {
+	if !clusterSummary.DeletionTimestamp.IsZero() {
+		return ""
+	}
+
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		if fs.Status != configv1beta1.FeatureStatusFailed && fs.Status != configv1beta1.FeatureStatusFailedNonRetriable {
+			continue
+		}
+		if fs.LastAppliedTime == nil || !fs.LastAppliedTime.After(since) {
+			continue
+		}
+		message := ""
+		if fs.FailureMessage != nil {
+			message = *fs.FailureMessage
+		}
+		return fmt.Sprintf("%s:%s/%s %s: %s", clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, fs.FeatureID, message)
+	}
+
+	return ""
+}
+
+// isProfileCircuitBreakerTripped returns true if the circuit breaker of the ClusterProfile/Profile
+// owning the ClusterSummary is tripped
+func isProfileCircuitBreakerTripped(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary) (bool, error) {
+
+	ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || ownerRef == nil {
+		return false, err
+	}
+
+	ref := &configv1beta1.ProfileReference{Kind: ownerRef.Kind, Name: ownerRef.Name}
+	if ownerRef.Kind == configv1beta1.ProfileKind {
+		ref.Namespace = clusterSummary.Namespace
+	}
+
+	_, _, status, err := getReferencedProfile(ctx, c, ref)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	return isCircuitBreakerTripped(status), nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	It("evaluateCircuitBreaker trips above the failure threshold and is reset by annotation", func() {
+		namespace := randomString()
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				CircuitBreaker: &configv1beta1.CircuitBreaker{FailureThreshold: 40},
+			},
+		}
+
+		initObjects := []client.Object{clusterProfile}
+		failureMessage := "chart version not found"
+		for i := 0; i < 2; i++ {
+			cluster := corev1.ObjectReference{
+				Namespace: namespace, Name: randomString(),
+				Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			}
+			clusterProfile.Status.MatchingClusterRefs = append(clusterProfile.Status.MatchingClusterRefs, cluster)
+
+			// Only first cluster failed
+			featureStatus := configv1beta1.FeatureStatusProvisioned
+			if i == 0 {
+				featureStatus = configv1beta1.FeatureStatusFailed
+			}
+			initObjects = append(initObjects, &configv1beta1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      randomString(),
+					Labels:    map[string]string{controllers.ClusterProfileLabelName: clusterProfile.Name},
+				},
+				Spec: configv1beta1.ClusterSummarySpec{
+					ClusterNamespace: namespace,
+					ClusterName:      cluster.Name,
+					ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				},
+				Status: configv1beta1.ClusterSummaryStatus{
+					FeatureSummaries: []configv1beta1.FeatureSummary{
+						{
+							FeatureID: configv1beta1.FeatureHelm, Status: featureStatus,
+							FailureMessage: &failureMessage, LastAppliedTime: &metav1.Time{Time: time.Now()},
+						},
+					},
+				},
+			})
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// 1 out of 2 clusters failed: 50% is above 40%
+		tripped, err := controllers.EvaluateCircuitBreaker(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		Expect(tripped).To(BeTrue())
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.TrippedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring(failureMessage))
+
+		// Reset by annotation. Annotation is removed and failures before reset are not counted anymore
+		clusterProfile.Annotations = map[string]string{configv1beta1.ResetCircuitBreakerAnnotation: "ok"}
+		tripped, err = controllers.EvaluateCircuitBreaker(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		Expect(tripped).To(BeFalse())
+		Expect(clusterProfile.Annotations).ToNot(HaveKey(configv1beta1.ResetCircuitBreakerAnnotation))
+		Expect(meta.IsStatusConditionFalse(clusterProfile.Status.Conditions, configv1beta1.TrippedCondition)).To(BeTrue())
+
+		// Circuit breaker disabled
+		clusterProfile.Spec.CircuitBreaker = nil
+		tripped, err = controllers.EvaluateCircuitBreaker(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		Expect(tripped).To(BeFalse())
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.TrippedCondition)).To(BeNil())
+	})
+
+	It("evaluateCircuitBreaker evaluates failure ratio over clusters where deployment completed", func() {
+		namespace := randomString()
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				// No MaxUpdate: all matching clusters are updated at once
+				CircuitBreaker: &configv1beta1.CircuitBreaker{FailureThreshold: 20},
+			},
+		}
+
+		failureMessage := "chart version not found"
+		clusterSummaries := make([]*configv1beta1.ClusterSummary, 10)
+		initObjects := []client.Object{clusterProfile}
+		for i := range clusterSummaries {
+			cluster := corev1.ObjectReference{
+				Namespace: namespace, Name: randomString(),
+				Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			}
+			clusterProfile.Status.MatchingClusterRefs = append(clusterProfile.Status.MatchingClusterRefs, cluster)
+
+			// First two clusters failed, deployment is still in progress in all others
+			featureStatus := configv1beta1.FeatureStatusProvisioning
+			if i < 2 {
+				featureStatus = configv1beta1.FeatureStatusFailed
+			}
+			clusterSummaries[i] = &configv1beta1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      randomString(),
+					Labels:    map[string]string{controllers.ClusterProfileLabelName: clusterProfile.Name},
+				},
+				Spec: configv1beta1.ClusterSummarySpec{
+					ClusterNamespace: namespace,
+					ClusterName:      cluster.Name,
+					ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				},
+				Status: configv1beta1.ClusterSummaryStatus{
+					FeatureSummaries: []configv1beta1.FeatureSummary{
+						{
+							FeatureID: configv1beta1.FeatureHelm, Status: featureStatus,
+							FailureMessage: &failureMessage, LastAppliedTime: &metav1.Time{Time: time.Now()},
+						},
+					},
+				},
+			}
+			initObjects = append(initObjects, clusterSummaries[i])
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// Deployment completed in only 2 clusters: sample is too small
+		tripped, err := controllers.EvaluateCircuitBreaker(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		Expect(tripped).To(BeFalse())
+
+		// Deployment completed successfully in 3 more clusters: 2 out of 5 (40%) failed
+		for i := 2; i < 5; i++ {
+			clusterSummaries[i].Status.FeatureSummaries[0].Status = configv1beta1.FeatureStatusProvisioned
+			Expect(c.Status().Update(context.TODO(), clusterSummaries[i])).To(Succeed())
+		}
+		tripped, err = controllers.EvaluateCircuitBreaker(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		Expect(tripped).To(BeTrue())
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.TrippedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Message).To(ContainSubstring("2 out of 5 clusters failed"))
+	})
+
+	It("updateClusterSummaries does not create ClusterSummaries while circuit breaker is tripped", func() {
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Status:     libsveltosv1beta1.SveltosClusterStatus{Ready: true},
+		}
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				SyncMode: configv1beta1.SyncModeContinuous,
+			},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{
+					{
+						Namespace: sveltosCluster.Namespace, Name: sveltosCluster.Name,
+						Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+					},
+				},
+				Conditions: []metav1.Condition{
+					{
+						Type: configv1beta1.TrippedCondition, Status: metav1.ConditionTrue,
+						Reason: "FailureThresholdExceeded", LastTransitionTime: metav1.Now(),
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile, sveltosCluster).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// A tripped circuit breaker is not a reconciliation error
+		Expect(controllers.UpdateClusterSummaries(context.TODO(), c, profileScope)).To(Succeed())
+
+		clusterSummaries := &configv1beta1.ClusterSummaryList{}
+		Expect(c.List(context.TODO(), clusterSummaries)).To(Succeed())
+		Expect(clusterSummaries.Items).To(BeEmpty())
+	})
+})
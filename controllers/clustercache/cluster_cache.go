@@ -19,11 +19,14 @@ package clustercache
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2/textlogger"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/secret"
@@ -43,7 +46,7 @@ var (
 type clusterCache struct {
 	rwMux sync.RWMutex
 	// Keeps cache of rest.Config for existing clusters
-	configs map[corev1.ObjectReference]*rest.Config
+	configs map[corev1.ObjectReference]*cachedConfig
 
 	// key: cluster, value: Secret with kubeconfig
 	clusters map[corev1.ObjectReference]*corev1.ObjectReference
@@ -51,8 +54,32 @@ type clusterCache struct {
 	// key: secret, value: set of clusters
 	// A secret can potentially contain kubeconfig for one or more clusters
 	secrets map[corev1.ObjectReference]*libsveltosset.Set
+
+	// key: cluster, value: rate limiter shared by all clients of the cluster
+	limiters map[corev1.ObjectReference]*clusterRateLimiter
+
+	// handlers invoked every time a cluster is removed from the cache (either because
+	// cluster is gone or because the Secret with its kubeconfig changed)
+	handlers []RemoveClusterHandler
+}
+
+// cachedConfig is the rest.Config cached for a cluster
+type cachedConfig struct {
+	config *rest.Config
+
+	// refreshAt is when credentials must be refreshed. Zero if credentials never expire
+	refreshAt time.Time
 }
 
+func (c *cachedConfig) needsRefresh(now time.Time) bool {
+	return !c.refreshAt.IsZero() && !now.Before(c.refreshAt)
+}
+
+// RemoveClusterHandler is invoked when cached data for a cluster is removed.
+// Components keeping long lived connections to a managed cluster (like watches)
+// can use it to close those and reconnect with fresh credentials.
+type RemoveClusterHandler func(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType)
+
 // GetManager return manager instance
 func GetManager() *clusterCache {
 	if managerInstance == nil {
@@ -60,9 +87,10 @@ func GetManager() *clusterCache {
 		defer lock.Unlock()
 		if managerInstance == nil {
 			managerInstance = &clusterCache{
-				configs:  make(map[corev1.ObjectReference]*rest.Config),
+				configs:  make(map[corev1.ObjectReference]*cachedConfig),
 				clusters: make(map[corev1.ObjectReference]*corev1.ObjectReference),
 				secrets:  make(map[corev1.ObjectReference]*libsveltosset.Set),
+				limiters: make(map[corev1.ObjectReference]*clusterRateLimiter),
 				rwMux:    sync.RWMutex{},
 			}
 		}
@@ -71,6 +99,15 @@ func GetManager() *clusterCache {
 	return managerInstance
 }
 
+// RegisterRemoveClusterHandler registers a handler invoked every time cached data for a
+// cluster is removed
+func (m *clusterCache) RegisterRemoveClusterHandler(handler RemoveClusterHandler) {
+	m.rwMux.Lock()
+	defer m.rwMux.Unlock()
+
+	m.handlers = append(m.handlers, handler)
+}
+
 // RemoveCluster removes restConfig cached data for the cluster
 func (m *clusterCache) RemoveCluster(clusterNamespace, clusterName string,
 	clusterType libsveltosv1beta1.ClusterType) {
@@ -78,7 +115,6 @@ func (m *clusterCache) RemoveCluster(clusterNamespace, clusterName string,
 	cluster := getClusterObjectReference(clusterNamespace, clusterName, clusterType)
 
 	m.rwMux.Lock()
-	defer m.rwMux.Unlock()
 
 	// Remove from cache the restConfig for this cluster
 	delete(m.configs, *cluster)
@@ -89,15 +125,23 @@ func (m *clusterCache) RemoveCluster(clusterNamespace, clusterName string,
 
 	// Do not track this cluster anymore
 	delete(m.clusters, *cluster)
+
+	handlers := m.handlers
+	m.rwMux.Unlock()
+
+	// Handlers are invoked without holding the lock as those might access the cache
+	for i := range handlers {
+		handlers[i](clusterNamespace, clusterName, clusterType)
+	}
 }
 
 // RemoveSecret removes any in-memory data related to secret
 func (m *clusterCache) RemoveSecret(sec *corev1.ObjectReference) {
 	m.rwMux.Lock()
-	defer m.rwMux.Unlock()
 
 	v, ok := m.secrets[*sec]
 	if !ok {
+		m.rwMux.Unlock()
 		return
 	}
 
@@ -106,11 +150,21 @@ func (m *clusterCache) RemoveSecret(sec *corev1.ObjectReference) {
 		delete(m.configs, clusters[i])
 		delete(m.clusters, clusters[i])
 	}
+
+	handlers := m.handlers
+	m.rwMux.Unlock()
+
+	// Handlers are invoked without holding the lock as those might access the cache
+	for i := range clusters {
+		for j := range handlers {
+			handlers[j](clusters[i].Namespace, clusters[i].Name, clusterproxy.GetClusterType(&clusters[i]))
+		}
+	}
 }
 
 // GetKubernetesRestConfig returns managed cluster restConfig.
-// If result is cached, it will be returned immediately. Otherwise it will be built
-// by fetching the Secret containing the cluster kubeconfig.
+// If result is cached, and credentials are not about to expire, it will be returned immediately.
+// Otherwise it will be built by fetching the Secret containing the cluster kubeconfig.
 // Admins restConfig are never cached.
 func (m *clusterCache) GetKubernetesRestConfig(ctx context.Context, mgmtClient client.Client,
 	clusterNamespace, clusterName, adminNamespace, adminName string,
@@ -118,19 +172,52 @@ func (m *clusterCache) GetKubernetesRestConfig(ctx context.Context, mgmtClient c
 
 	if adminNamespace != "" || adminName != "" {
 		// cluster configs for admins are not cached
-		return clusterproxy.GetKubernetesRestConfig(ctx, mgmtClient, clusterNamespace, clusterName,
+		config, err := clusterproxy.GetKubernetesRestConfig(ctx, mgmtClient, clusterNamespace, clusterName,
 			adminNamespace, adminName, clusterType, logger)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := validateCredentials(config, time.Now()); err != nil {
+			return nil, err
+		}
+		if limiter := m.GetRateLimiter(ctx, mgmtClient, clusterNamespace, clusterName, clusterType,
+			logger); limiter != nil {
+
+			config.RateLimiter = limiter
+		}
+		return config, nil
+	}
+
+	cluster := getClusterObjectReference(clusterNamespace, clusterName, clusterType)
+	limiter := m.GetRateLimiter(ctx, mgmtClient, clusterNamespace, clusterName, clusterType, logger)
+
+	m.rwMux.RLock()
+	entry, ok := m.configs[*cluster]
+	m.rwMux.RUnlock()
+	if ok {
+		switch {
+		case entry.needsRefresh(time.Now()):
+			// Evict entry so any long lived connection reconnects with fresh credentials
+			logger.V(logs.LogDebug).Info("remote restConfig credentials are expiring")
+			m.evictConfig(cluster, entry.config)
+		case entry.config.RateLimiter != limiter:
+			// Rate limits were set or removed for this cluster
+			logger.V(logs.LogDebug).Info("remote restConfig rate limits changed")
+			m.evictConfig(cluster, entry.config)
+		default:
+			logger.V(logs.LogInfo).Info("remote restConfig cache hit")
+			return entry.config, nil
+		}
 	}
 
 	m.rwMux.Lock()
 	defer m.rwMux.Unlock()
 
-	cluster := getClusterObjectReference(clusterNamespace, clusterName, clusterType)
+	// restConfig might have been cached in the meantime
+	if entry, ok := m.configs[*cluster]; ok && !entry.needsRefresh(time.Now()) &&
+		entry.config.RateLimiter == limiter {
 
-	config, ok := m.configs[*cluster]
-	if ok {
-		logger.V(logs.LogInfo).Info("remote restConfig cache hit")
-		return config, nil
+		return entry.config, nil
 	}
 
 	logger.V(logs.LogDebug).Info("remote restConfig cache miss")
@@ -140,10 +227,23 @@ func (m *clusterCache) GetKubernetesRestConfig(ctx context.Context, mgmtClient c
 		return nil, err
 	}
 
+	now := time.Now()
+	expiration, err := validateCredentials(remoteRestConfig, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig for cluster %s/%s: %w", clusterNamespace, clusterName, err)
+	}
+	m.evictOnUnauthorized(cluster, remoteRestConfig)
+	if limiter != nil {
+		remoteRestConfig.RateLimiter = limiter
+	}
+
 	secretInfo, err := getSecretObjectReference(ctx, mgmtClient, clusterNamespace, clusterName, clusterType)
 	if err == nil {
 		// Either all internal structures are updated or none is
-		m.configs[*cluster] = remoteRestConfig
+		m.configs[*cluster] = &cachedConfig{
+			config:    remoteRestConfig,
+			refreshAt: getRefreshTime(expiration, now),
+		}
 		m.clusters[*cluster] = secretInfo
 		v, ok := m.secrets[*secretInfo]
 		if !ok {
@@ -160,12 +260,7 @@ func (m *clusterCache) GetKubernetesClient(ctx context.Context, mgmtClient clien
 	clusterNamespace, clusterName, adminNamespace, adminName string,
 	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) (client.Client, error) {
 
-	if adminNamespace != "" || adminName != "" {
-		// cluster configs for admins are not cached
-		return clusterproxy.GetKubernetesClient(ctx, mgmtClient, clusterNamespace, clusterName,
-			adminNamespace, adminName, clusterType, logger)
-	}
-
+	// cluster configs for admins are not cached, but credentials are still validated
 	config, err := m.GetKubernetesRestConfig(ctx, mgmtClient, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterType, logger)
 	if err != nil {
@@ -183,7 +278,86 @@ func (m *clusterCache) StoreRestConfig(clusterNamespace, clusterName string,
 	m.rwMux.Lock()
 	defer m.rwMux.Unlock()
 
-	m.configs[*cluster] = config
+	m.configs[*cluster] = &cachedConfig{config: config}
+}
+
+// RefreshExpiringConfigs periodically evicts cached restConfigs whose credentials are about to expire.
+// Registered RemoveClusterHandlers are invoked, so long lived connections reconnect with fresh
+// credentials before current ones expire. It returns when ctx is cancelled.
+func (m *clusterCache) RefreshExpiringConfigs(ctx context.Context, interval time.Duration, logger logr.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		expiring := make(map[corev1.ObjectReference]*rest.Config)
+		m.rwMux.RLock()
+		for cluster, entry := range m.configs {
+			if entry.needsRefresh(now) {
+				expiring[cluster] = entry.config
+			}
+		}
+		m.rwMux.RUnlock()
+
+		for cluster, config := range expiring {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("refreshing credentials for cluster %s/%s",
+				cluster.Namespace, cluster.Name))
+			m.evictConfig(&cluster, config)
+		}
+	}
+}
+
+// WrapRestConfig prepares a restConfig for cluster which was not obtained from this cache (for instance
+// the one helm builds from a kubeconfig): exec credential plugins never prompt for input and a 401
+// (Unauthorized) from the managed cluster evicts cached data for cluster.
+func (m *clusterCache) WrapRestConfig(clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, config *rest.Config) {
+
+	if config.ExecProvider != nil {
+		config.ExecProvider.InteractiveMode = clientcmdapi.NeverExecInteractiveMode
+	}
+
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &unauthorizedRoundTripper{
+			delegate: rt,
+			onUnauthorized: func() {
+				go m.RemoveCluster(clusterNamespace, clusterName, clusterType)
+			},
+		}
+	})
+}
+
+// evictOnUnauthorized makes any client built from config evict it from the cache
+// when the managed cluster replies with 401 (Unauthorized)
+func (m *clusterCache) evictOnUnauthorized(cluster *corev1.ObjectReference, config *rest.Config) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &unauthorizedRoundTripper{
+			delegate: rt,
+			onUnauthorized: func() {
+				// Handlers might close the very connection which got the 401
+				go m.evictConfig(cluster, config)
+			},
+		}
+	})
+}
+
+// evictConfig removes cached data for cluster, only if cached restConfig is still config
+func (m *clusterCache) evictConfig(cluster *corev1.ObjectReference, config *rest.Config) {
+	m.rwMux.RLock()
+	entry, ok := m.configs[*cluster]
+	m.rwMux.RUnlock()
+
+	if !ok || entry.config != config {
+		return
+	}
+
+	m.RemoveCluster(cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster))
 }
 
 func (m *clusterCache) updateSecretMap(sec, cluster *corev1.ObjectReference) {
@@ -113,6 +113,34 @@ var _ = Describe("Clustercache", func() {
 		cacheMgr.RemoveSecret(secretObj)
 		Expect(cacheMgr.GetConfigFromMap(clusterObj)).To(BeNil())
 	})
+
+	It("RemoveCluster and RemoveSecret invoke registered handlers", func() {
+		secret := createClusterResources(cluster)
+
+		removed := make(map[string]int)
+		cacheMgr := clustercache.GetManager()
+		cacheMgr.RegisterRemoveClusterHandler(
+			func(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType) {
+				Expect(clusterType).To(Equal(libsveltosv1beta1.ClusterTypeSveltos))
+				removed[clusterNamespace+"/"+clusterName]++
+			})
+
+		_, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), testEnv.Client, cluster.Namespace,
+			cluster.Name, "", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+
+		secretObj := &corev1.ObjectReference{
+			Namespace:  secret.Namespace,
+			Name:       secret.Name,
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		}
+		cacheMgr.RemoveSecret(secretObj)
+		Expect(removed[cluster.Namespace+"/"+cluster.Name]).To(Equal(1))
+
+		cacheMgr.RemoveCluster(cluster.Namespace, cluster.Name, libsveltosv1beta1.ClusterTypeSveltos)
+		Expect(removed[cluster.Namespace+"/"+cluster.Name]).To(Equal(2))
+	})
 })
 
 func createClusterResources(cluster *libsveltosv1beta1.SveltosCluster) *corev1.Secret {
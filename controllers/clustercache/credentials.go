@@ -0,0 +1,208 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustercache
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Short-lived credentials
+// Kubeconfigs might contain credentials which expire: bearer tokens (like the ones created with a
+// TokenRequest) or client certificates. Those kubeconfigs are still cached, but cache entries are
+// expiry-aware:
+// - when credentials are about to expire, the cached rest.Config is evicted so the kubeconfig is read
+// again from its Secret (RefreshExpiringConfigs does that proactively so long lived watches reconnect
+// before credentials expire);
+// - a kubeconfig whose credentials are already expired is rejected.
+// Kubeconfigs can also rely on exec credential plugins (EKS/GKE style). The plugin refreshes credentials
+// itself, but since it runs a binary within this pod, only binaries in the allowlist can be used.
+// Finally, any remote client getting a 401 (Unauthorized) evicts the cached rest.Config of its cluster.
+// Clients not built from this cache (like helm ones) get the same behavior via ValidateKubeconfig and
+// WrapRestConfig.
+
+const (
+	// credentialRefreshSkew is how long before expiration credentials are refreshed
+	credentialRefreshSkew = time.Minute
+
+	// DefaultCredentialRefreshInterval is how often cached rest.Configs are checked for expiring credentials
+	DefaultCredentialRefreshInterval = 30 * time.Second
+)
+
+var (
+	execPluginMux       sync.RWMutex
+	execPluginAllowlist []string
+)
+
+// SetExecPluginAllowlist sets the exec credential plugins kubeconfigs can use. An entry
+// containing a path separator must match the plugin command exactly, otherwise it is matched
+// against the command base name. Kubeconfigs using any other exec plugin are rejected.
+func SetExecPluginAllowlist(binaries []string) {
+	execPluginMux.Lock()
+	defer execPluginMux.Unlock()
+
+	execPluginAllowlist = make([]string, 0, len(binaries))
+	for i := range binaries {
+		if binary := strings.TrimSpace(binaries[i]); binary != "" {
+			execPluginAllowlist = append(execPluginAllowlist, binary)
+		}
+	}
+}
+
+func isExecPluginAllowed(command string) bool {
+	execPluginMux.RLock()
+	defer execPluginMux.RUnlock()
+
+	for i := range execPluginAllowlist {
+		if strings.Contains(execPluginAllowlist[i], "/") {
+			if command == execPluginAllowlist[i] {
+				return true
+			}
+		} else if filepath.Base(command) == execPluginAllowlist[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCredentials verifies exec credential plugin, if any, is allowed and credentials
+// are not expired. It returns when credentials expire (zero if those never expire).
+func validateCredentials(config *rest.Config, now time.Time) (time.Time, error) {
+	if config.ExecProvider != nil {
+		if !isExecPluginAllowed(config.ExecProvider.Command) {
+			return time.Time{}, fmt.Errorf("exec credential plugin %q is not allowed", config.ExecProvider.Command)
+		}
+		// Controller has no terminal. Plugins must never prompt for input
+		config.ExecProvider.InteractiveMode = clientcmdapi.NeverExecInteractiveMode
+	}
+
+	expiration := getCredentialsExpiration(config)
+	if !expiration.IsZero() && !now.Before(expiration) {
+		return time.Time{}, fmt.Errorf("kubeconfig credentials expired at %s", expiration.Format(time.RFC3339))
+	}
+
+	return expiration, nil
+}
+
+// ValidateKubeconfig verifies kubeconfig exec credential plugin, if any, is allowed and credentials
+// are not expired. Kubeconfigs used outside of this cache (like the ones passed to helm) must be
+// validated with it.
+func ValidateKubeconfig(kubeconfig []byte) error {
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = validateCredentials(config, time.Now())
+	return err
+}
+
+// getCredentialsExpiration returns the earliest expiration between the bearer token (when this is a JWT)
+// and the client certificate. Zero is returned if neither expires.
+func getCredentialsExpiration(config *rest.Config) time.Time {
+	var expiration time.Time
+
+	earliest := func(t time.Time) {
+		if !t.IsZero() && (expiration.IsZero() || t.Before(expiration)) {
+			expiration = t
+		}
+	}
+
+	earliest(getTokenExpiration(config.BearerToken))
+	earliest(getCertificateExpiration(config.CertData))
+
+	return expiration
+}
+
+// getTokenExpiration returns the exp claim of a JWT. Token signature is not verified,
+// the managed cluster does that. Zero is returned if token is not a JWT or has no exp claim.
+func getTokenExpiration(token string) time.Time {
+	const jwtParts = 3
+	parts := strings.Split(token, ".")
+	if len(parts) != jwtParts {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
+
+func getCertificateExpiration(certData []byte) time.Time {
+	block, _ := pem.Decode(certData)
+	if block == nil {
+		return time.Time{}
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return cert.NotAfter
+}
+
+// getRefreshTime returns when credentials expiring at expiration must be refreshed.
+// If it is too late to refresh those ahead of time, they are refreshed once expired.
+func getRefreshTime(expiration, now time.Time) time.Time {
+	if expiration.IsZero() {
+		return expiration
+	}
+
+	refresh := expiration.Add(-credentialRefreshSkew)
+	if refresh.Before(now) {
+		return expiration
+	}
+	return refresh
+}
+
+// unauthorizedRoundTripper invokes onUnauthorized every time the managed cluster
+// replies with 401 (Unauthorized)
+type unauthorizedRoundTripper struct {
+	delegate       http.RoundTripper
+	onUnauthorized func()
+}
+
+func (rt *unauthorizedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		rt.onUnauthorized()
+	}
+	return resp, err
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustercache_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	execPluginName = "fake-exec-plugin"
+)
+
+var _ = Describe("Clustercache credentials", func() {
+	var logger logr.Logger
+	var cluster *libsveltosv1beta1.SveltosCluster
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		logger = textlogger.NewLogger(textlogger.NewConfig())
+		cluster = &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cache" + randomString(),
+				Namespace: "cache" + randomString(),
+			},
+		}
+
+		// Managed cluster API server: only requests with exec-token are authorized
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer exec-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		clustercache.SetExecPluginAllowlist(nil)
+	})
+
+	It("exec credential plugins can be used only when in the allowlist", func() {
+		plugin := filepath.Join(GinkgoT().TempDir(), execPluginName)
+		Expect(os.WriteFile(plugin, []byte(`#!/bin/sh
+echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"exec-token"}}'
+`), 0o700)).To(Succeed()) //nolint: gosec // test plugin must be executable
+
+		authInfo := &clientcmdapi.AuthInfo{
+			Exec: &clientcmdapi.ExecConfig{
+				APIVersion:      "client.authentication.k8s.io/v1",
+				Command:         plugin,
+				InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+			},
+		}
+		c := getFakeClientWithKubeconfig(cluster, server, authInfo)
+
+		cacheMgr := clustercache.GetManager()
+		_, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("is not allowed"))
+
+		clustercache.SetExecPluginAllowlist([]string{execPluginName})
+		config, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+
+		Expect(getStatusCode(config, server.URL)).To(Equal(http.StatusOK))
+	})
+
+	It("rejects expired tokens and caches valid ones", func() {
+		c := getFakeClientWithKubeconfig(cluster, server,
+			&clientcmdapi.AuthInfo{Token: getJWT(time.Now().Add(-time.Minute))})
+
+		cacheMgr := clustercache.GetManager()
+		_, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("expired"))
+
+		cluster.Name = "cache" + randomString()
+		c = getFakeClientWithKubeconfig(cluster, server,
+			&clientcmdapi.AuthInfo{Token: getJWT(time.Now().Add(time.Hour))})
+		config, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+
+		cached, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(cached).To(BeIdenticalTo(config))
+	})
+
+	It("evicts cached restConfig when managed cluster replies with 401", func() {
+		c := getFakeClientWithKubeconfig(cluster, server, &clientcmdapi.AuthInfo{Token: "revoked-token"})
+
+		removed := make(chan string, 1)
+		cacheMgr := clustercache.GetManager()
+		cacheMgr.RegisterRemoveClusterHandler(
+			func(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType) {
+				if clusterNamespace == cluster.Namespace && clusterName == cluster.Name {
+					select {
+					case removed <- clusterName:
+					default:
+					}
+				}
+			})
+
+		config, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+
+		clusterObj := &corev1.ObjectReference{
+			Namespace:  cluster.Namespace,
+			Name:       cluster.Name,
+			Kind:       libsveltosv1beta1.SveltosClusterKind,
+			APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+		Expect(cacheMgr.GetConfigFromMap(clusterObj)).ToNot(BeNil())
+
+		Expect(getStatusCode(config, server.URL)).To(Equal(http.StatusUnauthorized))
+		Eventually(removed, time.Minute, time.Second).Should(Receive(Equal(cluster.Name)))
+		Expect(cacheMgr.GetConfigFromMap(clusterObj)).To(BeNil())
+	})
+})
+
+// getFakeClientWithKubeconfig returns a client with the SveltosCluster and the Secret
+// containing its kubeconfig
+func getFakeClientWithKubeconfig(cluster *libsveltosv1beta1.SveltosCluster, server *httptest.Server,
+	authInfo *clientcmdapi.AuthInfo) client.Client {
+
+	// Credentials are only sent over TLS
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters["cluster"] = &clientcmdapi.Cluster{
+		Server: server.URL,
+		CertificateAuthorityData: pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: server.Certificate().Raw,
+		}),
+	}
+	kubeconfig.AuthInfos["user"] = authInfo
+	kubeconfig.Contexts["context"] = &clientcmdapi.Context{Cluster: "cluster", AuthInfo: "user"}
+	kubeconfig.CurrentContext = "context"
+
+	data, err := clientcmd.Write(*kubeconfig)
+	Expect(err).To(BeNil())
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name + sveltosKubeconfigPostfix,
+		},
+		Data: map[string][]byte{
+			"value": data,
+		},
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster.DeepCopy(), secret).Build()
+}
+
+func getStatusCode(config *rest.Config, server string) int {
+	httpClient, err := rest.HTTPClientFor(config)
+	Expect(err).To(BeNil())
+
+	resp, err := httpClient.Get(server + "/version")
+	Expect(err).To(BeNil())
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+// getJWT returns an unsigned JWT expiring at expiration
+func getJWT(expiration time.Time) string {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	return fmt.Sprintf("%s.%s.%s", encode(`{"alg":"none"}`),
+		encode(fmt.Sprintf(`{"exp":%d}`, expiration.Unix())), encode("signature"))
+}
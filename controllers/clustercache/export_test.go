@@ -22,7 +22,11 @@ import (
 )
 
 func (m *clusterCache) GetConfigFromMap(cluster *corev1.ObjectReference) *rest.Config {
-	return m.configs[*cluster]
+	entry, ok := m.configs[*cluster]
+	if !ok {
+		return nil
+	}
+	return entry.config
 }
 
 func (m *clusterCache) GetSecretForCluster(cluster *corev1.ObjectReference) *corev1.ObjectReference {
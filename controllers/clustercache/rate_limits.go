@@ -0,0 +1,211 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustercache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Managed cluster API rate limits
+// By default clients to managed clusters use whatever QPS/burst the kubeconfig gives them, each client
+// with its own rate limiter. When a QPS is configured, either for all clusters or for a SveltosCluster
+// with the ClusterAPIQPSAnnotation/ClusterAPIBurstAnnotation annotations, all clients of the cluster
+// (including helm ones) share the same rate limiter. This protects small clusters when several
+// ClusterSummaries are deployed at once.
+
+var (
+	rateLimitsMux sync.RWMutex
+	defaultQPS    float32
+	defaultBurst  int
+
+	clusterAPIQPSGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "cluster_api_qps",
+			Help:      "Maximum queries per second sent to a managed cluster API server. 0 if not limited",
+		},
+		[]string{"cluster_type", "cluster_namespace", "cluster_name"},
+	)
+
+	clusterAPIBurstGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "cluster_api_burst",
+			Help:      "Maximum burst of queries sent to a managed cluster API server. 0 if not limited",
+		},
+		[]string{"cluster_type", "cluster_namespace", "cluster_name"},
+	)
+)
+
+//nolint:gochecknoinits // forced pattern, can't workaround
+func init() {
+	metrics.Registry.MustRegister(clusterAPIQPSGauge, clusterAPIBurstGauge)
+}
+
+// SetRemoteRateLimits sets the QPS and burst for managed cluster API servers. Zero QPS leaves clients
+// with the kubeconfig settings, unless a SveltosCluster overrides those with annotations.
+func SetRemoteRateLimits(qps float32, burst int) {
+	rateLimitsMux.Lock()
+	defer rateLimitsMux.Unlock()
+
+	defaultQPS = qps
+	defaultBurst = burst
+}
+
+// clusterRateLimiter is the rate limiter shared by all clients of a managed cluster.
+// Limits can be changed while clients are using it.
+type clusterRateLimiter struct {
+	mux     sync.RWMutex
+	qps     float32
+	burst   int
+	limiter flowcontrol.RateLimiter
+}
+
+func newClusterRateLimiter(qps float32, burst int) *clusterRateLimiter {
+	return &clusterRateLimiter{
+		qps:     qps,
+		burst:   burst,
+		limiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+	}
+}
+
+func (l *clusterRateLimiter) update(qps float32, burst int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.qps == qps && l.burst == burst {
+		return
+	}
+	l.qps = qps
+	l.burst = burst
+	l.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+}
+
+func (l *clusterRateLimiter) get() flowcontrol.RateLimiter {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+	return l.limiter
+}
+
+func (l *clusterRateLimiter) TryAccept() bool {
+	return l.get().TryAccept()
+}
+
+func (l *clusterRateLimiter) Accept() {
+	l.get().Accept()
+}
+
+func (l *clusterRateLimiter) Wait(ctx context.Context) error {
+	return l.get().Wait(ctx)
+}
+
+func (l *clusterRateLimiter) Stop() {
+	// Limiter is shared, clients must not stop it
+}
+
+func (l *clusterRateLimiter) QPS() float32 {
+	return l.get().QPS()
+}
+
+// GetRateLimiter returns the rate limiter shared by all clients of the managed cluster.
+// Nil is returned if no limit is configured for the cluster.
+func (m *clusterCache) GetRateLimiter(ctx context.Context, mgmtClient client.Client,
+	clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
+	logger logr.Logger) flowcontrol.RateLimiter {
+
+	qps, burst := getRemoteRateLimits(ctx, mgmtClient, clusterNamespace, clusterName, clusterType, logger)
+	clusterAPIQPSGauge.WithLabelValues(string(clusterType), clusterNamespace, clusterName).Set(float64(qps))
+	clusterAPIBurstGauge.WithLabelValues(string(clusterType), clusterNamespace, clusterName).Set(float64(burst))
+
+	cluster := getClusterObjectReference(clusterNamespace, clusterName, clusterType)
+
+	m.rwMux.Lock()
+	defer m.rwMux.Unlock()
+
+	if qps <= 0 {
+		delete(m.limiters, *cluster)
+		return nil
+	}
+
+	limiter, ok := m.limiters[*cluster]
+	if !ok {
+		limiter = newClusterRateLimiter(qps, burst)
+		m.limiters[*cluster] = limiter
+	} else {
+		limiter.update(qps, burst)
+	}
+
+	return limiter
+}
+
+// getRemoteRateLimits returns QPS and burst for the managed cluster. SveltosCluster annotations,
+// when valid, take precedence over the default ones.
+func getRemoteRateLimits(ctx context.Context, mgmtClient client.Client,
+	clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
+	logger logr.Logger) (qps float32, burst int) {
+
+	rateLimitsMux.RLock()
+	qps, burst = defaultQPS, defaultBurst
+	rateLimitsMux.RUnlock()
+
+	if clusterType == libsveltosv1beta1.ClusterTypeSveltos {
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{}
+		err := mgmtClient.Get(ctx, client.ObjectKey{Namespace: clusterNamespace, Name: clusterName}, sveltosCluster)
+		if err == nil {
+			annotations := sveltosCluster.GetAnnotations()
+			if v, ok := annotations[configv1beta1.ClusterAPIQPSAnnotation]; ok {
+				if parsed, err := strconv.ParseFloat(v, 32); err == nil && parsed >= 0 {
+					qps = float32(parsed)
+				} else {
+					logger.V(logs.LogInfo).Info(fmt.Sprintf("invalid %s annotation: %q",
+						configv1beta1.ClusterAPIQPSAnnotation, v))
+				}
+			}
+			if v, ok := annotations[configv1beta1.ClusterAPIBurstAnnotation]; ok {
+				if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+					burst = parsed
+				} else {
+					logger.V(logs.LogInfo).Info(fmt.Sprintf("invalid %s annotation: %q",
+						configv1beta1.ClusterAPIBurstAnnotation, v))
+				}
+			}
+		}
+	}
+
+	if qps <= 0 {
+		return 0, 0
+	}
+	if burst <= 0 {
+		// Without a burst no request would ever be accepted
+		burst = int(math.Ceil(float64(qps)))
+	}
+	return qps, burst
+}
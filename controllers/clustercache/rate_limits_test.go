@@ -0,0 +1,96 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustercache_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Clustercache rate limits", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		clustercache.SetRemoteRateLimits(0, 0)
+	})
+
+	It("all clients of a cluster share a rate limiter, configurable with SveltosCluster annotations", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		cluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cache" + randomString(),
+				Namespace: "cache" + randomString(),
+			},
+		}
+
+		c := getFakeClientWithKubeconfig(cluster, server, &clientcmdapi.AuthInfo{Token: "token"})
+
+		// No limit configured: kubeconfig settings are used
+		cacheMgr := clustercache.GetManager()
+		config, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(config.RateLimiter).To(BeNil())
+
+		// Default limits are set: cached restConfig is rebuilt with the cluster rate limiter
+		clustercache.SetRemoteRateLimits(5, 10)
+		config, err = cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(config.RateLimiter).ToNot(BeNil())
+		Expect(config.RateLimiter.QPS()).To(Equal(float32(5)))
+
+		// Annotation overrides default. Same rate limiter is updated
+		currentCluster := &libsveltosv1beta1.SveltosCluster{}
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(cluster), currentCluster)).To(Succeed())
+		currentCluster.Annotations = map[string]string{
+			configv1beta1.ClusterAPIQPSAnnotation:   "2",
+			configv1beta1.ClusterAPIBurstAnnotation: "4",
+		}
+		Expect(c.Update(context.TODO(), currentCluster)).To(Succeed())
+
+		limiter := cacheMgr.GetRateLimiter(context.TODO(), c, cluster.Namespace, cluster.Name,
+			libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(limiter).To(BeIdenticalTo(config.RateLimiter))
+		Expect(config.RateLimiter.QPS()).To(Equal(float32(2)))
+
+		cached, err := cacheMgr.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(cached).To(BeIdenticalTo(config))
+	})
+})
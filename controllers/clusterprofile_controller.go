@@ -26,7 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -52,6 +52,7 @@ type ClusterProfileReconciler struct {
 	Scheme               *runtime.Scheme
 	ConcurrentReconciles int
 	Logger               logr.Logger
+	EventRecorder        record.EventRecorder
 
 	// use a Mutex to update Map as MaxConcurrentReconciles is higher than one
 	Mux sync.Mutex
@@ -82,6 +83,7 @@ type ClusterProfileReconciler struct {
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clustersummaries,verbs=get;list;update;create;delete
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterreports,verbs=get;list;update;create;watch;delete
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterconfigurations,verbs=get;list;update;create;watch;delete
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=profileapprovals,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;watch;list
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters/status,verbs=get;watch;list
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;watch;list
@@ -90,6 +92,7 @@ type ClusterProfileReconciler struct {
 //+kubebuilder:rbac:groups=lib.projectsveltos.io,resources=sveltosclusters/status,verbs=get;watch;list
 
 func (r *ClusterProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx = withEventRecorder(ctx, r.EventRecorder)
 	logger := ctrl.LoggerFrom(ctx)
 	logger.V(logs.LogInfo).Info("Reconciling")
 	// Fecth the ClusterProfile instance
@@ -191,6 +194,12 @@ func (r *ClusterProfileReconciler) reconcileNormal(
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
 
+	if isCircuitBreakerTripped(profileScope.GetStatus()) {
+		// Reconcile again to reset the circuit breaker once CoolDown elapses
+		logger.V(logs.LogInfo).Info("circuit breaker is tripped")
+		return reconcile.Result{Requeue: true, RequeueAfter: circuitBreakerRequeueAfter}
+	}
+
 	logger.V(logs.LogInfo).Info("Reconcile success")
 	return reconcile.Result{}
 }
@@ -208,6 +217,21 @@ func (r *ClusterProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				SetPredicates(mgr.GetLogger().WithValues("predicate", "clustersetpredicate")),
 			),
 		).
+		Watches(&configv1beta1.ProfileApproval{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueClusterProfileForProfileApproval),
+		).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueClusterProfileForReference),
+			builder.WithPredicates(
+				ConfigMapPredicates(mgr.GetLogger().WithValues("predicate", "configmappredicate")),
+			),
+		).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueClusterProfileForReference),
+			builder.WithPredicates(
+				SecretPredicates(mgr.GetLogger().WithValues("predicate", "secretpredicate")),
+			),
+		).
 		Watches(&libsveltosv1beta1.SveltosCluster{},
 			handler.EnqueueRequestsFromMapFunc(r.requeueClusterProfileForSveltosCluster),
 			builder.WithPredicates(
@@ -326,23 +350,5 @@ func (r *ClusterProfileReconciler) GetController() controller.Controller {
 func (r *ClusterProfileReconciler) getClustersFromClusterSets(ctx context.Context, clusterSetRefs []string,
 	logger logr.Logger) ([]corev1.ObjectReference, error) {
 
-	clusters := make([]corev1.ObjectReference, 0)
-	for i := range clusterSetRefs {
-		clusterSet := &libsveltosv1beta1.ClusterSet{}
-		if err := r.Client.Get(ctx,
-			types.NamespacedName{Name: clusterSetRefs[i]},
-			clusterSet); err != nil {
-			if apierrors.IsNotFound(err) {
-				continue
-			}
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get clusterSet %s", clusterSetRefs[i]))
-			return nil, err
-		}
-
-		if clusterSet.Status.SelectedClusterRefs != nil {
-			clusters = append(clusters, clusterSet.Status.SelectedClusterRefs...)
-		}
-	}
-
-	return clusters, nil
+	return getClustersFromClusterSets(ctx, r.Client, clusterSetRefs, logger)
 }
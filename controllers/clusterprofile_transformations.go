@@ -40,6 +40,20 @@ func (r *ClusterProfileReconciler) requeueClusterProfileForClusterSet(
 	return requeueForSet(clusterSet, r.ClusterSetMap, configv1beta1.ClusterProfileKind, r.Logger)
 }
 
+func (r *ClusterProfileReconciler) requeueClusterProfileForProfileApproval(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	return requeueForProfileApproval(o, configv1beta1.ClusterProfileKind)
+}
+
+func (r *ClusterProfileReconciler) requeueClusterProfileForReference(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	return requeueForPlanReference(ctx, r.Client, o, configv1beta1.ClusterProfileKind)
+}
+
 func (r *ClusterProfileReconciler) requeueClusterProfileForSveltosCluster(
 	ctx context.Context, o client.Object,
 ) []reconcile.Request {
@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -34,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,6 +45,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -90,6 +94,7 @@ type ClusterSummaryReconciler struct {
 	client.Client
 	Scheme               *runtime.Scheme
 	Logger               logr.Logger
+	EventRecorder        record.EventRecorder
 	ReportMode           ReportMode
 	ShardKey             string // when set, only clusters matching the ShardKey will be reconciled
 	Version              string
@@ -102,7 +107,10 @@ type ClusterSummaryReconciler struct {
 	ConflictRetryTime time.Duration
 	ctrl              controller.Controller
 
-	eventRecorder record.EventRecorder
+	// When drift detection is done by polling, interval between two consecutive evaluations
+	// and maximum number of resources fetched in parallel from each managed cluster.
+	DriftDetectionPollingInterval    time.Duration
+	DriftDetectionPollingConcurrency int
 }
 
 // If the drift-detection component is deployed in the management cluster, the addon-controller will deploy ResourceSummaries within the same cluster,
@@ -118,7 +126,7 @@ type ClusterSummaryReconciler struct {
 //+kubebuilder:rbac:groups=lib.projectsveltos.io,resources=resourcesummaries,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=lib.projectsveltos.io,resources=resourcesummaries/status,verbs=get;list;update
 //+kubebuilder:rbac:groups=lib.projectsveltos.io,resources=reloaders,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups="",resources=events,verbs=create;update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;update;patch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get;watch;list
@@ -129,8 +137,10 @@ type ClusterSummaryReconciler struct {
 //+kubebuilder:rbac:groups="source.toolkit.fluxcd.io",resources=ocirepositories/status,verbs=get;watch;list
 //+kubebuilder:rbac:groups="source.toolkit.fluxcd.io",resources=buckets,verbs=get;watch;list
 //+kubebuilder:rbac:groups="source.toolkit.fluxcd.io",resources=buckets/status,verbs=get;watch;list
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=deploymentwindows,verbs=get;list;watch
 
 func (r *ClusterSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx = withEventRecorder(ctx, r.EventRecorder)
 	logger := ctrl.LoggerFrom(ctx)
 	logger.V(logs.LogInfo).Info("Reconciling")
 
@@ -255,6 +265,40 @@ func (r *ClusterSummaryReconciler) reconcileDelete(
 			return reconcile.Result{}, nil
 		}
 
+		// Profiles depending on this one must be withdrawn first. In DryRun mode nothing is withdrawn.
+		if !clusterSummaryScope.IsDryRunSync() {
+			allRemoved, msg, err := r.areDependentsRemoved(ctx, clusterSummaryScope, logger)
+			if err != nil {
+				return reconcile.Result{Requeue: true, RequeueAfter: deleteRequeueAfter}, nil
+			}
+			if !allRemoved {
+				forced, err := isWithdrawalForced(ctx, r.Client, clusterSummaryScope.ClusterSummary)
+				if err != nil {
+					return reconcile.Result{Requeue: true, RequeueAfter: deleteRequeueAfter}, nil
+				}
+				if forced {
+					logger.V(logs.LogInfo).Info("withdrawal forced. Not waiting for dependents")
+				}
+				allRemoved = forced
+			}
+			if !allRemoved {
+				r.resetFeatureStatus(clusterSummaryScope, configv1beta1.FeatureStatusRemoving)
+				setWaitingForDependents(clusterSummaryScope, true, msg)
+				if err := updateProfileWaitingForDependentsCondition(ctx, r.Client,
+					clusterSummaryScope.ClusterSummary, logger); err != nil {
+					logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update WaitingForDependents condition: %v", err))
+				}
+				return reconcile.Result{Requeue: true, RequeueAfter: deleteRequeueAfter}, nil
+			}
+			if isWaitingForDependents(clusterSummaryScope.ClusterSummary) {
+				setWaitingForDependents(clusterSummaryScope, false, "")
+				if err := updateProfileWaitingForDependentsCondition(ctx, r.Client,
+					clusterSummaryScope.ClusterSummary, logger); err != nil {
+					logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update WaitingForDependents condition: %v", err))
+				}
+			}
+		}
+
 		if !isDeleted {
 			// if cluster is marked for deletion do not try to remove ResourceSummaries.
 			// those are only deployed in the managed cluster so no need to cleanup on a deleted cluster
@@ -307,6 +351,9 @@ func (r *ClusterSummaryReconciler) reconcileDelete(
 		}
 	}
 
+	removeAuditTriggers(clusterSummaryScope.ClusterSummary)
+	forgetAutoShardClusterSummary(clusterSummaryScope.ClusterSummary)
+
 	// Cluster is not present anymore or cleanup succeeded
 	logger.V(logs.LogInfo).Info("Removing finalizer")
 	if controllerutil.ContainsFinalizer(clusterSummaryScope.ClusterSummary, configv1beta1.ClusterSummaryFinalizer) {
@@ -364,6 +411,31 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 		return reconcile.Result{}, nil
 	}
 
+	tripped, err := isProfileCircuitBreakerTripped(ctx, r.Client, clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	if tripped {
+		logger.V(logs.LogInfo).Info("profile circuit breaker is tripped. Do not deploy.")
+		return reconcile.Result{Requeue: true, RequeueAfter: circuitBreakerRequeueAfter}, nil
+	}
+
+	stale, err := isApprovedPlanStale(ctx, r.Client, clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	setPlanPendingApproval(clusterSummaryScope, stale)
+	if stale {
+		logger.V(logs.LogInfo).Info("approved plan does not match current plan anymore. Do not deploy.")
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
+	err = evaluateDeploymentWindows(ctx, r.Client, clusterSummaryScope, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to evaluate deployment windows. Do not deploy.")
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
 	err = r.startWatcherForTemplateResourceRefs(ctx, clusterSummaryScope.ClusterSummary)
 	if err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to start watcher on resources referenced in TemplateResourceRefs.")
@@ -374,8 +446,16 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
 	}
+	if allDeployed && wereDependenciesBlocking(clusterSummaryScope.ClusterSummary.Status.Dependencies) {
+		clusterSummaryScope.SetDependenciesUnblocked()
+	}
+	previousMsg := clusterSummaryScope.ClusterSummary.Status.Dependencies
 	clusterSummaryScope.SetDependenciesMessage(&msg)
 	if !allDeployed {
+		// Only report when the blocking dependencies change, not on every requeue
+		if previousMsg == nil || *previousMsg != msg {
+			recordNormalEvent(ctx, clusterSummaryScope.ClusterSummary, eventReasonDependenciesNotDeployed, "%s", msg)
+		}
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
 	}
 
@@ -406,6 +486,11 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 
 	logger.V(logs.LogInfo).Info("Reconciling ClusterSummary success")
 
+	if deferred, next, _ := clusterSummaryScope.IsDeploymentDeferred(); deferred {
+		// deployments are waiting for next deployment window
+		return reconcile.Result{Requeue: true, RequeueAfter: getDeploymentWindowRequeueAfter(next)}, nil
+	}
+
 	if clusterSummaryScope.IsDryRunSync() {
 		r.resetFeatureStatusToProvisioning(clusterSummaryScope)
 		// we need to keep retrying in DryRun ClusterSummaries
@@ -417,11 +502,18 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterSummaryReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
-	c, err := ctrl.NewControllerManagedBy(mgr).
+	options := controller.Options{
+		MaxConcurrentReconciles: r.ConcurrentReconciles,
+	}
+	if isAutoShardingEnabled() {
+		// With automatic sharding every replica manages the clusters assigned to it.
+		// Only the remaining controllers run on the elected leader.
+		options.NeedLeaderElection = ptr.To(false)
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&configv1beta1.ClusterSummary{}).
-		WithOptions(controller.Options{
-			MaxConcurrentReconciles: r.ConcurrentReconciles,
-		}).
+		WithOptions(options).
 		Watches(&libsveltosv1beta1.SveltosCluster{},
 			handler.EnqueueRequestsFromMapFunc(r.requeueClusterSummaryForSveltosCluster),
 			builder.WithPredicates(
@@ -440,24 +532,46 @@ func (r *ClusterSummaryReconciler) SetupWithManager(ctx context.Context, mgr ctr
 				SecretPredicates(mgr.GetLogger().WithValues("predicate", "secretpredicate")),
 			),
 		).
-		Build(r)
+		Watches(&configv1beta1.Rollback{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueClusterSummaryForRollback),
+			// Rollback Status is updated by ClusterSummary reconciler itself
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		)
+	if isAutoShardingEnabled() {
+		b = b.WatchesRawSource(source.Channel(autoSharding.events, &handler.EnqueueRequestForObject{}))
+	}
+
+	c, err := b.Build(r)
 	if err != nil {
 		return fmt.Errorf("error creating controller: %w", err)
 	}
 
+	// Configuration drifts are reported with Events on ClusterSummaries
+	ctx = withEventRecorder(ctx, r.EventRecorder)
+
 	// At this point we don't know yet whether CAPI is present in the cluster.
 	// Later on, in main, we detect that and if CAPI is present WatchForCAPI will be invoked.
-	if r.ReportMode == CollectFromManagementCluster {
-		go collectAndProcessResourceSummaries(ctx, mgr.GetClient(), r.ShardKey, r.Version, mgr.GetLogger())
+	if getDriftDetectionPolling() {
+		go pollForConfigurationDrifts(ctx, mgr.GetClient(), r.ShardKey, r.DriftDetectionPollingInterval,
+			r.DriftDetectionPollingConcurrency, mgr.GetLogger())
+	} else if r.ReportMode == CollectFromManagementCluster {
+		go watchResourceSummaries(ctx, mgr.GetClient(), r.ShardKey, r.Version, mgr.GetLogger())
 	}
 
 	if getAgentInMgmtCluster() {
 		go removeStaleDriftDetectionResources(ctx, r.Logger)
 	}
 
+	if isAutoShardingEnabled() {
+		go r.runAutoSharding(ctx, mgr.GetLogger())
+	}
+
+	go r.monitorFeaturesHealth(ctx, mgr.GetLogger())
+
+	go processNotifications(ctx, mgr.GetClient(), mgr.GetLogger())
+
 	initializeManager(ctrl.Log.WithName("watchers"), mgr.GetConfig(), mgr.GetClient())
 
-	r.eventRecorder = mgr.GetEventRecorderFor("event-recorder")
 	r.ctrl = c
 
 	return err
@@ -881,13 +995,13 @@ func (r *ClusterSummaryReconciler) getPolicyRefReferences(ctx context.Context,
 	currentReferences := &libsveltosset.Set{}
 	for i := range clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs {
 		referencedNamespace := clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i].Namespace
-		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(),
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName, referencedNamespace, cs.Spec.ClusterType)
 		if err != nil {
 			return nil, err
 		}
 
-		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(),
+		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName,
 			clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i].Name, cs.Spec.ClusterType)
 		if err != nil {
@@ -915,13 +1029,13 @@ func (r *ClusterSummaryReconciler) getKustomizationRefReferences(ctx context.Con
 
 		referencedNamespace := kr.Namespace
 
-		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(),
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName, referencedNamespace, cs.Spec.ClusterType)
 		if err != nil {
 			return nil, err
 		}
 
-		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(),
+		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName, kr.Name, cs.Spec.ClusterType)
 		if err != nil {
 			return nil, err
@@ -968,13 +1082,13 @@ func getKustomizationValueFrom(ctx context.Context, clusterSummaryScope *scope.C
 	cs := clusterSummaryScope.ClusterSummary
 	for i := range kr.ValuesFrom {
 		referencedNamespace := kr.ValuesFrom[i].Namespace
-		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(),
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName, referencedNamespace, cs.Spec.ClusterType)
 		if err != nil {
 			return nil, err
 		}
 
-		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(),
+		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName, kr.ValuesFrom[i].Name, cs.Spec.ClusterType)
 		if err != nil {
 			return nil, err
@@ -1026,13 +1140,13 @@ func getHelmChartValueFrom(ctx context.Context, clusterSummaryScope *scope.Clust
 	cs := clusterSummaryScope.ClusterSummary
 	for i := range hc.ValuesFrom {
 		referencedNamespace := hc.ValuesFrom[i].Namespace
-		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(),
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName, referencedNamespace, cs.Spec.ClusterType)
 		if err != nil {
 			return nil, err
 		}
 
-		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(),
+		referencedName, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(ctx),
 			cs.Spec.ClusterNamespace, cs.Spec.ClusterName, hc.ValuesFrom[i].Name, cs.Spec.ClusterType)
 		if err != nil {
 			return nil, err
@@ -1189,6 +1303,11 @@ func (r *ClusterSummaryReconciler) removeResourceSummary(ctx context.Context,
 func (r *ClusterSummaryReconciler) updateClusterShardPair(ctx context.Context,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
 
+	if isAutoShardingEnabled() {
+		// Clusters moving between replicas are handled without restarting
+		return nil
+	}
+
 	if hasShardChanged, err := sharding.RegisterClusterShard(ctx, r.Client, libsveltosv1beta1.ComponentAddonManager,
 		string(configv1beta1.FeatureHelm), r.ShardKey, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
 		clusterSummary.Spec.ClusterType); err != nil {
@@ -1209,6 +1328,10 @@ func (r *ClusterSummaryReconciler) updateClusterShardPair(ctx context.Context,
 func (r *ClusterSummaryReconciler) isClusterAShardMatch(ctx context.Context,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) (bool, error) {
 
+	if isAutoShardingEnabled() {
+		return r.isClusterAnAutoShardMatch(clusterSummary, logger)
+	}
+
 	cluster, err := clusterproxy.GetCluster(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
 		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
 	if err != nil {
@@ -1270,6 +1393,20 @@ func (r *ClusterSummaryReconciler) refreshInternalState(ctx context.Context,
 	return nil
 }
 
+const (
+	allDependenciesDeployedMessage = "All dependencies deployed"
+	noDependenciesMessage          = "no dependencies"
+)
+
+// wereDependenciesBlocking returns true if the dependencies message reports
+// dependencies not deployed yet
+func wereDependenciesBlocking(dependencyMessage *string) bool {
+	if dependencyMessage == nil || *dependencyMessage == "" {
+		return false
+	}
+	return *dependencyMessage != allDependenciesDeployedMessage && *dependencyMessage != noDependenciesMessage
+}
+
 // areDependenciesDeployed checks dependencies. All must be provisioned for this ClusterSummary to proceed further
 // reconciling add-ons and applications
 func (r *ClusterSummaryReconciler) areDependenciesDeployed(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
@@ -1286,15 +1423,17 @@ func (r *ClusterSummaryReconciler) areDependenciesDeployed(ctx context.Context,
 	}
 
 	for i := range clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.DependsOn {
-		profileName := clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.DependsOn[i]
-		logger.V(logs.LogDebug).Info(fmt.Sprintf("Considering %s %s", profileReference.Kind, profileName))
+		// A Profile can depend on a ClusterProfile
+		profileKind, profileName := configv1beta1.GetDependsOnProfile(profileReference.Kind,
+			clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.DependsOn[i])
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("Considering %s %s", profileKind, profileName))
 		var cs *configv1beta1.ClusterSummary
-		cs, err = getClusterSummary(ctx, r.Client, profileReference.Kind, profileName,
+		cs, err = getClusterSummary(ctx, r.Client, profileKind, profileName,
 			clusterSummaryScope.ClusterSummary.Spec.ClusterNamespace, clusterSummaryScope.ClusterSummary.Spec.ClusterName,
 			clusterSummaryScope.ClusterSummary.Spec.ClusterType)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				msg := fmt.Sprintf("ClusterSummary for %s %s not found", profileReference.Kind, profileName)
+				msg := fmt.Sprintf("ClusterSummary for %s %s not found", profileKind, profileName)
 				logger.V(logs.LogInfo).Info(msg)
 				return false, msg, nil
 			}
@@ -1303,20 +1442,127 @@ func (r *ClusterSummaryReconciler) areDependenciesDeployed(ctx context.Context,
 		}
 
 		if !isCluterSummaryProvisioned(cs) {
-			msg := fmt.Sprintf("%s %s is not fully deployed yet", profileReference.Kind, profileName)
+			msg := fmt.Sprintf("%s %s is not fully deployed yet", profileKind, profileName)
 			logger.V(logs.LogInfo).Info(msg)
 			return false, msg, nil
 		}
 	}
 
-	dependencyMessage = "All dependencies deployed"
+	dependencyMessage = allDependenciesDeployedMessage
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.DependsOn == nil {
-		dependencyMessage = "no dependencies"
+		dependencyMessage = noDependenciesMessage
 	}
 
 	return true, dependencyMessage, nil
 }
 
+// areDependentsRemoved checks profiles depending on this ClusterSummary's profile. Add-ons and applications
+// are withdrawn in reverse dependency order: this ClusterSummary can undeploy only once the ClusterSummaries
+// created, for the same cluster, by all dependent profiles are gone or fully Removed.
+// Dependents of both kinds are considered: a tenant Profile depending on a ClusterProfile (ClusterProfile/<name>
+// entry in DependsOn) blocks the ClusterProfile from being withdrawn.
+func (r *ClusterSummaryReconciler) areDependentsRemoved(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) (allRemoved bool, dependentsMessage string, err error) {
+
+	profileReference, err := configv1beta1.GetProfileOwnerReference(clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get profile owner: %v", err))
+		return false, "", fmt.Errorf("failed to get profile owner: %w", err)
+	}
+
+	if profileReference == nil {
+		return false, "", fmt.Errorf("profile owner not found: %w", err)
+	}
+
+	cs := clusterSummaryScope.ClusterSummary
+	listOptions := []client.ListOption{
+		client.InNamespace(cs.Spec.ClusterNamespace),
+		client.MatchingLabels{
+			configv1beta1.ClusterNameLabel: cs.Spec.ClusterName,
+			configv1beta1.ClusterTypeLabel: string(cs.Spec.ClusterType),
+		},
+	}
+
+	clusterSummaryList := &configv1beta1.ClusterSummaryList{}
+	if err := r.List(ctx, clusterSummaryList, listOptions...); err != nil {
+		return false, "", err
+	}
+
+	blocking := make([]string, 0)
+	for i := range clusterSummaryList.Items {
+		dependent := &clusterSummaryList.Items[i]
+		if dependent.Name == cs.Name {
+			continue
+		}
+
+		dependentKind, dependentName := getClusterSummaryProfile(dependent)
+		if dependentName == "" {
+			continue
+		}
+
+		if !dependsOnProfile(dependent, dependentKind, profileReference.Kind, profileReference.Name) {
+			continue
+		}
+
+		if dependsOnProfile(cs, profileReference.Kind, dependentKind, dependentName) {
+			// profiles depending on each other: there is no order to honour
+			continue
+		}
+
+		if !dependent.DeletionTimestamp.IsZero() && isClusterSummaryRemoved(dependent) {
+			continue
+		}
+
+		blocking = append(blocking, fmt.Sprintf("%s %s", dependentKind, dependentName))
+	}
+
+	if len(blocking) != 0 {
+		sort.Strings(blocking)
+		msg := fmt.Sprintf("waiting for dependents to be removed: %s", strings.Join(blocking, ", "))
+		logger.V(logs.LogInfo).Info(msg)
+		return false, msg, nil
+	}
+
+	return true, "", nil
+}
+
+// getClusterSummaryProfile returns kind and name of the profile which created the ClusterSummary.
+// Name is empty if ClusterSummary has no profile label.
+func getClusterSummaryProfile(clusterSummary *configv1beta1.ClusterSummary) (kind, name string) {
+	if name, ok := clusterSummary.Labels[ClusterProfileLabelName]; ok {
+		return configv1beta1.ClusterProfileKind, name
+	}
+	if name, ok := clusterSummary.Labels[ProfileLabelName]; ok {
+		return configv1beta1.ProfileKind, name
+	}
+	return "", ""
+}
+
+// dependsOnProfile returns true if the ClusterSummary, created by a profile of kind dependentKind,
+// lists the profile kind/name in DependsOn
+func dependsOnProfile(clusterSummary *configv1beta1.ClusterSummary, dependentKind, kind, name string) bool {
+	for i := range clusterSummary.Spec.ClusterProfileSpec.DependsOn {
+		prerequisiteKind, prerequisiteName := configv1beta1.GetDependsOnProfile(dependentKind,
+			clusterSummary.Spec.ClusterProfileSpec.DependsOn[i])
+		if prerequisiteKind == kind && prerequisiteName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isClusterSummaryRemoved returns true if all features of a ClusterSummary are Removed
+func isClusterSummaryRemoved(clusterSummary *configv1beta1.ClusterSummary) bool {
+	for i := range clusterSummary.Status.FeatureSummaries {
+		if clusterSummary.Status.FeatureSummaries[i].Status != configv1beta1.FeatureStatusRemoved {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (r *ClusterSummaryReconciler) setFailureMessage(clusterSummaryScope *scope.ClusterSummaryScope, failureMessage string) {
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.HelmCharts != nil {
 		clusterSummaryScope.SetFailureMessage(configv1beta1.FeatureHelm, &failureMessage)
@@ -1375,12 +1621,12 @@ func (r *ClusterSummaryReconciler) cleanupQueuedCleanOperations(clusterSummary *
 		string(configv1beta1.FeatureResources), clusterSummary.Spec.ClusterType, true)
 }
 
-// resetFeatureStatusToProvisioning reset status from Provisioned to Provisioning
+// resetFeatureStatusToProvisioning reset status from Provisioned (or Degraded) to Provisioning
 func (r *ClusterSummaryReconciler) resetFeatureStatusToProvisioning(clusterSummaryScope *scope.ClusterSummaryScope) {
 	status := configv1beta1.FeatureStatusProvisioning
 	for i := range clusterSummaryScope.ClusterSummary.Status.FeatureSummaries {
 		fs := &clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[i]
-		if fs.Status == configv1beta1.FeatureStatusProvisioned {
+		if isFeatureProvisionedOrDegraded(fs) {
 			fs.Status = status
 		}
 	}
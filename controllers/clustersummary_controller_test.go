@@ -1072,6 +1072,163 @@ var _ = Describe("ClustersummaryController", func() {
 		Expect(err).To(BeNil())
 		Expect(deployed).To(BeTrue())
 	})
+
+	It("areDependentsRemoved returns false till ClusterSummaries of dependent profiles are removed", func() {
+		dependentProfileName := randomString()
+		dependentSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.GetClusterSummaryName(configv1beta1.ClusterProfileKind,
+					dependentProfileName, clusterName, false),
+				Namespace: namespace,
+				Labels: map[string]string{
+					controllers.ClusterProfileLabelName: dependentProfileName,
+					configv1beta1.ClusterNameLabel:      clusterName,
+					configv1beta1.ClusterTypeLabel:      string(libsveltosv1beta1.ClusterTypeCapi),
+				},
+				Finalizers: []string{configv1beta1.ClusterSummaryFinalizer},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					DependsOn: []string{clusterProfile.Name},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+				},
+			},
+		}
+
+		initObjects := []client.Object{
+			dependentSummary,
+			clusterSummary,
+			clusterProfile,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		addOwnerReference(context.TODO(), c, clusterSummary, clusterProfile)
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client:       c,
+			Scheme:       scheme,
+			ClusterMap:   make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ReferenceMap: make(map[corev1.ObjectReference]*libsveltosset.Set),
+			PolicyMux:    sync.Mutex{},
+		}
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		// dependent ClusterSummary still exists
+		removed, msg, err := controllers.AreDependentsRemoved(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(removed).To(BeFalse())
+		Expect(msg).To(ContainSubstring(dependentProfileName))
+
+		// dependent ClusterSummary is being deleted but add-ons are still deployed
+		Expect(c.Delete(context.TODO(), dependentSummary)).To(Succeed())
+		removed, _, err = controllers.AreDependentsRemoved(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(removed).To(BeFalse())
+
+		// dependent ClusterSummary has withdrawn everything
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: dependentSummary.Namespace, Name: dependentSummary.Name},
+			dependentSummary)).To(Succeed())
+		dependentSummary.Status.FeatureSummaries[0].Status = configv1beta1.FeatureStatusRemoved
+		Expect(c.Status().Update(context.TODO(), dependentSummary)).To(Succeed())
+		removed, _, err = controllers.AreDependentsRemoved(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(removed).To(BeTrue())
+	})
+
+	It("areDependentsRemoved returns false while a Profile depending on the ClusterProfile is deployed", func() {
+		dependentProfileName := randomString()
+		dependentSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.GetClusterSummaryName(configv1beta1.ProfileKind,
+					dependentProfileName, clusterName, false),
+				Namespace: namespace,
+				Labels: map[string]string{
+					controllers.ProfileLabelName:   dependentProfileName,
+					configv1beta1.ClusterNameLabel: clusterName,
+					configv1beta1.ClusterTypeLabel: string(libsveltosv1beta1.ClusterTypeCapi),
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1beta1.Spec{
+					DependsOn: []string{configv1beta1.ClusterProfileKind + "/" + clusterProfile.Name},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusProvisioned},
+				},
+			},
+		}
+
+		// Profile with same name as the ClusterProfile listed without the ClusterProfile prefix
+		// depends on a Profile, not on the ClusterProfile
+		unrelatedSummary := dependentSummary.DeepCopy()
+		unrelatedSummary.Name = randomString()
+		unrelatedSummary.Labels[controllers.ProfileLabelName] = randomString()
+		unrelatedSummary.Spec.ClusterProfileSpec.DependsOn = []string{clusterProfile.Name}
+
+		initObjects := []client.Object{
+			dependentSummary,
+			unrelatedSummary,
+			clusterSummary,
+			clusterProfile,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		addOwnerReference(context.TODO(), c, clusterSummary, clusterProfile)
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client:       c,
+			Scheme:       scheme,
+			ClusterMap:   make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ReferenceMap: make(map[corev1.ObjectReference]*libsveltosset.Set),
+			PolicyMux:    sync.Mutex{},
+		}
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		removed, msg, err := controllers.AreDependentsRemoved(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(removed).To(BeFalse())
+		Expect(msg).To(ContainSubstring(fmt.Sprintf("%s %s", configv1beta1.ProfileKind, dependentProfileName)))
+		Expect(msg).ToNot(ContainSubstring(unrelatedSummary.Labels[controllers.ProfileLabelName]))
+
+		Expect(c.Delete(context.TODO(), dependentSummary)).To(Succeed())
+		removed, _, err = controllers.AreDependentsRemoved(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(removed).To(BeTrue())
+	})
 })
 
 var _ = Describe("ClusterSummaryReconciler: requeue methods", func() {
@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -97,6 +96,17 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 		return err
 	}
 
+	// When a Rollback pins the feature to a prior revision, such revision is deployed instead
+	pinnedRevision, err := getPinnedRevision(ctx, r.Client, clusterSummary, f.id, logger)
+	if err != nil {
+		message := err.Error()
+		clusterSummaryScope.SetFailureMessage(f.id, &message)
+		return err
+	}
+	if pinnedRevision != nil {
+		currentHash = getPinnedRevisionHash(pinnedRevision)
+	}
+
 	hash := r.getHash(clusterSummaryScope, f.id)
 
 	isConfigSame := reflect.DeepEqual(hash, currentHash)
@@ -111,6 +121,12 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 		return nil
 	}
 
+	if r.isDeferredByDeploymentWindow(clusterSummaryScope, f, isConfigSame, logger) {
+		return nil
+	}
+
+	recordAuditTrigger(clusterSummaryScope, f.id, hash, currentHash, isConfigSame)
+
 	return r.proceedDeployingFeature(ctx, clusterSummaryScope, f, isConfigSame, currentHash, logger)
 }
 
@@ -131,27 +147,53 @@ func (r *ClusterSummaryReconciler) proceedDeployingFeature(ctx context.Context,
 		resultError = result.Err
 	}
 
-	if status != nil {
+	var dependencyNotReadyError *DependencyNotReadyError
+	if status != nil && errors.As(resultError, &dependencyNotReadyError) {
+		// Deployment is blocked waiting for another entry of the profile. This is not a failure.
+		// Feature stays Provisioning, reporting the blocking entry, and deployment is queued again.
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("deployment is waiting for dependencies: %v", resultError))
+		var previousMessage *string
+		if fs := getFeatureSummaryForFeatureID(clusterSummary, f.id); fs != nil {
+			previousMessage = fs.FailureMessage
+		}
+		s := configv1beta1.FeatureStatusProvisioning
+		status = &s
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, currentHash, nil, logger)
+		message := resultError.Error()
+		clusterSummaryScope.SetFailureMessage(f.id, &message)
+		if previousMessage == nil || *previousMessage != message {
+			recordNormalEvent(ctx, clusterSummary, eventReasonDependenciesNotDeployed, "Feature: %s: %s", f.id, message)
+		}
+	} else if status != nil && isConcurrencyLimitError(resultError) {
+		// Too many deployments in progress. This is not a failure, deployment is queued again.
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("deployment is throttled: %v", resultError))
+		s := configv1beta1.FeatureStatusProvisioning
+		status = &s
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, currentHash, nil, logger)
+		message := resultError.Error()
+		clusterSummaryScope.SetFailureMessage(f.id, &message)
+	} else if status != nil {
 		logger.V(logs.LogDebug).Info(fmt.Sprintf("result is available. updating status: %v", *status))
-		r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, resultError, logger)
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, currentHash, resultError, logger)
 		if *status == configv1beta1.FeatureStatusProvisioned {
-			message := fmt.Sprintf("Feature: %s deployed to cluster %s %s/%s", f.id,
-				clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName)
-			r.eventRecorder.Eventf(clusterSummary, corev1.EventTypeNormal, "sveltos", message)
+			recordNormalEvent(ctx, clusterSummary, eventReasonFeatureDeployed, "Feature: %s deployed to cluster %s",
+				f.id, getClusterDescription(clusterSummary))
 			return nil
 		}
 		if resultError != nil {
+			recordWarningEvent(ctx, clusterSummary, eventReasonFeatureFailed, "Feature: %s failed to deploy to cluster %s: %v",
+				f.id, getClusterDescription(clusterSummary), resultError)
 			// Check if error is a NonRetriableError type
 			var nonRetriableError *NonRetriableError
 			if errors.As(resultError, &nonRetriableError) {
 				nonRetriableStatus := configv1beta1.FeatureStatusFailedNonRetriable
-				r.updateFeatureStatus(clusterSummaryScope, f.id, &nonRetriableStatus, currentHash, resultError, logger)
+				r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, &nonRetriableStatus, currentHash, resultError, logger)
 				return nil
 			}
 			if r.maxNumberOfConsecutiveFailureReached(clusterSummaryScope, f, logger) {
 				nonRetriableStatus := configv1beta1.FeatureStatusFailedNonRetriable
 				resultError := errors.New("the maximum number of consecutive errors has been reached")
-				r.updateFeatureStatus(clusterSummaryScope, f.id, &nonRetriableStatus, currentHash, resultError, logger)
+				r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, &nonRetriableStatus, currentHash, resultError, logger)
 				return nil
 			}
 		}
@@ -162,12 +204,12 @@ func (r *ClusterSummaryReconciler) proceedDeployingFeature(ctx context.Context,
 		logger.V(logs.LogDebug).Info("no result is available. mark status as provisioning")
 		s := configv1beta1.FeatureStatusProvisioning
 		status = &s
-		r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, nil, logger)
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, currentHash, nil, logger)
 	}
 
 	// Getting here means either feature failed to be deployed or configuration has changed.
 	// Feature must be (re)deployed.
-	options := deployer.Options{HandlerOptions: map[string]string{}}
+	options := getDeploymentOptions(ctx, r.Client, clusterSummary, logger)
 	if getAgentInMgmtCluster() {
 		options.HandlerOptions[driftDetectionInMgtmCluster] = "management"
 	}
@@ -175,8 +217,8 @@ func (r *ClusterSummaryReconciler) proceedDeployingFeature(ctx context.Context,
 	logger.V(logs.LogDebug).Info("queueing request to deploy")
 	if err := r.Deployer.Deploy(ctx, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
 		clusterSummary.Name, string(f.id), clusterSummary.Spec.ClusterType, false,
-		genericDeploy, programDeployMetrics, options); err != nil {
-		r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, err, logger)
+		withDeployerEventRecorder(r.EventRecorder, genericDeploy), programDeployMetrics, options); err != nil {
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, currentHash, err, logger)
 		return err
 	}
 
@@ -193,6 +235,13 @@ func genericDeploy(ctx context.Context, c client.Client,
 	// Code common to all features
 
 	// Before any per feature specific code
+	if !isDeploymentSlotReserved(o) {
+		release, err := acquireDeploymentSlot(ctx, c, clusterNamespace, clusterName, clusterType, logger)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
 
 	// Invoking per feature specific code
 	featureHandler := getHandlersForFeature(configv1beta1.FeatureID(featureID))
@@ -240,11 +289,28 @@ func (r *ClusterSummaryReconciler) undeployFeature(ctx context.Context, clusterS
 		clusterSummaryScope.Name(), string(f.id), clusterSummary.Spec.ClusterType, true)
 	status := r.convertResultStatus(result)
 
-	if status != nil {
+	var dependencyNotReadyError *DependencyNotReadyError
+	if status != nil && errors.As(result.Err, &dependencyNotReadyError) {
+		// Withdrawal is blocked waiting for dependent entries to be removed first
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("withdrawal is waiting for dependents: %v", result.Err))
+		s := configv1beta1.FeatureStatusRemoving
+		status = &s
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, nil, nil, logger)
+		message := result.Err.Error()
+		clusterSummaryScope.SetFailureMessage(f.id, &message)
+	} else if status != nil && isConcurrencyLimitError(result.Err) {
+		// Too many deployments in progress. This is not a failure, withdrawal is queued again.
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("withdrawal is throttled: %v", result.Err))
+		s := configv1beta1.FeatureStatusRemoving
+		status = &s
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, nil, nil, logger)
+		message := result.Err.Error()
+		clusterSummaryScope.SetFailureMessage(f.id, &message)
+	} else if status != nil {
 		if *status == configv1beta1.FeatureStatusProvisioning {
 			s := configv1beta1.FeatureStatusRemoving
 			status = &s
-			r.updateFeatureStatus(clusterSummaryScope, f.id, status, nil, result.Err, logger)
+			r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, nil, result.Err, logger)
 			return fmt.Errorf("feature is still being removed")
 		}
 
@@ -253,25 +319,29 @@ func (r *ClusterSummaryReconciler) undeployFeature(ctx context.Context, clusterS
 			logger.V(logs.LogInfo).Info("undeploying failing because of missing permission.")
 			tmpStatus := configv1beta1.FeatureStatusRemoved
 			status = &tmpStatus
-			r.updateFeatureStatus(clusterSummaryScope, f.id, status, nil, result.Err, logger)
+			r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, nil, result.Err, logger)
 			return nil
 		}
 
-		r.updateFeatureStatus(clusterSummaryScope, f.id, status, nil, result.Err, logger)
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, nil, result.Err, logger)
 		if *status == configv1beta1.FeatureStatusRemoved {
+			recordNormalEvent(ctx, clusterSummary, eventReasonFeatureRemoved, "Feature: %s removed from cluster %s",
+				f.id, getClusterDescription(clusterSummary))
 			return nil
 		}
 	} else {
 		logger.V(logs.LogDebug).Info("no result is available. mark status as removing")
 		s := configv1beta1.FeatureStatusRemoving
 		status = &s
-		r.updateFeatureStatus(clusterSummaryScope, f.id, status, nil, nil, logger)
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, nil, nil, logger)
 	}
 
 	logger.V(logs.LogDebug).Info("queueing request to un-deploy")
 	if err := r.Deployer.Deploy(ctx, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
-		clusterSummary.Name, string(f.id), clusterSummary.Spec.ClusterType, true, genericUndeploy, programDuration, deployer.Options{}); err != nil {
-		r.updateFeatureStatus(clusterSummaryScope, f.id, status, nil, err, logger)
+		clusterSummary.Name, string(f.id), clusterSummary.Spec.ClusterType, true,
+		withDeployerEventRecorder(r.EventRecorder, genericUndeploy), programDuration,
+		getDeploymentOptions(ctx, r.Client, clusterSummary, logger)); err != nil {
+		r.updateFeatureStatus(ctx, clusterSummaryScope, f.id, status, nil, err, logger)
 		return err
 	}
 
@@ -299,6 +369,14 @@ func genericUndeploy(ctx context.Context, c client.Client,
 		return err
 	}
 
+	if !isDeploymentSlotReserved(o) {
+		release, err := acquireDeploymentSlot(ctx, c, clusterNamespace, clusterName, clusterType, logger)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
 	// Invoking per feature specific code
 	featureHandler := getHandlersForFeature(configv1beta1.FeatureID(featureID))
 	if err := featureHandler.undeploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger); err != nil {
@@ -323,12 +401,12 @@ func (r *ClusterSummaryReconciler) isFeatureStatusPresent(clusterSummary *config
 }
 
 // isFeatureDeployed returns true if feature is marked as deployed (present in FeatureSummaries and status
-// is set to Provisioned).
+// is set to Provisioned or Degraded).
 func (r *ClusterSummaryReconciler) isFeatureDeployed(clusterSummary *configv1beta1.ClusterSummary,
 	featureID configv1beta1.FeatureID) bool {
 
 	fs := getFeatureSummaryForFeatureID(clusterSummary, featureID)
-	if fs != nil && fs.Status == configv1beta1.FeatureStatusProvisioned {
+	if fs != nil && isFeatureProvisionedOrDegraded(fs) {
 		return true
 	}
 
@@ -388,7 +466,7 @@ func (r *ClusterSummaryReconciler) getConsecutiveFailures(clusterSummaryScope *s
 	return 0
 }
 
-func (r *ClusterSummaryReconciler) updateFeatureStatus(clusterSummaryScope *scope.ClusterSummaryScope,
+func (r *ClusterSummaryReconciler) updateFeatureStatus(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
 	featureID configv1beta1.FeatureID, status *configv1beta1.FeatureStatus, hash []byte, statusError error,
 	logger logr.Logger) {
 
@@ -399,11 +477,25 @@ func (r *ClusterSummaryReconciler) updateFeatureStatus(clusterSummaryScope *scop
 	logger.V(logs.LogDebug).Info("updating clustersummary status")
 	now := metav1.NewTime(time.Now())
 
+	var previousStatus configv1beta1.FeatureStatus
+	if fs := getFeatureSummaryForFeatureID(clusterSummaryScope.ClusterSummary, featureID); fs != nil {
+		previousStatus = fs.Status
+	}
+
 	switch *status {
 	case configv1beta1.FeatureStatusProvisioned:
 		failed := false
 		clusterSummaryScope.SetFeatureStatus(featureID, configv1beta1.FeatureStatusProvisioned, hash, &failed)
 		clusterSummaryScope.SetFailureMessage(featureID, nil)
+		if isDriftPending(clusterSummaryScope.ClusterSummary.Namespace, clusterSummaryScope.ClusterSummary.Name,
+			string(featureID)) {
+
+			recordNormalEvent(ctx, clusterSummaryScope.ClusterSummary, eventReasonDriftRemediated,
+				"Feature: %s configuration drift remediated in cluster %s", featureID,
+				getClusterDescription(clusterSummaryScope.ClusterSummary))
+		}
+		trackDriftRemediation(clusterSummaryScope.ClusterSummary, string(featureID), logger)
+		clusterSummaryScope.ResetDriftDetectedTime(featureID)
 	case configv1beta1.FeatureStatusRemoved:
 		failed := false
 		clusterSummaryScope.SetFeatureStatus(featureID, configv1beta1.FeatureStatusRemoved, hash, &failed)
@@ -419,6 +511,8 @@ func (r *ClusterSummaryReconciler) updateFeatureStatus(clusterSummaryScope *scop
 		clusterSummaryScope.SetFailureMessage(featureID, &err)
 	}
 
+	notifyFeatureTransition(clusterSummaryScope.ClusterSummary, featureID, previousStatus, *status, statusError,
+		logger)
 	clusterSummaryScope.SetLastAppliedTime(featureID, &now)
 }
 
@@ -249,7 +249,7 @@ var _ = Describe("ClustersummaryDeployer", func() {
 		hash := []byte(randomString())
 		status := configv1beta1.FeatureStatusFailed
 		statusErr := fmt.Errorf("failed to deploy")
-		controllers.UpdateFeatureStatus(reconciler, clusterSummaryScope, configv1beta1.FeatureResources, &status,
+		controllers.UpdateFeatureStatus(reconciler, context.TODO(), clusterSummaryScope, configv1beta1.FeatureResources, &status,
 			hash, statusErr, textlogger.NewLogger(textlogger.NewConfig()))
 
 		Expect(len(clusterSummary.Status.FeatureSummaries)).To(Equal(1))
@@ -259,7 +259,7 @@ var _ = Describe("ClustersummaryDeployer", func() {
 		Expect(*clusterSummary.Status.FeatureSummaries[0].FailureMessage).To(Equal(statusErr.Error()))
 
 		status = configv1beta1.FeatureStatusProvisioned
-		controllers.UpdateFeatureStatus(reconciler, clusterSummaryScope, configv1beta1.FeatureResources, &status,
+		controllers.UpdateFeatureStatus(reconciler, context.TODO(), clusterSummaryScope, configv1beta1.FeatureResources, &status,
 			hash, nil, textlogger.NewLogger(textlogger.NewConfig()))
 		Expect(clusterSummary.Status.FeatureSummaries[0].FeatureID).To(Equal(configv1beta1.FeatureResources))
 		Expect(clusterSummary.Status.FeatureSummaries[0].Status).To(Equal(configv1beta1.FeatureStatusProvisioned))
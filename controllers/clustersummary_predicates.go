@@ -17,6 +17,7 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -275,7 +276,7 @@ func fluxGenericPredicate(obj client.Object, logger logr.Logger) bool {
 }
 
 func hasArtifactChanged(objNew, objOld client.Object) bool {
-	addTypeInformationToObject(getManagementClusterClient().Scheme(), objNew)
+	addTypeInformationToObject(getManagementClusterClient(context.TODO()).Scheme(), objNew)
 	switch objNew.GetObjectKind().GroupVersionKind().Kind {
 	case sourcev1.GitRepositoryKind:
 		newGitRepo := objNew.(*sourcev1.GitRepository)
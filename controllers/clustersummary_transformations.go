@@ -29,6 +29,7 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
@@ -184,6 +185,43 @@ func (r *ClusterSummaryReconciler) requeueClusterSummaryForReference(
 	return requests
 }
 
+// requeueClusterSummaryForRollback is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for ClusterSummaries created by the ClusterProfile/Profile a Rollback references.
+func (r *ClusterSummaryReconciler) requeueClusterSummaryForRollback(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	rollback := o.(*configv1beta1.Rollback)
+	logger := r.Logger.WithValues(
+		"objectMapper",
+		"requeueClusterSummaryForRollback",
+		"rollback",
+		rollback.Name,
+	)
+
+	logger.V(logs.LogVerbose).Info("reacting to Rollback change")
+
+	profileRef := &rollback.Spec.ProfileRef
+	clusterSummaries, err := getProfileClusterSummaries(ctx, r.Client, profileRef.Kind, profileRef.Namespace,
+		profileRef.Name)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+		return nil
+	}
+
+	requests := make([]ctrl.Request, len(clusterSummaries.Items))
+	for i := range clusterSummaries.Items {
+		requests[i] = ctrl.Request{
+			NamespacedName: client.ObjectKey{
+				Namespace: clusterSummaries.Items[i].Namespace,
+				Name:      clusterSummaries.Items[i].Name,
+			},
+		}
+	}
+
+	return requests
+}
+
 // requeueClusterSummaryForCluster is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
 // for ClusterSummary to update when its own Sveltos Cluster gets updated.
 func (r *ClusterSummaryReconciler) requeueClusterSummaryForSveltosCluster(
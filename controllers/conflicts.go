@@ -25,7 +25,6 @@ import (
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
-	"github.com/projectsveltos/libsveltos/lib/deployer"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
@@ -78,11 +77,9 @@ func getTier(tierData string) int32 {
 func requeueClusterSummary(ctx context.Context, featureID configv1beta1.FeatureID,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
 
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 
-	// TODO: change deployer to have an initialize method and a GetDeployer
-	// At this point deployer has been already initialized, so the argurment of GetClient are not important
-	d := deployer.GetClient(ctx, logger, c, 1)
+	d := getDeployer(ctx, c, logger)
 
 	if d.IsInProgress(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
 		clusterSummary.Name, string(featureID), clusterSummary.Spec.ClusterType, false) {
@@ -19,3 +19,7 @@ package dependencymanager
 func (m *instance) GetTrackedProfiles() ProfileDeployments {
 	return m.profileClusterRequests
 }
+
+var (
+	GetPrerequesites = getPrerequesites
+)
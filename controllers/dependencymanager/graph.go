@@ -0,0 +1,326 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependencymanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+const (
+	// ClusterStatusPending is reported for a cluster where a profile needs to be deployed
+	// but no ClusterSummary has reported any status yet
+	ClusterStatusPending = "Pending"
+)
+
+// GraphNode is a profile in the dependency graph
+type GraphNode struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+
+	// Clusters contains the provisioning status of this profile in each cluster
+	Clusters []ClusterStatus `json:"clusters,omitempty"`
+}
+
+// ClusterStatus is the provisioning status of a profile in a cluster
+type ClusterStatus struct {
+	Cluster corev1.ObjectReference `json:"cluster"`
+
+	// Status summarizes the status of all features deployed by the profile in this cluster
+	Status string `json:"status"`
+
+	// RequestedBy lists the dependent profiles this profile was automatically deployed
+	// to this cluster for
+	RequestedBy []corev1.ObjectReference `json:"requestedBy,omitempty"`
+}
+
+// GraphEdge indicates that Dependent depends on Prerequisite
+type GraphEdge struct {
+	Dependent    corev1.ObjectReference `json:"dependent"`
+	Prerequisite corev1.ObjectReference `json:"prerequisite"`
+}
+
+// DependencyGraph is the graph of profiles (ClusterProfiles and Profiles) linked by DependsOn
+type DependencyGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// BuildDependencyGraph builds the profile dependency graph. Only profiles with dependencies or
+// being a dependency are part of the graph. If cluster is set, only the profiles deployed (or to be
+// deployed) to that cluster and their status in that cluster are reported.
+func (m *instance) BuildDependencyGraph(ctx context.Context, c client.Client, cluster *corev1.ObjectReference,
+) (*DependencyGraph, error) {
+
+	nodes := map[corev1.ObjectReference]*GraphNode{}
+	edges := make([]GraphEdge, 0)
+
+	addNode := func(ref *corev1.ObjectReference) {
+		if _, ok := nodes[*ref]; !ok {
+			nodes[*ref] = &GraphNode{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}
+		}
+	}
+	addEdges := func(dependent *corev1.ObjectReference, dependsOn []string) {
+		for i := range dependsOn {
+			kind, name := configv1beta1.GetDependsOnProfile(dependent.Kind, dependsOn[i])
+			prerequisite := getProfileKey(kind, dependent.Namespace, name)
+			addNode(dependent)
+			addNode(prerequisite)
+			edges = append(edges, GraphEdge{Dependent: *dependent, Prerequisite: *prerequisite})
+		}
+	}
+
+	clusterProfiles := &configv1beta1.ClusterProfileList{}
+	if err := c.List(ctx, clusterProfiles); err != nil {
+		return nil, err
+	}
+	for i := range clusterProfiles.Items {
+		cp := &clusterProfiles.Items[i]
+		addEdges(getProfileKey(configv1beta1.ClusterProfileKind, "", cp.Name), cp.Spec.DependsOn)
+	}
+
+	profiles := &configv1beta1.ProfileList{}
+	if err := c.List(ctx, profiles); err != nil {
+		return nil, err
+	}
+	for i := range profiles.Items {
+		p := &profiles.Items[i]
+		addEdges(getProfileKey(configv1beta1.ProfileKind, p.Namespace, p.Name), p.Spec.DependsOn)
+	}
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := c.List(ctx, clusterSummaries); err != nil {
+		return nil, err
+	}
+	for i := range clusterSummaries.Items {
+		cs := &clusterSummaries.Items[i]
+		owner, err := configv1beta1.GetProfileOwnerReference(cs)
+		if err != nil || owner == nil {
+			continue
+		}
+		node, ok := nodes[*getProfileKey(owner.Kind, cs.Namespace, owner.Name)]
+		if !ok {
+			continue
+		}
+		node.Clusters = append(node.Clusters, ClusterStatus{
+			Cluster: getClusterRef(cs),
+			Status:  getClusterSummaryStatus(cs),
+		})
+	}
+
+	m.addRequestingProfiles(nodes)
+
+	return filterGraph(nodes, edges, cluster), nil
+}
+
+// addRequestingProfiles reports, for each profile, the dependents which caused the profile
+// to be automatically deployed to a cluster
+func (m *instance) addRequestingProfiles(nodes map[corev1.ObjectReference]*GraphNode) {
+	if !m.enabled {
+		return
+	}
+
+	m.chartMux.RLock()
+	defer m.chartMux.RUnlock()
+
+	for profile, node := range nodes {
+		clusterDeployments := m.profileClusterRequests.getClusterDeployments(&profile)
+		for clusterRef, requestingProfiles := range clusterDeployments.Clusters {
+			dependents := make([]corev1.ObjectReference, 0, len(requestingProfiles.Dependents))
+			for dependent := range requestingProfiles.Dependents {
+				dependents = append(dependents, dependent)
+			}
+			sortReferences(dependents)
+
+			found := false
+			for i := range node.Clusters {
+				if isSameCluster(&node.Clusters[i].Cluster, &clusterRef) {
+					node.Clusters[i].RequestedBy = dependents
+					found = true
+				}
+			}
+			if !found {
+				node.Clusters = append(node.Clusters, ClusterStatus{
+					Cluster:     clusterRef,
+					Status:      ClusterStatusPending,
+					RequestedBy: dependents,
+				})
+			}
+		}
+	}
+}
+
+func filterGraph(nodes map[corev1.ObjectReference]*GraphNode, edges []GraphEdge,
+	cluster *corev1.ObjectReference) *DependencyGraph {
+
+	graph := &DependencyGraph{Nodes: make([]GraphNode, 0), Edges: make([]GraphEdge, 0)}
+	kept := map[corev1.ObjectReference]bool{}
+
+	for ref, node := range nodes {
+		if cluster != nil {
+			clusters := make([]ClusterStatus, 0)
+			for i := range node.Clusters {
+				if isSameCluster(&node.Clusters[i].Cluster, cluster) {
+					clusters = append(clusters, node.Clusters[i])
+				}
+			}
+			if len(clusters) == 0 {
+				continue
+			}
+			node.Clusters = clusters
+		}
+
+		sort.Slice(node.Clusters, func(i, j int) bool {
+			return clusterKey(&node.Clusters[i].Cluster) < clusterKey(&node.Clusters[j].Cluster)
+		})
+		kept[ref] = true
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+
+	for i := range edges {
+		if kept[edges[i].Dependent] && kept[edges[i].Prerequisite] {
+			graph.Edges = append(graph.Edges, edges[i])
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return nodeID(graph.Nodes[i].Kind, graph.Nodes[i].Namespace, graph.Nodes[i].Name) <
+			nodeID(graph.Nodes[j].Kind, graph.Nodes[j].Namespace, graph.Nodes[j].Name)
+	})
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		return edgeID(&graph.Edges[i]) < edgeID(&graph.Edges[j])
+	})
+
+	return graph
+}
+
+// DOT returns the graph in Graphviz DOT format. Edges go from dependent to prerequisite.
+func (g *DependencyGraph) DOT() string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph profiles {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for i := range g.Nodes {
+		node := &g.Nodes[i]
+		label := nodeID(node.Kind, node.Namespace, node.Name)
+		for j := range node.Clusters {
+			label += fmt.Sprintf("\\n%s: %s", clusterKey(&node.Clusters[j].Cluster), node.Clusters[j].Status)
+		}
+		sb.WriteString(fmt.Sprintf("  %q [label=%q, color=%s];\n",
+			nodeID(node.Kind, node.Namespace, node.Name), label, getNodeColor(node)))
+	}
+
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		sb.WriteString(fmt.Sprintf("  %q -> %q;\n",
+			nodeID(e.Dependent.Kind, e.Dependent.Namespace, e.Dependent.Name),
+			nodeID(e.Prerequisite.Kind, e.Prerequisite.Namespace, e.Prerequisite.Name)))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// getNodeColor returns red if the profile failed in at least one cluster, green if the profile is
+// provisioned everywhere, orange otherwise
+func getNodeColor(node *GraphNode) string {
+	color := "green"
+	for i := range node.Clusters {
+		switch node.Clusters[i].Status {
+		case string(configv1beta1.FeatureStatusProvisioned), string(configv1beta1.FeatureStatusRemoved):
+		case string(configv1beta1.FeatureStatusFailed), string(configv1beta1.FeatureStatusFailedNonRetriable):
+			return "red"
+		default:
+			color = "orange"
+		}
+	}
+	return color
+}
+
+// getClusterSummaryStatus returns the status of a ClusterSummary, Pending if none was reported yet
+func getClusterSummaryStatus(cs *configv1beta1.ClusterSummary) string {
+	status := configv1beta1.GetClusterSummaryStatus(cs)
+	if status == "" {
+		return ClusterStatusPending
+	}
+	return string(status)
+}
+
+func getProfileKey(kind, namespace, name string) *corev1.ObjectReference {
+	if kind == configv1beta1.ClusterProfileKind {
+		namespace = ""
+	}
+	return &corev1.ObjectReference{
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+		APIVersion: configv1beta1.GroupVersion.String(),
+	}
+}
+
+func getClusterRef(cs *configv1beta1.ClusterSummary) corev1.ObjectReference {
+	ref := corev1.ObjectReference{
+		Namespace:  cs.Spec.ClusterNamespace,
+		Name:       cs.Spec.ClusterName,
+		Kind:       libsveltosv1beta1.SveltosClusterKind,
+		APIVersion: libsveltosv1beta1.GroupVersion.String(),
+	}
+	if cs.Spec.ClusterType == libsveltosv1beta1.ClusterTypeCapi {
+		ref.Kind = "Cluster"
+		ref.APIVersion = clusterv1.GroupVersion.String()
+	}
+	return ref
+}
+
+func isSameCluster(a, b *corev1.ObjectReference) bool {
+	return a.Kind == b.Kind && a.Namespace == b.Namespace && a.Name == b.Name
+}
+
+func clusterKey(ref *corev1.ObjectReference) string {
+	return fmt.Sprintf("%s:%s/%s", ref.Kind, ref.Namespace, ref.Name)
+}
+
+func nodeID(kind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s", kind, name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+func edgeID(e *GraphEdge) string {
+	return nodeID(e.Dependent.Kind, e.Dependent.Namespace, e.Dependent.Name) + "->" +
+		nodeID(e.Prerequisite.Kind, e.Prerequisite.Namespace, e.Prerequisite.Name)
+}
+
+func sortReferences(refs []corev1.ObjectReference) {
+	sort.Slice(refs, func(i, j int) bool {
+		return nodeID(refs[i].Kind, refs[i].Namespace, refs[i].Name) <
+			nodeID(refs[j].Kind, refs[j].Namespace, refs[j].Name)
+	})
+}
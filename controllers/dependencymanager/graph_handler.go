@@ -0,0 +1,166 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependencymanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// GraphPath is the path the dependency graph is served at on the diagnostics server
+	GraphPath = "/debug/dependencies"
+
+	graphFormatJSON = "json"
+	graphFormatDOT  = "dot"
+
+	// graphJSONKey and graphDOTKey are the keys of the dependency graph ConfigMap
+	graphJSONKey = "graph.json"
+	graphDOTKey  = "graph.dot"
+)
+
+// GraphHandler serves the profile dependency graph.
+// Query parameters:
+//   - format: json (default) or dot;
+//   - clusterNamespace, clusterName and clusterType (Capi or Sveltos): only reports profiles
+//     deployed to this cluster.
+func GraphHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m, err := GetManagerInstance()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		cluster, err := getClusterFromQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		graph, err := m.BuildDependencyGraph(r.Context(), m.client, cluster)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		switch format := r.URL.Query().Get("format"); format {
+		case "", graphFormatJSON:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(graph)
+		case graphFormatDOT:
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+			_, _ = w.Write([]byte(graph.DOT()))
+		default:
+			http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		}
+	})
+}
+
+func getClusterFromQuery(r *http.Request) (*corev1.ObjectReference, error) {
+	query := r.URL.Query()
+	clusterName := query.Get("clusterName")
+	if clusterName == "" {
+		return nil, nil
+	}
+
+	cluster := &corev1.ObjectReference{
+		Namespace: query.Get("clusterNamespace"),
+		Name:      clusterName,
+	}
+
+	switch clusterType := query.Get("clusterType"); libsveltosv1beta1.ClusterType(clusterType) {
+	case libsveltosv1beta1.ClusterTypeCapi:
+		cluster.Kind = "Cluster"
+		cluster.APIVersion = clusterv1.GroupVersion.String()
+	case libsveltosv1beta1.ClusterTypeSveltos, "":
+		cluster.Kind = libsveltosv1beta1.SveltosClusterKind
+		cluster.APIVersion = libsveltosv1beta1.GroupVersion.String()
+	default:
+		return nil, fmt.Errorf("unsupported clusterType %q", clusterType)
+	}
+
+	return cluster, nil
+}
+
+// StartGraphDump periodically stores the profile dependency graph, in JSON and DOT format,
+// in the ConfigMap namespace/name
+func StartGraphDump(ctx context.Context, namespace, name string, interval time.Duration, logger logr.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m, err := GetManagerInstance()
+			if err != nil {
+				continue
+			}
+			if err := m.dumpGraph(ctx, namespace, name); err != nil {
+				logger.V(logsettings.LogInfo).Info(fmt.Sprintf("failed to dump dependency graph: %v", err))
+			}
+		}
+	}
+}
+
+func (m *instance) dumpGraph(ctx context.Context, namespace, name string) error {
+	graph, err := m.BuildDependencyGraph(ctx, m.client, nil)
+	if err != nil {
+		return err
+	}
+
+	graphJSON, err := json.Marshal(graph)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string{
+		graphJSONKey: string(graphJSON),
+		graphDOTKey:  graph.DOT(),
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = m.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Data:       data,
+			}
+			return m.client.Create(ctx, configMap)
+		}
+		return err
+	}
+
+	configMap.Data = data
+	return m.client.Update(ctx, configMap)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependencymanager_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/dependencymanager"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Dependency graph", func() {
+	It("BuildDependencyGraph reports profiles, dependencies and per-cluster status", func() {
+		namespace := randomString()
+
+		// Profile app depends on Profile database and on ClusterProfile crds
+		crds := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+		database := &configv1beta1.Profile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+		}
+		app := &configv1beta1.Profile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Spec: configv1beta1.Spec{
+				DependsOn: []string{database.Name, configv1beta1.ClusterProfileKind + "/" + crds.Name},
+			},
+		}
+		// Profile with no dependencies is not part of the graph
+		other := &configv1beta1.Profile{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+		}
+
+		cluster1 := randomString()
+		cluster2 := randomString()
+
+		initObjects := []client.Object{
+			crds, database, app, other,
+			getClusterSummary(configv1beta1.ClusterProfileKind, crds.Name, namespace, cluster1,
+				configv1beta1.FeatureStatusProvisioned),
+			getClusterSummary(configv1beta1.ClusterProfileKind, crds.Name, namespace, cluster2,
+				configv1beta1.FeatureStatusFailed),
+			getClusterSummary(configv1beta1.ProfileKind, database.Name, namespace, cluster1,
+				configv1beta1.FeatureStatusProvisioning),
+			getClusterSummary(configv1beta1.ProfileKind, other.Name, namespace, cluster1,
+				configv1beta1.FeatureStatusProvisioned),
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		manager, err := dependencymanager.GetManagerInstance()
+		Expect(err).To(BeNil())
+
+		graph, err := manager.BuildDependencyGraph(context.TODO(), fakeClient, nil)
+		Expect(err).To(BeNil())
+		Expect(len(graph.Nodes)).To(Equal(3))
+		Expect(len(graph.Edges)).To(Equal(2))
+
+		for i := range graph.Nodes {
+			Expect(graph.Nodes[i].Name).ToNot(Equal(other.Name))
+			if graph.Nodes[i].Name == crds.Name {
+				Expect(graph.Nodes[i].Kind).To(Equal(configv1beta1.ClusterProfileKind))
+				Expect(len(graph.Nodes[i].Clusters)).To(Equal(2))
+			}
+		}
+
+		dot := graph.DOT()
+		Expect(dot).To(ContainSubstring("digraph"))
+		Expect(dot).To(ContainSubstring("\"Profile/" + namespace + "/" + app.Name + "\" -> \"ClusterProfile/" + crds.Name + "\""))
+		Expect(dot).To(ContainSubstring("Failed"))
+
+		// Filter by cluster: only crds is deployed to cluster2
+		graph, err = manager.BuildDependencyGraph(context.TODO(), fakeClient, &corev1.ObjectReference{
+			Namespace: namespace, Name: cluster2, Kind: libsveltosv1beta1.SveltosClusterKind,
+		})
+		Expect(err).To(BeNil())
+		Expect(len(graph.Nodes)).To(Equal(1))
+		Expect(graph.Nodes[0].Name).To(Equal(crds.Name))
+		Expect(len(graph.Nodes[0].Clusters)).To(Equal(1))
+		Expect(graph.Nodes[0].Clusters[0].Status).To(Equal(string(configv1beta1.FeatureStatusFailed)))
+		Expect(graph.Edges).To(BeEmpty())
+	})
+})
+
+func getClusterSummary(profileKind, profileName, clusterNamespace, clusterName string,
+	status configv1beta1.FeatureStatus) *configv1beta1.ClusterSummary {
+
+	return &configv1beta1.ClusterSummary{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: clusterNamespace,
+			Name:      randomString(),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					Kind:       profileKind,
+					APIVersion: configv1beta1.GroupVersion.String(),
+					Name:       profileName,
+					UID:        "uid",
+				},
+			},
+		},
+		Spec: configv1beta1.ClusterSummarySpec{
+			ClusterNamespace: clusterNamespace,
+			ClusterName:      clusterName,
+			ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+		},
+		Status: configv1beta1.ClusterSummaryStatus{
+			FeatureSummaries: []configv1beta1.FeatureSummary{
+				{FeatureID: configv1beta1.FeatureHelm, Status: status},
+			},
+		},
+	}
+}
@@ -173,6 +173,9 @@ type instance struct {
 	// enabled indicates whether auto deployment of pre-requesities is enabled or not
 	enabled bool
 
+	// client is used to build the dependency graph
+	client client.Client
+
 	// initialzied indicates whether manager is fully initialized (if enabled on boot, it
 	// reads profile instances to rebuild internal state)
 	initialized int32 // 0 = false, 1 = true
@@ -195,6 +198,7 @@ func InitializeManagerInstance(ctx context.Context, c client.Client, enabled boo
 				profilePrerequisites:   make(map[corev1.ObjectReference]*libsveltosset.Set),
 				profileToBeUpdated:     make(map[corev1.ObjectReference]bool),
 				enabled:                enabled,
+				client:                 c,
 			}
 
 			if enabled {
@@ -545,21 +549,28 @@ func (m *instance) rebuildStateWithClusterProfiles(ctx context.Context, c client
 		clusterProfileRef := &corev1.ObjectReference{Kind: configv1beta1.ClusterProfileKind,
 			APIVersion: configv1beta1.GroupVersion.String(), Name: clusterProfile.Name}
 		m.UpdateDependencies(clusterProfileRef, clusterProfile.Status.MatchingClusterRefs,
-			getPrerequesites(configv1beta1.ProfileKind, "", clusterProfile.Spec.DependsOn), logger)
+			getPrerequesites(configv1beta1.ClusterProfileKind, "", clusterProfile.Spec.DependsOn), logger)
 	}
 
 	return nil
 }
 
+// getPrerequesites returns the profiles of the same kind as the dependent listed in dependsOn.
+// Prerequisites of a different kind (a Profile depending on a ClusterProfile) are never automatically
+// deployed: a tenant Profile cannot cause a ClusterProfile to be deployed to more clusters.
 func getPrerequesites(kind, namespace string, dependsOn []string) []corev1.ObjectReference {
-	prerequesites := make([]corev1.ObjectReference, len(dependsOn))
+	prerequesites := make([]corev1.ObjectReference, 0, len(dependsOn))
 	for i := range dependsOn {
-		prerequesites[i] = corev1.ObjectReference{
+		prerequisiteKind, name := configv1beta1.GetDependsOnProfile(kind, dependsOn[i])
+		if prerequisiteKind != kind {
+			continue
+		}
+		prerequesites = append(prerequesites, corev1.ObjectReference{
 			Namespace:  namespace,
-			Name:       dependsOn[i],
+			Name:       name,
 			Kind:       kind,
 			APIVersion: configv1beta1.GroupVersion.String(),
-		}
+		})
 	}
 
 	return prerequesites
@@ -50,6 +50,29 @@ var _ = Describe("Dependency manager", func() {
 		logger = textlogger.NewLogger(textlogger.NewConfig())
 	})
 
+	It("getPrerequesites never returns ClusterProfiles a Profile depends on", func() {
+		clusterProfileName := randomString()
+		profile.Spec.DependsOn = append(profile.Spec.DependsOn,
+			configv1beta1.ClusterProfileKind+"/"+clusterProfileName)
+
+		prerequisites := dependencymanager.GetPrerequesites(configv1beta1.ProfileKind, profile.Namespace,
+			profile.Spec.DependsOn)
+		Expect(len(prerequisites)).To(Equal(len(profile.Spec.DependsOn) - 1))
+		for i := range prerequisites {
+			Expect(prerequisites[i].Kind).To(Equal(configv1beta1.ProfileKind))
+			Expect(prerequisites[i].Namespace).To(Equal(profile.Namespace))
+			Expect(prerequisites[i].Name).ToNot(Equal(clusterProfileName))
+		}
+
+		// ClusterProfiles can use the same form to reference other ClusterProfiles
+		prerequisites = dependencymanager.GetPrerequesites(configv1beta1.ClusterProfileKind, "",
+			[]string{clusterProfileName, configv1beta1.ClusterProfileKind + "/" + clusterProfileName})
+		Expect(len(prerequisites)).To(Equal(2))
+		Expect(prerequisites[0]).To(Equal(prerequisites[1]))
+		Expect(prerequisites[0].Kind).To(Equal(configv1beta1.ClusterProfileKind))
+		Expect(prerequisites[0].Name).To(Equal(clusterProfileName))
+	})
+
 	It("updateConfigMap tracks prerequisites", func() {
 		manager, err := dependencymanager.GetManagerInstance()
 		Expect(err).To(BeNil())
@@ -0,0 +1,203 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Withdrawal in reverse dependency order
+// A ClusterSummary being deleted waits for the ClusterSummaries created, for the same cluster, by profiles
+// depending on its profile to be withdrawn first (see areDependentsRemoved). While waiting, its features
+// report WaitingForDependentsReason and the owning ClusterProfile/Profile WaitingForDependents condition
+// lists the clusters where withdrawal is waiting and for which dependents.
+// A dependent profile which is not deleted and keeps matching the cluster would block the withdrawal
+// forever. Setting the ForceWithdrawalAnnotation on the ClusterProfile/Profile being withdrawn stops waiting.
+
+const (
+	// maxClustersInWaitingCondition is the maximum number of clusters listed in the WaitingForDependents condition
+	maxClustersInWaitingCondition = 10
+)
+
+// isWithdrawalForced returns true if the ClusterProfile/Profile owning the ClusterSummary has the
+// ForceWithdrawalAnnotation set
+func isWithdrawalForced(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+) (bool, error) {
+
+	ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || ownerRef == nil {
+		return false, err
+	}
+
+	ref := &configv1beta1.ProfileReference{Kind: ownerRef.Kind, Name: ownerRef.Name}
+	if ownerRef.Kind == configv1beta1.ProfileKind {
+		ref.Namespace = clusterSummary.Namespace
+	}
+
+	profile, _, _, err := getReferencedProfile(ctx, c, ref)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	_, ok := profile.GetAnnotations()[configv1beta1.ForceWithdrawalAnnotation]
+	return ok, nil
+}
+
+// setWaitingForDependents reports, on each feature, whether withdrawal is waiting for dependent
+// profiles to be withdrawn first
+func setWaitingForDependents(clusterSummaryScope *scope.ClusterSummaryScope, waiting bool, message string) {
+	for i := range clusterSummaryScope.ClusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[i]
+		if waiting {
+			reason := configv1beta1.WaitingForDependentsReason
+			msg := message
+			fs.FailureReason = &reason
+			fs.FailureMessage = &msg
+		} else if fs.FailureReason != nil && *fs.FailureReason == configv1beta1.WaitingForDependentsReason {
+			fs.FailureReason = nil
+			fs.FailureMessage = nil
+		}
+	}
+}
+
+// isWaitingForDependents returns true if withdrawal of the ClusterSummary is waiting for dependent profiles
+func isWaitingForDependents(clusterSummary *configv1beta1.ClusterSummary) bool {
+	if clusterSummary.DeletionTimestamp.IsZero() {
+		return false
+	}
+
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		if fs.FailureReason != nil && *fs.FailureReason == configv1beta1.WaitingForDependentsReason {
+			return true
+		}
+	}
+	return false
+}
+
+// setWaitingForDependentsCondition sets (or removes when no withdrawal is waiting) the WaitingForDependents
+// condition. Returns true if conditions changed.
+func setWaitingForDependentsCondition(status *configv1beta1.Status,
+	clusterSummaries []configv1beta1.ClusterSummary, generation int64) bool {
+
+	waiting := make([]string, 0)
+	for i := range clusterSummaries {
+		cs := &clusterSummaries[i]
+		if !isWaitingForDependents(cs) {
+			continue
+		}
+		entry := fmt.Sprintf("cluster %s:%s/%s", cs.Spec.ClusterType, cs.Spec.ClusterNamespace, cs.Spec.ClusterName)
+		for j := range cs.Status.FeatureSummaries {
+			if msg := cs.Status.FeatureSummaries[j].FailureMessage; msg != nil {
+				entry += fmt.Sprintf(": %s", *msg)
+				break
+			}
+		}
+		waiting = append(waiting, entry)
+	}
+
+	if len(waiting) == 0 {
+		return meta.RemoveStatusCondition(&status.Conditions, configv1beta1.WaitingForDependentsCondition)
+	}
+
+	sort.Strings(waiting)
+	message := strings.Join(waiting[:min(len(waiting), maxClustersInWaitingCondition)], "; ")
+	if len(waiting) > maxClustersInWaitingCondition {
+		message += fmt.Sprintf(" (and %d more)", len(waiting)-maxClustersInWaitingCondition)
+	}
+	message += fmt.Sprintf(". To withdraw without waiting, set annotation %s",
+		configv1beta1.ForceWithdrawalAnnotation)
+
+	return meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               configv1beta1.WaitingForDependentsCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             configv1beta1.WaitingForDependentsReason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// updateProfileWaitingForDependentsCondition updates the WaitingForDependents condition of the
+// ClusterProfile/Profile owning the ClusterSummary. The ClusterSummary status not persisted yet
+// is used in place of the stored one.
+func updateProfileWaitingForDependentsCondition(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
+
+	ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || ownerRef == nil {
+		return err
+	}
+
+	ref := &configv1beta1.ProfileReference{Kind: ownerRef.Kind, Name: ownerRef.Name}
+	if ownerRef.Kind == configv1beta1.ProfileKind {
+		ref.Namespace = clusterSummary.Namespace
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		profile, _, status, err := getReferencedProfile(ctx, c, ref)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		clusterSummaries, err := getProfileClusterSummaries(ctx, c, ref.Kind, ref.Namespace, ref.Name)
+		if err != nil {
+			return err
+		}
+		for i := range clusterSummaries.Items {
+			if clusterSummaries.Items[i].Namespace == clusterSummary.Namespace &&
+				clusterSummaries.Items[i].Name == clusterSummary.Name {
+
+				clusterSummaries.Items[i] = *clusterSummary
+			}
+		}
+
+		if !setWaitingForDependentsCondition(status, clusterSummaries.Items, profile.GetGeneration()) {
+			return nil
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("updating %s %s WaitingForDependents condition",
+			ref.Kind, ref.Name))
+		return c.Status().Update(ctx, profile)
+	})
+}
+
+// updateWaitingForDependentsCondition recomputes the WaitingForDependents condition of the ClusterProfile/Profile.
+// Conditions are persisted when profileScope is closed.
+func updateWaitingForDependentsCondition(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
+	clusterSummaries, err := getProfileClusterSummaries(ctx, c, profileScope.GetKind(),
+		profileScope.Profile.GetNamespace(), profileScope.Name())
+	if err != nil {
+		return err
+	}
+
+	setWaitingForDependentsCondition(profileScope.GetStatus(), clusterSummaries.Items,
+		profileScope.Profile.GetGeneration())
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Dependents removal", func() {
+	var clusterProfile *configv1beta1.ClusterProfile
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		clusterProfile = &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+
+		clusterName := randomString()
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels: map[string]string{
+					controllers.ClusterProfileLabelName: clusterProfile.Name,
+					configv1beta1.ClusterNameLabel:      clusterName,
+					configv1beta1.ClusterTypeLabel:      string(libsveltosv1beta1.ClusterTypeSveltos),
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Kind:       configv1beta1.ClusterProfileKind,
+						Name:       clusterProfile.Name,
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      clusterName,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusRemoving},
+				},
+			},
+		}
+	})
+
+	It("isWithdrawalForced returns true only when ForceWithdrawalAnnotation is set on the profile", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile, clusterSummary).Build()
+
+		forced, err := controllers.IsWithdrawalForced(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(forced).To(BeFalse())
+
+		clusterProfile.Annotations = map[string]string{configv1beta1.ForceWithdrawalAnnotation: "true"}
+		Expect(c.Update(context.TODO(), clusterProfile)).To(Succeed())
+
+		forced, err = controllers.IsWithdrawalForced(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(forced).To(BeTrue())
+	})
+
+	It("updateProfileWaitingForDependentsCondition lists clusters waiting for dependents on the profile", func() {
+		initObjects := []client.Object{clusterProfile, clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		// ClusterSummary is being deleted and waits for a dependent profile
+		dependent := "Profile " + randomString()
+		now := metav1.Now()
+		clusterSummary.DeletionTimestamp = &now
+		reason := configv1beta1.WaitingForDependentsReason
+		message := "waiting for dependents to be removed: " + dependent
+		clusterSummary.Status.FeatureSummaries[0].FailureReason = &reason
+		clusterSummary.Status.FeatureSummaries[0].FailureMessage = &message
+
+		Expect(controllers.UpdateProfileWaitingForDependentsCondition(context.TODO(), c, clusterSummary,
+			logger)).To(Succeed())
+
+		currentClusterProfile := &configv1beta1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name},
+			currentClusterProfile)).To(Succeed())
+		condition := meta.FindStatusCondition(currentClusterProfile.Status.Conditions,
+			configv1beta1.WaitingForDependentsCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring(clusterSummary.Spec.ClusterName))
+		Expect(condition.Message).To(ContainSubstring(dependent))
+		Expect(condition.Message).To(ContainSubstring(configv1beta1.ForceWithdrawalAnnotation))
+
+		// Dependents are gone
+		clusterSummary.Status.FeatureSummaries[0].FailureReason = nil
+		clusterSummary.Status.FeatureSummaries[0].FailureMessage = nil
+
+		Expect(controllers.UpdateProfileWaitingForDependentsCondition(context.TODO(), c, clusterSummary,
+			logger)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name},
+			currentClusterProfile)).To(Succeed())
+		Expect(meta.FindStatusCondition(currentClusterProfile.Status.Conditions,
+			configv1beta1.WaitingForDependentsCondition)).To(BeNil())
+	})
+})
@@ -0,0 +1,531 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Deployment priority
+// The deployer processes requests in FIFO order with a fixed number of workers. The priorityDeployer
+// sits in front of it: requests are held in a queue and handed over to the deployer only when a worker
+// is free. The request handed over is the one with the highest effective priority, which is the
+// profile Spec.Priority plus one for every aging interval the request has been waiting. So a low
+// priority request is eventually scheduled even when higher priority requests keep coming.
+// All pending requests age at the same pace, so their order never changes over time. Pending requests
+// are kept in a heap. Requests still being processed by the deployer are set aside when popped and
+// pushed back at the end of each dispatch pass.
+// Deployment concurrency limits (see deployment_concurrency.go) are enforced at hand over: a request
+// exceeding them stays pending, so it does not take a worker, and the deployment slot is reserved till
+// the worker is done.
+
+const (
+	// deploymentPriority is the deployer.Options key carrying the priority of a request
+	deploymentPriority = "deploymentPriority"
+
+	// maxConcurrentDeploymentsOption is the deployer.Options key carrying the maximum number of
+	// deployments running in parallel on the request cluster
+	maxConcurrentDeploymentsOption = "maxConcurrentDeployments"
+
+	// deploymentSlotReservedOption is the deployer.Options key set on requests whose deployment slot
+	// was reserved at hand over
+	deploymentSlotReservedOption = "deploymentSlotReserved"
+
+	// DefaultPriorityAgingInterval is how long a queued deployment needs to wait to gain one priority level
+	DefaultPriorityAgingInterval = time.Minute
+
+	dispatchInterval = time.Second
+)
+
+type priorityRequest struct {
+	key              string
+	clusterNamespace string
+	clusterName      string
+	applicant        string
+	featureID        string
+	clusterType      libsveltosv1beta1.ClusterType
+	cleanup          bool
+	handler          deployer.RequestHandler
+	metric           deployer.MetricHandler
+	options          deployer.Options
+	priority         int32
+	queuedAt         time.Time
+	// maxConcurrentDeployments is the maximum number of deployments running in parallel on the cluster
+	maxConcurrentDeployments int
+	// throttled is set once request was found exceeding deployment concurrency limits
+	throttled bool
+	// index is the position of the request in the pending heap
+	index int
+}
+
+// priorityQueue is a heap of pending requests, highest effective priority first
+type priorityQueue struct {
+	requests      []*priorityRequest
+	agingInterval time.Duration
+}
+
+func (q *priorityQueue) Len() int { return len(q.requests) }
+
+func (q *priorityQueue) Less(i, j int) bool {
+	return hasHigherPriority(q.requests[i], q.requests[j], q.agingInterval)
+}
+
+func (q *priorityQueue) Swap(i, j int) {
+	q.requests[i], q.requests[j] = q.requests[j], q.requests[i]
+	q.requests[i].index = i
+	q.requests[j].index = j
+}
+
+func (q *priorityQueue) Push(x any) {
+	req := x.(*priorityRequest)
+	req.index = len(q.requests)
+	q.requests = append(q.requests, req)
+}
+
+func (q *priorityQueue) Pop() any {
+	n := len(q.requests)
+	req := q.requests[n-1]
+	q.requests[n-1] = nil
+	req.index = -1
+	q.requests = q.requests[:n-1]
+	return req
+}
+
+type priorityDeployer struct {
+	deployer.DeployerInterface
+
+	workers       int
+	agingInterval time.Duration
+	logger        logr.Logger
+
+	mu       sync.Mutex
+	features map[string]bool
+	// pending contains requests not handed over to the deployer yet
+	pending priorityQueue
+	// pendingByKey indexes pending requests by key
+	pendingByKey map[string]*priorityRequest
+	// inFlight contains requests handed over to the deployer and not processed yet.
+	// Value releases the deployment slot reserved at hand over.
+	inFlight map[string]func()
+	// failed contains requests the deployer refused
+	failed map[string]error
+	// depth contains number of pending requests per priority
+	depth map[int32]int
+
+	notify chan struct{}
+}
+
+var (
+	priorityDeployerInstance *priorityDeployer
+)
+
+// NewPriorityDeployer returns a deployer which schedules requests to d, which has the given number
+// of workers, by priority
+func NewPriorityDeployer(ctx context.Context, d deployer.DeployerInterface, workers int,
+	agingInterval time.Duration, logger logr.Logger) deployer.DeployerInterface {
+
+	priorityDeployerInstance = newPriorityDeployer(d, workers, agingInterval, logger)
+	go priorityDeployerInstance.run(ctx)
+
+	return priorityDeployerInstance
+}
+
+func newPriorityDeployer(d deployer.DeployerInterface, workers int, agingInterval time.Duration,
+	logger logr.Logger) *priorityDeployer {
+
+	if agingInterval <= 0 {
+		agingInterval = DefaultPriorityAgingInterval
+	}
+
+	return &priorityDeployer{
+		DeployerInterface: d,
+		workers:           workers,
+		agingInterval:     agingInterval,
+		logger:            logger,
+		features:          make(map[string]bool),
+		pending:           priorityQueue{agingInterval: agingInterval},
+		pendingByKey:      make(map[string]*priorityRequest),
+		inFlight:          make(map[string]func()),
+		failed:            make(map[string]error),
+		depth:             make(map[int32]int),
+		notify:            make(chan struct{}, 1),
+	}
+}
+
+// getDeployer returns the deployer ClusterSummary requests are queued to
+func getDeployer(ctx context.Context, c client.Client, logger logr.Logger) deployer.DeployerInterface {
+	if priorityDeployerInstance != nil {
+		return priorityDeployerInstance
+	}
+
+	// At this point deployer has been already initialized, so the argurment of GetClient are not important
+	return deployer.GetClient(ctx, logger, c, 1)
+}
+
+// getDeploymentOptions returns the options a ClusterSummary request must be queued with
+func getDeploymentOptions(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	logger logr.Logger) deployer.Options {
+
+	options := deployer.Options{HandlerOptions: map[string]string{}}
+	options.HandlerOptions[deploymentPriority] = strconv.Itoa(int(clusterSummary.Spec.ClusterProfileSpec.Priority))
+	options.HandlerOptions[maxConcurrentDeploymentsOption] = strconv.Itoa(getMaxConcurrentDeployments(ctx, c,
+		clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType, logger))
+	return options
+}
+
+func getPriorityFromOptions(o deployer.Options) int32 {
+	if o.HandlerOptions == nil {
+		return 0
+	}
+
+	priority, err := strconv.ParseInt(o.HandlerOptions[deploymentPriority], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(priority)
+}
+
+func (p *priorityDeployer) RegisterFeatureID(featureID string) error {
+	if err := p.DeployerInterface.RegisterFeatureID(featureID); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.features[featureID] = true
+	return nil
+}
+
+func (p *priorityDeployer) Deploy(
+	ctx context.Context,
+	clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType,
+	cleanup bool,
+	f deployer.RequestHandler,
+	m deployer.MetricHandler,
+	o deployer.Options,
+) error {
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+	priority := getPriorityFromOptions(o)
+
+	p.mu.Lock()
+
+	if !p.features[featureID] {
+		p.mu.Unlock()
+		return fmt.Errorf("featureID %s is not registered", featureID)
+	}
+
+	delete(p.failed, key)
+
+	if req := p.getPending(key); req != nil {
+		// Request is already queued. Keep its position in the queue (time it was queued) but
+		// use latest handler, options and priority.
+		p.updateDepth(req.priority, -1)
+		req.handler = f
+		req.metric = m
+		req.options = o
+		req.priority = priority
+		req.maxConcurrentDeployments = getMaxConcurrentDeploymentsFromOptions(o)
+		p.updateDepth(req.priority, 1)
+		heap.Fix(&p.pending, req.index)
+		p.mu.Unlock()
+		return nil
+	}
+
+	req := &priorityRequest{
+		key:              key,
+		clusterNamespace: clusterNamespace,
+		clusterName:      clusterName,
+		applicant:        applicant,
+		featureID:        featureID,
+		clusterType:      clusterType,
+		cleanup:          cleanup,
+		handler:          f,
+		metric:           m,
+		options:          o,
+		priority:         priority,
+		queuedAt:         time.Now(),
+
+		maxConcurrentDeployments: getMaxConcurrentDeploymentsFromOptions(o),
+	}
+	heap.Push(&p.pending, req)
+	p.pendingByKey[key] = req
+	p.updateDepth(priority, 1)
+	p.mu.Unlock()
+
+	p.wakeUp()
+	return nil
+}
+
+func (p *priorityDeployer) GetResult(
+	ctx context.Context,
+	clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType,
+	cleanup bool,
+) deployer.Result {
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+
+	p.mu.Lock()
+	if p.getPending(key) != nil {
+		p.mu.Unlock()
+		return deployer.Result{ResultStatus: deployer.InProgress}
+	}
+	if err, ok := p.failed[key]; ok {
+		delete(p.failed, key)
+		p.mu.Unlock()
+		return deployer.Result{ResultStatus: deployer.Failed, Err: err}
+	}
+	p.mu.Unlock()
+
+	return p.DeployerInterface.GetResult(ctx, clusterNamespace, clusterName, applicant, featureID,
+		clusterType, cleanup)
+}
+
+// IsInProgress returns true if a request is queued, handed over to the deployer or being
+// processed by the deployer
+func (p *priorityDeployer) IsInProgress(
+	clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType,
+	cleanup bool,
+) bool {
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+
+	p.mu.Lock()
+	if _, ok := p.inFlight[key]; ok || p.getPending(key) != nil {
+		p.mu.Unlock()
+		return true
+	}
+	p.mu.Unlock()
+
+	return p.DeployerInterface.IsInProgress(clusterNamespace, clusterName, applicant, featureID,
+		clusterType, cleanup)
+}
+
+func (p *priorityDeployer) CleanupEntries(
+	clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType,
+	cleanup bool) {
+
+	key := deployer.GetKey(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req := p.getPending(key); req != nil {
+		p.updateDepth(req.priority, -1)
+		heap.Remove(&p.pending, req.index)
+		delete(p.pendingByKey, key)
+	}
+	delete(p.failed, key)
+
+	p.DeployerInterface.CleanupEntries(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup)
+
+	// A request removed from the deployer queue before a worker took it will never be processed
+	if release, ok := p.inFlight[key]; ok &&
+		!p.DeployerInterface.IsInProgress(clusterNamespace, clusterName, applicant, featureID, clusterType, cleanup) {
+
+		delete(p.inFlight, key)
+		release()
+	}
+}
+
+func (p *priorityDeployer) run(ctx context.Context) {
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.notify:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		p.dispatch(ctx)
+	}
+}
+
+// dispatch hands over pending requests, highest effective priority first, to the deployer
+// till all workers are busy
+func (p *priorityDeployer) dispatch(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Requests which cannot be handed over now are pushed back once done
+	skipped := make([]*priorityRequest, 0)
+	defer func() {
+		for i := range skipped {
+			heap.Push(&p.pending, skipped[i])
+		}
+	}()
+
+	for len(p.inFlight) < p.workers {
+		req := p.getNext(&skipped)
+		if req == nil {
+			return
+		}
+
+		release, _, err := tryAcquireDeploymentSlot(req.clusterNamespace, req.clusterName, req.clusterType,
+			req.maxConcurrentDeployments)
+		if err != nil {
+			// Slot was taken by a request not going through this deployer. Retry on next dispatch.
+			skipped = append(skipped, req)
+			return
+		}
+
+		delete(p.pendingByKey, req.key)
+		p.updateDepth(req.priority, -1)
+
+		p.logger.V(logs.LogDebug).Info(fmt.Sprintf("handing over request %s (priority %d, queued for %s)",
+			req.key, req.priority, time.Since(req.queuedAt)))
+
+		err = p.DeployerInterface.Deploy(ctx, req.clusterNamespace, req.clusterName, req.applicant,
+			req.featureID, req.clusterType, req.cleanup, p.getHandler(req.key, req.handler), req.metric,
+			getSlotReservedOptions(req.options))
+		if err != nil {
+			release()
+			p.logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to hand over request %s: %v", req.key, err))
+			p.failed[req.key] = err
+			continue
+		}
+		p.inFlight[req.key] = release
+	}
+}
+
+// getSlotReservedOptions returns a copy of the request options marking the deployment slot as reserved
+func getSlotReservedOptions(o deployer.Options) deployer.Options {
+	handlerOptions := make(map[string]string, len(o.HandlerOptions)+1)
+	for k, v := range o.HandlerOptions {
+		handlerOptions[k] = v
+	}
+	handlerOptions[deploymentSlotReservedOption] = "true"
+	o.HandlerOptions = handlerOptions
+	return o
+}
+
+// getNext pops the pending request to hand over next. It returns nil if none can be.
+// Requests still being processed by the deployer are skipped: they are handed over once
+// the deployer is done with them. So are requests exceeding deployment concurrency limits.
+// Skipped requests are appended to skipped and must be pushed back by the caller.
+func (p *priorityDeployer) getNext(skipped *[]*priorityRequest) *priorityRequest {
+	for p.pending.Len() > 0 {
+		req := heap.Pop(&p.pending).(*priorityRequest)
+		if _, ok := p.inFlight[req.key]; ok ||
+			p.DeployerInterface.IsInProgress(req.clusterNamespace, req.clusterName, req.applicant,
+				req.featureID, req.clusterType, req.cleanup) {
+
+			*skipped = append(*skipped, req)
+			continue
+		}
+
+		if limit := isDeploymentThrottled(req.clusterNamespace, req.clusterName, req.clusterType,
+			req.maxConcurrentDeployments); limit != "" {
+
+			if !req.throttled {
+				req.throttled = true
+				throttledDeploymentsCounter.WithLabelValues(string(req.clusterType), req.clusterNamespace,
+					req.clusterName, limit).Inc()
+			}
+			*skipped = append(*skipped, req)
+			if limit == "global" {
+				return nil
+			}
+			continue
+		}
+
+		return req
+	}
+
+	return nil
+}
+
+// hasHigherPriority returns true if a has a higher effective priority than b. Effective priority
+// is request priority increased by one for each aging interval the request has been waiting. As all
+// requests age at the same pace, the result does not depend on when it is evaluated.
+// Among requests with same effective priority, the one queued first comes first.
+func hasHigherPriority(a, b *priorityRequest, agingInterval time.Duration) bool {
+	if a.priority != b.priority {
+		aging := float64(b.queuedAt.Sub(a.queuedAt)) / float64(agingInterval)
+		if diff := float64(a.priority) - float64(b.priority) + aging; diff != 0 {
+			return diff > 0
+		}
+	}
+	return a.queuedAt.Before(b.queuedAt)
+}
+
+// getHandler wraps the request handler so that a worker is considered free as soon as handler returns
+func (p *priorityDeployer) getHandler(key string, f deployer.RequestHandler) deployer.RequestHandler {
+	return func(ctx context.Context, c client.Client,
+		clusterNamespace, clusterName, applicant, featureID string,
+		clusterType libsveltosv1beta1.ClusterType,
+		o deployer.Options, logger logr.Logger) error {
+
+		defer p.done(key)
+		return f(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	}
+}
+
+func (p *priorityDeployer) done(key string) {
+	p.mu.Lock()
+	release, ok := p.inFlight[key]
+	delete(p.inFlight, key)
+	p.mu.Unlock()
+
+	if ok {
+		release()
+	}
+
+	p.wakeUp()
+}
+
+func (p *priorityDeployer) wakeUp() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// getPending returns the pending request with given key, if any. Must be called with lock held.
+func (p *priorityDeployer) getPending(key string) *priorityRequest {
+	return p.pendingByKey[key]
+}
+
+// updateDepth updates number of pending requests with given priority. Must be called with lock held.
+func (p *priorityDeployer) updateDepth(priority int32, delta int) {
+	p.depth[priority] += delta
+	if p.depth[priority] <= 0 {
+		delete(p.depth, priority)
+		deployerQueueDepthGauge.DeleteLabelValues(strconv.Itoa(int(priority)))
+		return
+	}
+	deployerQueueDepthGauge.WithLabelValues(strconv.Itoa(int(priority))).Set(float64(p.depth[priority]))
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+// recordingDeployer records requests in the order they are handed over. Handlers are
+// invoked only by the test.
+type recordingDeployer struct {
+	applicants []string
+	handlers   []deployer.RequestHandler
+	// inProgress contains applicants whose previous request is still being processed
+	inProgress map[string]bool
+}
+
+func (d *recordingDeployer) RegisterFeatureID(featureID string) error {
+	return nil
+}
+
+func (d *recordingDeployer) Deploy(ctx context.Context, clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool, f deployer.RequestHandler, m deployer.MetricHandler,
+	o deployer.Options) error {
+
+	d.applicants = append(d.applicants, applicant)
+	d.handlers = append(d.handlers, f)
+	return nil
+}
+
+func (d *recordingDeployer) IsInProgress(clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool) bool {
+
+	return d.inProgress[applicant]
+}
+
+func (d *recordingDeployer) GetResult(ctx context.Context, clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool) deployer.Result {
+
+	return deployer.Result{ResultStatus: deployer.Deployed}
+}
+
+func (d *recordingDeployer) CleanupEntries(clusterNamespace, clusterName, applicant, featureID string,
+	clusterType libsveltosv1beta1.ClusterType, cleanup bool) {
+}
+
+// complete invokes the handler of the i-th request handed over
+func (d *recordingDeployer) complete(i int) {
+	Expect(d.handlers[i](context.TODO(), nil, "", "", d.applicants[i], "", libsveltosv1beta1.ClusterTypeCapi,
+		deployer.Options{}, logr.Discard())).To(Succeed())
+}
+
+var _ = Describe("Deployer priority", func() {
+	var inner *recordingDeployer
+	var clusterNamespace string
+	var clusterName string
+
+	noopHandler := func(ctx context.Context, c client.Client, clusterNamespace, clusterName, applicant, featureID string,
+		clusterType libsveltosv1beta1.ClusterType, o deployer.Options, logger logr.Logger) error {
+
+		return nil
+	}
+
+	deploy := func(d deployer.DeployerInterface, applicant string, priority int32) {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: applicant},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1beta1.Spec{Priority: priority},
+			},
+		}
+		Expect(d.Deploy(context.TODO(), clusterNamespace, clusterName, applicant,
+			string(configv1beta1.FeatureResources), libsveltosv1beta1.ClusterTypeCapi, false,
+			noopHandler, nil, controllers.GetDeploymentOptions(context.TODO(), nil, clusterSummary,
+				logr.Discard()))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		inner = &recordingDeployer{inProgress: map[string]bool{}}
+		clusterNamespace = randomString()
+		clusterName = randomString()
+	})
+
+	It("hands over higher priority requests first, only when a worker is free", func() {
+		d, dispatch := controllers.NewPriorityDeployerForTest(inner, 1, time.Hour,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(d.RegisterFeatureID(string(configv1beta1.FeatureResources))).To(Succeed())
+
+		deploy(d, "low-1", 0)
+		deploy(d, "low-2", 0)
+		deploy(d, "high", 100)
+
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"high"}))
+
+		// Worker is still busy
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"high"}))
+
+		// Requests not handed over yet are reported in progress
+		result := d.GetResult(context.TODO(), clusterNamespace, clusterName, "low-1",
+			string(configv1beta1.FeatureResources), libsveltosv1beta1.ClusterTypeCapi, false)
+		Expect(result.ResultStatus).To(Equal(deployer.InProgress))
+
+		inner.complete(0)
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"high", "low-1"}))
+
+		inner.complete(1)
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"high", "low-1", "low-2"}))
+		inner.complete(2)
+	})
+
+	It("reports queued and handed over requests as in progress", func() {
+		d, dispatch := controllers.NewPriorityDeployerForTest(inner, 1, time.Hour,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(d.RegisterFeatureID(string(configv1beta1.FeatureResources))).To(Succeed())
+
+		isInProgress := func(applicant string) bool {
+			return d.IsInProgress(clusterNamespace, clusterName, applicant,
+				string(configv1beta1.FeatureResources), libsveltosv1beta1.ClusterTypeCapi, false)
+		}
+
+		deploy(d, "first", 0)
+		deploy(d, "second", 0)
+		Expect(isInProgress("first")).To(BeTrue())
+		Expect(isInProgress("second")).To(BeTrue())
+
+		// first is handed over, second is still queued
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"first"}))
+		Expect(isInProgress("first")).To(BeTrue())
+		Expect(isInProgress("second")).To(BeTrue())
+
+		inner.complete(0)
+		Expect(isInProgress("first")).To(BeFalse())
+		Expect(isInProgress("second")).To(BeTrue())
+
+		dispatch(context.TODO())
+		inner.complete(1)
+		Expect(isInProgress("second")).To(BeFalse())
+	})
+
+	It("ages waiting requests so low priority ones are not starved", func() {
+		d, dispatch := controllers.NewPriorityDeployerForTest(inner, 1, time.Millisecond,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(d.RegisterFeatureID(string(configv1beta1.FeatureResources))).To(Succeed())
+
+		deploy(d, "low", 0)
+		time.Sleep(100 * time.Millisecond)
+		deploy(d, "high", 10)
+
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"low"}))
+		inner.complete(0)
+	})
+
+	It("skips requests still being processed by the deployer and hands those over later", func() {
+		d, dispatch := controllers.NewPriorityDeployerForTest(inner, 2, time.Hour,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(d.RegisterFeatureID(string(configv1beta1.FeatureResources))).To(Succeed())
+
+		inner.inProgress["busy"] = true
+		deploy(d, "busy", 100)
+		deploy(d, "low-1", 0)
+		deploy(d, "low-2", 0)
+		deploy(d, "low-3", 0)
+
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"low-1", "low-2"}))
+
+		// Deployer is done with previous request. Skipped request keeps its priority
+		inner.inProgress["busy"] = false
+		inner.complete(0)
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"low-1", "low-2", "busy"}))
+
+		inner.complete(1)
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"low-1", "low-2", "busy", "low-3"}))
+		inner.complete(2)
+		inner.complete(3)
+	})
+
+	It("drops requests removed with CleanupEntries", func() {
+		d, dispatch := controllers.NewPriorityDeployerForTest(inner, 1, time.Hour,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(d.RegisterFeatureID(string(configv1beta1.FeatureResources))).To(Succeed())
+
+		deploy(d, "first", 0)
+		deploy(d, "second", 0)
+		d.CleanupEntries(clusterNamespace, clusterName, "first", string(configv1beta1.FeatureResources),
+			libsveltosv1beta1.ClusterTypeCapi, false)
+
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"second"}))
+		inner.complete(0)
+	})
+
+	It("keeps requests exceeding the per cluster deployment limit pending", func() {
+		controllers.SetDeploymentConcurrency(1, 0)
+		defer controllers.SetDeploymentConcurrency(0, 0)
+
+		d, dispatch := controllers.NewPriorityDeployerForTest(inner, 2, time.Hour,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(d.RegisterFeatureID(string(configv1beta1.FeatureResources))).To(Succeed())
+
+		deploy(d, "first", 0)
+		deploy(d, "second", 0)
+
+		// A worker is free but cluster already has one deployment in progress
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"first"}))
+		result := d.GetResult(context.TODO(), clusterNamespace, clusterName, "second",
+			string(configv1beta1.FeatureResources), libsveltosv1beta1.ClusterTypeCapi, false)
+		Expect(result.ResultStatus).To(Equal(deployer.InProgress))
+
+		// Requests on other clusters are not throttled
+		otherCluster := clusterName
+		clusterName = randomString()
+		deploy(d, "other", 0)
+		clusterName = otherCluster
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"first", "other"}))
+
+		// Slot is released once worker is done
+		inner.complete(0)
+		dispatch(context.TODO())
+		Expect(inner.applicants).To(Equal([]string{"first", "other", "second"}))
+
+		inner.complete(1)
+		inner.complete(2)
+	})
+})
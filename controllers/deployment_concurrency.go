@@ -0,0 +1,228 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Deployment concurrency
+// Deployments and withdrawals run in the deployer worker pool. So that a managed cluster is not
+// overwhelmed when several ClusterSummaries are (re)deployed at once, the number of deployments running
+// in parallel can be capped:
+// - per managed cluster, across all ClusterSummaries. The MaxConcurrentDeploymentsAnnotation on a
+// SveltosCluster overrides the default;
+// - globally (in-flight budget).
+// Limits are enforced when the priority deployer hands requests over to the deployer: a request exceeding
+// any of those limits stays pending (feature stays Provisioning or Removing) and no worker is taken. The slot
+// is reserved at hand over and released once the worker is done.
+// Requests not going through the priority deployer reserve the slot when they run instead. A deployment
+// exceeding any of the limits then fails with a ConcurrencyLimitError. This is not a failure: feature
+// stays Provisioning (or Removing) and the deployment is queued again.
+
+// ConcurrencyLimitError is returned when a deployment cannot run because too many
+// deployments are in progress
+type ConcurrencyLimitError struct {
+	Message string
+}
+
+func (e *ConcurrencyLimitError) Error() string {
+	return e.Message
+}
+
+var (
+	concurrencyMux sync.Mutex
+	// Zero means no limit
+	maxConcurrentDeploymentsPerCluster int
+	maxInFlightDeployments             int
+
+	inFlightDeployments        int
+	clusterInFlightDeployments = make(map[corev1.ObjectReference]int)
+)
+
+// SetDeploymentConcurrency sets the maximum number of deployments running in parallel on each
+// managed cluster and overall. Zero means no limit.
+func SetDeploymentConcurrency(perCluster, global int) {
+	concurrencyMux.Lock()
+	defer concurrencyMux.Unlock()
+
+	maxConcurrentDeploymentsPerCluster = perCluster
+	maxInFlightDeployments = global
+}
+
+// acquireDeploymentSlot reserves a slot for a deployment on the managed cluster. Returned function
+// must be invoked to release the slot once deployment completes.
+func acquireDeploymentSlot(ctx context.Context, c client.Client, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) (func(), error) {
+
+	perCluster := getMaxConcurrentDeployments(ctx, c, clusterNamespace, clusterName, clusterType, logger)
+
+	release, limit, err := tryAcquireDeploymentSlot(clusterNamespace, clusterName, clusterType, perCluster)
+	if err != nil {
+		throttledDeploymentsCounter.WithLabelValues(string(clusterType), clusterNamespace, clusterName,
+			limit).Inc()
+		return nil, err
+	}
+	return release, nil
+}
+
+// getDeploymentLimitReached returns the limit ("global" or "cluster") preventing a new deployment on
+// the managed cluster from running now. Empty if none. Must be called with concurrencyMux held.
+func getDeploymentLimitReached(cluster *corev1.ObjectReference, perCluster int) string {
+	if maxInFlightDeployments > 0 && inFlightDeployments >= maxInFlightDeployments {
+		return "global"
+	}
+	if perCluster > 0 && clusterInFlightDeployments[*cluster] >= perCluster {
+		return "cluster"
+	}
+	return ""
+}
+
+// isDeploymentThrottled returns the limit ("global" or "cluster") preventing a new deployment on the
+// managed cluster from running now. Empty if none.
+func isDeploymentThrottled(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
+	perCluster int) string {
+
+	cluster := getClusterReference(clusterNamespace, clusterName, clusterType)
+
+	concurrencyMux.Lock()
+	defer concurrencyMux.Unlock()
+
+	return getDeploymentLimitReached(cluster, perCluster)
+}
+
+// tryAcquireDeploymentSlot reserves a slot for a deployment on the managed cluster, which can have at
+// most perCluster deployments in progress. If a limit is reached, a ConcurrencyLimitError is returned
+// along with the limit ("global" or "cluster"). Otherwise returned function must be invoked to release
+// the slot once deployment completes.
+func tryAcquireDeploymentSlot(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
+	perCluster int) (release func(), limit string, err error) {
+
+	cluster := getClusterReference(clusterNamespace, clusterName, clusterType)
+
+	concurrencyMux.Lock()
+	defer concurrencyMux.Unlock()
+
+	clusterMaxConcurrentDeploymentsGauge.WithLabelValues(string(clusterType), clusterNamespace,
+		clusterName).Set(float64(perCluster))
+
+	switch limit = getDeploymentLimitReached(cluster, perCluster); limit {
+	case "global":
+		return nil, limit, &ConcurrencyLimitError{
+			Message: fmt.Sprintf("waiting: %d deployments in progress (maximum in-flight deployments)",
+				inFlightDeployments),
+		}
+	case "cluster":
+		return nil, limit, &ConcurrencyLimitError{
+			Message: fmt.Sprintf("waiting: %d deployments in progress on cluster (maximum concurrent deployments)",
+				clusterInFlightDeployments[*cluster]),
+		}
+	}
+
+	inFlightDeployments++
+	clusterInFlightDeployments[*cluster]++
+	trackInFlightDeployments(cluster, clusterType)
+
+	return func() {
+		concurrencyMux.Lock()
+		defer concurrencyMux.Unlock()
+
+		inFlightDeployments--
+		clusterInFlightDeployments[*cluster]--
+		trackInFlightDeployments(cluster, clusterType)
+		if clusterInFlightDeployments[*cluster] == 0 {
+			delete(clusterInFlightDeployments, *cluster)
+		}
+	}, "", nil
+}
+
+// trackInFlightDeployments must be called with concurrencyMux held
+func trackInFlightDeployments(cluster *corev1.ObjectReference, clusterType libsveltosv1beta1.ClusterType) {
+	inFlightDeploymentsGauge.Set(float64(inFlightDeployments))
+	clusterInFlightDeploymentsGauge.WithLabelValues(string(clusterType), cluster.Namespace,
+		cluster.Name).Set(float64(clusterInFlightDeployments[*cluster]))
+}
+
+// getMaxConcurrentDeployments returns the maximum number of deployments running in parallel on the
+// managed cluster. SveltosCluster annotation, when valid, takes precedence over the default.
+func getMaxConcurrentDeployments(ctx context.Context, c client.Client, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) int {
+
+	concurrencyMux.Lock()
+	limit := maxConcurrentDeploymentsPerCluster
+	concurrencyMux.Unlock()
+
+	if clusterType != libsveltosv1beta1.ClusterTypeSveltos {
+		return limit
+	}
+
+	sveltosCluster := &libsveltosv1beta1.SveltosCluster{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: clusterNamespace, Name: clusterName}, sveltosCluster); err != nil {
+		return limit
+	}
+
+	v, ok := sveltosCluster.Annotations[configv1beta1.MaxConcurrentDeploymentsAnnotation]
+	if !ok {
+		return limit
+	}
+
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed < 0 {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("invalid %s annotation: %q",
+			configv1beta1.MaxConcurrentDeploymentsAnnotation, v))
+		return limit
+	}
+	return parsed
+}
+
+// getMaxConcurrentDeploymentsFromOptions returns the maximum number of deployments running in parallel
+// on the managed cluster, as computed when the request was queued. Default is used if not set.
+func getMaxConcurrentDeploymentsFromOptions(o deployer.Options) int {
+	if o.HandlerOptions != nil {
+		if v, err := strconv.Atoi(o.HandlerOptions[maxConcurrentDeploymentsOption]); err == nil {
+			return v
+		}
+	}
+
+	concurrencyMux.Lock()
+	defer concurrencyMux.Unlock()
+	return maxConcurrentDeploymentsPerCluster
+}
+
+// isDeploymentSlotReserved returns true if the priority deployer reserved the deployment slot
+// when handing the request over
+func isDeploymentSlotReserved(o deployer.Options) bool {
+	return o.HandlerOptions != nil && o.HandlerOptions[deploymentSlotReservedOption] == "true"
+}
+
+func isConcurrencyLimitError(err error) bool {
+	var concurrencyLimitError *ConcurrencyLimitError
+	return errors.As(err, &concurrencyLimitError)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Deployment concurrency", func() {
+	AfterEach(func() {
+		controllers.SetDeploymentConcurrency(0, 0)
+	})
+
+	It("caps deployments per cluster, with SveltosCluster annotation overriding the default", func() {
+		controllers.SetDeploymentConcurrency(1, 0)
+
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Annotations: map[string]string{
+					configv1beta1.MaxConcurrentDeploymentsAnnotation: "2",
+				},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sveltosCluster).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		capiClusterNamespace := randomString()
+		capiClusterName := randomString()
+		release, err := controllers.AcquireDeploymentSlot(context.TODO(), c, capiClusterNamespace, capiClusterName,
+			libsveltosv1beta1.ClusterTypeCapi, logger)
+		Expect(err).To(BeNil())
+		_, err = controllers.AcquireDeploymentSlot(context.TODO(), c, capiClusterNamespace, capiClusterName,
+			libsveltosv1beta1.ClusterTypeCapi, logger)
+		Expect(err).ToNot(BeNil())
+		var concurrencyLimitError *controllers.ConcurrencyLimitError
+		Expect(errors.As(err, &concurrencyLimitError)).To(BeTrue())
+
+		// Once a deployment completes, another one can start
+		release()
+		release, err = controllers.AcquireDeploymentSlot(context.TODO(), c, capiClusterNamespace, capiClusterName,
+			libsveltosv1beta1.ClusterTypeCapi, logger)
+		Expect(err).To(BeNil())
+		defer release()
+
+		// SveltosCluster allows two deployments in parallel
+		releases := make([]func(), 0)
+		for i := 0; i < 2; i++ {
+			release, err := controllers.AcquireDeploymentSlot(context.TODO(), c, sveltosCluster.Namespace,
+				sveltosCluster.Name, libsveltosv1beta1.ClusterTypeSveltos, logger)
+			Expect(err).To(BeNil())
+			releases = append(releases, release)
+		}
+		_, err = controllers.AcquireDeploymentSlot(context.TODO(), c, sveltosCluster.Namespace,
+			sveltosCluster.Name, libsveltosv1beta1.ClusterTypeSveltos, logger)
+		Expect(errors.As(err, &concurrencyLimitError)).To(BeTrue())
+
+		for i := range releases {
+			releases[i]()
+		}
+	})
+
+	It("caps deployments across all clusters", func() {
+		controllers.SetDeploymentConcurrency(0, 2)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		releases := make([]func(), 0)
+		for i := 0; i < 2; i++ {
+			release, err := controllers.AcquireDeploymentSlot(context.TODO(), c, randomString(), randomString(),
+				libsveltosv1beta1.ClusterTypeCapi, logger)
+			Expect(err).To(BeNil())
+			releases = append(releases, release)
+		}
+
+		_, err := controllers.AcquireDeploymentSlot(context.TODO(), c, randomString(), randomString(),
+			libsveltosv1beta1.ClusterTypeCapi, logger)
+		var concurrencyLimitError *controllers.ConcurrencyLimitError
+		Expect(errors.As(err, &concurrencyLimitError)).To(BeTrue())
+
+		for i := range releases {
+			releases[i]()
+		}
+	})
+})
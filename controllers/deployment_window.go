@@ -0,0 +1,361 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Deployment windows
+// A DeploymentWindow selects clusters and ClusterProfiles/Profiles and defines, via cron schedules,
+// recurring windows add-on changes can be deployed in (AllowedWindows) and windows add-on changes
+// cannot be deployed in (BlockedWindows, for instance holiday freezes).
+// Deployments to a cluster are allowed only when all DeploymentWindows matching both the cluster and
+// the profile allow them. Otherwise the ClusterSummary reconciler does not queue any new deployment.
+// Each deferred feature reports WaitingForWindow along with the start of the next deployment window
+// and the ClusterSummary is requeued for then. Deployments already in progress are let complete.
+// Configuration drifts are still remediated, unless a matching DeploymentWindow has DeferDriftRemediation
+// set. DryRun ClusterSummaries are never deferred as they do not change anything.
+
+const (
+	// maxDeploymentWindowIterations bounds the search for the next deployment window
+	maxDeploymentWindowIterations = 1000
+
+	// deploymentWindowRequeueAfter is the maximum time a ClusterSummary, whose deployments are deferred,
+	// waits before evaluating DeploymentWindows again (DeploymentWindows might have changed meanwhile)
+	deploymentWindowRequeueAfter = 10 * time.Minute
+)
+
+type scheduledWindow struct {
+	schedule *cronSchedule
+	duration time.Duration
+}
+
+// deploymentWindowSchedule is a parsed DeploymentWindow
+type deploymentWindowSchedule struct {
+	location              *time.Location
+	allowed               []scheduledWindow
+	blocked               []scheduledWindow
+	deferDriftRemediation bool
+}
+
+func parseWindows(windows []configv1beta1.Window) ([]scheduledWindow, error) {
+	result := make([]scheduledWindow, len(windows))
+	for i := range windows {
+		schedule, err := parseCronSchedule(windows[i].Schedule)
+		if err != nil {
+			return nil, err
+		}
+		if windows[i].Duration.Duration <= 0 {
+			return nil, fmt.Errorf("window %q: duration must be positive", windows[i].Schedule)
+		}
+		result[i] = scheduledWindow{schedule: schedule, duration: windows[i].Duration.Duration}
+	}
+	return result, nil
+}
+
+// parseDeploymentWindow validates a DeploymentWindow and parses its schedules
+func parseDeploymentWindow(dw *configv1beta1.DeploymentWindow) (*deploymentWindowSchedule, error) {
+	location := time.UTC
+	if dw.Spec.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(dw.Spec.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", dw.Spec.TimeZone, err)
+		}
+	}
+
+	allowed, err := parseWindows(dw.Spec.AllowedWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := parseWindows(dw.Spec.BlockedWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deploymentWindowSchedule{
+		location:              location,
+		allowed:               allowed,
+		blocked:               blocked,
+		deferDriftRemediation: dw.Spec.DeferDriftRemediation,
+	}, nil
+}
+
+// activeUntil returns, if window is open at time t, when it closes
+func (w *scheduledWindow) activeUntil(t time.Time) (time.Time, bool) {
+	start := w.schedule.next(t.Add(-w.duration))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, false
+	}
+	return start.Add(w.duration), true
+}
+
+// evaluate returns whether deployments are allowed at time t. When not allowed, it also returns the
+// next time this might change (zero if never).
+func (d *deploymentWindowSchedule) evaluate(t time.Time) (bool, time.Time) {
+	t = t.In(d.location)
+
+	var next time.Time
+	for i := range d.blocked {
+		if end, active := d.blocked[i].activeUntil(t); active {
+			next = earliest(next, end)
+		}
+	}
+	if !next.IsZero() {
+		return false, next
+	}
+
+	if len(d.allowed) == 0 {
+		return true, time.Time{}
+	}
+
+	for i := range d.allowed {
+		if _, active := d.allowed[i].activeUntil(t); active {
+			return true, time.Time{}
+		}
+		next = earliest(next, d.allowed[i].schedule.next(t))
+	}
+	return false, next
+}
+
+// earliest returns the earliest between two times. Zero times are ignored.
+func earliest(current, candidate time.Time) time.Time {
+	if current.IsZero() || (!candidate.IsZero() && candidate.Before(current)) {
+		return candidate
+	}
+	return current
+}
+
+// getNextDeploymentWindow returns whether all DeploymentWindows allow deployments at time now.
+// If not, it returns when all of them will next allow deployments (zero if never).
+func getNextDeploymentWindow(schedules []*deploymentWindowSchedule, now time.Time) (bool, time.Time) {
+	t := now
+	for i := 0; i < maxDeploymentWindowIterations; i++ {
+		var next time.Time
+		allowed := true
+		for j := range schedules {
+			if ok, n := schedules[j].evaluate(t); !ok {
+				allowed = false
+				next = earliest(next, n)
+			}
+		}
+
+		if allowed {
+			return i == 0, t
+		}
+
+		if next.IsZero() || !next.After(t) {
+			return false, time.Time{}
+		}
+		t = next
+	}
+
+	return false, time.Time{}
+}
+
+// selectorMatches returns true if labels match selector. An empty selector matches everything.
+func selectorMatches(selector *metav1.LabelSelector, objLabels map[string]string) (bool, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels.Set(objLabels)), nil
+}
+
+// getMatchingDeploymentWindows returns the DeploymentWindows matching both the cluster and the
+// ClusterProfile/Profile of a ClusterSummary
+func getMatchingDeploymentWindows(ctx context.Context, c client.Client,
+	clusterSummaryScope *scope.ClusterSummaryScope) ([]*deploymentWindowSchedule, error) {
+
+	deploymentWindows := &configv1beta1.DeploymentWindowList{}
+	if err := c.List(ctx, deploymentWindows); err != nil {
+		return nil, err
+	}
+
+	if len(deploymentWindows.Items) == 0 {
+		return nil, nil
+	}
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	cluster, err := clusterproxy.GetCluster(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+	if err != nil {
+		return nil, err
+	}
+
+	var profileLabels map[string]string
+	if clusterSummaryScope.Profile != nil {
+		profileLabels = clusterSummaryScope.Profile.GetLabels()
+	}
+
+	result := make([]*deploymentWindowSchedule, 0)
+	for i := range deploymentWindows.Items {
+		dw := &deploymentWindows.Items[i]
+
+		match, err := selectorMatches(&dw.Spec.ClusterSelector.LabelSelector, cluster.GetLabels())
+		if err != nil {
+			return nil, fmt.Errorf("DeploymentWindow %s: invalid clusterSelector: %w", dw.Name, err)
+		}
+		if !match {
+			continue
+		}
+
+		match, err = selectorMatches(&dw.Spec.ProfileSelector.LabelSelector, profileLabels)
+		if err != nil {
+			return nil, fmt.Errorf("DeploymentWindow %s: invalid profileSelector: %w", dw.Name, err)
+		}
+		if !match {
+			continue
+		}
+
+		// An invalid DeploymentWindow matching the cluster blocks deployments instead of being ignored:
+		// a typo in a freeze must not let changes through
+		schedule, err := parseDeploymentWindow(dw)
+		if err != nil {
+			return nil, fmt.Errorf("DeploymentWindow %s: %w", dw.Name, err)
+		}
+		result = append(result, schedule)
+	}
+
+	return result, nil
+}
+
+// evaluateDeploymentWindows verifies whether DeploymentWindows matching a ClusterSummary currently
+// allow deployments. If not, deployments are marked as deferred in the ClusterSummaryScope.
+func evaluateDeploymentWindows(ctx context.Context, c client.Client, clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) error {
+
+	if clusterSummaryScope.IsDryRunSync() {
+		return nil
+	}
+
+	schedules, err := getMatchingDeploymentWindows(ctx, c, clusterSummaryScope)
+	if err != nil {
+		return err
+	}
+
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	allowed, next := getNextDeploymentWindow(schedules, time.Now())
+	if allowed {
+		return nil
+	}
+
+	deferDriftRemediation := false
+	for i := range schedules {
+		deferDriftRemediation = deferDriftRemediation || schedules[i].deferDriftRemediation
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("outside deployment window. Next window: %s", next))
+	clusterSummaryScope.SetDeploymentDeferred(next, deferDriftRemediation)
+	return nil
+}
+
+// isDeferredByDeploymentWindow returns true if deploying a feature must wait for the next deployment window.
+// In such case, feature reports WaitingForWindow. Otherwise a previously reported WaitingForWindow is cleared.
+func (r *ClusterSummaryReconciler) isDeferredByDeploymentWindow(clusterSummaryScope *scope.ClusterSummaryScope,
+	f feature, isConfigSame bool, logger logr.Logger) bool {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+
+	deferred, next, deferDriftRemediation := clusterSummaryScope.IsDeploymentDeferred()
+	if deferred && isConfigSame && r.Deployer.IsInProgress(clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Name, string(f.id), clusterSummary.Spec.ClusterType, false) {
+		// Let a deployment already in progress complete
+		deferred = false
+	}
+
+	if deferred && !deferDriftRemediation && isDriftDetected(clusterSummary, f.id) {
+		logger.V(logs.LogDebug).Info("outside deployment window. Remediating configuration drift")
+		deferred = false
+	}
+
+	if !deferred {
+		clearWaitingForWindow(clusterSummaryScope, f.id)
+		return false
+	}
+
+	logger.V(logs.LogDebug).Info("outside deployment window. Deployment is deferred")
+	if !r.isFeatureStatusPresent(clusterSummary, f.id) {
+		clusterSummaryScope.SetFeatureStatus(f.id, configv1beta1.FeatureStatusProvisioning, nil, nil)
+	}
+
+	reason := configv1beta1.WaitingForWindowReason
+	clusterSummaryScope.SetFailureReason(f.id, &reason)
+	message := "outside deployment window. No deployment window is scheduled"
+	if !next.IsZero() {
+		message = fmt.Sprintf("outside deployment window. Next deployment window starts at %s",
+			next.UTC().Format(time.RFC3339))
+	}
+	clusterSummaryScope.SetFailureMessage(f.id, &message)
+	return true
+}
+
+// isDriftDetected returns true if a configuration drift detected for the feature has not been
+// remediated yet. This is based on ClusterSummary Status so that it holds after a restart and on
+// any replica.
+func isDriftDetected(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) bool {
+	fs := getFeatureSummaryForFeatureID(clusterSummary, featureID)
+	return fs != nil && fs.DriftDetectedTime != nil
+}
+
+// clearWaitingForWindow resets failure reason and message if feature was waiting for a deployment window
+func clearWaitingForWindow(clusterSummaryScope *scope.ClusterSummaryScope, featureID configv1beta1.FeatureID) {
+	for i := range clusterSummaryScope.ClusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[i]
+		if fs.FeatureID == featureID && fs.FailureReason != nil &&
+			*fs.FailureReason == configv1beta1.WaitingForWindowReason {
+
+			clusterSummaryScope.SetFailureReason(featureID, nil)
+			clusterSummaryScope.SetFailureMessage(featureID, nil)
+			return
+		}
+	}
+}
+
+// getDeploymentWindowRequeueAfter returns when a ClusterSummary, whose deployments are deferred,
+// must be reconciled again
+func getDeploymentWindowRequeueAfter(next time.Time) time.Duration {
+	if next.IsZero() {
+		return deploymentWindowRequeueAfter
+	}
+
+	requeueAfter := time.Until(next)
+	if requeueAfter > deploymentWindowRequeueAfter {
+		return deploymentWindowRequeueAfter
+	}
+	if requeueAfter < time.Second {
+		return time.Second
+	}
+	return requeueAfter
+}
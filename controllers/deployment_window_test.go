@@ -0,0 +1,150 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	fakedeployer "github.com/projectsveltos/libsveltos/lib/deployer/fake"
+)
+
+var _ = Describe("Deployment windows", func() {
+	It("getNextDeploymentWindow honors allowed windows and their time zone", func() {
+		maintenance := &configv1beta1.DeploymentWindow{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.DeploymentWindowSpec{
+				TimeZone: "Europe/Rome",
+				AllowedWindows: []configv1beta1.Window{
+					{Schedule: "0 22 * * 6", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+				},
+			},
+		}
+
+		// Friday. Next window is Saturday 22:00 in Rome (UTC+2 in June)
+		now := time.Date(2025, time.June, 13, 12, 0, 0, 0, time.UTC)
+		allowed, next, err := controllers.GetNextDeploymentWindow([]*configv1beta1.DeploymentWindow{maintenance}, now)
+		Expect(err).To(BeNil())
+		Expect(allowed).To(BeFalse())
+		Expect(next.Equal(time.Date(2025, time.June, 14, 20, 0, 0, 0, time.UTC))).To(BeTrue())
+
+		// Saturday 23:00 in Rome
+		now = time.Date(2025, time.June, 14, 21, 0, 0, 0, time.UTC)
+		allowed, _, err = controllers.GetNextDeploymentWindow([]*configv1beta1.DeploymentWindow{maintenance}, now)
+		Expect(err).To(BeNil())
+		Expect(allowed).To(BeTrue())
+	})
+
+	It("getNextDeploymentWindow combines freezes with allowed windows", func() {
+		freeze := &configv1beta1.DeploymentWindow{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.DeploymentWindowSpec{
+				BlockedWindows: []configv1beta1.Window{
+					{Schedule: "0 0 24 12 *", Duration: metav1.Duration{Duration: 10 * 24 * time.Hour}},
+				},
+			},
+		}
+		nightly := &configv1beta1.DeploymentWindow{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.DeploymentWindowSpec{
+				AllowedWindows: []configv1beta1.Window{
+					{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+				},
+			},
+		}
+
+		now := time.Date(2025, time.December, 26, 2, 30, 0, 0, time.UTC)
+		allowed, next, err := controllers.GetNextDeploymentWindow([]*configv1beta1.DeploymentWindow{freeze}, now)
+		Expect(err).To(BeNil())
+		Expect(allowed).To(BeFalse())
+		Expect(next.Equal(time.Date(2026, time.January, 3, 0, 0, 0, 0, time.UTC))).To(BeTrue())
+
+		// Freeze is over at midnight, but nightly window only opens at 02:00
+		allowed, next, err = controllers.GetNextDeploymentWindow([]*configv1beta1.DeploymentWindow{freeze, nightly}, now)
+		Expect(err).To(BeNil())
+		Expect(allowed).To(BeFalse())
+		Expect(next.Equal(time.Date(2026, time.January, 3, 2, 0, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	It("getNextDeploymentWindow rejects invalid schedules", func() {
+		invalid := &configv1beta1.DeploymentWindow{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.DeploymentWindowSpec{
+				BlockedWindows: []configv1beta1.Window{
+					{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+				},
+			},
+		}
+
+		_, _, err := controllers.GetNextDeploymentWindow([]*configv1beta1.DeploymentWindow{invalid}, time.Now())
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("isDeferredByDeploymentWindow remediates drifts recorded in ClusterSummary Status", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioning},
+				},
+			},
+		}
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).Build()
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         logger,
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client:   c,
+			Scheme:   scheme,
+			Deployer: fakedeployer.GetClient(context.TODO(), logger, c),
+		}
+
+		clusterSummaryScope.SetDeploymentDeferred(time.Now().Add(time.Hour), false)
+		Expect(reconciler.IsDeferredByDeploymentWindow(clusterSummaryScope, configv1beta1.FeatureHelm, logger)).To(BeTrue())
+
+		// A drift detected by any replica, even before a restart, is remediated outside the window
+		clusterSummary.Status.FeatureSummaries[0].DriftDetectedTime = &metav1.Time{Time: time.Now()}
+		Expect(reconciler.IsDeferredByDeploymentWindow(clusterSummaryScope, configv1beta1.FeatureHelm, logger)).To(BeFalse())
+
+		// Unless drift remediation is deferred as well
+		clusterSummaryScope.SetDeploymentDeferred(time.Now().Add(time.Hour), true)
+		Expect(reconciler.IsDeferredByDeploymentWindow(clusterSummaryScope, configv1beta1.FeatureHelm, logger)).To(BeTrue())
+	})
+})
@@ -0,0 +1,489 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	"github.com/projectsveltos/libsveltos/lib/patcher"
+	"github.com/projectsveltos/libsveltos/lib/sveltos_upgrade"
+)
+
+// When drift detection is done by polling, the addon-controller itself evaluates configuration drift.
+// ResourceSummary instances are stored in the management cluster (in the cluster namespace). For each
+// resource listed there, the current state in the managed cluster is fetched and its hash compared with
+// the one recorded in the ResourceSummary Status. On mismatch, the corresponding *Changed flag is set and
+// the ResourceSummary is processed exactly as the ones reported by drift-detection-manager.
+
+const (
+	// DefaultDriftDetectionPollingInterval is the default interval between two consecutive
+	// configuration drift evaluations
+	DefaultDriftDetectionPollingInterval = 2 * time.Minute
+	// DefaultDriftDetectionPollingConcurrency is the default maximum number of resources
+	// fetched in parallel from a managed cluster
+	DefaultDriftDetectionPollingConcurrency = 5
+)
+
+// pollForConfigurationDrifts periodically evaluates configuration drift for all clusters
+// matching a ClusterProfile/Profile in SyncModeContinuousWithDriftDetection.
+func pollForConfigurationDrifts(ctx context.Context, c client.Client, shardkey string,
+	interval time.Duration, concurrency int, logger logr.Logger) {
+
+	if interval <= 0 {
+		interval = DefaultDriftDetectionPollingInterval
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultDriftDetectionPollingConcurrency
+	}
+
+	for {
+		time.Sleep(interval)
+
+		logger.V(logs.LogVerbose).Info("polling for configuration drifts")
+		clusterList, err := clusterproxy.GetListOfClustersForShardKey(ctx, c, "", getCAPIOnboardAnnotation(),
+			shardkey, logger)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get clusters: %v", err))
+			continue
+		}
+
+		clustersWithDD, err := getListOfClusterWithDriftDetectionDeployed(ctx, c)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to collect clusters with drift detection: %v", err))
+			continue
+		}
+
+		for i := range clusterList {
+			cluster := &clusterList[i]
+			if _, ok := clustersWithDD[*cluster]; !ok {
+				continue
+			}
+			if !isClusterAssignedToThisReplica(cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster)) {
+				continue
+			}
+
+			err = pollClusterForConfigurationDrifts(ctx, c, cluster, concurrency, logger)
+			if err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to evaluate configuration drift in cluster: %s/%s %v",
+					cluster.Namespace, cluster.Name, err))
+			}
+		}
+	}
+}
+
+func pollClusterForConfigurationDrifts(ctx context.Context, c client.Client, cluster *corev1.ObjectReference,
+	concurrency int, logger logr.Logger) error {
+
+	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name))
+	ready, err := clusterproxy.IsClusterReadyToBeConfigured(ctx, c, cluster, logger)
+	if err != nil {
+		logger.V(logs.LogDebug).Info("cluster is not ready yet")
+		return err
+	}
+
+	if !ready {
+		return nil
+	}
+
+	clusterType := clusterproxy.GetClusterType(cluster)
+
+	rsList := libsveltosv1beta1.ResourceSummaryList{}
+	err = c.List(ctx, &rsList, client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{sveltos_upgrade.ClusterNameLabel: cluster.Name})
+	if err != nil {
+		return err
+	}
+
+	if len(rsList.Items) == 0 {
+		return nil
+	}
+
+	// ResourceSummary lists resources deployed by Sveltos. Those are always accessed
+	// using cluster-admin.
+	remoteClient, err := clustercache.GetManager().GetKubernetesClient(ctx, c, cluster.Namespace, cluster.Name,
+		"", "", clusterType, logger)
+	if err != nil {
+		return err
+	}
+
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		if !rs.DeletionTimestamp.IsZero() {
+			// ignore deleted resourceSummary
+			continue
+		}
+
+		l := logger.WithValues("resourceSummary", rs.Name)
+		err = evaluateResourceSummary(ctx, c, remoteClient, rs, concurrency, l)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evaluateResourceSummary fetches all resources listed in the ResourceSummary from the managed cluster
+// and compares their hash with the recorded one. ResourceSummary Status is updated accordingly and, if a
+// drift is detected, ResourceSummary is processed so that affected features are redeployed.
+func evaluateResourceSummary(ctx context.Context, c, remoteClient client.Client,
+	rs *libsveltosv1beta1.ResourceSummary, concurrency int, logger logr.Logger) error {
+
+	resourceHashes, resourcesChanged, err := evaluateResources(ctx, remoteClient, rs.Spec.Resources,
+		rs.Status.ResourceHashes, rs.Spec.Patches, concurrency, logger)
+	if err != nil {
+		return err
+	}
+
+	kustomizeResourceHashes, kustomizeResourcesChanged, err := evaluateResources(ctx, remoteClient,
+		rs.Spec.KustomizeResources, rs.Status.KustomizeResourceHashes, rs.Spec.Patches, concurrency, logger)
+	if err != nil {
+		return err
+	}
+
+	helmResources := make([]libsveltosv1beta1.Resource, 0)
+	for i := range rs.Spec.ChartResources {
+		helmResources = append(helmResources, rs.Spec.ChartResources[i].Resources...)
+	}
+	helmResourceHashes, helmResourcesChanged, err := evaluateResources(ctx, remoteClient, helmResources,
+		rs.Status.HelmResourceHashes, rs.Spec.Patches, concurrency, logger)
+	if err != nil {
+		return err
+	}
+
+	previousStatus := rs.Status.DeepCopy()
+	rs.Status.ResourceHashes = resourceHashes
+	rs.Status.KustomizeResourceHashes = kustomizeResourceHashes
+	rs.Status.HelmResourceHashes = helmResourceHashes
+	rs.Status.ResourcesChanged = rs.Status.ResourcesChanged || resourcesChanged
+	rs.Status.KustomizeResourcesChanged = rs.Status.KustomizeResourcesChanged || kustomizeResourcesChanged
+	rs.Status.HelmResourcesChanged = rs.Status.HelmResourcesChanged || helmResourcesChanged
+
+	if !reflect.DeepEqual(*previousStatus, rs.Status) {
+		err = c.Status().Update(ctx, rs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if rs.Status.ResourcesChanged || rs.Status.HelmResourcesChanged || rs.Status.KustomizeResourcesChanged {
+		logger.V(logs.LogDebug).Info("configuration drift detected")
+		return processResourceSummary(ctx, c, rs, logger)
+	}
+
+	return nil
+}
+
+// evaluateResources fetches resources (at most concurrency at a time) and returns their current hashes.
+// When a hash was previously recorded for a resource, it is kept and the returned bool is set to true
+// if the resource was either modified or deleted.
+func evaluateResources(ctx context.Context, remoteClient client.Client, resources []libsveltosv1beta1.Resource,
+	recorded []libsveltosv1beta1.ResourceHash, patches []libsveltosv1beta1.Patch, concurrency int,
+	logger logr.Logger) ([]libsveltosv1beta1.ResourceHash, bool, error) {
+
+	recordedHashes := make(map[libsveltosv1beta1.Resource]string, len(recorded))
+	for i := range recorded {
+		recordedHashes[getResourceHashKey(&recorded[i].Resource)] = recorded[i].Hash
+	}
+
+	type result struct {
+		hash    string
+		skip    bool
+		missing bool
+		err     error
+	}
+
+	results := make([]result, len(resources))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range resources {
+		if resources[i].IgnoreForConfigurationDrift {
+			results[i].skip = true
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			u, err := fetchDeployedResource(ctx, remoteClient, &resources[i])
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					results[i].missing = true
+					return
+				}
+				results[i].err = err
+				return
+			}
+			if hasIgnoreConfigurationDriftAnnotation(u) {
+				results[i].skip = true
+				return
+			}
+			results[i].hash, results[i].err = getDeployedResourceHash(u, patches)
+		}(i)
+	}
+	wg.Wait()
+
+	drift := false
+	var errs error
+	hashes := make([]libsveltosv1beta1.ResourceHash, 0, len(resources))
+	for i := range resources {
+		if results[i].skip {
+			continue
+		}
+		if results[i].err != nil {
+			errs = errors.Join(errs, results[i].err)
+			continue
+		}
+
+		key := getResourceHashKey(&resources[i])
+		previousHash, ok := recordedHashes[key]
+		if results[i].missing {
+			if ok {
+				logger.V(logs.LogDebug).Info(fmt.Sprintf("resource %s %s/%s not found",
+					key.Kind, key.Namespace, key.Name))
+				drift = true
+			}
+			continue
+		}
+
+		if ok && previousHash != results[i].hash {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("resource %s %s/%s has changed",
+				key.Kind, key.Namespace, key.Name))
+			drift = true
+		}
+
+		// On drift, recorded hash is kept. It will be reset once resources are redeployed.
+		currentHash := results[i].hash
+		if ok {
+			currentHash = previousHash
+		}
+		hashes = append(hashes, libsveltosv1beta1.ResourceHash{Resource: key, Hash: currentHash})
+	}
+
+	if len(hashes) == 0 {
+		return nil, drift, errs
+	}
+
+	return hashes, drift, errs
+}
+
+func getResourceHashKey(resource *libsveltosv1beta1.Resource) libsveltosv1beta1.Resource {
+	return libsveltosv1beta1.Resource{
+		Name:      resource.Name,
+		Namespace: resource.Namespace,
+		Group:     resource.Group,
+		Kind:      resource.Kind,
+		Version:   resource.Version,
+	}
+}
+
+func fetchDeployedResource(ctx context.Context, remoteClient client.Client, resource *libsveltosv1beta1.Resource,
+) (*unstructured.Unstructured, error) {
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   resource.Group,
+		Version: resource.Version,
+		Kind:    resource.Kind,
+	})
+
+	err := remoteClient.Get(ctx, types.NamespacedName{Namespace: resource.Namespace, Name: resource.Name}, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// getDeployedResourceHash returns the hash of a deployed resource. Only fields applied by Sveltos, as reported
+// by the resource managedFields, are considered, so that fields defaulted by the API server or set by other
+// controllers are not reported as drift. If the resource has no managedFields entry for Sveltos, everything
+// but metadata and status is considered. Fields matching a DriftExclusion (patches) are removed before
+// evaluating the hash.
+func getDeployedResourceHash(u *unstructured.Unstructured, patches []libsveltosv1beta1.Patch) (string, error) {
+	obj := u.DeepCopy()
+
+	owned, err := getSveltosOwnedFields(u)
+	if err != nil {
+		return "", err
+	}
+
+	if owned != nil {
+		projected, _ := projectFields(obj.Object, owned).(map[string]interface{})
+		obj = &unstructured.Unstructured{Object: projected}
+		// apiVersion and kind are needed to match patches
+		obj.SetAPIVersion(u.GetAPIVersion())
+		obj.SetKind(u.GetKind())
+		obj.SetName(u.GetName())
+		obj.SetNamespace(u.GetNamespace())
+	} else {
+		unstructured.RemoveNestedField(obj.Object, "status")
+		unstructured.RemoveNestedField(obj.Object, "metadata")
+		obj.SetName(u.GetName())
+		obj.SetNamespace(u.GetNamespace())
+	}
+
+	if len(patches) > 0 {
+		p := &patcher.CustomPatchPostRenderer{Patches: patches}
+		patchedObjects, err := p.RunUnstructured([]*unstructured.Unstructured{obj})
+		if err != nil {
+			return "", err
+		}
+		obj = patchedObjects[0]
+	}
+
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(data)
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// getSveltosFieldManagers returns the field managers used by Sveltos when deploying resources:
+// the one used when applying PolicyRefs and KustomizationRefs and the one used by helm.
+func getSveltosFieldManagers() map[string]bool {
+	helmManager := kube.ManagedFieldsManager
+	if helmManager == "" && len(os.Args[0]) > 0 {
+		helmManager = filepath.Base(os.Args[0])
+	}
+
+	return map[string]bool{
+		"application/apply-patch": true,
+		helmManager:               true,
+	}
+}
+
+// getSveltosOwnedFields returns the set of fields owned by Sveltos field managers.
+// Returns nil if the resource has no managedFields entry for Sveltos.
+func getSveltosOwnedFields(u *unstructured.Unstructured) (*fieldpath.Set, error) {
+	managers := getSveltosFieldManagers()
+
+	var owned *fieldpath.Set
+	managedFields := u.GetManagedFields()
+	for i := range managedFields {
+		if !managers[managedFields[i].Manager] || managedFields[i].FieldsV1 == nil {
+			continue
+		}
+
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(managedFields[i].FieldsV1.Raw)); err != nil {
+			return nil, err
+		}
+		if owned == nil {
+			owned = set
+		} else {
+			owned = owned.Union(set)
+		}
+	}
+
+	return owned, nil
+}
+
+// projectFields returns the portion of obj made of the fields in set
+func projectFields(obj interface{}, set *fieldpath.Set) interface{} {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for key := range v {
+			pe := fieldpath.PathElement{FieldName: &key}
+			if child, ok := set.Children.Get(pe); ok {
+				result[key] = projectFields(v[key], child)
+			} else if set.Members.Has(pe) {
+				result[key] = v[key]
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0)
+		for i := range v {
+			var child *fieldpath.Set
+			member := false
+			set.Children.Iterate(func(pe fieldpath.PathElement) {
+				if child == nil && matchListElement(pe, v[i], i) {
+					child, _ = set.Children.Get(pe)
+				}
+			})
+			set.Members.Iterate(func(pe fieldpath.PathElement) {
+				if !member && matchListElement(pe, v[i], i) {
+					member = true
+				}
+			})
+			if child != nil {
+				result = append(result, projectFields(v[i], child))
+			} else if member {
+				result = append(result, v[i])
+			}
+		}
+		return result
+	default:
+		return obj
+	}
+}
+
+// matchListElement returns true if the list element item, at position index, is selected by pe
+func matchListElement(pe fieldpath.PathElement, item interface{}, index int) bool {
+	switch {
+	case pe.Index != nil:
+		return *pe.Index == index
+	case pe.Value != nil:
+		return value.Equals(value.NewValueInterface(item), *pe.Value)
+	case pe.Key != nil:
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for _, f := range *pe.Key {
+			v, ok := m[f.Name]
+			if !ok || !value.Equals(value.NewValueInterface(v), f.Value) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Drift detection by polling", func() {
+	var configMap *corev1.ConfigMap
+	var resource libsveltosv1beta1.Resource
+
+	BeforeEach(func() {
+		configMap = &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "ConfigMap",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Data: map[string]string{
+				randomString(): randomString(),
+			},
+		}
+
+		resource = libsveltosv1beta1.Resource{
+			Namespace: configMap.Namespace,
+			Name:      configMap.Name,
+			Kind:      "ConfigMap",
+			Version:   "v1",
+		}
+	})
+
+	It("getDeployedResourceHash ignores status and fields set by the API server", func() {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configMap)
+		Expect(err).To(BeNil())
+		u := &unstructured.Unstructured{Object: content}
+
+		hash, err := controllers.GetDeployedResourceHash(u, nil)
+		Expect(err).To(BeNil())
+
+		u.SetResourceVersion(randomString())
+		u.SetGeneration(3)
+		u.SetUID("7d0b1b3c-7f2e-4d39-a9a4-5bd3c9c1f0aa")
+		Expect(unstructured.SetNestedField(u.Object, randomString(), "status", "phase")).To(Succeed())
+
+		currentHash, err := controllers.GetDeployedResourceHash(u, nil)
+		Expect(err).To(BeNil())
+		Expect(currentHash).To(Equal(hash))
+
+		// Without a managedFields entry for Sveltos, metadata is not considered
+		u.SetAnnotations(map[string]string{"deployment.kubernetes.io/revision": "2"})
+		currentHash, err = controllers.GetDeployedResourceHash(u, nil)
+		Expect(err).To(BeNil())
+		Expect(currentHash).To(Equal(hash))
+
+		Expect(unstructured.SetNestedField(u.Object, randomString(), "data", randomString())).To(Succeed())
+		currentHash, err = controllers.GetDeployedResourceHash(u, nil)
+		Expect(err).To(BeNil())
+		Expect(currentHash).ToNot(Equal(hash))
+	})
+
+	It("getDeployedResourceHash considers only fields applied by Sveltos", func() {
+		key := randomString()
+		configMap.Data = map[string]string{key: randomString()}
+		configMap.Labels = map[string]string{"app": randomString()}
+		configMap.ManagedFields = []metav1.ManagedFieldsEntry{
+			{
+				Manager:   "application/apply-patch",
+				Operation: metav1.ManagedFieldsOperationApply,
+				FieldsV1: &metav1.FieldsV1{
+					Raw: []byte(fmt.Sprintf(`{"f:data":{"f:%s":{}},"f:metadata":{"f:labels":{"f:app":{}}}}`, key)),
+				},
+			},
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configMap)
+		Expect(err).To(BeNil())
+		u := &unstructured.Unstructured{Object: content}
+
+		hash, err := controllers.GetDeployedResourceHash(u, nil)
+		Expect(err).To(BeNil())
+
+		// Fields set by other controllers are ignored
+		u.SetAnnotations(map[string]string{"kubectl.kubernetes.io/last-applied-configuration": randomString()})
+		Expect(unstructured.SetNestedField(u.Object, randomString(), "data", randomString())).To(Succeed())
+		currentHash, err := controllers.GetDeployedResourceHash(u, nil)
+		Expect(err).To(BeNil())
+		Expect(currentHash).To(Equal(hash))
+
+		// Label applied by Sveltos is considered
+		u.SetLabels(map[string]string{"app": randomString()})
+		currentHash, err = controllers.GetDeployedResourceHash(u, nil)
+		Expect(err).To(BeNil())
+		Expect(currentHash).ToNot(Equal(hash))
+	})
+
+	It("getDeployedResourceHash ignores fields matching DriftExclusions", func() {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configMap)
+		Expect(err).To(BeNil())
+		u := &unstructured.Unstructured{Object: content}
+
+		patches := controllers.TransformDriftExclusionsToPatches([]configv1beta1.DriftExclusion{
+			{
+				Paths: []string{"/data"},
+				Target: &libsveltosv1beta1.PatchSelector{
+					Kind:    "ConfigMap",
+					Version: "v1",
+				},
+			},
+		})
+
+		hash, err := controllers.GetDeployedResourceHash(u, patches)
+		Expect(err).To(BeNil())
+
+		Expect(unstructured.SetNestedField(u.Object, randomString(), "data", randomString())).To(Succeed())
+		currentHash, err := controllers.GetDeployedResourceHash(u, patches)
+		Expect(err).To(BeNil())
+		Expect(currentHash).To(Equal(hash))
+	})
+
+	It("evaluateResources records hashes and detects drifts", func() {
+		initObjects := []client.Object{configMap}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		resources := []libsveltosv1beta1.Resource{resource}
+
+		// No hash recorded yet. Current state is recorded
+		hashes, drift, err := controllers.EvaluateResources(context.TODO(), c, resources, nil, nil, 1, logger)
+		Expect(err).To(BeNil())
+		Expect(drift).To(BeFalse())
+		Expect(len(hashes)).To(Equal(1))
+		Expect(hashes[0].Resource).To(Equal(resource))
+
+		// Nothing changed
+		currentHashes, drift, err := controllers.EvaluateResources(context.TODO(), c, resources, hashes, nil, 1, logger)
+		Expect(err).To(BeNil())
+		Expect(drift).To(BeFalse())
+		Expect(currentHashes).To(Equal(hashes))
+
+		// Modify resource
+		configMap.Data = map[string]string{randomString(): randomString()}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+
+		currentHashes, drift, err = controllers.EvaluateResources(context.TODO(), c, resources, hashes, nil, 1, logger)
+		Expect(err).To(BeNil())
+		Expect(drift).To(BeTrue())
+		// On drift recorded hash is kept
+		Expect(currentHashes).To(Equal(hashes))
+
+		// Resources with driftDetectionIgnore annotation are not tracked
+		configMap.Annotations = map[string]string{"projectsveltos.io/driftDetectionIgnore": "ok"}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+
+		currentHashes, drift, err = controllers.EvaluateResources(context.TODO(), c, resources, hashes, nil, 1, logger)
+		Expect(err).To(BeNil())
+		Expect(drift).To(BeFalse())
+		Expect(currentHashes).To(BeNil())
+
+		// Deleted resources are reported as drift
+		Expect(c.Delete(context.TODO(), configMap)).To(Succeed())
+
+		_, drift, err = controllers.EvaluateResources(context.TODO(), c, resources, hashes, nil, 1, logger)
+		Expect(err).To(BeNil())
+		Expect(drift).To(BeTrue())
+	})
+
+	It("evaluateResources skips resources marked as IgnoreForConfigurationDrift", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		resource.IgnoreForConfigurationDrift = true
+		recorded := []libsveltosv1beta1.ResourceHash{{Resource: resource, Hash: randomString()}}
+
+		hashes, drift, err := controllers.EvaluateResources(context.TODO(), c, []libsveltosv1beta1.Resource{resource},
+			recorded, nil, 1, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(drift).To(BeFalse())
+		Expect(hashes).To(BeNil())
+	})
+})
@@ -0,0 +1,135 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+// Kubernetes Events
+// Besides logging, ClusterProfile/Profile and ClusterSummary reconcilers emit Events, so what happens
+// (and why) is visible to anyone with access to the management cluster:
+// - on ClusterProfiles/Profiles: deploying to and withdrawing from clusters, withdrawal blocked and
+// circuit breaker tripped;
+// - on ClusterSummaries: features deployed, failed or removed, helm releases installed, upgraded or
+// uninstalled, conflicts won or lost (along with the competing profile and tiers), configuration drifts
+// detected and remediated and deployments blocked by dependencies.
+// Each reconciler has its own recorder, which is carried by the context down to the code emitting Events.
+// Helm charts and resources are deployed by deployer workers, not by reconcilers, so requests queued to
+// the deployer carry the recorder of the ClusterSummary reconciler as well.
+
+const (
+	eventReasonClusterMatched          = "ClusterMatched"
+	eventReasonWithdrawing             = "Withdrawing"
+	eventReasonWithdrawalBlocked       = "WithdrawalBlocked"
+	eventReasonCircuitBreakerTripped   = "CircuitBreakerTripped"
+	eventReasonFeatureDeployed         = "FeatureDeployed"
+	eventReasonFeatureFailed           = "FeatureDeploymentFailed"
+	eventReasonFeatureRemoved          = "FeatureRemoved"
+	eventReasonReleaseInstalled        = "HelmReleaseInstalled"
+	eventReasonReleaseUpgraded         = "HelmReleaseUpgraded"
+	eventReasonReleaseUninstalled      = "HelmReleaseUninstalled"
+	eventReasonConflictWon             = "ConflictWon"
+	eventReasonConflictLost            = "ConflictLost"
+	eventReasonDriftDetected           = "DriftDetected"
+	eventReasonDriftRemediated         = "DriftRemediated"
+	eventReasonDependenciesNotDeployed = "DependenciesNotDeployed"
+)
+
+// eventRecorderKey is the context key carrying the recorder used to emit Events
+type eventRecorderKey struct{}
+
+// withEventRecorder returns a context in which Events are emitted with recorder
+func withEventRecorder(ctx context.Context, recorder record.EventRecorder) context.Context {
+	if recorder == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, eventRecorderKey{}, recorder)
+}
+
+func getEventRecorder(ctx context.Context) record.EventRecorder {
+	recorder, _ := ctx.Value(eventRecorderKey{}).(record.EventRecorder)
+	return recorder
+}
+
+// withDeployerEventRecorder returns a deployer request handler which invokes handler with a context
+// carrying recorder. Deployer workers do not run with the reconciler context.
+func withDeployerEventRecorder(recorder record.EventRecorder, handler deployer.RequestHandler) deployer.RequestHandler {
+	if recorder == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, c client.Client,
+		clusterNamespace, clusterName, applicant, featureID string,
+		clusterType libsveltosv1beta1.ClusterType,
+		o deployer.Options, logger logr.Logger) error {
+
+		return handler(withEventRecorder(ctx, recorder), c, clusterNamespace, clusterName, applicant,
+			featureID, clusterType, o, logger)
+	}
+}
+
+// recordEvent emits an Event on object. It is a no-op if context carries no recorder
+// (for instance when profiles are rendered offline).
+func recordEvent(ctx context.Context, object client.Object, eventType, reason, messageFmt string,
+	args ...interface{}) {
+
+	recorder := getEventRecorder(ctx)
+	if recorder == nil || object == nil {
+		return
+	}
+	recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+// recordWarningEvent emits a Warning Event on object
+func recordWarningEvent(ctx context.Context, object client.Object, reason, messageFmt string, args ...interface{}) {
+	recordEvent(ctx, object, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+// recordNormalEvent emits a Normal Event on object
+func recordNormalEvent(ctx context.Context, object client.Object, reason, messageFmt string, args ...interface{}) {
+	recordEvent(ctx, object, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// getClusterDescription returns a description of the cluster a ClusterSummary is for
+func getClusterDescription(clusterSummary *configv1beta1.ClusterSummary) string {
+	return fmt.Sprintf("%s %s/%s", clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName)
+}
+
+// getClusterSummaryProfileInfo returns a description of the ClusterProfile/Profile owning a ClusterSummary
+// along with its tier
+func getClusterSummaryProfileInfo(clusterSummary *configv1beta1.ClusterSummary) string {
+	owner := clusterSummary.Name
+	if ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary); err == nil && ownerRef != nil {
+		owner = fmt.Sprintf("%s %s", ownerRef.Kind, ownerRef.Name)
+		if ownerRef.Kind == configv1beta1.ProfileKind {
+			owner = fmt.Sprintf("%s %s/%s", ownerRef.Kind, clusterSummary.Namespace, ownerRef.Name)
+		}
+	}
+	return fmt.Sprintf("%s (tier %d)", owner, clusterSummary.Spec.ClusterProfileSpec.Tier)
+}
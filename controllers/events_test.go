@@ -0,0 +1,101 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+var _ = Describe("Events", func() {
+	var recorder *record.FakeRecorder
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	It("ClusterProfile reports deploying to and withdrawing from clusters", func() {
+		cluster := corev1.ObjectReference{
+			Namespace: randomString(), Name: randomString(),
+			Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{cluster},
+			},
+		}
+
+		initObjects := []client.Object{clusterProfile}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		ctx := controllers.WithEventRecorder(context.TODO(), recorder)
+		Expect(controllers.CreateClusterSummary(ctx, c, profileScope, &cluster)).To(Succeed())
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("ClusterMatched"), ContainSubstring(cluster.Name))))
+
+		// Cluster is not matching anymore
+		clusterProfile.Status.MatchingClusterRefs = nil
+		Expect(controllers.CleanClusterSummaries(ctx, c, profileScope)).ToNot(Succeed())
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("Withdrawing"), ContainSubstring(cluster.Name))))
+	})
+
+	It("requests queued to the deployer carry the recorder", func() {
+		var handlerRecorder any
+		handler := func(ctx context.Context, c client.Client, clusterNamespace, clusterName, applicant, featureID string,
+			clusterType libsveltosv1beta1.ClusterType, o deployer.Options, logger logr.Logger) error {
+
+			handlerRecorder = controllers.GetEventRecorder(ctx)
+			return nil
+		}
+
+		Expect(controllers.WithDeployerEventRecorder(recorder, handler)(context.TODO(), nil, randomString(),
+			randomString(), randomString(), randomString(), libsveltosv1beta1.ClusterTypeCapi, deployer.Options{},
+			logr.Discard())).To(Succeed())
+		Expect(handlerRecorder).To(Equal(recorder))
+
+		// Without a recorder no Event is emitted
+		Expect(controllers.GetEventRecorder(context.TODO())).To(BeNil())
+	})
+})
@@ -16,6 +16,20 @@ limitations under the License.
 
 package controllers
 
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
 var (
 	UpdateClusterSummaries                = updateClusterSummaries
 	CreateClusterSummary                  = createClusterSummary
@@ -61,6 +75,7 @@ var (
 	CanRemoveFinalizer                   = (*ClusterSummaryReconciler).canRemoveFinalizer
 	ReconcileDelete                      = (*ClusterSummaryReconciler).reconcileDelete
 	AreDependenciesDeployed              = (*ClusterSummaryReconciler).areDependenciesDeployed
+	AreDependentsRemoved                 = (*ClusterSummaryReconciler).areDependentsRemoved
 	SetFailureMessage                    = (*ClusterSummaryReconciler).setFailureMessage
 	ResetFeatureStatus                   = (*ClusterSummaryReconciler).resetFeatureStatus
 
@@ -126,6 +141,7 @@ var (
 	CreateReportForUnmanagedHelmRelease      = createReportForUnmanagedHelmRelease
 	UpdateClusterReportWithHelmReports       = updateClusterReportWithHelmReports
 	HandleCharts                             = handleCharts
+	UndeployHelmCharts                       = undeployHelmCharts
 	GetHelmReferenceResourceHash             = getHelmReferenceResourceHash
 	GetHelmChartValuesHash                   = getHelmChartValuesHash
 	GetCredentialsAndCAFiles                 = getCredentialsAndCAFiles
@@ -171,6 +187,43 @@ var (
 	CollectResourceSummariesFromCluster = collectResourceSummariesFromCluster
 )
 
+var (
+	GetClusterReference        = getClusterReference
+	HandleResourceSummaryEvent = handleResourceSummaryEvent
+	KeepResourceSummaryWatch   = keepResourceSummaryWatch
+)
+
+var (
+	GetDeployedResourceHash = getDeployedResourceHash
+	EvaluateResources       = evaluateResources
+)
+
+var (
+	IsResourceReady             = isResourceReady
+	UpdateFeaturesHealth        = updateFeaturesHealth
+	GetDegradedCondition        = getDegradedCondition
+	GetHealthMonitoringInterval = getHealthMonitoringInterval
+)
+
+var (
+	IsWithdrawalForced                         = isWithdrawalForced
+	UpdateProfileWaitingForDependentsCondition = updateProfileWaitingForDependentsCondition
+)
+
+var (
+	GetPolicyRefsToWaitFor        = getPolicyRefsToWaitFor
+	GetKustomizationRefsToWaitFor = getKustomizationRefsToWaitFor
+	InstantiateWaitReferences     = instantiateWaitReferences
+	SelectResourcesToWaitFor      = selectResourcesToWaitFor
+	GetPendingResources           = getPendingResources
+	GetWaitHash                   = getWaitHash
+)
+
+var (
+	SortResourcesForApply = sortResourcesForApply
+	SortGVKsForWithdraw   = sortGVKsForWithdraw
+)
+
 var (
 	InitializeManager = initializeManager
 )
@@ -211,3 +264,155 @@ var (
 	RemoveStaleResourceSummary = removeStaleResourceSummary
 	RemoveDuplicates           = removeDuplicates
 )
+
+var (
+	GetMaxConcurrentHelmCharts = getMaxConcurrentHelmCharts
+)
+
+var (
+	ValidateEntryDependencies     = validateEntryDependencies
+	GetDeploymentWaves            = getDeploymentWaves
+	GetWithdrawalOrder            = getWithdrawalOrder
+	CheckCrossFeatureDependencies = checkCrossFeatureDependencies
+	CheckDependentsRemoved        = checkDependentsRemoved
+)
+
+var (
+	UpdatePlan            = updatePlan
+	GetPlanHash           = getPlanHash
+	GetClusterSummarySpec = getClusterSummarySpec
+	IsApprovedPlanStale   = isApprovedPlanStale
+)
+
+var (
+	SimulateProfileChanges = simulateProfileChanges
+)
+
+var (
+	GetWithdrawalHash   = getWithdrawalHash
+	IsWithdrawalBlocked = isWithdrawalBlocked
+)
+
+var (
+	EvaluateCircuitBreaker = evaluateCircuitBreaker
+)
+
+var (
+	NewClusterNotification = newClusterNotification
+	Notify                 = notify
+)
+
+func SetNotificationRetryInterval(interval time.Duration) {
+	notificationRetryInterval = interval
+}
+
+// GetNextDeploymentWindow returns whether DeploymentWindows allow deployments at time now and,
+// if not, when they will next allow deployments
+func GetNextDeploymentWindow(deploymentWindows []*configv1beta1.DeploymentWindow, now time.Time) (bool, time.Time, error) {
+	schedules := make([]*deploymentWindowSchedule, len(deploymentWindows))
+	for i := range deploymentWindows {
+		var err error
+		schedules[i], err = parseDeploymentWindow(deploymentWindows[i])
+		if err != nil {
+			return false, time.Time{}, err
+		}
+	}
+
+	allowed, next := getNextDeploymentWindow(schedules, now)
+	return allowed, next, nil
+}
+
+// IsDeferredByDeploymentWindow returns true if deploying featureID must wait for the next deployment window
+func (r *ClusterSummaryReconciler) IsDeferredByDeploymentWindow(clusterSummaryScope *scope.ClusterSummaryScope,
+	featureID configv1beta1.FeatureID, logger logr.Logger) bool {
+
+	return r.isDeferredByDeploymentWindow(clusterSummaryScope, feature{id: featureID}, false, logger)
+}
+
+// RunChartDeployments deploys charts using deploy and returns, in order, the error (if any) of each deployed
+// chart along with the release names of the charts skipped
+func RunChartDeployments(charts []configv1beta1.HelmChart, maxConcurrent int, continueOnError bool,
+	deploy func(chart *configv1beta1.HelmChart) error) (errs []error, skipped []string) {
+
+	deployments := make([]*helmChartDeployment, len(charts))
+	for i := range charts {
+		deployments[i] = &helmChartDeployment{chart: &charts[i]}
+	}
+
+	runChartDeployments(context.TODO(), deployments, maxConcurrent, continueOnError,
+		func(_ context.Context, chart *configv1beta1.HelmChart) (*releaseInfo, *configv1beta1.ReleaseReport, error) {
+			return nil, nil, deploy(chart)
+		}, logr.Discard())
+
+	for i := range deployments {
+		if deployments[i].skipped {
+			skipped = append(skipped, deployments[i].chart.ReleaseName)
+			continue
+		}
+		errs = append(errs, deployments[i].err)
+	}
+	return errs, skipped
+}
+
+var (
+	RecordAuditTrigger = recordAuditTrigger
+	AuditDeployment    = auditDeployment
+)
+
+var (
+	StartRevisionSession  = startRevisionSession
+	EndRevisionSession    = endRevisionSession
+	RecordRenderedObjects = recordRenderedObjects
+	StoreRevision         = storeRevision
+	GetPinnedRevision     = getPinnedRevision
+	ListRevisionSecrets   = listRevisionSecrets
+)
+
+var (
+	IsClusterAssignedToThisReplica = isClusterAssignedToThisReplica
+)
+
+func GetShardOwner(members []string, key string) string {
+	return newShardRing(members).getOwner(key)
+}
+
+func (r *ClusterSummaryReconciler) UpdateShardMembership(ctx context.Context, logger logr.Logger) error {
+	return r.updateShardMembership(ctx, logger)
+}
+
+func IsShardMembershipReady() bool {
+	autoSharding.mu.Lock()
+	defer autoSharding.mu.Unlock()
+	return autoSharding.ready
+}
+
+func GetShardEvents() chan event.GenericEvent {
+	return autoSharding.events
+}
+
+var (
+	AcquireDeploymentSlot = acquireDeploymentSlot
+)
+
+var (
+	GetDeploymentOptions = getDeploymentOptions
+)
+
+// NewPriorityDeployerForTest returns a priority deployer along with the function handing over its
+// pending requests to d. No request is handed over till such function is invoked.
+func NewPriorityDeployerForTest(d deployer.DeployerInterface, workers int, agingInterval time.Duration,
+	logger logr.Logger) (deployer.DeployerInterface, func(ctx context.Context)) {
+
+	p := newPriorityDeployer(d, workers, agingInterval, logger)
+	return p, p.dispatch
+}
+
+func GetManagementClusterAccess() (client.Client, *rest.Config) {
+	return managementClusterClient, managementClusterConfig
+}
+
+var (
+	WithEventRecorder         = withEventRecorder
+	WithDeployerEventRecorder = withDeployerEventRecorder
+	GetEventRecorder          = getEventRecorder
+)
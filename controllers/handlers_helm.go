@@ -29,6 +29,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"dario.cat/mergo"
@@ -66,6 +67,7 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2/textlogger"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -86,6 +88,13 @@ import (
 var (
 	storage    = repo.File{}
 	helmLogger = textlogger.NewLogger(textlogger.NewConfig())
+
+	// storageMux protects storage, as charts might be deployed concurrently
+	storageMux sync.Mutex
+
+	// helmKubeconfigs contains, for each temporary kubeconfig file, the managed cluster it
+	// gives access to. Key: kubeconfig path; value: *helmKubeconfig
+	helmKubeconfigs sync.Map
 )
 
 const (
@@ -131,6 +140,11 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 		return err
 	}
 
+	if err = startRevisionSession(ctx, c, clusterSummary, configv1beta1.FeatureHelm, logger); err != nil {
+		return err
+	}
+	defer endRevisionSession(clusterSummary, configv1beta1.FeatureHelm)
+
 	startInMgmtCluster := startDriftDetectionInMgmtCluster(o)
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		// Deploy drift detection manager first. Have manager up by the time resourcesummary is created
@@ -156,13 +170,8 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 	logger = logger.WithValues("clusterSummary", clusterSummary.Name)
 	logger = logger.WithValues("admin", fmt.Sprintf("%s/%s", adminNamespace, adminName))
 
-	kubeconfigContent, err := clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
-		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
-	if err != nil {
-		return err
-	}
-
-	kubeconfig, closer, err := clusterproxy.CreateKubeconfig(logger, kubeconfigContent)
+	kubeconfig, closer, err := createHelmKubeconfig(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
 	if err != nil {
 		return err
 	}
@@ -215,7 +224,15 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 	if err != nil {
 		return err
 	}
-	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureHelm, logger)
+	err = validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureHelm, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := storeRevision(ctx, c, clusterSummary, configv1beta1.FeatureHelm, logger); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to store revision: %v", err))
+	}
+	return nil
 }
 
 func undeployHelmCharts(ctx context.Context, c client.Client,
@@ -240,13 +257,14 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 
 	logger.V(logs.LogDebug).Info("undeployHelmCharts")
 
-	kubeconfigContent, err := clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
-		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	// Entries of other features depending on entries of this feature must be withdrawn first
+	err = checkDependentsRemoved(clusterSummary, configv1beta1.FeatureHelm)
 	if err != nil {
 		return err
 	}
 
-	kubeconfig, closer, err := clusterproxy.CreateKubeconfig(logger, kubeconfigContent)
+	kubeconfig, closer, err := createHelmKubeconfig(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
 	if err != nil {
 		return err
 	}
@@ -272,6 +290,7 @@ func undeployHelmChartResources(ctx context.Context, c client.Client, clusterSum
 		return err
 	}
 	releaseReports = append(releaseReports, undeployedReports...)
+	auditWithdrawal(clusterSummary, configv1beta1.FeatureHelm, nil, releaseReports, logger)
 
 	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
@@ -319,7 +338,8 @@ func uninstallHelmCharts(ctx context.Context, c client.Client, clusterSummary *c
 	}
 
 	releaseReports := make([]configv1beta1.ReleaseReport, 0)
-	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+	// Charts are uninstalled before the charts they depend on
+	for _, i := range getWithdrawalOrder(&clusterSummary.Spec.ClusterProfileSpec, configv1beta1.FeatureHelm) {
 		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
 		canManage, err := determineChartOwnership(ctx, c, clusterSummary, currentChart, logger)
 		if err != nil {
@@ -490,7 +510,8 @@ func handleCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSumm
 		return err
 	}
 
-	releaseReports, chartDeployed, deployError := walkChartsAndDeploy(ctx, c, clusterSummary, kubeconfig, mgmtResources, logger)
+	releaseReports, chartDeployed, deployError := walkChartsAndDeploy(ctx, c, remoteClient, clusterSummary, kubeconfig,
+		mgmtResources, logger)
 
 	// If there was an helm release previous managed by this ClusterSummary and currently not referenced
 	// anymore, such helm release has been successfully remove at this point. So
@@ -507,6 +528,7 @@ func handleCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSumm
 		return err
 	}
 	releaseReports = append(releaseReports, undeployedReports...)
+	auditDeployment(clusterSummary, configv1beta1.FeatureHelm, nil, nil, releaseReports, deployError, logger)
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1beta1.SyncModeDryRun {
 		chartManager, mgrErr := chartmanager.GetChartManagerInstance(ctx, c)
 		if mgrErr != nil {
@@ -539,78 +561,55 @@ func handleCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSumm
 
 // walkChartsAndDeploy walks all referenced helm charts. Deploys (install or upgrade) any chart
 // this clusterSummary is registered to manage.
-func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+func walkChartsAndDeploy(ctx context.Context, c, remoteClient client.Client, clusterSummary *configv1beta1.ClusterSummary,
 	kubeconfig string, mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
 ) ([]configv1beta1.ReleaseReport, []configv1beta1.Chart, error) {
 
 	errorMsg := ""
-	conflictErrorMessage := ""
 	releaseReports := make([]configv1beta1.ReleaseReport, 0, len(clusterSummary.Spec.ClusterProfileSpec.HelmCharts))
 	chartDeployed := make([]configv1beta1.Chart, 0, len(clusterSummary.Spec.ClusterProfileSpec.HelmCharts))
-	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
-		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
 
-		instantiatedChart, err := getInstantiatedChart(ctx, clusterSummary, currentChart, mgmtResources, logger)
-		if err != nil {
-			return releaseReports, chartDeployed, err
-		}
+	// Charts are first instantiated and checked for conflicts. Charts this ClusterSummary can manage
+	// are then deployed (in parallel if MaxConcurrentHelmCharts allows it). Results are finally processed
+	// following the order charts are deployed.
+	deployments, conflictErrorMessage, stopErr := deployChartsInWaves(ctx, c, remoteClient, clusterSummary,
+		kubeconfig, mgmtResources, logger)
 
-		// Eventual conflicts are already resolved before this method is called (in updateStatusForeferencedHelmReleases)
-		// So it is safe to call CanManageChart here
-		canManage, err := canManageChart(ctx, c, clusterSummary, instantiatedChart, logger)
-		if err != nil {
-			return releaseReports, chartDeployed, err
+	for i := range deployments {
+		d := deployments[i]
+		if d.unmanagedReport != nil {
+			releaseReports = append(releaseReports, *d.unmanagedReport)
+			continue
 		}
 
-		if !canManage {
-			var report *configv1beta1.ReleaseReport
-			report, err = createReportForUnmanagedHelmRelease(ctx, c, clusterSummary, instantiatedChart, logger)
-			if err != nil {
-				return releaseReports, chartDeployed, err
-			}
-
-			releaseReports = append(releaseReports, *report)
-			conflictErrorMessage += generateConflictForHelmChart(ctx, clusterSummary, instantiatedChart)
-			// error is reported above, in updateStatusForReferencedHelmReleases.
-			if clusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict ||
-				clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
-
-				continue
-			}
-
-			// for helm chart a conflict is a non retriable error.
-			// when profile currently managing the helm chart is removed, all
-			// conflicting profiles will be automatically reconciled.
-			return releaseReports, chartDeployed,
-				&NonRetriableError{Message: conflictErrorMessage}
+		if d.skipped {
+			continue
 		}
 
-		var report *configv1beta1.ReleaseReport
-		var currentRelease *releaseInfo
-		currentRelease, report, err = handleChart(ctx, clusterSummary, mgmtResources, instantiatedChart, kubeconfig, logger)
-		if err != nil {
+		if d.err != nil {
 			if clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
 				errorMsg += fmt.Sprintf("chart: %s, release: %s, %v\n",
-					instantiatedChart.ChartName, instantiatedChart.ReleaseName, err)
+					d.chart.ChartName, d.chart.ReleaseName, d.err)
 				continue
 			}
-			return releaseReports, chartDeployed, err
+			return releaseReports, chartDeployed, d.err
 		}
 
-		err = updateValueHashOnHelmChartSummary(ctx, instantiatedChart, clusterSummary, logger)
+		err := updateValueHashOnHelmChartSummary(ctx, d.chart, clusterSummary, logger)
 		if err != nil {
 			return releaseReports, chartDeployed, err
 		}
 
-		releaseReports = append(releaseReports, *report)
+		releaseReports = append(releaseReports, *d.report)
 
+		currentRelease := d.release
 		if currentRelease != nil {
 			logger.V(logs.LogInfo).Info(fmt.Sprintf("release %s/%s (version %s) status: %s",
 				currentRelease.ReleaseNamespace, currentRelease.ReleaseName, currentRelease.ChartVersion, currentRelease.Status))
 			if currentRelease.Status == release.StatusDeployed.String() {
 				// Deployed chart is used for updating ClusterConfiguration. There is no ClusterConfiguration for mgmt cluster
 				chartDeployed = append(chartDeployed, configv1beta1.Chart{
-					RepoURL:         instantiatedChart.RepositoryURL,
+					RepoURL:         d.chart.RepositoryURL,
 					Namespace:       currentRelease.ReleaseNamespace,
 					ReleaseName:     currentRelease.ReleaseName,
 					ChartVersion:    currentRelease.ChartVersion,
@@ -622,6 +621,10 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 		}
 	}
 
+	if stopErr != nil {
+		return releaseReports, chartDeployed, stopErr
+	}
+
 	// This has to come before conflictErrorMessage as conflictErrorMessage is not retriable
 	// while any other generic error is
 	if errorMsg != "" {
@@ -638,8 +641,59 @@ func walkChartsAndDeploy(ctx context.Context, c client.Client, clusterSummary *c
 	return releaseReports, chartDeployed, nil
 }
 
+// prepareChartDeployments instantiates each helm chart and verifies whether this ClusterSummary can manage it.
+// Walking charts stops at the first error (or at the first conflict unless ContinueOnConflict is set or
+// profile is in DryRun mode). Such error is returned and only charts preceding it are deployed.
+func prepareChartDeployments(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	indexes []int, mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
+) (deployments []*helmChartDeployment, conflictErrorMessage string, stopErr error) {
+
+	deployments = make([]*helmChartDeployment, 0, len(indexes))
+	for _, i := range indexes {
+		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+
+		instantiatedChart, err := getInstantiatedChart(ctx, clusterSummary, currentChart, mgmtResources, logger)
+		if err != nil {
+			return deployments, conflictErrorMessage, err
+		}
+
+		// Eventual conflicts are already resolved before this method is called (in updateStatusForeferencedHelmReleases)
+		// So it is safe to call CanManageChart here
+		canManage, err := canManageChart(ctx, c, clusterSummary, instantiatedChart, logger)
+		if err != nil {
+			return deployments, conflictErrorMessage, err
+		}
+
+		if !canManage {
+			var report *configv1beta1.ReleaseReport
+			report, err = createReportForUnmanagedHelmRelease(ctx, c, clusterSummary, instantiatedChart, logger)
+			if err != nil {
+				return deployments, conflictErrorMessage, err
+			}
+
+			deployments = append(deployments, &helmChartDeployment{chart: instantiatedChart, unmanagedReport: report})
+			conflictErrorMessage += generateConflictForHelmChart(ctx, clusterSummary, instantiatedChart)
+			// error is reported above, in updateStatusForReferencedHelmReleases.
+			if clusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict ||
+				clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+
+				continue
+			}
+
+			// for helm chart a conflict is a non retriable error.
+			// when profile currently managing the helm chart is removed, all
+			// conflicting profiles will be automatically reconciled.
+			return deployments, conflictErrorMessage, &NonRetriableError{Message: conflictErrorMessage}
+		}
+
+		deployments = append(deployments, &helmChartDeployment{chart: instantiatedChart})
+	}
+
+	return deployments, conflictErrorMessage, nil
+}
+
 func generateConflictForHelmChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary, currentChart *configv1beta1.HelmChart) string {
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 
 	message := fmt.Sprintf("cannot manage chart %s/%s.", currentChart.ReleaseNamespace, currentChart.ReleaseName)
 	chartManager, err := chartmanager.GetChartManagerInstance(ctx, c)
@@ -720,8 +774,12 @@ func determineChartOwnership(ctx context.Context, c client.Client, claimingHelmM
 
 			// Set current ClusterSummary as the new manager
 			chartManager.SetManagerForChart(claimingHelmManager, currentChart)
+			recordHelmConflictEvents(ctx, claimingHelmManager, currentHelmManager, currentChart)
 			return true, nil
 		}
+		recordWarningEvent(ctx, claimingHelmManager, eventReasonConflictLost,
+			"Helm release %s/%s in cluster %s is managed by %s", currentChart.ReleaseNamespace, currentChart.ReleaseName,
+			getClusterDescription(claimingHelmManager), getClusterSummaryProfileInfo(currentHelmManager))
 		return false, nil
 	}
 
@@ -729,6 +787,17 @@ func determineChartOwnership(ctx context.Context, c client.Client, claimingHelmM
 	return true, nil
 }
 
+// recordHelmConflictEvents emits Events when a ClusterSummary takes over managing a helm release
+// from another ClusterSummary
+func recordHelmConflictEvents(ctx context.Context, winner, loser *configv1beta1.ClusterSummary, currentChart *configv1beta1.HelmChart) {
+	recordNormalEvent(ctx, winner, eventReasonConflictWon,
+		"Helm release %s/%s in cluster %s taken over from %s", currentChart.ReleaseNamespace, currentChart.ReleaseName,
+		getClusterDescription(winner), getClusterSummaryProfileInfo(loser))
+	recordWarningEvent(ctx, loser, eventReasonConflictLost,
+		"Helm release %s/%s in cluster %s taken over by %s", currentChart.ReleaseNamespace, currentChart.ReleaseName,
+		getClusterDescription(loser), getClusterSummaryProfileInfo(winner))
+}
+
 func resetHelmReleaseSummaries(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
 	currentChart *configv1beta1.HelmChart, logger logr.Logger) error {
 
@@ -841,7 +910,7 @@ func createRegistryClientOptions(ctx context.Context, clusterSummary *configv1be
 		return registryOptions, nil
 	}
 
-	credentialsPath, caPath, err := getCredentialsAndCAFiles(ctx, getManagementClusterClient(), clusterSummary,
+	credentialsPath, caPath, err := getCredentialsAndCAFiles(ctx, getManagementClusterClient(ctx), clusterSummary,
 		currentChart)
 	if err != nil {
 		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to process credentials %v", err))
@@ -854,7 +923,7 @@ func createRegistryClientOptions(ctx context.Context, clusterSummary *configv1be
 	registryOptions.skipTLSVerify = getInsecureSkipTLSVerify(currentChart)
 
 	if currentChart.RegistryCredentialsConfig.CredentialsSecretRef != nil {
-		credentialSecretNamespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(),
+		credentialSecretNamespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(ctx),
 			clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
 			currentChart.RegistryCredentialsConfig.CredentialsSecretRef.Namespace, clusterSummary.Spec.ClusterType)
 		if err != nil {
@@ -862,7 +931,7 @@ func createRegistryClientOptions(ctx context.Context, clusterSummary *configv1be
 		}
 
 		secret := &corev1.Secret{}
-		err = getManagementClusterClient().Get(ctx,
+		err = getManagementClusterClient(ctx).Get(ctx,
 			types.NamespacedName{
 				Namespace: credentialSecretNamespace,
 				Name:      currentChart.RegistryCredentialsConfig.CredentialsSecretRef.Name,
@@ -986,6 +1055,9 @@ func repoAddOrUpdate(settings *cli.EnvSettings, name, repoURL string, registryOp
 
 	chartRepo.CachePath = settings.RepositoryCache
 
+	storageMux.Lock()
+	defer storageMux.Unlock()
+
 	if storage.Has(entry.Name) {
 		logger.V(logs.LogDebug).Info("repository name already exists")
 		return nil
@@ -1165,7 +1237,7 @@ func uninstallRelease(ctx context.Context, clusterSummary *configv1beta1.Cluster
 		return nil
 	}
 
-	cluster, err := clusterproxy.GetCluster(ctx, getManagementClusterClient(), clusterSummary.Spec.ClusterNamespace,
+	cluster, err := clusterproxy.GetCluster(ctx, getManagementClusterClient(ctx), clusterSummary.Spec.ClusterNamespace,
 		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -1449,6 +1521,18 @@ func actionConfigInit(namespace, kubeconfig string, registryOptions *registryCli
 	// Use a 5m timeout
 	timeout := "5m"
 	configFlags.Timeout = &timeout
+	if v, ok := helmKubeconfigs.Load(kubeconfig); ok {
+		info := v.(*helmKubeconfig)
+		configFlags.WrapConfigFn = func(config *rest.Config) *rest.Config {
+			// Helm clients share the managed cluster rate limiter
+			if info.limiter != nil {
+				config.RateLimiter = info.limiter
+			}
+			clustercache.GetManager().WrapRestConfig(info.clusterNamespace, info.clusterName,
+				info.clusterType, config)
+			return config
+		}
+	}
 
 	err := actionConfig.Init(configFlags, namespace, "secret", debugf)
 	if err != nil {
@@ -1465,6 +1549,53 @@ func actionConfigInit(namespace, kubeconfig string, registryOptions *registryCli
 	return actionConfig, nil
 }
 
+// helmKubeconfig is the managed cluster a temporary kubeconfig file gives access to
+type helmKubeconfig struct {
+	clusterNamespace string
+	clusterName      string
+	clusterType      libsveltosv1beta1.ClusterType
+
+	// limiter is the managed cluster rate limiter. Nil if no limit is configured
+	limiter flowcontrol.RateLimiter
+}
+
+// createHelmKubeconfig creates a temporary kubeconfig file for helm to access the managed cluster.
+// Kubeconfig is validated first (exec credential plugin must be allowed and credentials must not be
+// expired). Helm clients created with it share the managed cluster rate limiter and evict cached
+// data for the cluster on 401 (Unauthorized). Returned function must be invoked once kubeconfig
+// is not used anymore.
+func createHelmKubeconfig(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+	adminNamespace, adminName string, clusterType libsveltosv1beta1.ClusterType, logger logr.Logger,
+) (kubeconfig string, closer func(), err error) {
+
+	kubeconfigContent, err := clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := clustercache.ValidateKubeconfig(kubeconfigContent); err != nil {
+		return "", nil, fmt.Errorf("invalid kubeconfig for cluster %s/%s: %w", clusterNamespace, clusterName, err)
+	}
+
+	kubeconfig, removeFile, err := clusterproxy.CreateKubeconfig(logger, kubeconfigContent)
+	if err != nil {
+		return "", nil, err
+	}
+
+	helmKubeconfigs.Store(kubeconfig, &helmKubeconfig{
+		clusterNamespace: clusterNamespace,
+		clusterName:      clusterName,
+		clusterType:      clusterType,
+		limiter:          clustercache.GetManager().GetRateLimiter(ctx, c, clusterNamespace, clusterName, clusterType, logger),
+	})
+
+	return kubeconfig, func() {
+		helmKubeconfigs.Delete(kubeconfig)
+		removeFile()
+	}, nil
+}
+
 func isChartInstallable(ch *chart.Chart) bool {
 	switch ch.Metadata.Type {
 	case "", "application":
@@ -1587,7 +1718,7 @@ func shouldUpgrade(ctx context.Context, currentRelease *releaseInfo, requestedCh
 			oldValueHash := getValueHashFromHelmChartSummary(requestedChart, clusterSummary)
 
 			// If Values configuration has changed, trigger an upgrade
-			c := getManagementClusterClient()
+			c := getManagementClusterClient(ctx)
 			currentValueHash, err := getHelmChartValuesHash(ctx, c, requestedChart, clusterSummary, logger)
 			if err != nil {
 				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get current values hash: %v", err))
@@ -1677,6 +1808,9 @@ func doInstallRelease(ctx context.Context, clusterSummary *configv1beta1.Cluster
 		return err
 	}
 
+	recordNormalEvent(ctx, clusterSummary, eventReasonReleaseInstalled, "Helm release %s/%s (chart %s version %s) installed in cluster %s",
+		requestedChart.ReleaseNamespace, requestedChart.ReleaseName, requestedChart.ChartName, requestedChart.ChartVersion,
+		getClusterDescription(clusterSummary))
 	return nil
 }
 
@@ -1696,8 +1830,15 @@ func doUninstallRelease(ctx context.Context, clusterSummary *configv1beta1.Clust
 		requestedChart.RepositoryURL,
 		requestedChart.RepositoryName))
 
-	return uninstallRelease(ctx, clusterSummary, requestedChart.ReleaseName, requestedChart.ReleaseNamespace,
+	err := uninstallRelease(ctx, clusterSummary, requestedChart.ReleaseName, requestedChart.ReleaseNamespace,
 		kubeconfig, registryOptions, requestedChart, logger)
+	if err != nil {
+		return err
+	}
+
+	recordNormalEvent(ctx, clusterSummary, eventReasonReleaseUninstalled, "Helm release %s/%s uninstalled from cluster %s",
+		requestedChart.ReleaseNamespace, requestedChart.ReleaseName, getClusterDescription(clusterSummary))
+	return nil
 }
 
 // doUpgradeRelease upgrades helm release in the CAPI Cluster.
@@ -1738,6 +1879,9 @@ func doUpgradeRelease(ctx context.Context, clusterSummary *configv1beta1.Cluster
 		return err
 	}
 
+	recordNormalEvent(ctx, clusterSummary, eventReasonReleaseUpgraded, "Helm release %s/%s (chart %s version %s) upgraded in cluster %s",
+		requestedChart.ReleaseNamespace, requestedChart.ReleaseName, requestedChart.ChartName, requestedChart.ChartVersion,
+		getClusterDescription(clusterSummary))
 	return nil
 }
 
@@ -2082,9 +2226,14 @@ func getInstantiatedValues(ctx context.Context, clusterSummary *configv1beta1.Cl
 	mgmtResources map[string]*unstructured.Unstructured, requestedChart *configv1beta1.HelmChart,
 	logger logr.Logger) (chartutil.Values, error) {
 
+	// When pinned to a revision by a Rollback, values as instantiated in such revision are used
+	if pinnedValues, pinned, err := getPinnedValues(clusterSummary, requestedChart); pinned {
+		return pinnedValues, err
+	}
+
 	// Get management cluster resources once
-	mgmtConfig := getManagementClusterConfig()
-	mgmtClient := getManagementClusterClient()
+	mgmtConfig := getManagementClusterConfig(ctx)
+	mgmtClient := getManagementClusterClient(ctx)
 
 	instantiatedValues, err := instantiateTemplateValues(ctx, mgmtConfig, mgmtClient,
 		clusterSummary, requestedChart.ChartName, requestedChart.Values, mgmtResources, logger)
@@ -2126,6 +2275,8 @@ func getInstantiatedValues(ctx context.Context, clusterSummary *configv1beta1.Cl
 
 	logger.V(logs.LogDebug).Info(fmt.Sprintf("Deploying helm charts with values %#v", valuesFrom))
 
+	recordRenderedValues(clusterSummary, requestedChart, result)
+
 	return chartutil.Values(result), nil
 }
 
@@ -2735,6 +2886,20 @@ func getResourceNamespace(r *unstructured.Unstructured, releaseNamespace string,
 func getHelmChartValuesHash(ctx context.Context, c client.Client, requestedChart *configv1beta1.HelmChart,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) ([]byte, error) {
 
+	// When pinned to a revision by a Rollback, hash reflects values stored in such revision
+	if pinnedValues, pinned, err := getPinnedValues(clusterSummary, requestedChart); pinned {
+		if err != nil {
+			return nil, err
+		}
+		yamlValues, err := pinnedValues.YAML()
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		h.Write([]byte("rollback:" + yamlValues))
+		return h.Sum(nil), nil
+	}
+
 	valuesFromHash, err := getHelmReferenceResourceHash(ctx, c, clusterSummary, requestedChart, logger)
 	if err != nil {
 		return nil, err
@@ -2750,7 +2915,7 @@ func getHelmChartValuesHash(ctx context.Context, c client.Client, requestedChart
 func updateValueHashOnHelmChartSummary(ctx context.Context, requestedChart *configv1beta1.HelmChart,
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
 
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 
 	helmChartValuesHash, err := getHelmChartValuesHash(ctx, c, requestedChart, clusterSummary, logger)
 	if err != nil {
@@ -2973,7 +3138,7 @@ func getHelmChartAndRepoName(ctx context.Context, requestedChart *configv1beta1.
 			return "", "", "", fmt.Errorf("Flux Source %v not found", sourceRef)
 		}
 
-		source, err := getSource(ctx, getManagementClusterClient(), sourceRef.Namespace, sourceRef.Name, sourceRef.Kind)
+		source, err := getSource(ctx, getManagementClusterClient(ctx), sourceRef.Namespace, sourceRef.Name, sourceRef.Kind)
 		if err != nil {
 			return "", "", "", err
 		}
@@ -3213,14 +3378,19 @@ func getInstantiatedChart(ctx context.Context, clusterSummary *configv1beta1.Clu
 	currentChart *configv1beta1.HelmChart, mgmtResources map[string]*unstructured.Unstructured,
 	logger logr.Logger) (*configv1beta1.HelmChart, error) {
 
+	// When pinned to a revision by a Rollback, chart as instantiated in such revision is used
+	if pinnedChart := getPinnedChart(clusterSummary, currentChart); pinnedChart != nil {
+		return pinnedChart, nil
+	}
+
 	// Marshal the struct to YAML
 	jsonData, err := yaml.Marshal(*currentChart)
 	if err != nil {
 		return nil, err
 	}
 
-	instantiatedChartString, err := instantiateTemplateValues(ctx, getManagementClusterConfig(),
-		getManagementClusterClient(), clusterSummary, currentChart.ChartName, string(jsonData), mgmtResources, logger)
+	instantiatedChartString, err := instantiateTemplateValues(ctx, getManagementClusterConfig(ctx),
+		getManagementClusterClient(ctx), clusterSummary, currentChart.ChartName, string(jsonData), mgmtResources, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -3231,6 +3401,8 @@ func getInstantiatedChart(ctx context.Context, clusterSummary *configv1beta1.Clu
 		return nil, err
 	}
 
+	recordRenderedChart(clusterSummary, currentChart, &instantiatedChart)
+
 	return &instantiatedChart, nil
 }
 
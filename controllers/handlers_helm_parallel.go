@@ -0,0 +1,169 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// helmChartDeployment contains an instantiated helm chart and the outcome of deploying it
+type helmChartDeployment struct {
+	chart *configv1beta1.HelmChart
+
+	// unmanagedReport is set when chart is managed by another ClusterSummary. Chart is not deployed.
+	unmanagedReport *configv1beta1.ReleaseReport
+
+	// skipped is set when chart was not deployed because deploying a previous chart failed
+	skipped bool
+
+	release *releaseInfo
+	report  *configv1beta1.ReleaseReport
+	err     error
+}
+
+// chartDeployFunc deploys a single helm chart
+type chartDeployFunc func(ctx context.Context, chart *configv1beta1.HelmChart) (*releaseInfo,
+	*configv1beta1.ReleaseReport, error)
+
+// deployChartsInWaves deploys helm charts in waves. With no dependencies between entries, there is a
+// single wave containing all charts in the order they are listed.
+// Walking charts stops at the first error preventing following charts from being deployed; such error is returned.
+func deployChartsInWaves(ctx context.Context, c, remoteClient client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	kubeconfig string, mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
+) (deployments []*helmChartDeployment, conflictErrorMessage string, stopErr error) {
+
+	waves, err := getDeploymentWaves(&clusterSummary.Spec.ClusterProfileSpec, configv1beta1.FeatureHelm)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i := range waves {
+		err = checkCrossFeatureDependencies(ctx, c, remoteClient, clusterSummary, configv1beta1.FeatureHelm,
+			waves[i], logger)
+		if err != nil {
+			return deployments, conflictErrorMessage, err
+		}
+
+		waveDeployments, waveConflictErrorMessage, waveErr := prepareChartDeployments(ctx, c, clusterSummary,
+			waves[i], mgmtResources, logger)
+		conflictErrorMessage += waveConflictErrorMessage
+
+		deployCharts(ctx, clusterSummary, mgmtResources, kubeconfig, waveDeployments, logger)
+		deployments = append(deployments, waveDeployments...)
+		if waveErr != nil {
+			return deployments, conflictErrorMessage, waveErr
+		}
+
+		if i < len(waves)-1 {
+			if !clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				for j := range waveDeployments {
+					if waveDeployments[j].err != nil {
+						// error is reported when processing deployments
+						return deployments, conflictErrorMessage, nil
+					}
+				}
+			}
+
+			err = checkDependedUponCharts(clusterSummary, waveDeployments, waves[i])
+			if err != nil {
+				return deployments, conflictErrorMessage, err
+			}
+		}
+	}
+
+	return deployments, conflictErrorMessage, nil
+}
+
+func getMaxConcurrentHelmCharts(clusterSummary *configv1beta1.ClusterSummary) int {
+	maxConcurrent := clusterSummary.Spec.ClusterProfileSpec.MaxConcurrentHelmCharts
+	if maxConcurrent == nil || *maxConcurrent < 1 {
+		return 1
+	}
+	return int(*maxConcurrent)
+}
+
+// deployCharts deploys all charts this ClusterSummary can manage, using at most MaxConcurrentHelmCharts
+// workers. Charts are started in the order they are listed. When deploying a chart fails and ContinueOnError
+// is not set, charts not started yet are skipped (charts already being deployed are not interrupted).
+func deployCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	mgmtResources map[string]*unstructured.Unstructured, kubeconfig string,
+	deployments []*helmChartDeployment, logger logr.Logger) {
+
+	deploy := func(ctx context.Context, chart *configv1beta1.HelmChart) (*releaseInfo,
+		*configv1beta1.ReleaseReport, error) {
+
+		return handleChart(ctx, clusterSummary, mgmtResources, chart, kubeconfig, logger)
+	}
+
+	runChartDeployments(ctx, deployments, getMaxConcurrentHelmCharts(clusterSummary),
+		clusterSummary.Spec.ClusterProfileSpec.ContinueOnError, deploy, logger)
+}
+
+func runChartDeployments(ctx context.Context, deployments []*helmChartDeployment, maxConcurrent int,
+	continueOnError bool, deploy chartDeployFunc, logger logr.Logger) {
+
+	if maxConcurrent > 1 {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("deploying helm charts with max concurrency %d", maxConcurrent))
+	}
+
+	var wg sync.WaitGroup
+	var mux sync.Mutex
+	failed := false
+
+	semaphore := make(chan struct{}, maxConcurrent)
+	for i := range deployments {
+		d := deployments[i]
+		if d.unmanagedReport != nil {
+			continue
+		}
+
+		semaphore <- struct{}{}
+
+		mux.Lock()
+		stop := failed && !continueOnError
+		mux.Unlock()
+		if stop {
+			<-semaphore
+			d.skipped = true
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			d.release, d.report, d.err = deploy(ctx, d.chart)
+			if d.err != nil {
+				mux.Lock()
+				failed = true
+				mux.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
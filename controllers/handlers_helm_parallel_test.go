@@ -0,0 +1,117 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/utils/ptr"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Parallel helm chart deployment", func() {
+	var charts []configv1beta1.HelmChart
+
+	BeforeEach(func() {
+		charts = make([]configv1beta1.HelmChart, 6)
+		for i := range charts {
+			charts[i] = configv1beta1.HelmChart{
+				ReleaseName:      randomString(),
+				ReleaseNamespace: randomString(),
+			}
+		}
+	})
+
+	It("getMaxConcurrentHelmCharts defaults to sequential deployment", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(controllers.GetMaxConcurrentHelmCharts(clusterSummary)).To(Equal(1))
+
+		clusterSummary.Spec.ClusterProfileSpec.MaxConcurrentHelmCharts = ptr.To(int32(4))
+		Expect(controllers.GetMaxConcurrentHelmCharts(clusterSummary)).To(Equal(4))
+	})
+
+	It("runChartDeployments deploys charts sequentially by default", func() {
+		var mux sync.Mutex
+		order := make([]string, 0)
+		errs, skipped := controllers.RunChartDeployments(charts, 1, false,
+			func(chart *configv1beta1.HelmChart) error {
+				mux.Lock()
+				defer mux.Unlock()
+				order = append(order, chart.ReleaseName)
+				return nil
+			})
+		Expect(skipped).To(BeEmpty())
+		Expect(len(errs)).To(Equal(len(charts)))
+		for i := range charts {
+			Expect(order[i]).To(Equal(charts[i].ReleaseName))
+		}
+	})
+
+	It("runChartDeployments never exceeds max concurrency", func() {
+		const maxConcurrent = 3
+		var mux sync.Mutex
+		current, peak := 0, 0
+		_, skipped := controllers.RunChartDeployments(charts, maxConcurrent, false,
+			func(_ *configv1beta1.HelmChart) error {
+				mux.Lock()
+				current++
+				peak = max(peak, current)
+				mux.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mux.Lock()
+				current--
+				mux.Unlock()
+				return nil
+			})
+		Expect(skipped).To(BeEmpty())
+		Expect(peak).To(Equal(maxConcurrent))
+	})
+
+	It("runChartDeployments skips charts not started yet when a chart fails", func() {
+		failingChart := charts[1].ReleaseName
+		errs, skipped := controllers.RunChartDeployments(charts, 1, false,
+			func(chart *configv1beta1.HelmChart) error {
+				if chart.ReleaseName == failingChart {
+					return errors.New("failed")
+				}
+				return nil
+			})
+		Expect(len(errs)).To(Equal(2))
+		Expect(errs[1]).ToNot(BeNil())
+		Expect(len(skipped)).To(Equal(len(charts) - 2))
+
+		// With ContinueOnError all charts are deployed
+		errs, skipped = controllers.RunChartDeployments(charts, 2, true,
+			func(chart *configv1beta1.HelmChart) error {
+				if chart.ReleaseName == failingChart {
+					return errors.New("failed")
+				}
+				return nil
+			})
+		Expect(len(errs)).To(Equal(len(charts)))
+		Expect(skipped).To(BeEmpty())
+	})
+})
@@ -34,6 +34,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2/textlogger"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,9 +44,11 @@ import (
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 	"github.com/projectsveltos/addon-controller/controllers"
 	"github.com/projectsveltos/addon-controller/controllers/chartmanager"
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
 )
 
 var _ = Describe("HandlersHelm", func() {
@@ -685,6 +689,50 @@ var _ = Describe("HandlersHelm", func() {
 		}
 		Expect(found).To(BeTrue())
 	})
+
+	It("helm refuses kubeconfigs using exec credential plugins not in the allowlist", func() {
+		clustercache.SetExecPluginAllowlist(nil)
+
+		clusterSummary.Spec.ClusterType = libsveltosv1beta1.ClusterTypeSveltos
+		sveltosCluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      clusterSummary.Spec.ClusterName,
+			},
+		}
+
+		kubeconfig := clientcmdapi.NewConfig()
+		kubeconfig.Clusters["cluster"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+		kubeconfig.AuthInfos["user"] = &clientcmdapi.AuthInfo{
+			Exec: &clientcmdapi.ExecConfig{
+				APIVersion:      "client.authentication.k8s.io/v1",
+				Command:         "/tmp/" + randomString(),
+				InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+			},
+		}
+		kubeconfig.Contexts["context"] = &clientcmdapi.Context{Cluster: "cluster", AuthInfo: "user"}
+		kubeconfig.CurrentContext = "context"
+		data, err := clientcmd.Write(*kubeconfig)
+		Expect(err).To(BeNil())
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sveltosCluster.Namespace,
+				Name:      sveltosCluster.Name + "-sveltos-kubeconfig",
+			},
+			Data: map[string][]byte{
+				"value": data,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary, sveltosCluster, secret).Build()
+
+		err = controllers.UndeployHelmCharts(context.TODO(), c, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, clusterSummary.Name, "", clusterSummary.Spec.ClusterType,
+			deployer.Options{}, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("is not allowed"))
+	})
 })
 
 var _ = Describe("Hash methods", func() {
@@ -1018,6 +1066,7 @@ var _ = Describe("Hash methods", func() {
 		verifyFileContent(caPath, caByte)
 		Expect(os.Remove(caPath)).To(Succeed())
 	})
+
 })
 
 func verifyFileContent(filePath string, data []byte) {
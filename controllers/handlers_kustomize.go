@@ -50,7 +50,6 @@ import (
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
 	"github.com/projectsveltos/libsveltos/lib/funcmap"
 	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
@@ -99,6 +98,11 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
+	if err = startRevisionSession(ctx, c, clusterSummary, featureHandler.id, logger); err != nil {
+		return err
+	}
+	defer endRevisionSession(clusterSummary, featureHandler.id)
+
 	remoteRestConfig, logger, err := getRestConfig(ctx, c, clusterSummary, logger)
 	if err != nil {
 		return err
@@ -149,6 +153,8 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 	// If a deployment error happened, do not try to clean stale resources. Because of the error potentially
 	// all resources might be considered stale at this time.
 	if deployError != nil {
+		auditDeployment(clusterSummary, configv1beta1.FeatureKustomize, localResourceReports, remoteResourceReports, nil,
+			deployError, logger)
 		return deployError
 	}
 
@@ -159,6 +165,8 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 	remoteResourceReports = append(remoteResourceReports, undeployed...)
+	auditDeployment(clusterSummary, configv1beta1.FeatureKustomize, localResourceReports, remoteResourceReports, nil,
+		nil, logger)
 
 	err = handleWatchers(ctx, clusterSummary, localResourceReports, featureHandler)
 	if err != nil {
@@ -180,14 +188,28 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return &configv1beta1.DryRunReconciliationError{}
 	}
 
-	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureKustomize, logger)
+	err = waitForDeployedResources(ctx, c, remoteRestConfig, clusterSummary, configv1beta1.FeatureKustomize,
+		getKustomizationRefsToWaitFor(clusterSummary), localResourceReports, remoteResourceReports, logger)
+	if err != nil {
+		return err
+	}
+
+	err = validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureKustomize, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := storeRevision(ctx, c, clusterSummary, featureHandler.id, logger); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to store revision: %v", err))
+	}
+	return nil
 }
 
 func cleanStaleKustomizeResources(ctx context.Context, remoteRestConfig *rest.Config, remoteClient client.Client,
 	clusterSummary *configv1beta1.ClusterSummary, localResourceReports, remoteResourceReports []configv1beta1.ResourceReport,
 	logger logr.Logger) (localUndeployed, remoteUndeployed []configv1beta1.ResourceReport, err error) {
 	// Clean stale resources in the management cluster
-	localUndeployed, err = cleanKustomizeResources(ctx, true, getManagementClusterConfig(), getManagementClusterClient(),
+	localUndeployed, err = cleanKustomizeResources(ctx, true, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 		clusterSummary, localResourceReports, logger)
 	if err != nil {
 		return
@@ -223,10 +245,16 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 
 	logger.V(logs.LogDebug).Info("undeployKustomizeRefs")
 
+	// Entries of other features depending on entries of this feature must be withdrawn first
+	err = checkDependentsRemoved(clusterSummary, configv1beta1.FeatureKustomize)
+	if err != nil {
+		return err
+	}
+
 	var resourceReports []configv1beta1.ResourceReport
 
 	// Undeploy from management cluster
-	_, err = undeployStaleResources(ctx, true, getManagementClusterConfig(), c, configv1beta1.FeatureKustomize,
+	_, err = undeployStaleResources(ctx, true, getManagementClusterConfig(ctx), c, configv1beta1.FeatureKustomize,
 		clusterSummary, getDeployedGroupVersionKinds(clusterSummary, configv1beta1.FeatureKustomize),
 		map[string]configv1beta1.Resource{}, logger)
 	if err != nil {
@@ -240,7 +268,7 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
+	remoteClient, err := clustercache.GetManager().GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -253,6 +281,7 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 	if err != nil {
 		return err
 	}
+	auditWithdrawal(clusterSummary, configv1beta1.FeatureKustomize, resourceReports, nil, logger)
 
 	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
@@ -408,7 +437,7 @@ func instantiateKustomizeSubstituteValues(ctx context.Context, clusterSummary *c
 	requestorName := clusterSummary.Namespace + clusterSummary.Name + "kustomize"
 
 	instantiatedValue, err :=
-		instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
+		instantiateTemplateValues(ctx, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 			clusterSummary, requestorName, stringifiedValues, mgmtResources, logger)
 	if err != nil {
 		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to instantiate values %v", err))
@@ -481,7 +510,7 @@ func deployKustomizeRef(ctx context.Context, c client.Client, remoteRestConfig *
 	defer os.RemoveAll(tmpDir)
 
 	// Path can be expressed as a template and instantiate using Cluster fields.
-	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
+	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 		clusterSummary, clusterSummary.GetName(), kustomizationRef.Path, nil, logger)
 	if err != nil {
 		return nil, nil, err
@@ -707,7 +736,7 @@ func deployKustomizeResources(ctx context.Context, c client.Client, remoteRestCo
 	// Assume that if objects are deployed in the management clusters, those are needed before any resource is deployed
 	// in the managed cluster. So try to deploy those first if any.
 
-	localConfig := rest.CopyConfig(getManagementClusterConfig())
+	localConfig := rest.CopyConfig(getManagementClusterConfig(ctx))
 	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
 	if adminName != "" {
 		localConfig.Impersonate = rest.ImpersonationConfig{
@@ -775,7 +804,7 @@ func handleDriftDetectionManagerDeploymentForKustomize(ctx context.Context, clus
 
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		// Deploy drift detection manager first. Have manager up by the time resourcesummary is created
-		err := deployDriftDetectionManagerInCluster(ctx, getManagementClusterClient(), clusterNamespace,
+		err := deployDriftDetectionManagerInCluster(ctx, getManagementClusterClient(ctx), clusterNamespace,
 			clusterName, clusterSummary.Name, clusterType, startInMgmtCluster, logger)
 		if err != nil {
 			return err
@@ -803,7 +832,7 @@ func handleKustomizeResourceSummaryDeployment(ctx context.Context, clusterSummar
 
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		// deploy ResourceSummary
-		err := deployResourceSummaryWithKustomizeResources(ctx, getManagementClusterClient(),
+		err := deployResourceSummaryWithKustomizeResources(ctx, getManagementClusterClient(ctx),
 			clusterNamespace, clusterName, clusterSummary, clusterType, remoteDeployed, logger)
 		if err != nil {
 			return err
@@ -839,19 +868,57 @@ func deployEachKustomizeRefs(ctx context.Context, c client.Client, remoteRestCon
 	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger,
 ) (localResourceReports, remoteResourceReports []configv1beta1.ResourceReport, err error) {
 
+	if revision := getSessionPinnedRevision(clusterSummary, configv1beta1.FeatureKustomize); revision != nil {
+		return deployPinnedRevision(ctx, c, remoteRestConfig, clusterSummary, revision, logger)
+	}
+
+	waves, err := getDeploymentWaves(&clusterSummary.Spec.ClusterProfileSpec, configv1beta1.FeatureKustomize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var remoteClient client.Client
+	if hasEntryDependencies(&clusterSummary.Spec.ClusterProfileSpec) {
+		remoteClient, err = client.New(remoteRestConfig, client.Options{Scheme: c.Scheme()})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	capacity := len(clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs)
 	localResourceReports = make([]configv1beta1.ResourceReport, 0, capacity)
 	remoteResourceReports = make([]configv1beta1.ResourceReport, 0, capacity)
-	for i := range clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs {
-		kustomizationRef := &clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[i]
-		var tmpLocal []configv1beta1.ResourceReport
-		var tmpRemote []configv1beta1.ResourceReport
-		tmpLocal, tmpRemote, err = deployKustomizeRef(ctx, c, remoteRestConfig, kustomizationRef, clusterSummary, logger)
+	// KustomizationRefs are deployed in waves. With no dependencies between entries, there is a single wave
+	// containing all KustomizationRefs in the order they are listed.
+	for i := range waves {
+		err = checkCrossFeatureDependencies(ctx, c, remoteClient, clusterSummary, configv1beta1.FeatureKustomize,
+			waves[i], logger)
 		if err != nil {
-			return nil, nil, err
+			return localResourceReports, remoteResourceReports, err
+		}
+
+		var waveLocal, waveRemote []configv1beta1.ResourceReport
+		for _, index := range waves[i] {
+			kustomizationRef := &clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[index]
+			var tmpLocal []configv1beta1.ResourceReport
+			var tmpRemote []configv1beta1.ResourceReport
+			tmpLocal, tmpRemote, err = deployKustomizeRef(ctx, c, remoteRestConfig, kustomizationRef, clusterSummary, logger)
+			if err != nil {
+				return localResourceReports, remoteResourceReports, err
+			}
+			waveLocal = append(waveLocal, tmpLocal...)
+			waveRemote = append(waveRemote, tmpRemote...)
+		}
+		localResourceReports = append(localResourceReports, waveLocal...)
+		remoteResourceReports = append(remoteResourceReports, waveRemote...)
+
+		if i < len(waves)-1 {
+			err = waitForDependedUponReferences(ctx, c, remoteClient, clusterSummary, configv1beta1.FeatureKustomize,
+				waves[i], waveLocal, waveRemote, logger)
+			if err != nil {
+				return localResourceReports, remoteResourceReports, err
+			}
 		}
-		localResourceReports = append(localResourceReports, tmpLocal...)
-		remoteResourceReports = append(remoteResourceReports, tmpRemote...)
 	}
 
 	return localResourceReports, remoteResourceReports, err
@@ -37,7 +37,6 @@ import (
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
@@ -56,6 +55,11 @@ func deployResources(ctx context.Context, c client.Client,
 		return err
 	}
 
+	if err = startRevisionSession(ctx, c, clusterSummary, featureHandler.id, logger); err != nil {
+		return err
+	}
+	defer endRevisionSession(clusterSummary, featureHandler.id)
+
 	remoteRestConfig, logger, err := getRestConfig(ctx, c, clusterSummary, logger)
 	if err != nil {
 		return err
@@ -104,6 +108,8 @@ func deployResources(ctx context.Context, c client.Client,
 	// If a deployment error happened, do not try to clean stale resources. Because of the error potentially
 	// all resources might be considered stale at this time.
 	if deployError != nil {
+		auditDeployment(clusterSummary, featureHandler.id, localResourceReports, remoteResourceReports, nil,
+			deployError, logger)
 		return deployError
 	}
 
@@ -114,6 +120,7 @@ func deployResources(ctx context.Context, c client.Client,
 		return err
 	}
 	remoteResourceReports = append(remoteResourceReports, undeployed...)
+	auditDeployment(clusterSummary, featureHandler.id, localResourceReports, remoteResourceReports, nil, nil, logger)
 
 	err = handleWatchers(ctx, clusterSummary, localResourceReports, featureHandler)
 	if err != nil {
@@ -131,7 +138,21 @@ func deployResources(ctx context.Context, c client.Client,
 		return err
 	}
 
-	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureResources, logger)
+	err = waitForDeployedResources(ctx, c, remoteRestConfig, clusterSummary, configv1beta1.FeatureResources,
+		getPolicyRefsToWaitFor(clusterSummary), localResourceReports, remoteResourceReports, logger)
+	if err != nil {
+		return err
+	}
+
+	err = validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1beta1.FeatureResources, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := storeRevision(ctx, c, clusterSummary, featureHandler.id, logger); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to store revision: %v", err))
+	}
+	return nil
 }
 
 func cleanStaleResources(ctx context.Context, remoteRestConfig *rest.Config, remoteClient client.Client,
@@ -139,7 +160,7 @@ func cleanStaleResources(ctx context.Context, remoteRestConfig *rest.Config, rem
 	logger logr.Logger) (localUndeployed, remoteUndeployed []configv1beta1.ResourceReport, err error) {
 
 	// Clean stale resources in the management cluster
-	localUndeployed, err = cleanPolicyRefResources(ctx, true, getManagementClusterConfig(), getManagementClusterClient(),
+	localUndeployed, err = cleanPolicyRefResources(ctx, true, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 		clusterSummary, localResourceReports, logger)
 	if err != nil {
 		return localUndeployed, nil, err
@@ -163,7 +184,7 @@ func handleDriftDetectionManagerDeployment(ctx context.Context, clusterSummary *
 
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		// Deploy drift detection manager first. Have manager up by the time resourcesummary is created
-		err := deployDriftDetectionManagerInCluster(ctx, getManagementClusterClient(), clusterNamespace,
+		err := deployDriftDetectionManagerInCluster(ctx, getManagementClusterClient(ctx), clusterNamespace,
 			clusterName, clusterSummary.Name, clusterType, startInMgmtCluster, logger)
 		if err != nil {
 			return err
@@ -191,7 +212,7 @@ func handleResourceSummaryDeployment(ctx context.Context, clusterSummary *config
 
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection {
 		// deploy ResourceSummary
-		err := deployResourceSummary(ctx, getManagementClusterClient(), clusterNamespace, clusterName,
+		err := deployResourceSummary(ctx, getManagementClusterClient(ctx), clusterNamespace, clusterName,
 			clusterSummary, clusterType, remoteDeployed, logger)
 		if err != nil {
 			return err
@@ -278,7 +299,13 @@ func undeployResources(ctx context.Context, c client.Client,
 
 	logger.V(logs.LogDebug).Info("undeployResources")
 
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
+	// Entries of other features depending on entries of this feature must be withdrawn first
+	err = checkDependentsRemoved(clusterSummary, configv1beta1.FeatureResources)
+	if err != nil {
+		return err
+	}
+
+	remoteClient, err := clustercache.GetManager().GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -294,7 +321,7 @@ func undeployResources(ctx context.Context, c client.Client,
 	var resourceReports []configv1beta1.ResourceReport
 
 	// Undeploy from management cluster
-	if _, err = undeployStaleResources(ctx, true, getManagementClusterConfig(), c, configv1beta1.FeatureResources,
+	if _, err = undeployStaleResources(ctx, true, getManagementClusterConfig(ctx), c, configv1beta1.FeatureResources,
 		clusterSummary, getDeployedGroupVersionKinds(clusterSummary, configv1beta1.FeatureResources),
 		map[string]configv1beta1.Resource{}, logger); err != nil {
 		return err
@@ -308,6 +335,7 @@ func undeployResources(ctx context.Context, c client.Client,
 	if err != nil {
 		return err
 	}
+	auditWithdrawal(clusterSummary, configv1beta1.FeatureResources, resourceReports, nil, logger)
 
 	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
@@ -423,6 +451,8 @@ func resourcesHash(ctx context.Context, c client.Client, clusterSummaryScope *sc
 		}
 	}
 
+	config += getWaitHash(getPolicyRefsToWaitFor(clusterSummary))
+
 	for i := range clusterSummary.Spec.ClusterProfileSpec.ValidateHealths {
 		h := &clusterSummary.Spec.ClusterProfileSpec.ValidateHealths[i]
 		if h.FeatureID == configv1beta1.FeatureResources {
@@ -503,18 +533,65 @@ func deployPolicyRefs(ctx context.Context, c client.Client, remoteConfig *rest.C
 	clusterSummary *configv1beta1.ClusterSummary, featureHandler feature,
 	logger logr.Logger) (localReports, remoteReports []configv1beta1.ResourceReport, err error) {
 
+	if revision := getSessionPinnedRevision(clusterSummary, featureHandler.id); revision != nil {
+		return deployPinnedRevision(ctx, c, remoteConfig, clusterSummary, revision, logger)
+	}
+
 	refs := featureHandler.getRefs(clusterSummary)
 
-	var objectsToDeployLocally []client.Object
-	var objectsToDeployRemotely []client.Object
-	// collect all referenced resources whose content need to be deployed
-	// in the management cluster (local) or manaded cluster (remote)
-	objectsToDeployLocally, objectsToDeployRemotely, err =
-		collectReferencedObjects(ctx, c, clusterSummary, refs, logger)
+	waves, err := getDeploymentWaves(&clusterSummary.Spec.ClusterProfileSpec, featureHandler.id)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return deployReferencedObjects(ctx, c, remoteConfig, clusterSummary,
-		objectsToDeployLocally, objectsToDeployRemotely, logger)
+	var remoteClient client.Client
+	if hasEntryDependencies(&clusterSummary.Spec.ClusterProfileSpec) {
+		remoteClient, err = client.New(remoteConfig, client.Options{Scheme: c.Scheme()})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// PolicyRefs are deployed in waves. With no dependencies between entries, there is a single wave
+	// containing all PolicyRefs in the order they are listed.
+	for i := range waves {
+		err = checkCrossFeatureDependencies(ctx, c, remoteClient, clusterSummary, featureHandler.id, waves[i], logger)
+		if err != nil {
+			return localReports, remoteReports, err
+		}
+
+		waveRefs := make([]configv1beta1.PolicyRef, len(waves[i]))
+		for j := range waves[i] {
+			waveRefs[j] = refs[waves[i][j]]
+		}
+
+		var objectsToDeployLocally []client.Object
+		var objectsToDeployRemotely []client.Object
+		// collect all referenced resources whose content need to be deployed
+		// in the management cluster (local) or manaded cluster (remote)
+		objectsToDeployLocally, objectsToDeployRemotely, err =
+			collectReferencedObjects(ctx, c, clusterSummary, waveRefs, logger)
+		if err != nil {
+			return localReports, remoteReports, err
+		}
+
+		var tmpLocal, tmpRemote []configv1beta1.ResourceReport
+		tmpLocal, tmpRemote, err = deployReferencedObjects(ctx, c, remoteConfig, clusterSummary,
+			objectsToDeployLocally, objectsToDeployRemotely, logger)
+		localReports = append(localReports, tmpLocal...)
+		remoteReports = append(remoteReports, tmpRemote...)
+		if err != nil {
+			return localReports, remoteReports, err
+		}
+
+		if i < len(waves)-1 {
+			err = waitForDependedUponReferences(ctx, c, remoteClient, clusterSummary, featureHandler.id, waves[i],
+				tmpLocal, tmpRemote, logger)
+			if err != nil {
+				return localReports, remoteReports, err
+			}
+		}
+	}
+
+	return localReports, remoteReports, nil
 }
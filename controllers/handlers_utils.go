@@ -158,7 +158,7 @@ func deployContentOfSource(ctx context.Context, deployingToMgmtCluster bool, des
 	defer os.RemoveAll(tmpDir)
 
 	// Path can be expressed as a template and instantiate using Cluster fields.
-	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
+	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 		clusterSummary, clusterSummary.GetName(), path, nil, logger)
 	if err != nil {
 		return nil, err
@@ -443,23 +443,41 @@ func applyPatches(ctx context.Context, clusterSummary *configv1beta1.ClusterSumm
 // Returns an error if one occurred. Otherwise it returns a slice containing the name of
 // the policies deployed in the form of kind.group:namespace:name for namespaced policies
 // and kind.group::name for cluster wide policies.
-//
-//nolint:funlen // requires a lot of arguments because kustomize and plain resources are using this function
+// Objects, once patched, are recorded in the revision being rendered, if any.
 func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destConfig *rest.Config,
 	destClient client.Client, referencedUnstructured []*unstructured.Unstructured, referencedObject *corev1.ObjectReference,
 	featureID configv1beta1.FeatureID, clusterSummary *configv1beta1.ClusterSummary, mgmtResources map[string]*unstructured.Unstructured,
 	subresources []string, logger logr.Logger) (reports []configv1beta1.ResourceReport, err error) {
 
-	profile, profileTier, err := configv1beta1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(), clusterSummary)
+	referencedUnstructured, err = applyPatches(ctx, clusterSummary, referencedUnstructured, mgmtResources, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	referencedUnstructured, err = applyPatches(ctx, clusterSummary, referencedUnstructured, mgmtResources, logger)
+	recordRenderedObjects(clusterSummary, featureID, deployingToMgmtCluster, referencedObject, subresources,
+		referencedUnstructured)
+
+	return deployRenderedUnstructured(ctx, deployingToMgmtCluster, destConfig, destClient, referencedUnstructured,
+		referencedObject, featureID, clusterSummary, subresources, logger)
+}
+
+// deployRenderedUnstructured deploys referencedUnstructured objects, already instantiated and patched.
+//
+//nolint:funlen // requires a lot of arguments because kustomize and plain resources are using this function
+func deployRenderedUnstructured(ctx context.Context, deployingToMgmtCluster bool, destConfig *rest.Config,
+	destClient client.Client, referencedUnstructured []*unstructured.Unstructured, referencedObject *corev1.ObjectReference,
+	featureID configv1beta1.FeatureID, clusterSummary *configv1beta1.ClusterSummary,
+	subresources []string, logger logr.Logger) (reports []configv1beta1.ResourceReport, err error) {
+
+	profile, profileTier, err := configv1beta1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(ctx), clusterSummary)
 	if err != nil {
 		return nil, err
 	}
 
+	// Apply resources ordered by kind (Namespaces, CRDs, RBAC, workloads, ..., webhooks)
+	referencedUnstructured = sortResourcesForApply(referencedUnstructured)
+	crds := appliedCRDs{}
+
 	conflictErrorMsg := ""
 	errorMsg := ""
 	reports = make([]configv1beta1.ResourceReport, 0)
@@ -488,6 +506,14 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 			return nil, err
 		}
 
+		// If policy is an instance of a CRD deployed earlier, CRD must be established first
+		if clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1beta1.SyncModeDryRun {
+			err = crds.waitForCRD(ctx, destConfig, destClient, policy, logger)
+			if err != nil {
+				return reports, err
+			}
+		}
+
 		dr, err := k8s_utils.GetDynamicResourceInterface(destConfig, policy.GroupVersionKind(), policy.GetNamespace())
 		if err != nil {
 			return nil, err
@@ -506,6 +532,10 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 					continue
 				} else {
 					conflictErrorMsg += conflictResourceReport.Message
+					recordWarningEvent(ctx, clusterSummary, eventReasonConflictLost,
+						"Resource %s %s/%s in cluster %s is managed by another profile (tier %d). %s",
+						policy.GetKind(), policy.GetNamespace(), policy.GetName(), getClusterDescription(clusterSummary),
+						getTier(resourceInfo.OwnerTier), conflictResourceReport.Message)
 					if clusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict {
 						continue
 					}
@@ -554,6 +584,8 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 			}
 			return reports, err
 		}
+
+		crds.record(policy)
 	}
 
 	return reports, handleDeployUnstructuredErrors(conflictErrorMsg, errorMsg, clusterSummary)
@@ -608,7 +640,7 @@ func addMetadata(policy *unstructured.Unstructured, resourceVersion string,
 func requeueAllOldOwners(ctx context.Context, resourceInfo *deployer.ResourceInfo,
 	featureID configv1beta1.FeatureID, clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
 
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 
 	profileOwners := resourceInfo.GetOwnerReferences()
 
@@ -660,6 +692,13 @@ func requeueAllOldOwners(ctx context.Context, resourceInfo *deployer.ResourceInf
 		if err != nil {
 			return err
 		}
+
+		resource := fmt.Sprintf("%s %s/%s", resourceInfo.CurrentResource.GetKind(),
+			resourceInfo.CurrentResource.GetNamespace(), resourceInfo.CurrentResource.GetName())
+		recordNormalEvent(ctx, clusterSummary, eventReasonConflictWon, "Resource %s in cluster %s taken over from %s",
+			resource, getClusterDescription(clusterSummary), getClusterSummaryProfileInfo(ownerClusterSummary))
+		recordWarningEvent(ctx, ownerClusterSummary, eventReasonConflictLost, "Resource %s in cluster %s taken over by %s",
+			resource, getClusterDescription(ownerClusterSummary), getClusterSummaryProfileInfo(clusterSummary))
 	}
 
 	return nil
@@ -982,7 +1021,7 @@ func collectContent(ctx context.Context, clusterSummary *configv1beta1.ClusterSu
 		section := data[k]
 
 		if instantiateTemplate {
-			instance, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
+			instance, err := instantiateTemplateValues(ctx, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 				clusterSummary, clusterSummary.GetName(), section, mgmtResources, logger)
 			if err != nil {
 				logger.Error(err, fmt.Sprintf("failed to instantiate policy from Data %.100s", section))
@@ -991,7 +1030,7 @@ func collectContent(ctx context.Context, clusterSummary *configv1beta1.ClusterSu
 
 			section = instance
 		} else if instantiateLua {
-			instance, err := instantiateWithLuaScript(ctx, getManagementClusterConfig(), getManagementClusterClient(),
+			instance, err := instantiateWithLuaScript(ctx, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 				clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
 				section, mgmtResources, logger)
 			if err != nil {
@@ -1106,7 +1145,7 @@ func getClusterSummaryAndClusterClient(ctx context.Context, clusterNamespace, cl
 	}
 
 	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
-	clusterClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
+	clusterClient, err := clustercache.GetManager().GetKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
 		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return nil, nil, err
@@ -1141,7 +1180,7 @@ func collectReferencedObjects(ctx context.Context, controlClusterClient client.C
 		var object client.Object
 		reference := &references[i]
 
-		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(),
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(ctx),
 			clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, references[i].Namespace,
 			clusterSummary.Spec.ClusterType)
 		if err != nil {
@@ -1150,7 +1189,7 @@ func collectReferencedObjects(ctx context.Context, controlClusterClient client.C
 			return nil, nil, err
 		}
 
-		name, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(),
+		name, err := libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(ctx),
 			clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, references[i].Name,
 			clusterSummary.Spec.ClusterType)
 		if err != nil {
@@ -1217,7 +1256,7 @@ func deployReferencedObjects(ctx context.Context, c client.Client, remoteConfig
 	// Assume that if objects are deployed in the management clusters, those are needed before any
 	// resource is deployed in the managed cluster. So try to deploy those first if any.
 
-	localConfig := rest.CopyConfig(getManagementClusterConfig())
+	localConfig := rest.CopyConfig(getManagementClusterConfig(ctx))
 	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
 	if adminName != "" {
 		localConfig.Impersonate = rest.ImpersonationConfig{
@@ -1295,7 +1334,7 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 	logger.V(logs.LogDebug).Info("removing stale resources")
 
 	if !isMgmtCluster {
-		cluster, err := clusterproxy.GetCluster(ctx, getManagementClusterClient(), clusterSummary.Spec.ClusterNamespace,
+		cluster, err := clusterproxy.GetCluster(ctx, getManagementClusterClient(ctx), clusterSummary.Spec.ClusterNamespace,
 			clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
@@ -1312,7 +1351,7 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 		}
 	}
 
-	profile, _, err := configv1beta1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(), clusterSummary)
+	profile, _, err := configv1beta1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(ctx), clusterSummary)
 	if err != nil {
 		return nil, err
 	}
@@ -1336,6 +1375,8 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
 	}
 
+	// Resources are removed in reverse apply order (webhooks first, Namespaces and CRDs last)
+	deployedGVKs = sortGVKsForWithdraw(deployedGVKs)
 	for i := range deployedGVKs {
 		// TODO: move this to separate method
 		logger.V(logs.LogDebug).Info(fmt.Sprintf("removing stale resources for GVK %s", deployedGVKs[i].String()))
@@ -1745,7 +1786,7 @@ func updateDeployedGroupVersionKind(ctx context.Context, clusterSummary *configv
 		return clusterSummary, nil
 	}
 
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 
 	currentClusterSummary := &configv1beta1.ClusterSummary{}
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -1773,7 +1814,7 @@ func updateDeployedGroupVersionKind(ctx context.Context, clusterSummary *configv
 		// update status with list of GroupVersionKinds deployed in a Managed and Management Cluster
 		appendDeployedGroupVersionKinds(currentClusterSummary, gvks, featureID)
 
-		return getManagementClusterClient().Status().Update(ctx, currentClusterSummary)
+		return getManagementClusterClient(ctx).Status().Update(ctx, currentClusterSummary)
 	})
 
 	return currentClusterSummary, err
@@ -2017,7 +2058,7 @@ func initiatePatches(ctx context.Context, clusterSummary *configv1beta1.ClusterS
 	instantiatedPatches = clusterSummary.Spec.ClusterProfileSpec.Patches
 
 	for k := range instantiatedPatches {
-		instantiatedPatch, err := instantiateTemplateValues(ctx, getManagementClusterConfig(), getManagementClusterClient(),
+		instantiatedPatch, err := instantiateTemplateValues(ctx, getManagementClusterConfig(ctx), getManagementClusterClient(ctx),
 			clusterSummary, requestor, instantiatedPatches[k].Patch, mgmtResources, logger)
 		if err != nil {
 			return nil, err
@@ -2044,6 +2085,12 @@ func getClusterProfileSpecHash(ctx context.Context, clusterSummary *configv1beta
 		config += ("agentless")
 	}
 
+	// When drift detection is done by polling, ResourceSummary instances are in the management cluster
+	// and no drift-detection-manager is deployed. Redeploy when switching in or out of this mode.
+	if clusterProfileSpec.SyncMode == configv1beta1.SyncModeContinuousWithDriftDetection && getDriftDetectionPolling() {
+		config += ("polling")
+	}
+
 	// If Reloader changes, Reloader needs to be deployed or undeployed
 	// So consider it in the hash
 	config += fmt.Sprintf("%v", clusterProfileSpec.Reloader)
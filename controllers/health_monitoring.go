@@ -0,0 +1,520 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Once a feature is Provisioned, ValidateHealths are only evaluated again on the next deployment.
+// When a ClusterProfile/Profile sets HealthMonitoring, ValidateHealths (and optionally the built-in
+// readiness of all deployed resources) are periodically re-evaluated. Features failing those checks
+// are moved to Degraded, features recovering are moved back to Provisioned.
+// A Degraded feature is considered deployed: it is not redeployed unless RedeployOnDegraded is set.
+
+const (
+	// interval at which ClusterSummaries are examined to find the ones due for a health evaluation
+	healthMonitoringCheckInterval = 30 * time.Second
+
+	defaultHealthMonitoringInterval = 5 * time.Minute
+
+	// maximum number of degraded features listed in the Degraded condition message
+	maxDegradedFeaturesInCondition = 5
+
+	degradedReason = "FeaturesDegraded"
+	healthyReason  = "FeaturesHealthy"
+)
+
+// monitorFeaturesHealth periodically re-evaluates the health of all provisioned features for
+// ClusterSummaries with HealthMonitoring enabled.
+func (r *ClusterSummaryReconciler) monitorFeaturesHealth(ctx context.Context, logger logr.Logger) {
+	// key: ClusterSummary; value: last time health was evaluated
+	lastEvaluation := make(map[types.NamespacedName]time.Time)
+
+	for {
+		time.Sleep(healthMonitoringCheckInterval)
+
+		logger.V(logs.LogVerbose).Info("evaluating health of provisioned features")
+		clusterSummaries := &configv1beta1.ClusterSummaryList{}
+		if err := r.List(ctx, clusterSummaries); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterSummaries: %v", err))
+			continue
+		}
+
+		monitored := make(map[types.NamespacedName]bool)
+		// Clients to managed clusters are created once per cluster per pass
+		remoteClients := make(map[string]client.Client)
+		for i := range clusterSummaries.Items {
+			cs := &clusterSummaries.Items[i]
+			if !isHealthMonitoringEnabled(cs) {
+				continue
+			}
+
+			key := types.NamespacedName{Namespace: cs.Namespace, Name: cs.Name}
+			monitored[key] = true
+
+			if last, ok := lastEvaluation[key]; ok &&
+				time.Since(last) < getHealthMonitoringInterval(cs.Spec.ClusterProfileSpec.HealthMonitoring) {
+
+				continue
+			}
+			lastEvaluation[key] = time.Now()
+
+			l := logger.WithValues("clustersummary", fmt.Sprintf("%s/%s", cs.Namespace, cs.Name))
+			isMatch, err := r.isClusterAShardMatch(ctx, cs, l)
+			if err != nil || !isMatch {
+				continue
+			}
+
+			if err := evaluateFeaturesHealth(ctx, r.Client, cs, remoteClients, l); err != nil {
+				l.V(logs.LogInfo).Info(fmt.Sprintf("failed to evaluate features health: %v", err))
+			}
+		}
+
+		for key := range lastEvaluation {
+			if _, ok := monitored[key]; !ok {
+				delete(lastEvaluation, key)
+				forgetFeatureHealth(key.Namespace, key.Name)
+			}
+		}
+	}
+}
+
+func isHealthMonitoringEnabled(clusterSummary *configv1beta1.ClusterSummary) bool {
+	if clusterSummary.Spec.ClusterProfileSpec.HealthMonitoring == nil {
+		return false
+	}
+
+	if !clusterSummary.DeletionTimestamp.IsZero() {
+		return false
+	}
+
+	return clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1beta1.SyncModeDryRun
+}
+
+func getHealthMonitoringInterval(healthMonitoring *configv1beta1.HealthMonitoring) time.Duration {
+	if healthMonitoring == nil || healthMonitoring.Interval == nil || healthMonitoring.Interval.Duration <= 0 {
+		return defaultHealthMonitoringInterval
+	}
+
+	return healthMonitoring.Interval.Duration
+}
+
+// isFeatureProvisionedOrDegraded returns true if feature was successfully deployed, regardless of
+// whether it is currently healthy.
+func isFeatureProvisionedOrDegraded(fs *configv1beta1.FeatureSummary) bool {
+	return fs.Status == configv1beta1.FeatureStatusProvisioned || fs.Status == configv1beta1.FeatureStatusDegraded
+}
+
+// evaluateFeaturesHealth evaluates health of all provisioned (or degraded) features in the ClusterSummary
+// and updates their status accordingly. remoteClients caches, across ClusterSummaries, clients to
+// managed clusters.
+func evaluateFeaturesHealth(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	remoteClients map[string]client.Client, logger logr.Logger) error {
+
+	features := make([]configv1beta1.FeatureID, 0, len(clusterSummary.Status.FeatureSummaries))
+	for i := range clusterSummary.Status.FeatureSummaries {
+		if isFeatureProvisionedOrDegraded(&clusterSummary.Status.FeatureSummaries[i]) {
+			features = append(features, clusterSummary.Status.FeatureSummaries[i].FeatureID)
+		}
+	}
+
+	if len(features) == 0 {
+		return nil
+	}
+
+	remoteConfig, logger, err := getRestConfig(ctx, c, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	// key: feature; value: empty if healthy, reason otherwise
+	results := make(map[configv1beta1.FeatureID]string)
+	for i := range features {
+		l := logger.WithValues("feature", features[i])
+		msg, err := evaluateFeatureHealth(ctx, c, remoteConfig, clusterSummary, features[i], remoteClients, l)
+		if err != nil {
+			// Not being able to evaluate health is not a proof feature is unhealthy
+			l.V(logs.LogInfo).Info(fmt.Sprintf("failed to evaluate health: %v", err))
+			continue
+		}
+		results[features[i]] = msg
+	}
+
+	return updateFeaturesHealth(ctx, c, clusterSummary, results, logger)
+}
+
+// evaluateFeatureHealth returns an empty string if feature is healthy. Otherwise returns why feature
+// is not healthy.
+func evaluateFeatureHealth(ctx context.Context, c client.Client, remoteConfig *rest.Config,
+	clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	remoteClients map[string]client.Client, logger logr.Logger) (string, error) {
+
+	// Same checks run at deployment time
+	if err := validateHealthPolicies(ctx, remoteConfig, clusterSummary, featureID, logger); err != nil {
+		return err.Error(), nil
+	}
+
+	if !clusterSummary.Spec.ClusterProfileSpec.HealthMonitoring.CheckDeployedResources {
+		return "", nil
+	}
+
+	resources, err := getFeatureDeployedResources(ctx, c, clusterSummary, featureID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resources) == 0 {
+		return "", nil
+	}
+
+	remoteClient, err := getHealthMonitoringClient(ctx, c, clusterSummary, remoteClients, logger)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range resources {
+		resource := &libsveltosv1beta1.Resource{
+			Namespace: resources[i].Namespace,
+			Name:      resources[i].Name,
+			Group:     resources[i].Group,
+			Kind:      resources[i].Kind,
+			Version:   resources[i].Version,
+		}
+		u, err := fetchDeployedResource(ctx, remoteClient, resource)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("%s %s/%s not found", resource.Kind, resource.Namespace, resource.Name), nil
+			}
+			return "", err
+		}
+
+		if ready, msg := isResourceReady(u); !ready {
+			return msg, nil
+		}
+	}
+
+	return "", nil
+}
+
+// getHealthMonitoringClient returns the client to the managed cluster, as seen by the ClusterSummary
+// admin. A client is created (through the cluster cache) only the first time a cluster is seen.
+func getHealthMonitoringClient(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	remoteClients map[string]client.Client, logger logr.Logger) (client.Client, error) {
+
+	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
+	key := fmt.Sprintf("%s:%s/%s:%s/%s", clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, adminNamespace, adminName)
+	if remoteClient, ok := remoteClients[key]; ok {
+		return remoteClient, nil
+	}
+
+	remoteClient, err := clustercache.GetManager().GetKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteClients[key] = remoteClient
+	return remoteClient, nil
+}
+
+// getFeatureDeployedResources returns the resources deployed by a ClusterSummary feature, as recorded
+// in the ClusterConfiguration. Helm charts are recorded as releases and not as resources, so nothing is
+// returned for the Helm feature.
+func getFeatureDeployedResources(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID) ([]configv1beta1.Resource, error) {
+
+	feature, err := getClusterConfigurationFeature(ctx, c, clusterSummary, featureID)
+	if err != nil || feature == nil {
+		return nil, err
+	}
+
+	return feature.Resources, nil
+}
+
+// getClusterConfigurationFeature returns what the ClusterConfiguration records as deployed by a
+// ClusterSummary feature. Nil is returned if nothing is recorded.
+func getClusterConfigurationFeature(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID) (*configv1beta1.Feature, error) {
+
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterConfiguration := &configv1beta1.ClusterConfiguration{}
+	err = c.Get(ctx,
+		types.NamespacedName{
+			Namespace: clusterSummary.Spec.ClusterNamespace,
+			Name:      getClusterConfigurationName(clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType),
+		},
+		clusterConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := configv1beta1.GetClusterConfigurationSectionIndex(clusterConfiguration, profileOwnerRef.Kind,
+		profileOwnerRef.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var features []configv1beta1.Feature
+	if profileOwnerRef.Kind == configv1beta1.ClusterProfileKind {
+		features = clusterConfiguration.Status.ClusterProfileResources[index].Features
+	} else {
+		features = clusterConfiguration.Status.ProfileResources[index].Features
+	}
+
+	for i := range features {
+		if features[i].FeatureID == featureID {
+			return &features[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// updateFeaturesHealth moves unhealthy features to Degraded and recovered features back to Provisioned.
+// When any feature changes state, the Degraded condition of the owning ClusterProfile/Profile is updated.
+func updateFeaturesHealth(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	results map[configv1beta1.FeatureID]string, logger logr.Logger) error {
+
+	changed := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		changed = false
+		for i := range currentClusterSummary.Status.FeatureSummaries {
+			fs := &currentClusterSummary.Status.FeatureSummaries[i]
+			msg, ok := results[fs.FeatureID]
+			// Feature might have been redeployed in the meantime
+			if !ok || !isFeatureProvisionedOrDegraded(fs) {
+				continue
+			}
+
+			if updateFeatureHealth(currentClusterSummary, fs, msg, logger) {
+				changed = true
+			}
+		}
+
+		if !changed {
+			return nil
+		}
+
+		return c.Status().Update(ctx, currentClusterSummary)
+	})
+	if err != nil || !changed {
+		return err
+	}
+
+	return updateProfileDegradedCondition(ctx, c, clusterSummary, logger)
+}
+
+// updateFeatureHealth updates feature status based on health evaluation (msg is empty when
+// healthy). Returns true if feature status changed.
+func updateFeatureHealth(clusterSummary *configv1beta1.ClusterSummary, fs *configv1beta1.FeatureSummary,
+	msg string, logger logr.Logger) bool {
+
+	healthMonitoring := clusterSummary.Spec.ClusterProfileSpec.HealthMonitoring
+
+	if msg == "" {
+		trackFeatureHealth(clusterSummary, string(fs.FeatureID), false, false, logger)
+		if fs.Status != configv1beta1.FeatureStatusDegraded {
+			return false
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("feature %s is healthy again", fs.FeatureID))
+		fs.Status = configv1beta1.FeatureStatusProvisioned
+		fs.FailureMessage = nil
+		return true
+	}
+
+	transition := fs.Status != configv1beta1.FeatureStatusDegraded
+	trackFeatureHealth(clusterSummary, string(fs.FeatureID), true, transition, logger)
+	if !transition && fs.FailureMessage != nil && *fs.FailureMessage == msg {
+		return false
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("feature %s is degraded: %s", fs.FeatureID, msg))
+	fs.Status = configv1beta1.FeatureStatusDegraded
+	fs.FailureMessage = &msg
+	if transition && healthMonitoring != nil && healthMonitoring.RedeployOnDegraded {
+		// Resetting the hash forces ClusterSummary reconciler to redeploy the feature
+		fs.Hash = nil
+	}
+	return true
+}
+
+// getProfileClusterSummaries returns all ClusterSummaries created for a ClusterProfile/Profile
+func getProfileClusterSummaries(ctx context.Context, c client.Client, profileKind, profileNamespace,
+	profileName string) (*configv1beta1.ClusterSummaryList, error) {
+
+	listOptions := []client.ListOption{}
+	if profileKind == configv1beta1.ClusterProfileKind {
+		listOptions = append(listOptions, client.MatchingLabels{ClusterProfileLabelName: profileName})
+	} else {
+		listOptions = append(listOptions,
+			client.MatchingLabels{ProfileLabelName: profileName},
+			client.InNamespace(profileNamespace))
+	}
+
+	clusterSummaryList := &configv1beta1.ClusterSummaryList{}
+	if err := c.List(ctx, clusterSummaryList, listOptions...); err != nil {
+		return nil, err
+	}
+
+	return clusterSummaryList, nil
+}
+
+// getDegradedCondition returns the Degraded condition summarizing the state of all features
+// in the given ClusterSummaries
+func getDegradedCondition(clusterSummaries []configv1beta1.ClusterSummary, generation int64) *metav1.Condition {
+	degraded := make([]string, 0)
+	for i := range clusterSummaries {
+		cs := &clusterSummaries[i]
+		for j := range cs.Status.FeatureSummaries {
+			fs := &cs.Status.FeatureSummaries[j]
+			if fs.Status != configv1beta1.FeatureStatusDegraded {
+				continue
+			}
+			entry := fmt.Sprintf("%s in cluster %s:%s/%s", fs.FeatureID, cs.Spec.ClusterType,
+				cs.Spec.ClusterNamespace, cs.Spec.ClusterName)
+			if fs.FailureMessage != nil {
+				entry += fmt.Sprintf(": %s", *fs.FailureMessage)
+			}
+			degraded = append(degraded, entry)
+		}
+	}
+
+	if len(degraded) == 0 {
+		return &metav1.Condition{
+			Type:               configv1beta1.DegradedCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             healthyReason,
+			Message:            "all provisioned features are healthy",
+			ObservedGeneration: generation,
+		}
+	}
+
+	message := strings.Join(degraded[:min(len(degraded), maxDegradedFeaturesInCondition)], "; ")
+	if len(degraded) > maxDegradedFeaturesInCondition {
+		message += fmt.Sprintf(" (and %d more)", len(degraded)-maxDegradedFeaturesInCondition)
+	}
+
+	return &metav1.Condition{
+		Type:               configv1beta1.DegradedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             degradedReason,
+		Message:            message,
+		ObservedGeneration: generation,
+	}
+}
+
+// setDegradedCondition sets (or removes when health monitoring is not enabled) the Degraded condition.
+// Returns true if conditions changed.
+func setDegradedCondition(spec *configv1beta1.Spec, status *configv1beta1.Status,
+	clusterSummaries []configv1beta1.ClusterSummary, generation int64) bool {
+
+	if spec.HealthMonitoring == nil {
+		return meta.RemoveStatusCondition(&status.Conditions, configv1beta1.DegradedCondition)
+	}
+
+	return meta.SetStatusCondition(&status.Conditions, *getDegradedCondition(clusterSummaries, generation))
+}
+
+// updateProfileDegradedCondition updates the Degraded condition of the ClusterProfile/Profile
+// owning the ClusterSummary.
+func updateProfileDegradedCondition(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, logger logr.Logger) error {
+
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var profile client.Object
+		var spec *configv1beta1.Spec
+		var status *configv1beta1.Status
+		if profileOwnerRef.Kind == configv1beta1.ClusterProfileKind {
+			clusterProfile := &configv1beta1.ClusterProfile{}
+			if err := c.Get(ctx, types.NamespacedName{Name: profileOwnerRef.Name}, clusterProfile); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			profile, spec, status = clusterProfile, &clusterProfile.Spec, &clusterProfile.Status
+		} else {
+			p := &configv1beta1.Profile{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: profileOwnerRef.Name},
+				p); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			profile, spec, status = p, &p.Spec, &p.Status
+		}
+
+		clusterSummaries, err := getProfileClusterSummaries(ctx, c, profileOwnerRef.Kind,
+			profile.GetNamespace(), profile.GetName())
+		if err != nil {
+			return err
+		}
+
+		if !setDegradedCondition(spec, status, clusterSummaries.Items, profile.GetGeneration()) {
+			return nil
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("updating %s %s Degraded condition",
+			profileOwnerRef.Kind, profile.GetName()))
+		return c.Status().Update(ctx, profile)
+	})
+}
+
+// updateDegradedCondition recomputes the Degraded condition of the ClusterProfile/Profile. Conditions are
+// persisted when profileScope is closed.
+func updateDegradedCondition(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
+	clusterSummaries, err := getProfileClusterSummaries(ctx, c, profileScope.GetKind(),
+		profileScope.Profile.GetNamespace(), profileScope.Name())
+	if err != nil {
+		return err
+	}
+
+	setDegradedCondition(profileScope.GetSpec(), profileScope.GetStatus(), clusterSummaries.Items,
+		profileScope.Profile.GetGeneration())
+	return nil
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Health monitoring", func() {
+	It("isResourceReady evaluates readiness of well known kinds", func() {
+		deployment := &unstructured.Unstructured{}
+		deployment.SetAPIVersion("apps/v1")
+		deployment.SetKind("Deployment")
+		deployment.SetNamespace(randomString())
+		deployment.SetName(randomString())
+		deployment.SetGeneration(2)
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "spec", "replicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "status", "observedGeneration")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "status", "updatedReplicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(1), "status", "availableReplicas")).To(Succeed())
+
+		ready, msg := controllers.IsResourceReady(deployment)
+		Expect(ready).To(BeFalse())
+		Expect(msg).To(ContainSubstring("1 out of 2 replicas available"))
+
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "status", "availableReplicas")).To(Succeed())
+		ready, _ = controllers.IsResourceReady(deployment)
+		Expect(ready).To(BeTrue())
+
+		// Status not reflecting latest generation yet
+		deployment.SetGeneration(3)
+		ready, msg = controllers.IsResourceReady(deployment)
+		Expect(ready).To(BeFalse())
+		Expect(msg).To(ContainSubstring("observedGeneration"))
+
+		job := &unstructured.Unstructured{}
+		job.SetAPIVersion("batch/v1")
+		job.SetKind("Job")
+		job.SetName(randomString())
+		ready, _ = controllers.IsResourceReady(job)
+		Expect(ready).To(BeFalse())
+
+		Expect(unstructured.SetNestedSlice(job.Object, []interface{}{
+			map[string]interface{}{"type": "Complete", "status": "True"},
+		}, "status", "conditions")).To(Succeed())
+		ready, _ = controllers.IsResourceReady(job)
+		Expect(ready).To(BeTrue())
+
+		// Resources with no readiness rule are ready
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(randomString())
+		ready, _ = controllers.IsResourceReady(configMap)
+		Expect(ready).To(BeTrue())
+
+		// Any resource reporting a Ready condition which is not true is not ready
+		Expect(unstructured.SetNestedSlice(configMap.Object, []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "False", "message": randomString()},
+		}, "status", "conditions")).To(Succeed())
+		ready, _ = controllers.IsResourceReady(configMap)
+		Expect(ready).To(BeFalse())
+	})
+
+	It("getHealthMonitoringInterval defaults to five minutes", func() {
+		Expect(controllers.GetHealthMonitoringInterval(nil)).To(Equal(5 * time.Minute))
+		Expect(controllers.GetHealthMonitoringInterval(&configv1beta1.HealthMonitoring{
+			Interval: &metav1.Duration{Duration: time.Minute},
+		})).To(Equal(time.Minute))
+	})
+
+	It("getDegradedCondition lists degraded features", func() {
+		msg := randomString()
+		clusterSummaries := []configv1beta1.ClusterSummary{
+			{
+				Spec: configv1beta1.ClusterSummarySpec{
+					ClusterNamespace: randomString(),
+					ClusterName:      randomString(),
+					ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+				},
+				Status: configv1beta1.ClusterSummaryStatus{
+					FeatureSummaries: []configv1beta1.FeatureSummary{
+						{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+					},
+				},
+			},
+		}
+
+		condition := controllers.GetDegradedCondition(clusterSummaries, 1)
+		Expect(condition.Type).To(Equal(configv1beta1.DegradedCondition))
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+		clusterSummaries[0].Status.FeatureSummaries[0].Status = configv1beta1.FeatureStatusDegraded
+		clusterSummaries[0].Status.FeatureSummaries[0].FailureMessage = &msg
+		condition = controllers.GetDegradedCondition(clusterSummaries, 1)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring(msg))
+		Expect(condition.Message).To(ContainSubstring(clusterSummaries[0].Spec.ClusterName))
+	})
+
+	It("updateFeaturesHealth moves features to Degraded and back to Provisioned", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+			Spec: configv1beta1.Spec{
+				HealthMonitoring: &configv1beta1.HealthMonitoring{},
+			},
+		}
+
+		hash := []byte(randomString())
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels: map[string]string{
+					controllers.ClusterProfileLabelName: clusterProfile.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1beta1.ClusterProfileKind,
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Name:       clusterProfile.Name,
+						UID:        types.UID(randomString()),
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HealthMonitoring: &configv1beta1.HealthMonitoring{},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusProvisioned, Hash: hash},
+				},
+			},
+		}
+
+		initObjects := []client.Object{clusterProfile, clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		msg := randomString()
+		Expect(controllers.UpdateFeaturesHealth(context.TODO(), c, clusterSummary,
+			map[configv1beta1.FeatureID]string{configv1beta1.FeatureResources: msg}, logger)).To(Succeed())
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Status).To(Equal(configv1beta1.FeatureStatusDegraded))
+		Expect(*currentClusterSummary.Status.FeatureSummaries[0].FailureMessage).To(Equal(msg))
+		// RedeployOnDegraded is not set. Hash must not be reset
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Hash).To(Equal(hash))
+
+		currentClusterProfile := &configv1beta1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(currentClusterProfile.Status.Conditions,
+			configv1beta1.DegradedCondition)).To(BeTrue())
+
+		Expect(controllers.UpdateFeaturesHealth(context.TODO(), c, clusterSummary,
+			map[configv1beta1.FeatureID]string{configv1beta1.FeatureResources: ""}, logger)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Status).To(Equal(configv1beta1.FeatureStatusProvisioned))
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].FailureMessage).To(BeNil())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(currentClusterProfile.Status.Conditions,
+			configv1beta1.DegradedCondition)).To(BeTrue())
+	})
+
+	It("updateFeaturesHealth resets hash when RedeployOnDegraded is set", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1beta1.ClusterProfileKind,
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Name:       randomString(),
+						UID:        types.UID(randomString()),
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1beta1.Spec{
+					HealthMonitoring: &configv1beta1.HealthMonitoring{RedeployOnDegraded: true},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned,
+						Hash: []byte(randomString())},
+				},
+			},
+		}
+
+		initObjects := []client.Object{clusterSummary}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		Expect(controllers.UpdateFeaturesHealth(context.TODO(), c, clusterSummary,
+			map[configv1beta1.FeatureID]string{configv1beta1.FeatureHelm: randomString()},
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Status).To(Equal(configv1beta1.FeatureStatusDegraded))
+		Expect(currentClusterSummary.Status.FeatureSummaries[0].Hash).To(BeNil())
+	})
+})
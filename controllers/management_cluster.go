@@ -20,9 +20,14 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
 )
 
 var (
@@ -33,8 +38,30 @@ var (
 	capiOnboardAnnotation   string
 	driftDetectionRegistry  string
 	agentInMgmtCluster      bool
+	driftDetectionPolling   bool
 )
 
+// offlineManagementClusterKey is the context key carrying the offline management cluster access
+// used while profiles are rendered against local fixtures (see Render).
+type offlineManagementClusterKey struct{}
+
+type offlineManagementCluster struct {
+	client client.Client
+	config *rest.Config
+}
+
+// withOfflineManagementCluster returns a context in which any management cluster resource is read
+// with c, which is backed by local fixtures, instead of accessing the management cluster.
+func withOfflineManagementCluster(ctx context.Context, c client.Client) context.Context {
+	return context.WithValue(ctx, offlineManagementClusterKey{},
+		&offlineManagementCluster{client: c, config: &rest.Config{}})
+}
+
+func getOfflineManagementCluster(ctx context.Context) *offlineManagementCluster {
+	offline, _ := ctx.Value(offlineManagementClusterKey{}).(*offlineManagementCluster)
+	return offline
+}
+
 func SetManagementClusterAccess(c client.Client, config *rest.Config) {
 	managementClusterClient = c
 	managementClusterConfig = config
@@ -60,11 +87,24 @@ func SetAgentInMgmtCluster(isInMgmtCluster bool) {
 	agentInMgmtCluster = isInMgmtCluster
 }
 
-func getManagementClusterConfig() *rest.Config {
+// SetDriftDetectionPolling enables agentless drift detection. When set, no drift-detection-manager
+// is deployed. The addon-controller itself periodically compares deployed resources with the
+// hashes recorded in ResourceSummary instances stored in the management cluster.
+func SetDriftDetectionPolling(polling bool) {
+	driftDetectionPolling = polling
+}
+
+func getManagementClusterConfig(ctx context.Context) *rest.Config {
+	if offline := getOfflineManagementCluster(ctx); offline != nil {
+		return offline.config
+	}
 	return managementClusterConfig
 }
 
-func getManagementClusterClient() client.Client {
+func getManagementClusterClient(ctx context.Context) client.Client {
+	if offline := getOfflineManagementCluster(ctx); offline != nil {
+		return offline.client
+	}
 	return managementClusterClient
 }
 
@@ -88,8 +128,41 @@ func getAgentInMgmtCluster() bool {
 	return agentInMgmtCluster
 }
 
+func getDriftDetectionPolling() bool {
+	return driftDetectionPolling
+}
+
+// isResourceSummaryInMgmtCluster returns true if ResourceSummary instances are stored in the
+// management cluster. That is the case when drift-detection-manager runs in the management cluster
+// or when drift detection is done by polling.
+func isResourceSummaryInMgmtCluster() bool {
+	return getAgentInMgmtCluster() || getDriftDetectionPolling()
+}
+
+// getManagementClusterResource fetches a resource of any kind from the management cluster
+func getManagementClusterResource(ctx context.Context, config *rest.Config, gvk schema.GroupVersionKind,
+	namespace, name string) (*unstructured.Unstructured, error) {
+
+	if offline := getOfflineManagementCluster(ctx); offline != nil {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		err := offline.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, u)
+		if err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	dr, err := k8s_utils.GetDynamicResourceInterface(config, gvk, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return dr.Get(ctx, name, metav1.GetOptions{})
+}
+
 func collectDriftDetectionConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 	configMap := &corev1.ConfigMap{}
 
 	err := c.Get(ctx, types.NamespacedName{Namespace: projectsveltos, Name: getDriftDetectionConfigMap()},
@@ -102,7 +175,7 @@ func collectDriftDetectionConfigMap(ctx context.Context) (*corev1.ConfigMap, err
 }
 
 func collectLuaConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 	configMap := &corev1.ConfigMap{}
 
 	err := c.Get(ctx, types.NamespacedName{Namespace: projectsveltos, Name: getLuaConfigMap()},
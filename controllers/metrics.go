@@ -17,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -56,6 +57,16 @@ var (
 		[]string{"cluster_type", "cluster_namespace", "cluster_name", "feature"},
 	)
 
+	driftRemediationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "projectsveltos",
+			Name:      "drift_remediation_time_seconds",
+			Help:      "Time between a configuration drift being detected and the affected feature being redeployed",
+			Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800},
+		},
+		[]string{"cluster_type", "feature"},
+	)
+
 	driftCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "projectsveltos",
@@ -64,14 +75,93 @@ var (
 		},
 		[]string{"cluster_type", "cluster_namespace", "cluster_name", "feature"},
 	)
+
+	degradedFeaturesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "degraded_features",
+			Help:      "Set to 1 when a provisioned feature is currently failing health checks, 0 otherwise",
+		},
+		[]string{"cluster_type", "cluster_namespace", "cluster_name", "clustersummary", "feature"},
+	)
+
+	degradationCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "total_degradations",
+			Help:      "Total number of times a provisioned feature moved to Degraded",
+		},
+		[]string{"cluster_type", "cluster_namespace", "cluster_name", "feature"},
+	)
+
+	inFlightDeploymentsGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "in_flight_deployments",
+			Help:      "Number of deployments and withdrawals currently running",
+		},
+	)
+
+	clusterInFlightDeploymentsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "cluster_in_flight_deployments",
+			Help:      "Number of deployments and withdrawals currently running on a managed cluster",
+		},
+		[]string{"cluster_type", "cluster_namespace", "cluster_name"},
+	)
+
+	clusterMaxConcurrentDeploymentsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "cluster_max_concurrent_deployments",
+			Help:      "Maximum number of deployments running in parallel on a managed cluster. 0 if not limited",
+		},
+		[]string{"cluster_type", "cluster_namespace", "cluster_name"},
+	)
+
+	throttledDeploymentsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "throttled_deployments_total",
+			Help:      "Total number of deployments postponed because of a concurrency limit (cluster or global)",
+		},
+		[]string{"cluster_type", "cluster_namespace", "cluster_name", "limit"},
+	)
+
+	deployerQueueDepthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "projectsveltos",
+			Name:      "deployer_queue_depth",
+			Help:      "Number of deployments and withdrawals waiting for a deployer worker, per priority",
+		},
+		[]string{"priority"},
+	)
+
+	droppedNotificationsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "dropped_notifications_total",
+			Help:      "Total number of notifications dropped because the notification queue was full",
+		},
+	)
 )
 
 //nolint:gochecknoinits // forced pattern, can't workaround
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(programResourceDurationHistogram, programChartDurationHistogram, reconciliationCounter, driftCounter)
+	metrics.Registry.MustRegister(programResourceDurationHistogram, programChartDurationHistogram, reconciliationCounter, driftCounter,
+		driftRemediationHistogram, degradedFeaturesGauge, degradationCounter, inFlightDeploymentsGauge,
+		clusterInFlightDeploymentsGauge, clusterMaxConcurrentDeploymentsGauge, throttledDeploymentsCounter, deployerQueueDepthGauge,
+		droppedNotificationsCounter)
 }
 
+var (
+	driftsMux sync.Mutex
+	// key: ClusterSummary and feature; value: time configuration drift was detected
+	pendingDrifts = make(map[string]time.Time)
+)
+
 func newResourceHistogram(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
 	logger logr.Logger) prometheus.Histogram {
 
@@ -192,3 +282,91 @@ func trackDrifts(clusterNamespace, clusterName, featureID, clusterType string, l
 	logger.V(logs.LogVerbose).Info(fmt.Sprintf("Tracking drifts for %s %s/%s %s",
 		clusterType, clusterNamespace, clusterName, featureID))
 }
+
+func getPendingDriftKey(clusterSummaryNamespace, clusterSummaryName, featureID string) string {
+	return fmt.Sprintf("%s/%s/%s", clusterSummaryNamespace, clusterSummaryName, featureID)
+}
+
+// recordDriftDetection stores the time a configuration drift was detected for a ClusterSummary feature.
+// If a previous drift is still pending remediation, the original time is kept.
+func recordDriftDetection(clusterSummaryNamespace, clusterSummaryName, featureID string) {
+	driftsMux.Lock()
+	defer driftsMux.Unlock()
+
+	key := getPendingDriftKey(clusterSummaryNamespace, clusterSummaryName, featureID)
+	if _, ok := pendingDrifts[key]; !ok {
+		pendingDrifts[key] = time.Now()
+	}
+}
+
+// isDriftPending returns true if a configuration drift detected for a ClusterSummary feature
+// has not been remediated yet.
+func isDriftPending(clusterSummaryNamespace, clusterSummaryName, featureID string) bool {
+	driftsMux.Lock()
+	defer driftsMux.Unlock()
+
+	_, ok := pendingDrifts[getPendingDriftKey(clusterSummaryNamespace, clusterSummaryName, featureID)]
+	return ok
+}
+
+// trackDriftRemediation is invoked when a feature is provisioned. If a configuration drift was pending,
+// time elapsed since the drift was detected is observed.
+func trackDriftRemediation(clusterSummary *configv1beta1.ClusterSummary, featureID string, logger logr.Logger) {
+	driftsMux.Lock()
+	defer driftsMux.Unlock()
+
+	key := getPendingDriftKey(clusterSummary.Namespace, clusterSummary.Name, featureID)
+	detectionTime, ok := pendingDrifts[key]
+	if !ok {
+		return
+	}
+	delete(pendingDrifts, key)
+
+	driftRemediationHistogram.With(prometheus.Labels{
+		"cluster_type": string(clusterSummary.Spec.ClusterType),
+		"feature":      featureID,
+	}).Observe(time.Since(detectionTime).Seconds())
+
+	logger.V(logs.LogVerbose).Info(fmt.Sprintf("Tracking drift remediation for %s %s/%s %s",
+		clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, featureID))
+}
+
+// trackFeatureHealth reports whether a provisioned feature is currently Degraded. When the feature
+// moves from Provisioned to Degraded, transition is counted as well.
+func trackFeatureHealth(clusterSummary *configv1beta1.ClusterSummary, featureID string, degraded, transition bool,
+	logger logr.Logger) {
+
+	value := float64(0)
+	if degraded {
+		value = 1
+	}
+
+	degradedFeaturesGauge.With(prometheus.Labels{
+		"cluster_type":      string(clusterSummary.Spec.ClusterType),
+		"cluster_namespace": clusterSummary.Spec.ClusterNamespace,
+		"cluster_name":      clusterSummary.Spec.ClusterName,
+		"clustersummary":    clusterSummary.Name,
+		"feature":           featureID,
+	}).Set(value)
+
+	if degraded && transition {
+		degradationCounter.With(prometheus.Labels{
+			"cluster_type":      string(clusterSummary.Spec.ClusterType),
+			"cluster_namespace": clusterSummary.Spec.ClusterNamespace,
+			"cluster_name":      clusterSummary.Spec.ClusterName,
+			"feature":           featureID,
+		}).Inc()
+	}
+
+	logger.V(logs.LogVerbose).Info(fmt.Sprintf("Tracking health for %s %s/%s %s (degraded: %t)",
+		clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		featureID, degraded))
+}
+
+// forgetFeatureHealth removes health metrics for a ClusterSummary not monitored anymore.
+func forgetFeatureHealth(clusterNamespace, clusterSummaryName string) {
+	degradedFeaturesGauge.DeletePartialMatch(prometheus.Labels{
+		"cluster_namespace": clusterNamespace,
+		"clustersummary":    clusterSummaryName,
+	})
+}
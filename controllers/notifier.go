@@ -0,0 +1,602 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Notifications
+// Notifiers subscribe to profile and cluster state transitions (feature failed, failed with a non
+// retriable error or provisioned, rollout halted, drift detected) and deliver them to an HTTP webhook,
+// a Slack-compatible endpoint or a ConfigMap.
+// Transitions are detected where FeatureSummaries are updated (updateFeatureStatus), where drifts are
+// processed and where the circuit breaker trips. Notifications are queued and a single worker matches
+// them against Notifiers, so reconcilers are never slowed down by a slow endpoint. Matching notifications
+// are then handed over to a per Notifier worker with its own queue, so that an unreachable endpoint only
+// delays its own notifications. Failed deliveries are retried with exponential backoff, up to MaxRetries
+// times, by requeueing them once the backoff expires: a worker never sleeps through a retry.
+// Identical notifications are delivered only once per deduplication window.
+// Secrets and ConfigMaps referenced by sinks must be in the addon-controller namespace, so that
+// Notifiers cannot be used to read Secrets or overwrite ConfigMaps in any other namespace.
+
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=notifiers,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+const (
+	maxQueuedNotifications             = 1000
+	maxQueuedNotifierDeliveries        = 100
+	maxNotificationRetryInterval       = 5 * time.Minute
+	notificationTimeout                = 10 * time.Second
+	defaultNotifierDeduplicationWindow = 10 * time.Minute
+	notifierURLSecretKey               = "url"
+
+	defaultSlackTemplate = `[{{.Transition}}] {{.ProfileKind}} {{.ProfileName}}` +
+		`{{if .ClusterName}} cluster {{.ClusterType}} {{.ClusterNamespace}}/{{.ClusterName}}{{end}}` +
+		`{{if .FeatureID}} ({{.FeatureID}}){{end}}: {{.Message}}`
+)
+
+var (
+	notificationQueue = make(chan *notification, maxQueuedNotifications)
+
+	// notificationRetryInterval is the delay before the first retry. It doubles at each retry.
+	notificationRetryInterval = time.Second
+
+	// key: Notifier name
+	notifierSinks    = make(map[string]*notifierSink)
+	notifierSinksMux sync.Mutex
+
+	// key: notifier and notification; value: time deduplication expires
+	deliveredNotifications    = make(map[string]time.Time)
+	deliveredNotificationsMux sync.Mutex
+
+	invalidConfigMapKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+	// notifierNamespace is the only namespace Secrets and ConfigMaps referenced by sinks can be in
+	notifierNamespace = "projectsveltos"
+)
+
+// SetNotifierNamespace sets the namespace Secrets and ConfigMaps referenced by Notifier sinks
+// must be in. This is the addon-controller namespace.
+func SetNotifierNamespace(namespace string) {
+	notifierNamespace = namespace
+}
+
+// notification is a profile or cluster state transition. Fields are available to Notifier templates.
+type notification struct {
+	Transition       configv1beta1.NotificationTransition `json:"transition"`
+	ProfileKind      string                               `json:"profileKind,omitempty"`
+	ProfileNamespace string                               `json:"profileNamespace,omitempty"`
+	ProfileName      string                               `json:"profileName,omitempty"`
+	ClusterType      string                               `json:"clusterType,omitempty"`
+	ClusterNamespace string                               `json:"clusterNamespace,omitempty"`
+	ClusterName      string                               `json:"clusterName,omitempty"`
+	FeatureID        string                               `json:"featureID,omitempty"`
+	Message          string                               `json:"message,omitempty"`
+	Time             time.Time                            `json:"time"`
+}
+
+// key identifies what notification is about (profile, cluster, feature and transition)
+func (n *notification) key() string {
+	parts := []string{n.ProfileKind, n.ProfileNamespace, n.ProfileName}
+	if n.ClusterName != "" {
+		parts = append(parts, n.ClusterType, n.ClusterNamespace, n.ClusterName)
+	}
+	if n.FeatureID != "" {
+		parts = append(parts, n.FeatureID)
+	}
+	parts = append(parts, string(n.Transition))
+
+	nonEmpty := make([]string, 0, len(parts))
+	for i := range parts {
+		if parts[i] != "" {
+			nonEmpty = append(nonEmpty, parts[i])
+		}
+	}
+	return invalidConfigMapKeyChars.ReplaceAllString(strings.Join(nonEmpty, "."), "-")
+}
+
+func newClusterNotification(clusterSummary *configv1beta1.ClusterSummary, featureID string,
+	transition configv1beta1.NotificationTransition, message string) *notification {
+
+	n := &notification{
+		Transition:       transition,
+		ClusterType:      string(clusterSummary.Spec.ClusterType),
+		ClusterNamespace: clusterSummary.Spec.ClusterNamespace,
+		ClusterName:      clusterSummary.Spec.ClusterName,
+		FeatureID:        featureID,
+		Message:          message,
+		Time:             time.Now().UTC(),
+	}
+
+	if ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary); err == nil && ownerRef != nil {
+		n.ProfileKind = ownerRef.Kind
+		n.ProfileName = ownerRef.Name
+		if ownerRef.Kind == configv1beta1.ProfileKind {
+			n.ProfileNamespace = clusterSummary.Namespace
+		}
+	}
+
+	return n
+}
+
+func newProfileNotification(profile client.Object, transition configv1beta1.NotificationTransition,
+	message string) *notification {
+
+	return &notification{
+		Transition:       transition,
+		ProfileKind:      profile.GetObjectKind().GroupVersionKind().Kind,
+		ProfileNamespace: profile.GetNamespace(),
+		ProfileName:      profile.GetName(),
+		Message:          message,
+		Time:             time.Now().UTC(),
+	}
+}
+
+// notifyFeatureTransition queues a notification if a feature status change is a transition
+// Notifiers can subscribe to
+func notifyFeatureTransition(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	previous, current configv1beta1.FeatureStatus, statusError error, logger logr.Logger) {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun || previous == current {
+		return
+	}
+
+	var transition configv1beta1.NotificationTransition
+	switch current {
+	case configv1beta1.FeatureStatusFailed:
+		transition = configv1beta1.NotificationTransitionFailed
+	case configv1beta1.FeatureStatusFailedNonRetriable:
+		transition = configv1beta1.NotificationTransitionFailedNonRetriable
+	case configv1beta1.FeatureStatusProvisioned:
+		transition = configv1beta1.NotificationTransitionProvisioned
+	default:
+		return
+	}
+
+	message := fmt.Sprintf("feature %s is %s", featureID, current)
+	if statusError != nil {
+		message = statusError.Error()
+	}
+
+	queueNotification(newClusterNotification(clusterSummary, string(featureID), transition, message), logger)
+}
+
+// notificationDelivery is a notification to deliver to a Notifier sink
+type notificationDelivery struct {
+	notifier string
+	n        *notification
+	dedupKey string
+	// attempt is the number of failed delivery attempts so far
+	attempt int32
+}
+
+// notifierSink queues and delivers notifications for one Notifier
+type notifierSink struct {
+	deliveries chan *notificationDelivery
+}
+
+// queueNotification queues a notification for delivery. Notification is dropped if queue is full.
+func queueNotification(n *notification, logger logr.Logger) {
+	select {
+	case notificationQueue <- n:
+	default:
+		droppedNotificationsCounter.Inc()
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("notification queue is full. Dropping notification %s", n.key()))
+	}
+}
+
+// processNotifications hands queued notifications over to matching Notifiers till context is cancelled
+func processNotifications(ctx context.Context, c client.Client, logger logr.Logger) {
+	logger = logger.WithValues("worker", "notifications")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-notificationQueue:
+			if err := notify(ctx, c, n, logger); err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to process notification %s: %v", n.key(), err))
+			}
+		}
+	}
+}
+
+// notify queues a notification for delivery to all matching Notifiers
+func notify(ctx context.Context, c client.Client, n *notification, logger logr.Logger) error {
+	notifiers := &configv1beta1.NotifierList{}
+	if err := c.List(ctx, notifiers); err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range notifiers.Items {
+		notifier := &notifiers.Items[i]
+		l := logger.WithValues("notifier", notifier.Name)
+
+		match, err := isNotifierMatching(ctx, c, notifier, n)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notifier %s: %w", notifier.Name, err))
+			continue
+		}
+		if !match {
+			continue
+		}
+
+		dedupKey := fmt.Sprintf("%s/%s/%s", notifier.Name, n.key(), n.Message)
+		if isNotificationDuplicated(dedupKey) {
+			l.V(logs.LogDebug).Info(fmt.Sprintf("notification %s already delivered", n.key()))
+			continue
+		}
+
+		queueDelivery(ctx, c, &notificationDelivery{notifier: notifier.Name, n: n, dedupKey: dedupKey}, l)
+	}
+
+	return errors.Join(errs...)
+}
+
+// isNotifierMatching returns true if Notifier subscribed to notification
+func isNotifierMatching(ctx context.Context, c client.Client, notifier *configv1beta1.Notifier,
+	n *notification) (bool, error) {
+
+	if len(notifier.Spec.Transitions) > 0 {
+		found := false
+		for i := range notifier.Spec.Transitions {
+			if notifier.Spec.Transitions[i] == n.Transition {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if n.ProfileName != "" && len(notifier.Spec.ProfileSelector.LabelSelector.MatchLabels)+
+		len(notifier.Spec.ProfileSelector.LabelSelector.MatchExpressions) > 0 {
+
+		profile, _, _, err := getReferencedProfile(ctx, c, &configv1beta1.ProfileReference{
+			Kind: n.ProfileKind, Namespace: n.ProfileNamespace, Name: n.ProfileName})
+		if err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		match, err := selectorMatches(&notifier.Spec.ProfileSelector.LabelSelector, profile.GetLabels())
+		if err != nil || !match {
+			return false, err
+		}
+	}
+
+	if n.ClusterName != "" && len(notifier.Spec.ClusterSelector.LabelSelector.MatchLabels)+
+		len(notifier.Spec.ClusterSelector.LabelSelector.MatchExpressions) > 0 {
+
+		cluster, err := clusterproxy.GetCluster(ctx, c, n.ClusterNamespace, n.ClusterName,
+			libsveltosv1beta1.ClusterType(n.ClusterType))
+		if err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return selectorMatches(&notifier.Spec.ClusterSelector.LabelSelector, cluster.GetLabels())
+	}
+
+	return true, nil
+}
+
+func getNotifierDeduplicationWindow(notifier *configv1beta1.Notifier) time.Duration {
+	if notifier.Spec.DeduplicationWindow != nil {
+		return notifier.Spec.DeduplicationWindow.Duration
+	}
+	return defaultNotifierDeduplicationWindow
+}
+
+func isNotificationDuplicated(key string) bool {
+	deliveredNotificationsMux.Lock()
+	defer deliveredNotificationsMux.Unlock()
+
+	expiration, ok := deliveredNotifications[key]
+	return ok && time.Now().Before(expiration)
+}
+
+func recordDeliveredNotification(key string, window time.Duration) {
+	deliveredNotificationsMux.Lock()
+	defer deliveredNotificationsMux.Unlock()
+
+	now := time.Now()
+	for k, expiration := range deliveredNotifications {
+		if !now.Before(expiration) {
+			delete(deliveredNotifications, k)
+		}
+	}
+	deliveredNotifications[key] = now.Add(window)
+}
+
+// queueDelivery queues a notification for delivery to a Notifier sink, starting the Notifier
+// worker if needed. Notification is dropped if the Notifier queue is full.
+func queueDelivery(ctx context.Context, c client.Client, d *notificationDelivery, logger logr.Logger) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	notifierSinksMux.Lock()
+	defer notifierSinksMux.Unlock()
+
+	sink, ok := notifierSinks[d.notifier]
+	if !ok {
+		sink = &notifierSink{deliveries: make(chan *notificationDelivery, maxQueuedNotifierDeliveries)}
+		notifierSinks[d.notifier] = sink
+		go sink.run(ctx, c, d.notifier, logger.WithValues("worker", "notifier", "notifier", d.notifier))
+	}
+
+	select {
+	case sink.deliveries <- d:
+	default:
+		droppedNotificationsCounter.Inc()
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("notifier queue is full. Dropping notification %s", d.n.key()))
+	}
+}
+
+// run delivers notifications queued for a Notifier till context is cancelled or the Notifier is deleted
+func (s *notifierSink) run(ctx context.Context, c client.Client, notifierName string, logger logr.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-s.deliveries:
+			found, err := deliverNotification(ctx, c, d, logger)
+			if err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to deliver notification %s: %v", d.n.key(), err))
+			}
+			if !found && s.stop(notifierName) {
+				return
+			}
+		}
+	}
+}
+
+// stop removes the worker of a deleted Notifier, unless notifications are still queued
+func (s *notifierSink) stop(notifierName string) bool {
+	notifierSinksMux.Lock()
+	defer notifierSinksMux.Unlock()
+
+	if len(s.deliveries) != 0 {
+		return false
+	}
+	if notifierSinks[notifierName] == s {
+		delete(notifierSinks, notifierName)
+	}
+	return true
+}
+
+// deliverNotification makes one attempt at delivering a notification to a Notifier sink. On failure,
+// delivery is requeued once the backoff expires, up to Notifier MaxRetries times.
+// Returns false if the Notifier does not exist anymore.
+func deliverNotification(ctx context.Context, c client.Client, d *notificationDelivery,
+	logger logr.Logger) (found bool, err error) {
+
+	notifier := &configv1beta1.Notifier{}
+	if err := c.Get(ctx, types.NamespacedName{Name: d.notifier}, notifier); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return true, err
+	}
+
+	if isNotificationDuplicated(d.dedupKey) {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("notification %s already delivered", d.n.key()))
+		return true, nil
+	}
+
+	payload, err := renderNotification(notifier, d.n)
+	if err != nil {
+		return true, err
+	}
+
+	err = sendNotification(ctx, c, notifier, d.n, payload)
+	if err == nil {
+		recordDeliveredNotification(d.dedupKey, getNotifierDeduplicationWindow(notifier))
+		return true, nil
+	}
+	if d.attempt >= notifier.Spec.MaxRetries {
+		return true, err
+	}
+
+	delay := getNotificationRetryInterval(d.attempt)
+	d.attempt++
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to deliver notification (attempt %d): %v. Retrying in %s",
+		d.attempt, err, delay))
+	time.AfterFunc(delay, func() { queueDelivery(ctx, c, d, logger) })
+	return true, nil
+}
+
+// getNotificationRetryInterval returns the delay before retrying a delivery which already failed
+// attempt+1 times. It doubles at each retry.
+func getNotificationRetryInterval(attempt int32) time.Duration {
+	delay := notificationRetryInterval
+	for i := int32(0); i < attempt && delay < maxNotificationRetryInterval; i++ {
+		delay *= 2
+	}
+	return min(delay, maxNotificationRetryInterval)
+}
+
+// renderNotification returns the payload to deliver to a Notifier sink
+func renderNotification(notifier *configv1beta1.Notifier, n *notification) ([]byte, error) {
+	text := notifier.Spec.Template
+	if text == "" && notifier.Spec.Sink.Type == configv1beta1.NotifierSinkSlack {
+		text = defaultSlackTemplate
+	}
+
+	var payload []byte
+	if text == "" {
+		var err error
+		payload, err = json.Marshal(n)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		tmpl, err := template.New(notifier.Name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		var buffer bytes.Buffer
+		if err := tmpl.Execute(&buffer, n); err != nil {
+			return nil, fmt.Errorf("failed to render template: %w", err)
+		}
+		payload = buffer.Bytes()
+	}
+
+	if notifier.Spec.Sink.Type == configv1beta1.NotifierSinkSlack {
+		return json.Marshal(map[string]string{"text": string(payload)})
+	}
+	return payload, nil
+}
+
+func sendNotification(ctx context.Context, c client.Client, notifier *configv1beta1.Notifier,
+	n *notification, payload []byte) error {
+
+	switch notifier.Spec.Sink.Type {
+	case configv1beta1.NotifierSinkWebhook, configv1beta1.NotifierSinkSlack:
+		url, err := getNotifierURL(ctx, c, &notifier.Spec.Sink)
+		if err != nil {
+			return err
+		}
+		return postNotification(ctx, url, payload)
+	case configv1beta1.NotifierSinkConfigMap:
+		return storeNotification(ctx, c, &notifier.Spec.Sink, n, payload)
+	default:
+		return fmt.Errorf("unsupported sink type %q", notifier.Spec.Sink.Type)
+	}
+}
+
+func getNotifierURL(ctx context.Context, c client.Client, sink *configv1beta1.NotifierSink) (string, error) {
+	if sink.URL != "" {
+		return sink.URL, nil
+	}
+
+	if sink.URLSecretRef == nil {
+		return "", errors.New("neither url nor urlSecretRef is set")
+	}
+
+	namespace, err := getNotifierSinkNamespace(sink.URLSecretRef.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	secret := &corev1.Secret{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: sink.URLSecretRef.Name}, secret)
+	if err != nil {
+		return "", err
+	}
+
+	url, ok := secret.Data[notifierURLSecretKey]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s does not contain key %q", secret.Namespace, secret.Name, notifierURLSecretKey)
+	}
+	return strings.TrimSpace(string(url)), nil
+}
+
+// getNotifierSinkNamespace returns the namespace of a Secret/ConfigMap referenced by a sink.
+// An empty namespace defaults to the addon-controller namespace. Any other namespace is refused.
+func getNotifierSinkNamespace(namespace string) (string, error) {
+	if namespace == "" {
+		return notifierNamespace, nil
+	}
+	if namespace != notifierNamespace {
+		return "", fmt.Errorf("sink can only reference resources in namespace %s", notifierNamespace)
+	}
+	return namespace, nil
+}
+
+func postNotification(ctx context.Context, url string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, notificationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// storeNotification stores the latest notification per profile/cluster and transition in a ConfigMap
+func storeNotification(ctx context.Context, c client.Client, sink *configv1beta1.NotifierSink,
+	n *notification, payload []byte) error {
+
+	if sink.ConfigMapRef == nil {
+		return errors.New("configMapRef is not set")
+	}
+
+	namespace, err := getNotifierSinkNamespace(sink.ConfigMapRef.Namespace)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: sink.ConfigMapRef.Name},
+			configMap)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      sink.ConfigMapRef.Name,
+				},
+				Data: map[string]string{n.key(): string(payload)},
+			}
+			return c.Create(ctx, configMap)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[n.key()] = string(payload)
+		return c.Update(ctx, configMap)
+	})
+}
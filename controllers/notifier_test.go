@@ -0,0 +1,256 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Notifier", func() {
+	var sveltosCluster *libsveltosv1beta1.SveltosCluster
+	var clusterSummary *configv1beta1.ClusterSummary
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		controllers.SetNotificationRetryInterval(time.Millisecond)
+		ctx, cancel = context.WithCancel(context.TODO())
+
+		sveltosCluster = &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"env": "production"},
+			},
+		}
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sveltosCluster.Namespace,
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Kind:       configv1beta1.ClusterProfileKind,
+						Name:       randomString(),
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: sveltosCluster.Namespace,
+				ClusterName:      sveltosCluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+		}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("notify delivers to webhook with retries and deduplication", func() {
+		var mux sync.Mutex
+		var bodies []string
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.Lock()
+			defer mux.Unlock()
+			requests++
+			if requests == 1 {
+				// First attempt fails. Delivery is retried.
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+		}))
+		defer server.Close()
+
+		getRequests := func() int {
+			mux.Lock()
+			defer mux.Unlock()
+			return requests
+		}
+		getBodies := func() []string {
+			mux.Lock()
+			defer mux.Unlock()
+			return bodies
+		}
+
+		notifier := &configv1beta1.Notifier{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.NotifierSpec{
+				ClusterSelector: libsveltosv1beta1.Selector{
+					LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "production"}},
+				},
+				Transitions: []configv1beta1.NotificationTransition{configv1beta1.NotificationTransitionFailed},
+				Sink:        configv1beta1.NotifierSink{Type: configv1beta1.NotifierSinkWebhook, URL: server.URL},
+				Template:    `{"cluster": "{{.ClusterName}}", "status": "{{.Transition}}", "error": "{{.Message}}"}`,
+				MaxRetries:  2,
+			},
+		}
+
+		initObjects := []client.Object{sveltosCluster, notifier}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		n := controllers.NewClusterNotification(clusterSummary, string(configv1beta1.FeatureHelm),
+			configv1beta1.NotificationTransitionFailed, "chart not found")
+		Expect(controllers.Notify(ctx, c, n, logger)).To(Succeed())
+		Eventually(getBodies, time.Second, 10*time.Millisecond).Should(HaveLen(1))
+		Expect(getRequests()).To(Equal(2))
+		Expect(getBodies()[0]).To(ContainSubstring(sveltosCluster.Name))
+		Expect(getBodies()[0]).To(ContainSubstring("chart not found"))
+
+		// Same notification is deduplicated
+		Expect(controllers.Notify(ctx, c, n, logger)).To(Succeed())
+		Consistently(getRequests, 100*time.Millisecond, 10*time.Millisecond).Should(Equal(2))
+
+		// Transition Notifier is not subscribed to
+		n = controllers.NewClusterNotification(clusterSummary, string(configv1beta1.FeatureHelm),
+			configv1beta1.NotificationTransitionProvisioned, "deployed")
+		Expect(controllers.Notify(ctx, c, n, logger)).To(Succeed())
+		Consistently(getRequests, 100*time.Millisecond, 10*time.Millisecond).Should(Equal(2))
+	})
+
+	It("notify stores notifications in a ConfigMap sink", func() {
+		namespace := randomString()
+		controllers.SetNotifierNamespace(namespace)
+		defer controllers.SetNotifierNamespace("projectsveltos")
+
+		configMapRef := &corev1.ObjectReference{Namespace: namespace, Name: randomString()}
+		notifier := &configv1beta1.Notifier{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.NotifierSpec{
+				Sink: configv1beta1.NotifierSink{Type: configv1beta1.NotifierSinkConfigMap, ConfigMapRef: configMapRef},
+			},
+		}
+
+		initObjects := []client.Object{sveltosCluster, notifier}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		n := controllers.NewClusterNotification(clusterSummary, string(configv1beta1.FeatureResources),
+			configv1beta1.NotificationTransitionDriftDetected, "configuration drift detected")
+		Expect(controllers.Notify(ctx, c, n, logger)).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Eventually(func() error {
+			return c.Get(context.TODO(), types.NamespacedName{Namespace: configMapRef.Namespace, Name: configMapRef.Name},
+				configMap)
+		}, time.Second, 10*time.Millisecond).Should(Succeed())
+		Expect(configMap.Data).To(HaveLen(1))
+		for k, v := range configMap.Data {
+			Expect(k).To(ContainSubstring(string(configv1beta1.NotificationTransitionDriftDetected)))
+			Expect(v).To(ContainSubstring(sveltosCluster.Name))
+		}
+	})
+
+	It("notify refuses sinks referencing resources outside the addon-controller namespace", func() {
+		configMapRef := &corev1.ObjectReference{Namespace: "kube-system", Name: randomString()}
+		notifier := &configv1beta1.Notifier{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.NotifierSpec{
+				Sink: configv1beta1.NotifierSink{Type: configv1beta1.NotifierSinkConfigMap, ConfigMapRef: configMapRef},
+			},
+		}
+
+		initObjects := []client.Object{sveltosCluster, notifier}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		n := controllers.NewClusterNotification(clusterSummary, string(configv1beta1.FeatureResources),
+			configv1beta1.NotificationTransitionDriftDetected, "configuration drift detected")
+		Expect(controllers.Notify(ctx, c, n, logger)).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Consistently(func() bool {
+			err := c.Get(context.TODO(), types.NamespacedName{Namespace: configMapRef.Namespace, Name: configMapRef.Name},
+				configMap)
+			return apierrors.IsNotFound(err)
+		}, 100*time.Millisecond, 10*time.Millisecond).Should(BeTrue())
+	})
+
+	It("an unreachable endpoint does not delay notifications to other Notifiers", func() {
+		var mux sync.Mutex
+		failedRequests := 0
+		failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.Lock()
+			failedRequests++
+			mux.Unlock()
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer failingServer.Close()
+
+		delivered := make(chan struct{}, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivered <- struct{}{}
+		}))
+		defer server.Close()
+
+		failingNotifier := &configv1beta1.Notifier{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.NotifierSpec{
+				Sink:       configv1beta1.NotifierSink{Type: configv1beta1.NotifierSinkWebhook, URL: failingServer.URL},
+				MaxRetries: 10,
+			},
+		}
+		notifier := &configv1beta1.Notifier{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.NotifierSpec{
+				Sink: configv1beta1.NotifierSink{Type: configv1beta1.NotifierSinkWebhook, URL: server.URL},
+			},
+		}
+
+		initObjects := []client.Object{sveltosCluster, failingNotifier, notifier}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		n := controllers.NewClusterNotification(clusterSummary, string(configv1beta1.FeatureHelm),
+			configv1beta1.NotificationTransitionFailed, randomString())
+		Expect(controllers.Notify(ctx, c, n, logger)).To(Succeed())
+		Eventually(delivered, 150*time.Millisecond).Should(Receive())
+
+		// Failing endpoint keeps being retried
+		Eventually(func() int {
+			mux.Lock()
+			defer mux.Unlock()
+			return failedRequests
+		}, 2*time.Second, 10*time.Millisecond).Should(BeNumerically(">", 1))
+	})
+})
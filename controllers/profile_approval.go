@@ -0,0 +1,461 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/gdexlab/go-render/render"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+// Approval workflow
+// When a ClusterProfile/Profile has ApprovalRequired set, every Spec produces a plan identified
+// by the hash of the Spec and of the content of the resources it references (ConfigMaps, Secrets
+// and Flux sources) for each matching cluster. The plan hash is computed once per ClusterProfile/Profile
+// reconciliation and stored in Status.Plan.
+// Till the plan is approved:
+// - ClusterSummaries running an approved plan keep it, with their SyncMode (so drift detection keeps
+// working). Those do not deploy anything new, since any change to the Spec or to a referenced resource
+// requires approval, and report PlanPendingApproval;
+// - any other ClusterSummary gets the new Spec in DryRun mode and only fills ClusterReports with what
+// would change in its cluster.
+// Once approved, ClusterSummaries get the new Spec, with the profile SyncMode, and the plan hash
+// (ClusterSummary Spec.PlanHash) and the plan is applied.
+// Because the approval carries the plan hash, any Spec change or any change to a referenced resource
+// after approval produces a new plan and the approval does not apply anymore.
+
+// getPlanHash returns the hash of the ClusterProfile/Profile plan. An approval must carry this hash.
+func getPlanHash(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) (string, error) {
+	return computePlanHash(ctx, c, profileScope.GetSpec(), profileScope.GetStatus().MatchingClusterRefs)
+}
+
+// computePlanHash returns the hash of the Spec and of the content of the resources referenced
+// by the Spec, instantiated for each matching cluster. Hash does not depend on the order of
+// matchingClusters.
+func computePlanHash(ctx context.Context, c client.Client, spec *configv1beta1.Spec,
+	clusters []corev1.ObjectReference) (string, error) {
+
+	matchingClusters := make([]corev1.ObjectReference, len(clusters))
+	copy(matchingClusters, clusters)
+	sortClusterRefs(matchingClusters)
+
+	h := sha256.New()
+	config := render.AsCode(spec)
+
+	references := getPlanReferences(spec)
+	for i := range matchingClusters {
+		cluster := &matchingClusters[i]
+		clusterType := clusterproxy.GetClusterType(cluster)
+		for j := range references {
+			ref := &references[j]
+			namespace, name, err := getPlanReferenceNamespacedName(ctx, c, cluster, clusterType, ref)
+			if err != nil {
+				return "", err
+			}
+
+			referenceHash, err := getPlanReferenceHash(ctx, c, ref.Kind, namespace, name)
+			if err != nil {
+				return "", err
+			}
+			config += fmt.Sprintf("%s:%s/%s:%s", ref.Kind, namespace, name, referenceHash)
+		}
+	}
+
+	h.Write([]byte(config))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// getPlanReferenceNamespacedName returns namespace and name of a referenced resource for a given
+// cluster. Only templates need the cluster to be instantiated.
+func getPlanReferenceNamespacedName(ctx context.Context, c client.Client, cluster *corev1.ObjectReference,
+	clusterType libsveltosv1beta1.ClusterType, ref *corev1.ObjectReference) (namespace, name string, err error) {
+
+	namespace = ref.Namespace
+	if namespace == "" {
+		namespace = cluster.Namespace
+	} else if strings.Contains(namespace, "{{") {
+		namespace, err = libsveltostemplate.GetReferenceResourceNamespace(ctx, c,
+			cluster.Namespace, cluster.Name, ref.Namespace, clusterType)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	name = ref.Name
+	if strings.Contains(name, "{{") {
+		name, err = libsveltostemplate.GetReferenceResourceName(ctx, c,
+			cluster.Namespace, cluster.Name, ref.Name, clusterType)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return namespace, name, nil
+}
+
+// getPlanReferences returns all resources referenced by the Spec whose content is deployed:
+// PolicyRefs, KustomizationRefs (and their ValuesFrom) and helm charts ValuesFrom
+func getPlanReferences(spec *configv1beta1.Spec) []corev1.ObjectReference {
+	references := make([]corev1.ObjectReference, 0)
+	for i := range spec.PolicyRefs {
+		ref := &spec.PolicyRefs[i]
+		references = append(references,
+			corev1.ObjectReference{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name})
+	}
+	for i := range spec.KustomizationRefs {
+		ref := &spec.KustomizationRefs[i]
+		references = append(references,
+			corev1.ObjectReference{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name})
+		for j := range ref.ValuesFrom {
+			references = append(references, corev1.ObjectReference{Kind: ref.ValuesFrom[j].Kind,
+				Namespace: ref.ValuesFrom[j].Namespace, Name: ref.ValuesFrom[j].Name})
+		}
+	}
+	for i := range spec.HelmCharts {
+		chart := &spec.HelmCharts[i]
+		for j := range chart.ValuesFrom {
+			references = append(references, corev1.ObjectReference{Kind: chart.ValuesFrom[j].Kind,
+				Namespace: chart.ValuesFrom[j].Namespace, Name: chart.ValuesFrom[j].Name})
+		}
+	}
+
+	return references
+}
+
+// getPlanReferenceHash returns the content hash of a referenced ConfigMap/Secret or the artifact
+// revision of a referenced Flux source. A missing resource has an empty hash.
+func getPlanReferenceHash(ctx context.Context, c client.Client, kind, namespace, name string,
+) (string, error) {
+
+	switch kind {
+	case string(libsveltosv1beta1.ConfigMapReferencedResourceKind):
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap); err != nil {
+			return "", client.IgnoreNotFound(err)
+		}
+		return getConfigMapHash(configMap), nil
+	case string(libsveltosv1beta1.SecretReferencedResourceKind):
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			return "", client.IgnoreNotFound(err)
+		}
+		return getSecretHash(secret), nil
+	default:
+		source, err := getSource(ctx, c, namespace, name, kind)
+		if err != nil {
+			return "", client.IgnoreNotFound(err)
+		}
+		if source == nil {
+			return "", nil
+		}
+		var config string
+		if s, ok := source.(sourcev1.Source); ok && s.GetArtifact() != nil {
+			config += s.GetArtifact().Revision
+		}
+		if source.GetAnnotations() != nil {
+			config += getDataSectionHash(source.GetAnnotations())
+		}
+		return config, nil
+	}
+}
+
+// updatePlan updates Status.Plan for ClusterProfiles/Profiles requiring approval
+func updatePlan(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
+	status := profileScope.GetStatus()
+	if !isApprovalRequired(profileScope) {
+		status.Plan = nil
+		return nil
+	}
+
+	hash, err := getPlanHash(ctx, c, profileScope)
+	if err != nil {
+		return err
+	}
+	approved, err := isPlanApproved(ctx, c, profileScope, hash)
+	if err != nil {
+		return err
+	}
+
+	plan := &configv1beta1.Plan{Hash: hash, Approved: approved}
+	if !approved {
+		plan.ClusterReports, err = getPlanClusterReports(ctx, c, profileScope)
+		if err != nil {
+			return err
+		}
+	}
+	status.Plan = plan
+
+	return nil
+}
+
+// isPlanApproved returns true if the plan with the given hash is approved, either by the
+// ApprovedPlanAnnotation on the profile or by a ProfileApproval
+func isPlanApproved(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	hash string) (bool, error) {
+
+	annotations := profileScope.Profile.GetAnnotations()
+	if annotations != nil && annotations[configv1beta1.ApprovedPlanAnnotation] == hash {
+		return true, nil
+	}
+
+	approvals := &configv1beta1.ProfileApprovalList{}
+	if err := c.List(ctx, approvals); err != nil {
+		return false, err
+	}
+
+	for i := range approvals.Items {
+		approval := &approvals.Items[i]
+		if isApprovalForProfile(approval, profileScope.GetKind(), profileScope.Profile.GetNamespace(),
+			profileScope.Name()) && approval.Spec.PlanHash == hash {
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isApprovalForProfile(approval *configv1beta1.ProfileApproval, kind, namespace, name string) bool {
+	ref := &approval.Spec.ProfileRef
+	if ref.Kind != kind || ref.Name != name {
+		return false
+	}
+	return kind == configv1beta1.ClusterProfileKind || ref.Namespace == namespace
+}
+
+// getPlanClusterReports returns the ClusterReports reporting what the plan would change, one per
+// matching cluster not running an approved plan
+func getPlanClusterReports(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+) ([]corev1.ObjectReference, error) {
+
+	matchingClusters := profileScope.GetStatus().MatchingClusterRefs
+	reports := make([]corev1.ObjectReference, 0, len(matchingClusters))
+	for i := range matchingClusters {
+		cluster := &matchingClusters[i]
+		clusterType := clusterproxy.GetClusterType(cluster)
+		clusterSummary, err := getClusterSummary(ctx, c, profileScope.GetKind(), profileScope.Name(),
+			cluster.Namespace, cluster.Name, clusterType)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && isRunningApprovedPlan(clusterSummary) {
+			continue
+		}
+		reports = append(reports, corev1.ObjectReference{
+			Kind:       configv1beta1.ClusterReportKind,
+			APIVersion: configv1beta1.GroupVersion.String(),
+			Namespace:  cluster.Namespace,
+			Name:       getClusterReportName(profileScope.GetKind(), profileScope.Name(), cluster.Name, clusterType),
+		})
+	}
+	return reports, nil
+}
+
+// isRunningApprovedPlan returns true if ClusterSummary is deploying (and not just evaluating in DryRun
+// mode) its Spec
+func isRunningApprovedPlan(clusterSummary *configv1beta1.ClusterSummary) bool {
+	return clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1beta1.SyncModeDryRun
+}
+
+// isApprovalRequired returns true if changes to the ClusterProfile/Profile must be approved.
+// Approval only applies to profiles continuously syncing matching clusters.
+func isApprovalRequired(profileScope *scope.ProfileScope) bool {
+	return profileScope.GetSpec().ApprovalRequired && profileScope.IsContinuousSync()
+}
+
+// isPlanPendingApproval returns true if the current ClusterProfile/Profile plan requires an
+// approval which has not been given yet. Status.Plan must have been updated by updatePlan first.
+func isPlanPendingApproval(profileScope *scope.ProfileScope) bool {
+	if !isApprovalRequired(profileScope) {
+		return false
+	}
+
+	plan := profileScope.GetStatus().Plan
+	return plan == nil || !plan.Approved
+}
+
+// isApprovedPlanStale returns true if the ClusterProfile/Profile owning the ClusterSummary requires
+// approval and the ClusterSummary runs an approved plan which is not the current plan anymore, for
+// instance because Spec or a referenced ConfigMap changed. Such a ClusterSummary must not deploy till
+// the new plan has been approved. Current plan hash is the one computed by the ClusterProfile/Profile
+// reconciler.
+func isApprovedPlanStale(ctx context.Context, c client.Client,
+	clusterSummary *configv1beta1.ClusterSummary) (bool, error) {
+
+	if !clusterSummary.Spec.ClusterProfileSpec.ApprovalRequired || !isRunningApprovedPlan(clusterSummary) {
+		return false, nil
+	}
+
+	ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || ownerRef == nil {
+		return false, err
+	}
+
+	ref := &configv1beta1.ProfileReference{Kind: ownerRef.Kind, Name: ownerRef.Name}
+	if ownerRef.Kind == configv1beta1.ProfileKind {
+		ref.Namespace = clusterSummary.Namespace
+	}
+
+	_, _, status, err := getReferencedProfile(ctx, c, ref)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	plan := status.Plan
+	return plan == nil || !plan.Approved || plan.Hash != clusterSummary.Spec.PlanHash, nil
+}
+
+// setPlanPendingApproval reports, on each feature, whether the ClusterSummary is not deploying because
+// the ClusterProfile/Profile plan is pending approval
+func setPlanPendingApproval(clusterSummaryScope *scope.ClusterSummaryScope, pending bool) {
+	spec := &clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec
+	features := map[configv1beta1.FeatureID]bool{
+		configv1beta1.FeatureResources: len(spec.PolicyRefs) != 0,
+		configv1beta1.FeatureHelm:      len(spec.HelmCharts) != 0,
+		configv1beta1.FeatureKustomize: len(spec.KustomizationRefs) != 0,
+	}
+
+	for featureID, configured := range features {
+		fs := getFeatureSummaryForFeatureID(clusterSummaryScope.ClusterSummary, featureID)
+		if !pending || !configured {
+			if fs != nil && fs.FailureReason != nil && *fs.FailureReason == configv1beta1.PlanPendingApprovalReason {
+				clusterSummaryScope.SetFailureReason(featureID, nil)
+				clusterSummaryScope.SetFailureMessage(featureID, nil)
+			}
+			continue
+		}
+
+		if fs == nil {
+			clusterSummaryScope.SetFeatureStatus(featureID, configv1beta1.FeatureStatusProvisioning, nil, nil)
+		}
+		reason := configv1beta1.PlanPendingApprovalReason
+		clusterSummaryScope.SetFailureReason(featureID, &reason)
+		message := "profile plan changed and is pending approval. Approved plan is kept"
+		clusterSummaryScope.SetFailureMessage(featureID, &message)
+	}
+}
+
+// requeueForPlanReference returns the ClusterProfiles/Profiles of the given kind requiring approval
+// which reference the given ConfigMap/Secret, so that their plan is recomputed
+func requeueForPlanReference(ctx context.Context, c client.Client, o client.Object, kind string,
+) []reconcile.Request {
+
+	referenceKind := string(libsveltosv1beta1.ConfigMapReferencedResourceKind)
+	if _, ok := o.(*corev1.Secret); ok {
+		referenceKind = string(libsveltosv1beta1.SecretReferencedResourceKind)
+	}
+
+	requests := make([]reconcile.Request, 0)
+	addIfReferenced := func(namespace, name string, spec *configv1beta1.Spec) {
+		if !spec.ApprovalRequired {
+			return
+		}
+		references := getPlanReferences(spec)
+		for i := range references {
+			// Names can be templates instantiated per cluster. Requeue whenever the name
+			// cannot be compared.
+			if references[i].Kind == referenceKind &&
+				(references[i].Name == o.GetName() || strings.Contains(references[i].Name, "{{")) {
+
+				requests = append(requests,
+					reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+				return
+			}
+		}
+	}
+
+	if kind == configv1beta1.ClusterProfileKind {
+		clusterProfiles := &configv1beta1.ClusterProfileList{}
+		if err := c.List(ctx, clusterProfiles); err != nil {
+			return nil
+		}
+		for i := range clusterProfiles.Items {
+			cp := &clusterProfiles.Items[i]
+			addIfReferenced("", cp.Name, &cp.Spec)
+		}
+		return requests
+	}
+
+	profiles := &configv1beta1.ProfileList{}
+	if err := c.List(ctx, profiles, client.InNamespace(o.GetNamespace())); err != nil {
+		return nil
+	}
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		addIfReferenced(profile.Namespace, profile.Name, &profile.Spec)
+	}
+	return requests
+}
+
+// getClusterSummarySpec returns the Spec for a ClusterSummary created by this ClusterProfile/Profile and
+// the hash of the approved plan such Spec belongs to. clusterSummary is the existing ClusterSummary, if any.
+// While a plan is pending approval, a ClusterSummary running an approved plan keeps it, while any other
+// ClusterSummary gets the new Spec in DryRun mode so that its cluster only reports what would change.
+func getClusterSummarySpec(profileScope *scope.ProfileScope, clusterSummary *configv1beta1.ClusterSummary,
+) (spec *configv1beta1.Spec, planHash string) {
+
+	spec = profileScope.GetSpec()
+	if !isApprovalRequired(profileScope) {
+		return spec, ""
+	}
+
+	if !isPlanPendingApproval(profileScope) {
+		return spec, profileScope.GetStatus().Plan.Hash
+	}
+
+	if clusterSummary != nil && isRunningApprovedPlan(clusterSummary) {
+		return &clusterSummary.Spec.ClusterProfileSpec, clusterSummary.Spec.PlanHash
+	}
+
+	pending := spec.DeepCopy()
+	pending.SyncMode = configv1beta1.SyncModeDryRun
+	return pending, ""
+}
+
+// requeueForProfileApproval returns the ClusterProfile/Profile of the given kind a ProfileApproval
+// is for
+func requeueForProfileApproval(o client.Object, kind string) []reconcile.Request {
+	approval, ok := o.(*configv1beta1.ProfileApproval)
+	if !ok || approval.Spec.ProfileRef.Kind != kind {
+		return nil
+	}
+
+	namespace := approval.Spec.ProfileRef.Namespace
+	if kind == configv1beta1.ClusterProfileKind {
+		namespace = ""
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: namespace, Name: approval.Spec.ProfileRef.Name}},
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Profile approval", func() {
+	var clusterProfile *configv1beta1.ClusterProfile
+
+	BeforeEach(func() {
+		clusterProfile = &configv1beta1.ClusterProfile{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       configv1beta1.ClusterProfileKind,
+				APIVersion: configv1beta1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				SyncMode:         configv1beta1.SyncModeContinuous,
+				ApprovalRequired: true,
+				PolicyRefs: []configv1beta1.PolicyRef{
+					{Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Namespace: randomString(), Name: randomString()},
+				},
+			},
+			Status: configv1beta1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{
+					{
+						Namespace: randomString(), Name: randomString(),
+						Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+					},
+				},
+			},
+		}
+	})
+
+	getProfileScope := func(c client.Client) *scope.ProfileScope {
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		return profileScope
+	}
+
+	getSyncMode := func(profileScope *scope.ProfileScope, clusterSummary *configv1beta1.ClusterSummary,
+	) configv1beta1.SyncMode {
+
+		spec, _ := controllers.GetClusterSummarySpec(profileScope, clusterSummary)
+		return spec.SyncMode
+	}
+
+	It("keeps ClusterSummaries in DryRun till the plan is approved with a ProfileApproval", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		profileScope := getProfileScope(c)
+
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		plan := clusterProfile.Status.Plan
+		Expect(plan).ToNot(BeNil())
+		Expect(plan.Approved).To(BeFalse())
+		hash, err := controllers.GetPlanHash(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		Expect(plan.Hash).To(Equal(hash))
+		Expect(len(plan.ClusterReports)).To(Equal(1))
+		Expect(getSyncMode(profileScope, nil)).To(Equal(configv1beta1.SyncModeDryRun))
+		// Profile Spec is never modified
+		Expect(clusterProfile.Spec.SyncMode).To(Equal(configv1beta1.SyncModeContinuous))
+
+		approval := &configv1beta1.ProfileApproval{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.ProfileApprovalSpec{
+				ProfileRef: configv1beta1.ProfileReference{
+					Kind: configv1beta1.ClusterProfileKind, Name: clusterProfile.Name,
+				},
+				PlanHash: plan.Hash,
+			},
+		}
+		Expect(c.Create(context.TODO(), approval)).To(Succeed())
+
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(clusterProfile.Status.Plan.Approved).To(BeTrue())
+		Expect(clusterProfile.Status.Plan.ClusterReports).To(BeNil())
+		Expect(getSyncMode(profileScope, nil)).To(Equal(configv1beta1.SyncModeContinuous))
+
+		// Any Spec change produces a new plan which needs a new approval
+		clusterProfile.Spec.PolicyRefs[0].Name = randomString()
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(clusterProfile.Status.Plan.Approved).To(BeFalse())
+		Expect(clusterProfile.Status.Plan.Hash).ToNot(Equal(approval.Spec.PlanHash))
+		Expect(getSyncMode(profileScope, nil)).To(Equal(configv1beta1.SyncModeDryRun))
+	})
+
+	It("accepts approvals via annotation and ignores ApprovalRequired for non continuous SyncMode", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		profileScope := getProfileScope(c)
+
+		hash, err := controllers.GetPlanHash(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		clusterProfile.Annotations = map[string]string{
+			configv1beta1.ApprovedPlanAnnotation: hash,
+		}
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(clusterProfile.Status.Plan.Approved).To(BeTrue())
+		Expect(getSyncMode(profileScope, nil)).To(Equal(configv1beta1.SyncModeContinuous))
+
+		clusterProfile.Spec.SyncMode = configv1beta1.SyncModeOneTime
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(clusterProfile.Status.Plan).To(BeNil())
+		Expect(getSyncMode(profileScope, nil)).To(Equal(configv1beta1.SyncModeOneTime))
+	})
+
+	It("invalidates the approval when a referenced ConfigMap changes", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterProfile.Spec.PolicyRefs[0].Namespace,
+				Name:      clusterProfile.Spec.PolicyRefs[0].Name,
+			},
+			Data: map[string]string{"policy": randomString()},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile, configMap).Build()
+		profileScope := getProfileScope(c)
+
+		hash, err := controllers.GetPlanHash(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		clusterProfile.Annotations = map[string]string{
+			configv1beta1.ApprovedPlanAnnotation: hash,
+		}
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(clusterProfile.Status.Plan.Approved).To(BeTrue())
+
+		configMap.Data = map[string]string{"policy": randomString()}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(clusterProfile.Status.Plan.Approved).To(BeFalse())
+		Expect(clusterProfile.Status.Plan.Hash).ToNot(Equal(hash))
+		Expect(getSyncMode(profileScope, nil)).To(Equal(configv1beta1.SyncModeDryRun))
+	})
+	It("keeps the approved plan on ClusterSummaries while a new plan is pending approval", func() {
+		cluster := &clusterProfile.Status.MatchingClusterRefs[0]
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+				Labels: map[string]string{
+					controllers.ClusterProfileLabelName: clusterProfile.Name,
+					configv1beta1.ClusterNameLabel:      cluster.Name,
+					configv1beta1.ClusterTypeLabel:      string(libsveltosv1beta1.ClusterTypeSveltos),
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Kind:       configv1beta1.ClusterProfileKind,
+						Name:       clusterProfile.Name,
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		profileScope := getProfileScope(c)
+
+		hash, err := controllers.GetPlanHash(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		clusterProfile.Annotations = map[string]string{
+			configv1beta1.ApprovedPlanAnnotation: hash,
+		}
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(c.Update(context.TODO(), clusterProfile)).To(Succeed())
+
+		spec, planHash := controllers.GetClusterSummarySpec(profileScope, nil)
+		Expect(planHash).To(Equal(hash))
+		clusterSummary.Spec.ClusterProfileSpec = *spec
+		clusterSummary.Spec.PlanHash = planHash
+		Expect(c.Create(context.TODO(), clusterSummary)).To(Succeed())
+
+		stale, err := controllers.IsApprovedPlanStale(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(stale).To(BeFalse())
+
+		// Spec changes. New plan is pending approval
+		clusterProfile.Spec.PolicyRefs[0].Name = randomString()
+		Expect(controllers.UpdatePlan(context.TODO(), c, profileScope)).To(Succeed())
+		Expect(c.Update(context.TODO(), clusterProfile)).To(Succeed())
+		Expect(clusterProfile.Status.Plan.Approved).To(BeFalse())
+
+		// Cluster runs the approved plan, with its SyncMode, so no plan report for it
+		Expect(clusterProfile.Status.Plan.ClusterReports).To(BeEmpty())
+		spec, planHash = controllers.GetClusterSummarySpec(profileScope, clusterSummary)
+		Expect(spec.SyncMode).To(Equal(configv1beta1.SyncModeContinuous))
+		Expect(spec.PolicyRefs[0].Name).To(Equal(clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[0].Name))
+		Expect(planHash).To(Equal(hash))
+		// Clusters not running an approved plan get the new Spec in DryRun mode
+		Expect(getSyncMode(profileScope, nil)).To(Equal(configv1beta1.SyncModeDryRun))
+
+		// ClusterSummary does not deploy till the new plan is approved
+		stale, err = controllers.IsApprovedPlanStale(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(stale).To(BeTrue())
+	})
+
+	It("plan hash does not depend on the order of matching clusters", func() {
+		clusterProfile.Status.MatchingClusterRefs = append(clusterProfile.Status.MatchingClusterRefs,
+			corev1.ObjectReference{
+				Namespace: randomString(), Name: randomString(),
+				Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			})
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		profileScope := getProfileScope(c)
+
+		hash, err := controllers.GetPlanHash(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+
+		refs := clusterProfile.Status.MatchingClusterRefs
+		refs[0], refs[1] = refs[1], refs[0]
+		reversedHash, err := controllers.GetPlanHash(context.TODO(), c, profileScope)
+		Expect(err).To(BeNil())
+		Expect(reversedHash).To(Equal(hash))
+	})
+})
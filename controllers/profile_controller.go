@@ -24,7 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -53,6 +53,7 @@ type ProfileReconciler struct {
 	Scheme               *runtime.Scheme
 	ConcurrentReconciles int
 	Logger               logr.Logger
+	EventRecorder        record.EventRecorder
 
 	// use a Mutex to update Map as MaxConcurrentReconciles is higher than one
 	Mux sync.Mutex
@@ -108,6 +109,7 @@ type ProfileReconciler struct {
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clustersummaries,verbs=get;list;update;create;delete
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterreports,verbs=get;list;update;create;watch;delete
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterconfigurations,verbs=get;list;update;create;watch;delete
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=profileapprovals,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;watch;list
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters/status,verbs=get;watch;list
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;watch;list
@@ -116,6 +118,7 @@ type ProfileReconciler struct {
 //+kubebuilder:rbac:groups=lib.projectsveltos.io,resources=sveltosclusters/status,verbs=get;watch;list
 
 func (r *ProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx = withEventRecorder(ctx, r.EventRecorder)
 	logger := ctrl.LoggerFrom(ctx)
 	logger.V(logs.LogInfo).Info("Reconciling")
 
@@ -222,6 +225,12 @@ func (r *ProfileReconciler) reconcileNormal(
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
 
+	if isCircuitBreakerTripped(profileScope.GetStatus()) {
+		// Reconcile again to reset the circuit breaker once CoolDown elapses
+		logger.V(logs.LogInfo).Info("circuit breaker is tripped")
+		return reconcile.Result{Requeue: true, RequeueAfter: circuitBreakerRequeueAfter}
+	}
+
 	logger.V(logs.LogInfo).Info("Reconcile success")
 	return reconcile.Result{}
 }
@@ -239,6 +248,21 @@ func (r *ProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				SetPredicates(mgr.GetLogger().WithValues("predicate", "setpredicate")),
 			),
 		).
+		Watches(&configv1beta1.ProfileApproval{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileForProfileApproval),
+		).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileForReference),
+			builder.WithPredicates(
+				ConfigMapPredicates(mgr.GetLogger().WithValues("predicate", "configmappredicate")),
+			),
+		).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileForReference),
+			builder.WithPredicates(
+				SecretPredicates(mgr.GetLogger().WithValues("predicate", "secretpredicate")),
+			),
+		).
 		Watches(&libsveltosv1beta1.SveltosCluster{},
 			handler.EnqueueRequestsFromMapFunc(r.requeueProfileForSveltosCluster),
 			builder.WithPredicates(
@@ -394,24 +418,5 @@ func (r *ProfileReconciler) GetController() controller.Controller {
 func (r *ProfileReconciler) getClustersFromSets(ctx context.Context, namespace string, setRefs []string,
 	logger logr.Logger) ([]corev1.ObjectReference, error) {
 
-	clusters := make([]corev1.ObjectReference, 0)
-	for i := range setRefs {
-		set := &libsveltosv1beta1.Set{}
-		if err := r.Client.Get(ctx,
-			types.NamespacedName{Namespace: namespace, Name: setRefs[i]},
-			set); err != nil {
-			if apierrors.IsNotFound(err) {
-				continue
-			}
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get set %s/%s",
-				namespace, setRefs[i]))
-			return nil, err
-		}
-
-		if set.Status.SelectedClusterRefs != nil {
-			clusters = append(clusters, set.Status.SelectedClusterRefs...)
-		}
-	}
-
-	return clusters, nil
+	return getClustersFromSets(ctx, r.Client, namespace, setRefs, logger)
 }
@@ -0,0 +1,515 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Within a profile, PolicyRefs, HelmCharts and KustomizationRefs can depend on each other (DependsOn),
+// also across features. Each feature deploys its entries in waves: an entry always belongs to a later
+// wave than the entries of the same feature it depends on.
+// - before a wave is deployed, dependencies belonging to other features must be deployed and ready;
+// - before next wave is deployed, entries of the current wave other entries depend on must be ready.
+// While a dependency is not ready, feature stays Provisioning and the blocking entry is reported.
+// On withdrawal, a feature is undeployed only once features containing dependent entries are removed,
+// and helm charts are uninstalled in reverse order.
+
+// DependencyNotReadyError is returned when deploying (or withdrawing) a feature is blocked waiting
+// for another entry of the same profile.
+type DependencyNotReadyError struct {
+	Message string
+}
+
+func (e *DependencyNotReadyError) Error() string {
+	return e.Message
+}
+
+// profileEntry is a PolicyRef, HelmChart or KustomizationRef of a profile
+type profileEntry struct {
+	identifier string
+	featureID  configv1beta1.FeatureID
+	index      int
+	dependsOn  []string
+
+	// set for PolicyRefs and KustomizationRefs
+	reference *waitReference
+	local     bool
+
+	// set for HelmCharts
+	releaseNamespace string
+	releaseName      string
+}
+
+func (e *profileEntry) String() string {
+	if e.identifier != "" {
+		return e.identifier
+	}
+
+	switch e.featureID {
+	case configv1beta1.FeatureHelm:
+		return fmt.Sprintf("helm chart %s/%s", e.releaseNamespace, e.releaseName)
+	default:
+		return fmt.Sprintf("%s %s %s/%s", strings.ToLower(string(e.featureID)), e.reference.kind,
+			e.reference.namespace, e.reference.name)
+	}
+}
+
+func getProfileEntries(spec *configv1beta1.Spec) []profileEntry {
+	entries := make([]profileEntry, 0, len(spec.PolicyRefs)+len(spec.HelmCharts)+len(spec.KustomizationRefs))
+	for i := range spec.PolicyRefs {
+		ref := &spec.PolicyRefs[i]
+		entries = append(entries, profileEntry{
+			identifier: ref.Identifier, featureID: configv1beta1.FeatureResources, index: i, dependsOn: ref.DependsOn,
+			reference: &waitReference{namespace: ref.Namespace, name: ref.Name, kind: ref.Kind, instantiate: true},
+			local:     ref.DeploymentType == configv1beta1.DeploymentTypeLocal,
+		})
+	}
+	for i := range spec.HelmCharts {
+		chart := &spec.HelmCharts[i]
+		entries = append(entries, profileEntry{
+			identifier: chart.Identifier, featureID: configv1beta1.FeatureHelm, index: i, dependsOn: chart.DependsOn,
+			releaseNamespace: chart.ReleaseNamespace, releaseName: chart.ReleaseName,
+		})
+	}
+	for i := range spec.KustomizationRefs {
+		ref := &spec.KustomizationRefs[i]
+		entries = append(entries, profileEntry{
+			identifier: ref.Identifier, featureID: configv1beta1.FeatureKustomize, index: i, dependsOn: ref.DependsOn,
+			reference: &waitReference{namespace: ref.Namespace, name: ref.Name, kind: ref.Kind},
+			local:     ref.DeploymentType == configv1beta1.DeploymentTypeLocal,
+		})
+	}
+	return entries
+}
+
+func hasEntryDependencies(spec *configv1beta1.Spec) bool {
+	entries := getProfileEntries(spec)
+	for i := range entries {
+		if len(entries[i].dependsOn) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getEntriesByIdentifier returns entries with an Identifier. An error is returned if Identifiers
+// are not unique.
+func getEntriesByIdentifier(entries []profileEntry) (map[string]*profileEntry, error) {
+	result := make(map[string]*profileEntry)
+	for i := range entries {
+		if entries[i].identifier == "" {
+			continue
+		}
+		if _, ok := result[entries[i].identifier]; ok {
+			return nil, &NonRetriableError{Message: fmt.Sprintf("identifier %s is used by more than one entry",
+				entries[i].identifier)}
+		}
+		result[entries[i].identifier] = &entries[i]
+	}
+	return result, nil
+}
+
+// validateEntryDependencies verifies Identifiers are unique, DependsOn only references existing
+// entries and there are no cycles
+func validateEntryDependencies(spec *configv1beta1.Spec) error {
+	entries := getProfileEntries(spec)
+	byID, err := getEntriesByIdentifier(entries)
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		for _, dep := range entries[i].dependsOn {
+			if _, ok := byID[dep]; !ok {
+				return &NonRetriableError{Message: fmt.Sprintf("%s depends on %s which does not exist",
+					entries[i].String(), dep)}
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(byID))
+	var visit func(id string, path []string) error
+	visit = func(id string, path []string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			return &NonRetriableError{Message: fmt.Sprintf("dependency cycle detected: %s",
+				strings.Join(append(path, id), " -> "))}
+		}
+		state[id] = visiting
+		for _, dep := range byID[id].dependsOn {
+			if err := visit(dep, append(path, id)); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+
+	for i := range entries {
+		for _, dep := range entries[i].dependsOn {
+			if err := visit(dep, []string{entries[i].String()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getDeploymentWaves returns, for the given feature, the indexes of its entries grouped in waves.
+// Entries of a wave only depend on entries (of the same feature) in previous waves. Within a wave,
+// entries keep the order they are listed with. With no dependencies, there is a single wave.
+func getDeploymentWaves(spec *configv1beta1.Spec, featureID configv1beta1.FeatureID) ([][]int, error) {
+	if err := validateEntryDependencies(spec); err != nil {
+		return nil, err
+	}
+
+	entries := getProfileEntries(spec)
+	byID, _ := getEntriesByIdentifier(entries)
+
+	levels := make(map[int]int)
+	var getLevel func(e *profileEntry) int
+	getLevel = func(e *profileEntry) int {
+		if level, ok := levels[e.index]; ok {
+			return level
+		}
+		level := 0
+		for _, dep := range e.dependsOn {
+			if depEntry := byID[dep]; depEntry.featureID == featureID {
+				level = max(level, getLevel(depEntry)+1)
+			}
+		}
+		levels[e.index] = level
+		return level
+	}
+
+	waves := make([][]int, 0)
+	for i := range entries {
+		if entries[i].featureID != featureID {
+			continue
+		}
+		level := getLevel(&entries[i])
+		for len(waves) <= level {
+			waves = append(waves, make([]int, 0))
+		}
+		waves[level] = append(waves[level], entries[i].index)
+	}
+
+	return waves, nil
+}
+
+// getWithdrawalOrder returns the indexes of the feature entries in the order those must be withdrawn:
+// dependents before the entries they depend on
+func getWithdrawalOrder(spec *configv1beta1.Spec, featureID configv1beta1.FeatureID) []int {
+	waves, err := getDeploymentWaves(spec, featureID)
+	if err != nil {
+		// Invalid dependencies. Withdraw following the order entries are listed with.
+		order := make([]int, 0)
+		for _, e := range getProfileEntries(spec) {
+			if e.featureID == featureID {
+				order = append(order, e.index)
+			}
+		}
+		return order
+	}
+
+	order := make([]int, 0)
+	for i := len(waves) - 1; i >= 0; i-- {
+		order = append(order, waves[i]...)
+	}
+	return order
+}
+
+// getFeatureEntry returns the entry of the given feature at the given index
+func getFeatureEntry(entries []profileEntry, featureID configv1beta1.FeatureID, index int) *profileEntry {
+	for i := range entries {
+		if entries[i].featureID == featureID && entries[i].index == index {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// getDependedUponEntries returns the entries, among the given ones, other entries of the same feature depend on
+func getDependedUponEntries(spec *configv1beta1.Spec, featureID configv1beta1.FeatureID, indexes []int) []profileEntry {
+	entries := getProfileEntries(spec)
+
+	dependedUpon := make(map[string]bool)
+	for i := range entries {
+		if entries[i].featureID != featureID {
+			continue
+		}
+		for _, dep := range entries[i].dependsOn {
+			dependedUpon[dep] = true
+		}
+	}
+
+	result := make([]profileEntry, 0)
+	for _, index := range indexes {
+		e := getFeatureEntry(entries, featureID, index)
+		if e != nil && e.identifier != "" && dependedUpon[e.identifier] {
+			result = append(result, *e)
+		}
+	}
+	return result
+}
+
+// checkCrossFeatureDependencies returns a DependencyNotReadyError if any of the given entries depends on
+// an entry of another feature which is not deployed and ready yet
+func checkCrossFeatureDependencies(ctx context.Context, c, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID, indexes []int,
+	logger logr.Logger) error {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return nil
+	}
+
+	entries := getProfileEntries(&clusterSummary.Spec.ClusterProfileSpec)
+	byID, err := getEntriesByIdentifier(entries)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		e := getFeatureEntry(entries, featureID, index)
+		if e == nil {
+			continue
+		}
+		for _, dep := range e.dependsOn {
+			depEntry := byID[dep]
+			if depEntry.featureID == featureID {
+				continue
+			}
+
+			ready, reason, err := isEntryReady(ctx, c, remoteClient, clusterSummary, depEntry)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				msg := fmt.Sprintf("%s is waiting for %s: %s", e.String(), dep, reason)
+				logger.V(logs.LogDebug).Info(msg)
+				return &DependencyNotReadyError{Message: msg}
+			}
+		}
+	}
+
+	return nil
+}
+
+// isEntryReady returns true if the entry has been deployed and, for PolicyRefs and KustomizationRefs,
+// all the resources it deployed are ready. Helm charts are ready once the release is deployed (set
+// Options.Wait for helm to wait for the release resources).
+func isEntryReady(ctx context.Context, c, remoteClient client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	e *profileEntry) (ready bool, reason string, err error) {
+
+	if e.local {
+		// Resources deployed in the management cluster are not tracked in the ClusterConfiguration.
+		// Rely on the feature status.
+		if !isFeatureProvisioned(clusterSummary, e.featureID) {
+			return false, fmt.Sprintf("feature %s not provisioned yet", e.featureID), nil
+		}
+		return true, "", nil
+	}
+
+	feature, err := getClusterConfigurationFeature(ctx, c, clusterSummary, e.featureID)
+	if err != nil {
+		return false, "", err
+	}
+
+	if e.featureID == configv1beta1.FeatureHelm {
+		if feature != nil {
+			for i := range feature.Charts {
+				if feature.Charts[i].Namespace == e.releaseNamespace && feature.Charts[i].ReleaseName == e.releaseName {
+					return true, "", nil
+				}
+			}
+		}
+		return false, "release not deployed yet", nil
+	}
+
+	waits, err := instantiateWaitReferences(ctx, c, clusterSummary, []waitReference{*e.reference})
+	if err != nil {
+		return false, "", err
+	}
+
+	resources := make([]resourceToWaitFor, 0)
+	if feature != nil {
+		for i := range feature.Resources {
+			owner := feature.Resources[i].Owner
+			if _, ok := waits[corev1.ObjectReference{Kind: owner.Kind, Namespace: owner.Namespace, Name: owner.Name}]; ok {
+				resources = append(resources, resourceToWaitFor{resource: feature.Resources[i]})
+			}
+		}
+	}
+	if len(resources) == 0 {
+		return false, "not deployed yet", nil
+	}
+
+	pending, _, err := getPendingResources(ctx, c, remoteClient, resources)
+	if err != nil {
+		return false, "", err
+	}
+	if len(pending) != 0 {
+		return false, getPendingResourcesMessage(pending), nil
+	}
+
+	return true, "", nil
+}
+
+// waitForDependedUponReferences waits for resources deployed because of the PolicyRefs/KustomizationRefs
+// other entries of the same feature depend on to be ready
+func waitForDependedUponReferences(ctx context.Context, c client.Client, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID, indexes []int,
+	localReports, remoteReports []configv1beta1.ResourceReport, logger logr.Logger) error {
+
+	dependedUpon := getDependedUponEntries(&clusterSummary.Spec.ClusterProfileSpec, featureID, indexes)
+	if len(dependedUpon) == 0 {
+		return nil
+	}
+
+	refs := make([]waitReference, len(dependedUpon))
+	for i := range dependedUpon {
+		refs[i] = *dependedUpon[i].reference
+	}
+
+	return waitForResources(ctx, c, remoteClient, clusterSummary, featureID, refs, localReports, remoteReports, logger)
+}
+
+// checkDependedUponCharts returns a DependencyNotReadyError if any of the given charts, other charts depend on,
+// is not deployed
+func checkDependedUponCharts(clusterSummary *configv1beta1.ClusterSummary, deployments []*helmChartDeployment,
+	indexes []int) error {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return nil
+	}
+
+	dependedUpon := getDependedUponEntries(&clusterSummary.Spec.ClusterProfileSpec, configv1beta1.FeatureHelm, indexes)
+	for i := range dependedUpon {
+		var status string
+		for j := range deployments {
+			d := deployments[j]
+			if d.chart.ReleaseNamespace == dependedUpon[i].releaseNamespace &&
+				d.chart.ReleaseName == dependedUpon[i].releaseName && d.release != nil {
+
+				status = d.release.Status
+			}
+		}
+		if status != release.StatusDeployed.String() {
+			return &DependencyNotReadyError{Message: fmt.Sprintf("charts depending on %s are waiting for it to be deployed",
+				dependedUpon[i].String())}
+		}
+	}
+
+	return nil
+}
+
+// checkDependentsRemoved returns a DependencyNotReadyError if entries of other features depending on
+// entries of this feature have not been removed yet. When features depend on each other, no ordering
+// is enforced.
+func checkDependentsRemoved(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) error {
+	if clusterSummary.Spec.ClusterProfileSpec.StopMatchingBehavior == configv1beta1.LeavePolicies {
+		return nil
+	}
+
+	entries := getProfileEntries(&clusterSummary.Spec.ClusterProfileSpec)
+	byID, err := getEntriesByIdentifier(entries)
+	if err != nil {
+		return nil
+	}
+
+	// featureDeps[a][b] is set if an entry of feature a depends on an entry of feature b
+	featureDeps := make(map[configv1beta1.FeatureID]map[configv1beta1.FeatureID]string)
+	for i := range entries {
+		for _, dep := range entries[i].dependsOn {
+			depEntry, ok := byID[dep]
+			if !ok || depEntry.featureID == entries[i].featureID {
+				continue
+			}
+			if featureDeps[entries[i].featureID] == nil {
+				featureDeps[entries[i].featureID] = make(map[configv1beta1.FeatureID]string)
+			}
+			featureDeps[entries[i].featureID][depEntry.featureID] = entries[i].String()
+		}
+	}
+
+	for dependentFeature, deps := range featureDeps {
+		dependent, ok := deps[featureID]
+		if !ok {
+			continue
+		}
+		if _, cycle := featureDeps[featureID][dependentFeature]; cycle {
+			continue
+		}
+		if !isFeatureWithdrawn(clusterSummary, dependentFeature) {
+			return &DependencyNotReadyError{Message: fmt.Sprintf("waiting for %s to be withdrawn", dependent)}
+		}
+	}
+
+	return nil
+}
+
+func isFeatureProvisioned(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) bool {
+	fs := getFeatureSummaryForFeatureID(clusterSummary, featureID)
+	return fs != nil && isFeatureProvisionedOrDegraded(fs)
+}
+
+// isFeatureWithdrawn returns true if feature is removed or was never deployed
+func isFeatureWithdrawn(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) bool {
+	fs := getFeatureSummaryForFeatureID(clusterSummary, featureID)
+	return fs == nil || fs.Status == configv1beta1.FeatureStatusRemoved
+}
+
+// waitForResources waits for resources deployed because of the given references to be ready, using
+// the default wait timeout
+func waitForResources(ctx context.Context, c, remoteClient client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, refs []waitReference, localReports, remoteReports []configv1beta1.ResourceReport,
+	logger logr.Logger) error {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return nil
+	}
+
+	waits, err := instantiateWaitReferences(ctx, c, clusterSummary, refs)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	resources := selectResourcesToWaitFor(localReports, waits, true, start)
+	resources = append(resources, selectResourcesToWaitFor(remoteReports, waits, false, start)...)
+
+	return waitForResourcesToBeReady(ctx, c, remoteClient, clusterSummary, featureID, resources, logger)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Profile entry dependencies", func() {
+	var spec *configv1beta1.Spec
+
+	BeforeEach(func() {
+		// cert-manager chart <- cluster-issuer PolicyRef <- certificates PolicyRef
+		// cert-manager chart <- ingress chart
+		spec = &configv1beta1.Spec{
+			HelmCharts: []configv1beta1.HelmChart{
+				{ReleaseNamespace: "ingress", ReleaseName: "ingress", Identifier: "ingress", DependsOn: []string{"cert-manager"}},
+				{ReleaseNamespace: "cert-manager", ReleaseName: "cert-manager", Identifier: "cert-manager"},
+				{ReleaseNamespace: randomString(), ReleaseName: randomString()},
+			},
+			PolicyRefs: []configv1beta1.PolicyRef{
+				{
+					Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Name: "certificates",
+					Identifier: "certificates", DependsOn: []string{"cluster-issuer"},
+				},
+				{
+					Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Name: "cluster-issuer",
+					Identifier: "cluster-issuer", DependsOn: []string{"cert-manager"},
+				},
+			},
+		}
+	})
+
+	It("validateEntryDependencies detects unknown references, duplicated identifiers and cycles", func() {
+		Expect(controllers.ValidateEntryDependencies(spec)).To(Succeed())
+
+		spec.PolicyRefs[0].DependsOn = []string{randomString()}
+		err := controllers.ValidateEntryDependencies(spec)
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+
+		spec.PolicyRefs[0].DependsOn = nil
+		spec.PolicyRefs[0].Identifier = "cert-manager"
+		Expect(controllers.ValidateEntryDependencies(spec)).ToNot(Succeed())
+
+		spec.PolicyRefs[0].Identifier = "certificates"
+		spec.HelmCharts[1].DependsOn = []string{"certificates"}
+		spec.PolicyRefs[0].DependsOn = []string{"cluster-issuer"}
+		err = controllers.ValidateEntryDependencies(spec)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("cycle"))
+	})
+
+	It("getDeploymentWaves groups entries of a feature honoring dependencies within the feature", func() {
+		waves, err := controllers.GetDeploymentWaves(spec, configv1beta1.FeatureHelm)
+		Expect(err).To(BeNil())
+		Expect(waves).To(Equal([][]int{{1, 2}, {0}}))
+
+		waves, err = controllers.GetDeploymentWaves(spec, configv1beta1.FeatureResources)
+		Expect(err).To(BeNil())
+		Expect(waves).To(Equal([][]int{{1}, {0}}))
+
+		// No dependencies: single wave in listed order
+		spec.HelmCharts[0].DependsOn = nil
+		waves, err = controllers.GetDeploymentWaves(spec, configv1beta1.FeatureHelm)
+		Expect(err).To(BeNil())
+		Expect(waves).To(Equal([][]int{{0, 1, 2}}))
+
+		waves, err = controllers.GetDeploymentWaves(spec, configv1beta1.FeatureKustomize)
+		Expect(err).To(BeNil())
+		Expect(waves).To(BeEmpty())
+	})
+
+	It("getWithdrawalOrder withdraws dependents first", func() {
+		Expect(controllers.GetWithdrawalOrder(spec, configv1beta1.FeatureHelm)).To(Equal([]int{0, 1, 2}))
+		Expect(controllers.GetWithdrawalOrder(spec, configv1beta1.FeatureResources)).To(Equal([]int{0, 1}))
+
+		spec.HelmCharts[0].DependsOn = nil
+		spec.HelmCharts[1].DependsOn = []string{"ingress"}
+		Expect(controllers.GetWithdrawalOrder(spec, configv1beta1.FeatureHelm)).To(Equal([]int{1, 0, 2}))
+	})
+
+	It("checkCrossFeatureDependencies blocks entries till dependencies in other features are deployed", func() {
+		clusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1beta1.ClusterProfileKind,
+						APIVersion: configv1beta1.GroupVersion.String(),
+						Name:       clusterProfile.Name,
+						UID:        types.UID(randomString()),
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace:   randomString(),
+				ClusterName:        randomString(),
+				ClusterType:        libsveltosv1beta1.ClusterTypeCapi,
+				ClusterProfileSpec: *spec,
+			},
+		}
+
+		clusterConfiguration := &configv1beta1.ClusterConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.GetClusterConfigurationName(clusterSummary.Spec.ClusterName,
+					libsveltosv1beta1.ClusterTypeCapi),
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+			},
+			Status: configv1beta1.ClusterConfigurationStatus{
+				ClusterProfileResources: []configv1beta1.ClusterProfileResource{
+					{ClusterProfileName: clusterProfile.Name},
+				},
+			},
+		}
+
+		initObjects := []client.Object{clusterConfiguration}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		// cluster-issuer PolicyRef depends on cert-manager chart which is not deployed yet
+		err := controllers.CheckCrossFeatureDependencies(context.TODO(), c, c, clusterSummary,
+			configv1beta1.FeatureResources, []int{1}, logr.Discard())
+		Expect(err).ToNot(BeNil())
+		var dependencyNotReadyError *controllers.DependencyNotReadyError
+		Expect(errors.As(err, &dependencyNotReadyError)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("cert-manager"))
+
+		// certificates PolicyRef only depends on entries of the same feature
+		Expect(controllers.CheckCrossFeatureDependencies(context.TODO(), c, c, clusterSummary,
+			configv1beta1.FeatureResources, []int{0}, logr.Discard())).To(Succeed())
+
+		clusterConfiguration.Status.ClusterProfileResources[0].Features = []configv1beta1.Feature{
+			{
+				FeatureID: configv1beta1.FeatureHelm,
+				Charts: []configv1beta1.Chart{
+					{Namespace: "cert-manager", ReleaseName: "cert-manager", RepoURL: randomString(),
+						ChartVersion: randomString()},
+				},
+			},
+		}
+		Expect(c.Status().Update(context.TODO(), clusterConfiguration)).To(Succeed())
+
+		Expect(controllers.CheckCrossFeatureDependencies(context.TODO(), c, c, clusterSummary,
+			configv1beta1.FeatureResources, []int{1}, logr.Discard())).To(Succeed())
+	})
+
+	It("checkDependentsRemoved withdraws features containing dependents first", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterProfileSpec: *spec,
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureResources, Status: configv1beta1.FeatureStatusRemoving},
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusRemoving},
+				},
+			},
+		}
+
+		// PolicyRefs depend on a chart: helm charts are withdrawn only after PolicyRefs
+		err := controllers.CheckDependentsRemoved(clusterSummary, configv1beta1.FeatureHelm)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("cluster-issuer"))
+		Expect(controllers.CheckDependentsRemoved(clusterSummary, configv1beta1.FeatureResources)).To(Succeed())
+
+		clusterSummary.Status.FeatureSummaries[0].Status = configv1beta1.FeatureStatusRemoved
+		Expect(controllers.CheckDependentsRemoved(clusterSummary, configv1beta1.FeatureHelm)).To(Succeed())
+	})
+})
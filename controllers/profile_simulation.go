@@ -0,0 +1,514 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/chartmanager"
+	"github.com/projectsveltos/addon-controller/controllers/dependencymanager"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Impact analysis
+// A ProfileSimulation evaluates, without creating any ClusterSummary, what would happen if
+// ClusterSelector, ClusterRefs, SetRefs, StopMatchingBehavior and/or Tier of a ClusterProfile/Profile
+// were changed:
+// - clusters which would start/stop matching are found the same way ClusterProfile/Profile
+// reconcilers do (getMatchingClusters plus (Cluster)Sets plus clusters required by dependent profiles);
+// - Helm releases changing owner are found using the chartmanager registrations and conflict tiers;
+// - resources changing owner are found using the ClusterConfigurations and conflict tiers. Only
+// resources this profile already deploys in at least one cluster are considered.
+
+const (
+	helmReleaseKind = "HelmRelease"
+)
+
+// getSimulatedSpec returns the profile Spec with the ProfileSimulation fields applied
+func getSimulatedSpec(spec *configv1beta1.Spec,
+	simulation *configv1beta1.ProfileSimulation) *configv1beta1.Spec {
+
+	simulated := spec.DeepCopy()
+	if simulation.Spec.ClusterSelector != nil {
+		simulated.ClusterSelector = *simulation.Spec.ClusterSelector
+	}
+	if simulation.Spec.ClusterRefs != nil {
+		simulated.ClusterRefs = simulation.Spec.ClusterRefs
+	}
+	if simulation.Spec.SetRefs != nil {
+		simulated.SetRefs = simulation.Spec.SetRefs
+	}
+	if simulation.Spec.StopMatchingBehavior != nil {
+		simulated.StopMatchingBehavior = *simulation.Spec.StopMatchingBehavior
+	}
+	if simulation.Spec.Tier != nil {
+		simulated.Tier = *simulation.Spec.Tier
+	}
+	return simulated
+}
+
+// getReferencedProfile fetches the referenced ClusterProfile/Profile
+func getReferencedProfile(ctx context.Context, c client.Client, ref *configv1beta1.ProfileReference,
+) (client.Object, *configv1beta1.Spec, *configv1beta1.Status, error) {
+
+	if ref.Kind == configv1beta1.ClusterProfileKind {
+		clusterProfile := &configv1beta1.ClusterProfile{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, clusterProfile); err != nil {
+			return nil, nil, nil, err
+		}
+		return clusterProfile, &clusterProfile.Spec, &clusterProfile.Status, nil
+	}
+
+	profile := &configv1beta1.Profile{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, profile); err != nil {
+		return nil, nil, nil, err
+	}
+	return profile, &profile.Spec, &profile.Status, nil
+}
+
+// getSimulatedMatchingClusters returns the clusters a ClusterProfile/Profile with the given Spec would match
+func getSimulatedMatchingClusters(ctx context.Context, c client.Client, ref *configv1beta1.ProfileReference,
+	spec *configv1beta1.Spec, logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	matchingClusters, err := getMatchingClusters(ctx, c, ref.Namespace, &spec.ClusterSelector.LabelSelector,
+		spec.ClusterRefs, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var setClusters []corev1.ObjectReference
+	if ref.Kind == configv1beta1.ClusterProfileKind {
+		setClusters, err = getClustersFromClusterSets(ctx, c, spec.SetRefs, logger)
+	} else {
+		setClusters, err = getClustersFromSets(ctx, c, ref.Namespace, spec.SetRefs, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+	matchingClusters = append(matchingClusters, setClusters...)
+
+	depManager, err := dependencymanager.GetManagerInstance()
+	if err != nil {
+		return nil, err
+	}
+	profileRef := &corev1.ObjectReference{Kind: ref.Kind, APIVersion: configv1beta1.GroupVersion.String(),
+		Namespace: ref.Namespace, Name: ref.Name}
+	matchingClusters = append(matchingClusters, depManager.GetClusterDeployments(profileRef)...)
+
+	return removeDuplicates(matchingClusters), nil
+}
+
+// getClusterDifference returns the clusters in a but not in b
+func getClusterDifference(a, b []corev1.ObjectReference) []corev1.ObjectReference {
+	inB := make(map[corev1.ObjectReference]bool, len(b))
+	for i := range b {
+		inB[getClusterRefKey(&b[i])] = true
+	}
+
+	result := make([]corev1.ObjectReference, 0)
+	for i := range a {
+		if !inB[getClusterRefKey(&a[i])] {
+			result = append(result, a[i])
+		}
+	}
+	sortClusterRefs(result)
+	return result
+}
+
+func getClusterRefKey(cluster *corev1.ObjectReference) corev1.ObjectReference {
+	return corev1.ObjectReference{Kind: cluster.Kind, Namespace: cluster.Namespace, Name: cluster.Name}
+}
+
+func sortClusterRefs(clusters []corev1.ObjectReference) {
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Namespace != clusters[j].Namespace {
+			return clusters[i].Namespace < clusters[j].Namespace
+		}
+		if clusters[i].Name != clusters[j].Name {
+			return clusters[i].Name < clusters[j].Name
+		}
+		return clusters[i].Kind < clusters[j].Kind
+	})
+}
+
+// simulateProfileChanges computes the impact of the changes described by a ProfileSimulation
+func simulateProfileChanges(ctx context.Context, c client.Client, simulation *configv1beta1.ProfileSimulation,
+	logger logr.Logger) (*configv1beta1.ProfileSimulationStatus, error) {
+
+	ref := &simulation.Spec.ProfileRef
+	if ref.Kind == configv1beta1.ClusterProfileKind {
+		ref = &configv1beta1.ProfileReference{Kind: ref.Kind, Name: ref.Name}
+	}
+
+	_, spec, status, err := getReferencedProfile(ctx, c, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	simulatedSpec := getSimulatedSpec(spec, simulation)
+	simulatedClusters, err := getSimulatedMatchingClusters(ctx, c, ref, simulatedSpec, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &configv1beta1.ProfileSimulationStatus{
+		NewlyMatchingClusters: getClusterDifference(simulatedClusters, status.MatchingClusterRefs),
+		StopMatchingClusters:  getClusterDifference(status.MatchingClusterRefs, simulatedClusters),
+	}
+	// Add-ons are withdrawn only if at least one cluster stops matching
+	result.WithdrawnFromClusters = len(result.StopMatchingClusters) > 0 &&
+		simulatedSpec.StopMatchingBehavior != configv1beta1.LeavePolicies
+
+	helmChanges, err := getHelmOwnershipChanges(ctx, c, ref, simulatedSpec, simulatedClusters,
+		result.StopMatchingClusters, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceChanges, err := getResourceOwnershipChanges(ctx, c, ref, simulatedSpec, status.MatchingClusterRefs,
+		simulatedClusters, result.StopMatchingClusters, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	result.OwnershipChanges = append(helmChanges, resourceChanges...)
+	return result, nil
+}
+
+// getClusterSummaryOwner returns the profile owning a ClusterSummary along with its tier
+func getClusterSummaryOwner(ctx context.Context, c client.Client, namespace, name string,
+) (*configv1beta1.ProfileReference, int32, error) {
+
+	clusterSummary := &configv1beta1.ClusterSummary{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, clusterSummary); err != nil {
+		return nil, 0, err
+	}
+
+	ownerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ownerRef == nil {
+		return nil, 0, fmt.Errorf("clusterSummary %s/%s has no owner", namespace, name)
+	}
+
+	owner := &configv1beta1.ProfileReference{Kind: ownerRef.Kind, Name: ownerRef.Name}
+	if ownerRef.Kind == configv1beta1.ProfileKind {
+		owner.Namespace = namespace
+	}
+	return owner, clusterSummary.Spec.ClusterProfileSpec.Tier, nil
+}
+
+// getHelmOwnershipChanges returns the Helm releases which would change owner.
+// In matching clusters, this profile takes over releases managed by profiles with a higher tier,
+// and gives up releases it manages when another registered profile has a lower tier.
+// In clusters which would stop matching, releases it manages go to the next registered profile.
+func getHelmOwnershipChanges(ctx context.Context, c client.Client, ref *configv1beta1.ProfileReference,
+	spec *configv1beta1.Spec, matchingClusters, stopMatchingClusters []corev1.ObjectReference,
+	logger logr.Logger) ([]configv1beta1.OwnershipChange, error) {
+
+	chartManager, err := chartmanager.GetChartManagerInstance(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	stopMatching := make(map[corev1.ObjectReference]bool, len(stopMatchingClusters))
+	for i := range stopMatchingClusters {
+		stopMatching[getClusterRefKey(&stopMatchingClusters[i])] = true
+	}
+
+	clusters := append(append([]corev1.ObjectReference{}, matchingClusters...), stopMatchingClusters...)
+	sortClusterRefs(clusters)
+
+	changes := make([]configv1beta1.OwnershipChange, 0)
+	for i := range clusters {
+		cluster := &clusters[i]
+		clusterType := clusterproxy.GetClusterType(cluster)
+		ownName := GetClusterSummaryName(ref.Kind, ref.Name, cluster.Name,
+			clusterType == libsveltosv1beta1.ClusterTypeSveltos)
+
+		for j := range spec.HelmCharts {
+			chart := &spec.HelmCharts[j]
+			registered := chartManager.GetRegisteredClusterSummariesForChart(cluster.Namespace, cluster.Name,
+				clusterType, chart)
+
+			var change *configv1beta1.OwnershipChange
+			if stopMatching[getClusterRefKey(cluster)] {
+				change, err = getHelmOwnershipChangeOnStopMatching(ctx, c, ref, ownName, cluster.Namespace, registered)
+			} else {
+				change, err = getHelmOwnershipChangeOnTier(ctx, c, ref, spec.Tier, ownName, cluster.Namespace,
+					registered)
+			}
+			if err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to evaluate helm release %s/%s ownership: %v",
+					chart.ReleaseNamespace, chart.ReleaseName, err))
+				return nil, err
+			}
+			if change != nil {
+				change.Cluster = *cluster
+				change.Kind = helmReleaseKind
+				change.Namespace = chart.ReleaseNamespace
+				change.Name = chart.ReleaseName
+				changes = append(changes, *change)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func getHelmOwnershipChangeOnStopMatching(ctx context.Context, c client.Client, ref *configv1beta1.ProfileReference,
+	ownName, clusterNamespace string, registered []string) (*configv1beta1.OwnershipChange, error) {
+
+	if len(registered) == 0 || registered[0] != ownName {
+		return nil, nil
+	}
+
+	change := &configv1beta1.OwnershipChange{CurrentOwner: ref}
+	if len(registered) > 1 {
+		newOwner, _, err := getClusterSummaryOwner(ctx, c, clusterNamespace, registered[1])
+		if err != nil {
+			return nil, err
+		}
+		change.NewOwner = newOwner
+	}
+	return change, nil
+}
+
+func getHelmOwnershipChangeOnTier(ctx context.Context, c client.Client, ref *configv1beta1.ProfileReference,
+	tier int32, ownName, clusterNamespace string, registered []string) (*configv1beta1.OwnershipChange, error) {
+
+	if len(registered) == 0 {
+		return nil, nil
+	}
+
+	if registered[0] != ownName {
+		currentOwner, currentTier, err := getClusterSummaryOwner(ctx, c, clusterNamespace, registered[0])
+		if err != nil {
+			return nil, err
+		}
+		if !hasHigherOwnershipPriority(currentTier, tier) {
+			return nil, nil
+		}
+		return &configv1beta1.OwnershipChange{CurrentOwner: currentOwner, NewOwner: ref}, nil
+	}
+
+	// This profile is the current owner. The registered profile with the lowest tier,
+	// if lower than the simulated one, would take over.
+	var newOwner *configv1beta1.ProfileReference
+	newOwnerTier := tier
+	for i := 1; i < len(registered); i++ {
+		owner, ownerTier, err := getClusterSummaryOwner(ctx, c, clusterNamespace, registered[i])
+		if err != nil {
+			return nil, err
+		}
+		if hasHigherOwnershipPriority(newOwnerTier, ownerTier) {
+			newOwner, newOwnerTier = owner, ownerTier
+		}
+	}
+	if newOwner == nil {
+		return nil, nil
+	}
+	return &configv1beta1.OwnershipChange{CurrentOwner: ref, NewOwner: newOwner}, nil
+}
+
+// getProfileResources returns the resources deployed in a cluster, per profile, as
+// reported by the cluster ClusterConfiguration
+func getProfileResources(ctx context.Context, c client.Client, cluster *corev1.ObjectReference,
+) (map[configv1beta1.ProfileReference][]configv1beta1.Resource, error) {
+
+	clusterConfiguration, err := getClusterConfiguration(ctx, c, cluster.Namespace,
+		getClusterConfigurationName(cluster.Name, clusterproxy.GetClusterType(cluster)))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := make(map[configv1beta1.ProfileReference][]configv1beta1.Resource)
+	addFeatures := func(ref configv1beta1.ProfileReference, features []configv1beta1.Feature) {
+		for i := range features {
+			result[ref] = append(result[ref], features[i].Resources...)
+		}
+	}
+	for i := range clusterConfiguration.Status.ClusterProfileResources {
+		cpr := &clusterConfiguration.Status.ClusterProfileResources[i]
+		addFeatures(configv1beta1.ProfileReference{Kind: configv1beta1.ClusterProfileKind,
+			Name: cpr.ClusterProfileName}, cpr.Features)
+	}
+	for i := range clusterConfiguration.Status.ProfileResources {
+		pr := &clusterConfiguration.Status.ProfileResources[i]
+		addFeatures(configv1beta1.ProfileReference{Kind: configv1beta1.ProfileKind,
+			Namespace: cluster.Namespace, Name: pr.ProfileName}, pr.Features)
+	}
+
+	return result, nil
+}
+
+func getResourceKey(resource *configv1beta1.Resource) string {
+	return fmt.Sprintf("%s/%s/%s/%s", resource.Group, resource.Kind, resource.Namespace, resource.Name)
+}
+
+// getResourceOwnershipChanges returns the resources which would change owner.
+// In matching clusters, this profile takes over resources it deploys elsewhere when they are
+// deployed by profiles with a higher tier. In clusters which would stop matching, resources
+// deployed by this profile are not managed anymore.
+func getResourceOwnershipChanges(ctx context.Context, c client.Client, ref *configv1beta1.ProfileReference,
+	spec *configv1beta1.Spec, currentClusters, matchingClusters, stopMatchingClusters []corev1.ObjectReference,
+	logger logr.Logger) ([]configv1beta1.OwnershipChange, error) {
+
+	perCluster := make(map[corev1.ObjectReference]map[configv1beta1.ProfileReference][]configv1beta1.Resource)
+	getResources := func(cluster *corev1.ObjectReference) (map[configv1beta1.ProfileReference][]configv1beta1.Resource, error) {
+		key := getClusterRefKey(cluster)
+		if resources, ok := perCluster[key]; ok {
+			return resources, nil
+		}
+		resources, err := getProfileResources(ctx, c, cluster)
+		if err != nil {
+			return nil, err
+		}
+		perCluster[key] = resources
+		return resources, nil
+	}
+
+	// Resources this profile deploys
+	ownResources := make(map[string]bool)
+	for i := range currentClusters {
+		resources, err := getResources(&currentClusters[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := range resources[*ref] {
+			ownResources[getResourceKey(&resources[*ref][j])] = true
+		}
+	}
+
+	changes := make([]configv1beta1.OwnershipChange, 0)
+	sortClusterRefs(matchingClusters)
+	for i := range matchingClusters {
+		cluster := &matchingClusters[i]
+		resources, err := getResources(cluster)
+		if err != nil {
+			return nil, err
+		}
+		clusterChanges, err := getClusterResourceOwnershipChanges(ctx, c, ref, spec.Tier, cluster,
+			resources, ownResources)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to evaluate resources ownership in cluster %s/%s: %v",
+				cluster.Namespace, cluster.Name, err))
+			return nil, err
+		}
+		changes = append(changes, clusterChanges...)
+	}
+
+	for i := range stopMatchingClusters {
+		cluster := &stopMatchingClusters[i]
+		resources, err := getResources(cluster)
+		if err != nil {
+			return nil, err
+		}
+		for j := range resources[*ref] {
+			changes = append(changes, getResourceOwnershipChange(cluster, &resources[*ref][j], ref, nil))
+		}
+	}
+
+	return changes, nil
+}
+
+func getClusterResourceOwnershipChanges(ctx context.Context, c client.Client, ref *configv1beta1.ProfileReference,
+	tier int32, cluster *corev1.ObjectReference,
+	resources map[configv1beta1.ProfileReference][]configv1beta1.Resource, ownResources map[string]bool,
+) ([]configv1beta1.OwnershipChange, error) {
+
+	deployedByThis := make(map[string]bool)
+	for i := range resources[*ref] {
+		deployedByThis[getResourceKey(&resources[*ref][i])] = true
+	}
+
+	owners := make([]configv1beta1.ProfileReference, 0, len(resources))
+	for owner := range resources {
+		if owner != *ref {
+			owners = append(owners, owner)
+		}
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s/%s", owners[i].Kind, owners[i].Namespace, owners[i].Name) <
+			fmt.Sprintf("%s/%s/%s", owners[j].Kind, owners[j].Namespace, owners[j].Name)
+	})
+
+	changes := make([]configv1beta1.OwnershipChange, 0)
+	for i := range owners {
+		owner := owners[i]
+		var ownerTier *int32
+		for j := range resources[owner] {
+			resource := &resources[owner][j]
+			key := getResourceKey(resource)
+			if !ownResources[key] || deployedByThis[key] {
+				continue
+			}
+			if ownerTier == nil {
+				clusterSummary, err := getClusterSummary(ctx, c, owner.Kind, owner.Name, cluster.Namespace,
+					cluster.Name, clusterproxy.GetClusterType(cluster))
+				if err != nil {
+					if apierrors.IsNotFound(err) {
+						break
+					}
+					return nil, err
+				}
+				ownerTier = &clusterSummary.Spec.ClusterProfileSpec.Tier
+			}
+			if hasHigherOwnershipPriority(*ownerTier, tier) {
+				changes = append(changes, getResourceOwnershipChange(cluster, resource, &owner, ref))
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func getResourceOwnershipChange(cluster *corev1.ObjectReference, resource *configv1beta1.Resource,
+	currentOwner, newOwner *configv1beta1.ProfileReference) configv1beta1.OwnershipChange {
+
+	change := configv1beta1.OwnershipChange{
+		Cluster:   *cluster,
+		Kind:      resource.Kind,
+		Group:     resource.Group,
+		Namespace: resource.Namespace,
+		Name:      resource.Name,
+	}
+	if currentOwner != nil {
+		owner := *currentOwner
+		change.CurrentOwner = &owner
+	}
+	if newOwner != nil {
+		owner := *newOwner
+		change.NewOwner = &owner
+	}
+	return change
+}
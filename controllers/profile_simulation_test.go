@@ -0,0 +1,182 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/controllers/chartmanager"
+	"github.com/projectsveltos/addon-controller/controllers/dependencymanager"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Profile simulation", func() {
+	It("simulateProfileChanges reports clusters and ownership changes without creating ClusterSummaries", func() {
+		namespace := randomString()
+		clusters := []corev1.ObjectReference{
+			{
+				Namespace: namespace, Name: randomString(),
+				Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			},
+			{
+				Namespace: namespace, Name: randomString(),
+				Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			},
+		}
+
+		chart := configv1beta1.HelmChart{
+			RepositoryURL: randomString(), RepositoryName: randomString(), ChartName: randomString(),
+			ChartVersion: "v1.0.0", ReleaseName: randomString(), ReleaseNamespace: randomString(),
+		}
+		resource := configv1beta1.Resource{Name: randomString(), Namespace: randomString(), Kind: "ServiceAccount",
+			Version: "v1"}
+
+		// simulated profile matches first cluster, where it deploys resource
+		simulated := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				ClusterRefs: []corev1.ObjectReference{clusters[0]},
+				HelmCharts:  []configv1beta1.HelmChart{chart},
+				Tier:        100,
+			},
+			Status: configv1beta1.Status{MatchingClusterRefs: []corev1.ObjectReference{clusters[0]}},
+		}
+
+		// other profile matches second cluster, where it manages both chart and resource
+		other := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString(), UID: "other"},
+			Spec: configv1beta1.Spec{
+				ClusterRefs: []corev1.ObjectReference{clusters[1]},
+				HelmCharts:  []configv1beta1.HelmChart{chart},
+				Tier:        50,
+			},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      controllers.GetClusterSummaryName(configv1beta1.ClusterProfileKind, other.Name, clusters[1].Name, true),
+				Labels: map[string]string{
+					controllers.ClusterProfileLabelName: other.Name,
+					configv1beta1.ClusterNameLabel:      clusters[1].Name,
+					configv1beta1.ClusterTypeLabel:      string(libsveltosv1beta1.ClusterTypeSveltos),
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String(),
+						Name: other.Name, UID: other.UID},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace:   namespace,
+				ClusterName:        clusters[1].Name,
+				ClusterType:        libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: other.Spec,
+			},
+		}
+
+		getClusterConfiguration := func(cluster *corev1.ObjectReference, profileName string,
+		) *configv1beta1.ClusterConfiguration {
+
+			return &configv1beta1.ClusterConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      controllers.GetClusterConfigurationName(cluster.Name, libsveltosv1beta1.ClusterTypeSveltos),
+				},
+				Status: configv1beta1.ClusterConfigurationStatus{
+					ClusterProfileResources: []configv1beta1.ClusterProfileResource{
+						{
+							ClusterProfileName: profileName,
+							Features: []configv1beta1.Feature{
+								{FeatureID: configv1beta1.FeatureResources, Resources: []configv1beta1.Resource{resource}},
+							},
+						},
+					},
+				},
+			}
+		}
+
+		tier := int32(10)
+		simulation := &configv1beta1.ProfileSimulation{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.ProfileSimulationSpec{
+				ProfileRef:  configv1beta1.ProfileReference{Kind: configv1beta1.ClusterProfileKind, Name: simulated.Name},
+				ClusterRefs: []corev1.ObjectReference{clusters[1]},
+				Tier:        &tier,
+			},
+		}
+
+		initObjects := []client.Object{
+			simulated, other, clusterSummary, simulation,
+			getClusterConfiguration(&clusters[0], simulated.Name),
+			getClusterConfiguration(&clusters[1], other.Name),
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		dependencymanager.InitializeManagerInstance(context.TODO(), c, false, logger)
+		chartManager, err := chartmanager.GetChartManagerInstance(context.TODO(), c)
+		Expect(err).To(BeNil())
+		chartManager.RegisterClusterSummaryForCharts(clusterSummary)
+
+		status, err := controllers.SimulateProfileChanges(context.TODO(), c, simulation, logger)
+		Expect(err).To(BeNil())
+		Expect(status.NewlyMatchingClusters).To(ConsistOf(clusters[1]))
+		Expect(status.StopMatchingClusters).To(ConsistOf(clusters[0]))
+		Expect(status.WithdrawnFromClusters).To(BeTrue())
+
+		simulatedRef := &configv1beta1.ProfileReference{Kind: configv1beta1.ClusterProfileKind, Name: simulated.Name}
+		otherRef := &configv1beta1.ProfileReference{Kind: configv1beta1.ClusterProfileKind, Name: other.Name}
+		Expect(status.OwnershipChanges).To(ConsistOf(
+			configv1beta1.OwnershipChange{Cluster: clusters[1], Kind: "HelmRelease", Namespace: chart.ReleaseNamespace,
+				Name: chart.ReleaseName, CurrentOwner: otherRef, NewOwner: simulatedRef},
+			configv1beta1.OwnershipChange{Cluster: clusters[1], Kind: resource.Kind, Namespace: resource.Namespace,
+				Name: resource.Name, CurrentOwner: otherRef, NewOwner: simulatedRef},
+			configv1beta1.OwnershipChange{Cluster: clusters[0], Kind: resource.Kind, Namespace: resource.Namespace,
+				Name: resource.Name, CurrentOwner: simulatedRef},
+		))
+
+		// With a tier not lower than the current owner one, nothing changes hands in the second cluster
+		tier = 50
+		status, err = controllers.SimulateProfileChanges(context.TODO(), c, simulation, logger)
+		Expect(err).To(BeNil())
+		Expect(len(status.OwnershipChanges)).To(Equal(1))
+		Expect(status.OwnershipChanges[0].Cluster).To(Equal(clusters[0]))
+
+		// When no cluster stops matching, add-ons are not withdrawn from any cluster
+		simulation.Spec.ClusterRefs = clusters
+		status, err = controllers.SimulateProfileChanges(context.TODO(), c, simulation, logger)
+		Expect(err).To(BeNil())
+		Expect(status.StopMatchingClusters).To(BeEmpty())
+		Expect(status.WithdrawnFromClusters).To(BeFalse())
+
+		// No ClusterSummary is created
+		clusterSummaries := &configv1beta1.ClusterSummaryList{}
+		Expect(c.List(context.TODO(), clusterSummaries)).To(Succeed())
+		Expect(len(clusterSummaries.Items)).To(Equal(1))
+	})
+})
@@ -26,6 +26,13 @@ import (
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
 )
 
+func (r *ProfileReconciler) requeueProfileForReference(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	return requeueForPlanReference(ctx, r.Client, o, configv1beta1.ProfileKind)
+}
+
 func (r *ProfileReconciler) requeueProfileForSveltosCluster(
 	ctx context.Context, o client.Object,
 ) []reconcile.Request {
@@ -40,6 +47,13 @@ func (r *ProfileReconciler) requeueProfileForSveltosCluster(
 	return requeueForCluster(cluster, r.Profiles, r.ClusterLabels, r.ClusterMap, configv1beta1.ProfileKind, r.Logger)
 }
 
+func (r *ProfileReconciler) requeueProfileForProfileApproval(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	return requeueForProfileApproval(o, configv1beta1.ProfileKind)
+}
+
 func (r *ProfileReconciler) requeueProfileForCluster(
 	ctx context.Context, cluster *clusterv1.Cluster,
 ) []reconcile.Request {
@@ -64,6 +64,57 @@ func getMatchingClusters(ctx context.Context, c client.Client, namespace string,
 	return matchingCluster, nil
 }
 
+// getClustersFromClusterSets returns all clusters selected by the referenced ClusterSets
+func getClustersFromClusterSets(ctx context.Context, c client.Client, clusterSetRefs []string,
+	logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	clusters := make([]corev1.ObjectReference, 0)
+	for i := range clusterSetRefs {
+		clusterSet := &libsveltosv1beta1.ClusterSet{}
+		if err := c.Get(ctx,
+			types.NamespacedName{Name: clusterSetRefs[i]},
+			clusterSet); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get clusterSet %s", clusterSetRefs[i]))
+			return nil, err
+		}
+
+		if clusterSet.Status.SelectedClusterRefs != nil {
+			clusters = append(clusters, clusterSet.Status.SelectedClusterRefs...)
+		}
+	}
+
+	return clusters, nil
+}
+
+// getClustersFromSets returns all clusters selected by the referenced Sets in namespace
+func getClustersFromSets(ctx context.Context, c client.Client, namespace string, setRefs []string,
+	logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	clusters := make([]corev1.ObjectReference, 0)
+	for i := range setRefs {
+		set := &libsveltosv1beta1.Set{}
+		if err := c.Get(ctx,
+			types.NamespacedName{Namespace: namespace, Name: setRefs[i]},
+			set); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get set %s/%s",
+				namespace, setRefs[i]))
+			return nil, err
+		}
+
+		if set.Status.SelectedClusterRefs != nil {
+			clusters = append(clusters, set.Status.SelectedClusterRefs...)
+		}
+	}
+
+	return clusters, nil
+}
+
 // allClusterSummariesGone returns true if all ClusterSummaries owned by a
 // ClusterProfile/Profile instances are gone.
 func allClusterSummariesGone(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) bool {
@@ -436,14 +487,17 @@ func updateClusterSummary(ctx context.Context, c client.Client, profileScope *sc
 		return err
 	}
 
-	if reflect.DeepEqual(profileScope.GetSpec(), clusterSummary.Spec.ClusterProfileSpec) &&
+	spec, planHash := getClusterSummarySpec(profileScope, clusterSummary)
+	if reflect.DeepEqual(spec, &clusterSummary.Spec.ClusterProfileSpec) &&
+		planHash == clusterSummary.Spec.PlanHash &&
 		reflect.DeepEqual(profileScope.Profile.GetAnnotations(), clusterSummary.Annotations) {
 		// Nothing has changed
 		return nil
 	}
 
 	clusterSummary.Annotations = profileScope.Profile.GetAnnotations()
-	clusterSummary.Spec.ClusterProfileSpec = *profileScope.GetSpec()
+	clusterSummary.Spec.ClusterProfileSpec = *spec
+	clusterSummary.Spec.PlanHash = planHash
 	clusterSummary.Spec.ClusterType = clusterproxy.GetClusterType(cluster)
 	addClusterSummaryLabels(clusterSummary, profileScope, cluster)
 	// Copy annotation. Paused annotation might be set on ClusterProfile.
@@ -486,6 +540,7 @@ func createClusterSummary(ctx context.Context, c client.Client, profileScope *sc
 	clusterSummaryName := GetClusterSummaryName(profileScope.GetKind(), profileScope.Name(),
 		cluster.Name, cluster.APIVersion == libsveltosv1beta1.GroupVersion.String())
 
+	spec, planHash := getClusterSummarySpec(profileScope, nil)
 	clusterSummary := &configv1beta1.ClusterSummary{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      clusterSummaryName,
@@ -503,7 +558,8 @@ func createClusterSummary(ctx context.Context, c client.Client, profileScope *sc
 		Spec: configv1beta1.ClusterSummarySpec{
 			ClusterNamespace:   cluster.Namespace,
 			ClusterName:        cluster.Name,
-			ClusterProfileSpec: *profileScope.GetSpec(),
+			ClusterProfileSpec: *spec,
+			PlanHash:           planHash,
 		},
 	}
 
@@ -513,7 +569,13 @@ func createClusterSummary(ctx context.Context, c client.Client, profileScope *sc
 	// Copy annotation. Paused annotation might be set on ClusterProfile.
 	clusterSummary.Annotations = profileScope.Profile.GetAnnotations()
 
-	return c.Create(ctx, clusterSummary)
+	if err := c.Create(ctx, clusterSummary); err != nil {
+		return err
+	}
+
+	recordNormalEvent(ctx, profileScope.Profile, eventReasonClusterMatched, "Deploying add-ons to cluster %s",
+		getClusterDescription(clusterSummary))
+	return nil
 }
 
 // updateClusterSummaries for each Sveltos/Cluster currently matching ClusterProfile/Profile:
@@ -541,6 +603,7 @@ func updateClusterSummaries(ctx context.Context, c client.Client, profileScope *
 	updatedClusters, updatingClusters := getUpdatedAndUpdatingClusters(profileScope)
 
 	maxUpdate := getMaxUpdate(profileScope)
+	tripped := isCircuitBreakerTripped(profileScope.GetStatus())
 
 	skippedUpdate := false
 	// Consider matchingCluster number and MaxUpdate, walk remaining matching clusters.  If more clusters can be
@@ -588,6 +651,12 @@ func updateClusterSummaries(ctx context.Context, c client.Client, profileScope *
 			continue
 		}
 
+		// While circuit breaker is tripped, clusters not updated yet keep their current configuration
+		if tripped {
+			logger.V(logs.LogDebug).Info("Circuit breaker is tripped")
+			continue
+		}
+
 		// ClusterProfile does not look at whether Cluster is paused or not.
 		// If a Cluster exists and it is a match, ClusterSummary is created (and ClusterSummary.Spec kept in sync if mode is
 		// continuous).
@@ -607,6 +676,12 @@ func updateClusterSummaries(ctx context.Context, c client.Client, profileScope *
 		profileScope.GetStatus().UpdatingClusters.Hash = currentHash
 	}
 
+	if tripped {
+		// Tripped condition reports the circuit breaker state. Keep tracking the rollout so that it
+		// resumes once the circuit breaker is reset.
+		return nil
+	}
+
 	if skippedUpdate {
 		return fmt.Errorf("not all clusters updated yet. %d still being updated",
 			len(profileScope.GetStatus().UpdatingClusters.Clusters))
@@ -651,6 +726,9 @@ func patchClusterSummary(ctx context.Context, c client.Client, profileScope *sco
 
 // cleanClusterSummaries finds all ClusterSummary currently owned by ClusterProfile/Profile.
 // For each such ClusterSummary, if corresponding Sveltos/Cluster is not a match anymore, deletes ClusterSummary
+// ClusterSummaries are all deleted at once. Withdrawing in reverse DependsOn order is enforced by the
+// ClusterSummary controller, which does not undeploy till ClusterSummaries of dependent profiles are removed.
+// No ClusterSummary is deleted if the withdrawal guard blocks the withdrawal.
 func cleanClusterSummaries(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
 	matching := make(map[string]bool)
 
@@ -678,14 +756,51 @@ func cleanClusterSummaries(ctx context.Context, c client.Client, profileScope *s
 		return err
 	}
 
+	// Clusters add-ons would be withdrawn from, out of all existing clusters with add-ons deployed.
+	// Clusters which are gone do not count towards the withdrawal guard limits.
+	withdrawn := make([]string, 0)
+	gone := make(map[string]bool)
+	total := 0
+	for i := range clusterSummaryList.Items {
+		cs := &clusterSummaryList.Items[i]
+		if util.IsOwnedByObject(cs, profileScope.Profile) && cs.DeletionTimestamp.IsZero() {
+			clusterInfo := getClusterInfo(cs.Spec.ClusterNamespace, cs.Spec.ClusterName, cs.Spec.ClusterType)
+			if _, ok := matching[clusterInfo]; !ok {
+				clusterGone, err := isClusterGone(ctx, c, cs)
+				if err != nil {
+					return err
+				}
+				if clusterGone {
+					gone[clusterInfo] = true
+					continue
+				}
+				withdrawn = append(withdrawn,
+					fmt.Sprintf("%s:%s/%s", cs.Spec.ClusterType, cs.Spec.ClusterNamespace, cs.Spec.ClusterName))
+			}
+			total++
+		}
+	}
+	blocked, confirmed := isWithdrawalBlocked(profileScope, withdrawn, total)
+	if blocked {
+		// Blocking a withdrawal is a policy decision, not an error. The WithdrawalBlocked condition
+		// reports it and the profile is reconciled again once the withdrawal is confirmed.
+		recordWarningEvent(ctx, profileScope.Profile, eventReasonWithdrawalBlocked,
+			"Withdrawal from %d out of %d clusters blocked. Confirmation required", len(withdrawn), total)
+	}
+
 	// Check if any ClusterSummary instance that needs to be removed is still present
 	foundClusterSummaries := false
 	for i := range clusterSummaryList.Items {
 		cs := &clusterSummaryList.Items[i]
 
-		if util.IsOwnedByObject(cs, profileScope.Profile) {
-			if _, ok := matching[getClusterInfo(cs.Spec.ClusterNamespace, cs.Spec.ClusterName, cs.Spec.ClusterType)]; !ok {
+		clusterInfo := getClusterInfo(cs.Spec.ClusterNamespace, cs.Spec.ClusterName, cs.Spec.ClusterType)
+		if (!blocked || gone[clusterInfo]) && util.IsOwnedByObject(cs, profileScope.Profile) {
+			if _, ok := matching[clusterInfo]; !ok {
 				foundClusterSummaries = true
+				if cs.DeletionTimestamp.IsZero() {
+					recordNormalEvent(ctx, profileScope.Profile, eventReasonWithdrawing, "Withdrawing add-ons from cluster %s",
+						getClusterDescription(cs))
+				}
 				err := c.Delete(ctx, cs)
 				if err != nil {
 					profileScope.Logger.Error(err, fmt.Sprintf("failed to update ClusterSummary for cluster %s/%s",
@@ -694,11 +809,16 @@ func cleanClusterSummaries(ctx context.Context, c client.Client, profileScope *s
 				}
 			}
 		}
-		if err := updateClusterSummarySyncMode(ctx, c, cs, profileScope.GetSpec().SyncMode); err != nil {
+		spec, _ := getClusterSummarySpec(profileScope, cs)
+		if err := updateClusterSummarySyncMode(ctx, c, cs, spec.SyncMode); err != nil {
 			return err
 		}
 	}
 
+	if confirmed {
+		consumeWithdrawalConfirmation(profileScope)
+	}
+
 	if foundClusterSummaries {
 		return fmt.Errorf("clusterSummaries still present")
 	}
@@ -730,11 +850,12 @@ func updateClusterSummarySyncMode(ctx context.Context, c client.Client,
 // ClusterReports
 
 // updateClusterReports for each Sveltos/Cluster currently matching ClusterProfile/Profile:
-// - if syncMode is DryRun, creates corresponding ClusterReport if one does not exist already;
+// - if syncMode is DryRun (or a plan is pending approval), creates corresponding ClusterReport if one
+// does not exist already;
 // - if syncMode is DryRun, deletes ClusterReports for any Sveltos/Cluster not matching anymore;
 // - if syncMode is not DryRun, deletes ClusterReports created by this ClusterProfile instance
 func updateClusterReports(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
-	if profileScope.IsDryRunSync() {
+	if profileScope.IsDryRunSync() || isPlanPendingApproval(profileScope) {
 		err := createClusterReports(ctx, c, profileScope)
 		if err != nil {
 			profileScope.Logger.Error(err, "failed to create ClusterReports")
@@ -850,12 +971,14 @@ func cleanClusterReports(ctx context.Context, c client.Client, profileScope *sco
 	return nil
 }
 
-// getProfileSpecHash returns hash of current clusterProfile/Profile Spec
+// getProfileSpecHash returns hash of the Spec ClusterSummaries created by this clusterProfile/Profile
+// must have
 func getProfileSpecHash(profileScope *scope.ProfileScope) []byte {
 	h := sha256.New()
 	var config string
 
-	config += render.AsCode(profileScope.GetSpec())
+	spec, _ := getClusterSummarySpec(profileScope, nil)
+	config += render.AsCode(spec)
 
 	h.Write([]byte(config))
 	return h.Sum(nil)
@@ -1006,6 +1129,12 @@ func reconcileDeleteCommon(ctx context.Context, c client.Client, profileScope *s
 		return err
 	}
 
+	// Report clusters where withdrawal waits for dependent profiles
+	if err := updateWaitingForDependentsCondition(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to update WaitingForDependents condition")
+		return err
+	}
+
 	if !allClusterSummariesGone(ctx, c, profileScope) {
 		msg := "not all clusterSummaries are gone"
 		logger.V(logs.LogInfo).Info(msg)
@@ -1044,23 +1173,45 @@ func reconcileNormalCommon(ctx context.Context, c client.Client, profileScope *s
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterConfigurations")
 		return err
 	}
+	// When approval is required, compute the plan for the current Spec and verify whether it is approved
+	if err := updatePlan(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to update plan")
+		return err
+	}
 	// For each matching Sveltos/Cluster, create or delete corresponding ClusterReport if needed
 	if err := updateClusterReports(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterReports")
 		return err
 	}
+	// Trip (or reset) the circuit breaker based on the failures across matching clusters
+	if _, err := evaluateCircuitBreaker(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to evaluate circuit breaker")
+		return err
+	}
 	// For each matching Sveltos/Cluster, create/update corresponding ClusterSummary
 	if err := updateClusterSummaries(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterSummaries")
 		return err
 	}
 
+	// Summarize health of provisioned features in all matching clusters
+	if err := updateDegradedCondition(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to update Degraded condition")
+		return err
+	}
+
 	// For Sveltos/Cluster not matching, deletes corresponding ClusterSummary
 	if err := cleanClusterSummaries(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to clean ClusterSummaries")
 		return err
 	}
 
+	// Report clusters where withdrawal waits for dependent profiles
+	if err := updateWaitingForDependentsCondition(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to update WaitingForDependents condition")
+		return err
+	}
+
 	// For Sveltos/Cluster not matching, removes ClusterProfile/Profile as OwnerReference
 	// from corresponding ClusterConfiguration
 	if err := cleanClusterConfigurations(ctx, c, profileScope); err != nil {
@@ -1105,17 +1256,24 @@ func getConsumersForEntry(currentMap map[corev1.ObjectReference]*libsveltosset.S
 	return s
 }
 
+// getPrerequesites returns the profiles this profile depends on and that the dependency manager can
+// automatically deploy to this profile's matching clusters. Only prerequisites of the same kind are
+// returned: a Profile depending on a ClusterProfile must not cause the ClusterProfile to be deployed.
 func getPrerequesites(profileScope *scope.ProfileScope) []corev1.ObjectReference {
 	spec := profileScope.GetSpec()
 
-	prerequesites := make([]corev1.ObjectReference, len(spec.DependsOn))
+	prerequesites := make([]corev1.ObjectReference, 0, len(spec.DependsOn))
 	for i := range spec.DependsOn {
-		prerequesites[i] = corev1.ObjectReference{
+		kind, name := configv1beta1.GetDependsOnProfile(profileScope.GetKind(), spec.DependsOn[i])
+		if kind != profileScope.GetKind() {
+			continue
+		}
+		prerequesites = append(prerequesites, corev1.ObjectReference{
 			Namespace:  profileScope.Namespace(),
-			Name:       spec.DependsOn[i],
-			Kind:       profileScope.GetKind(),
+			Name:       name,
+			Kind:       kind,
 			APIVersion: configv1beta1.GroupVersion.String(),
-		}
+		})
 	}
 
 	return prerequesites
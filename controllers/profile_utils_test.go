@@ -28,6 +28,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -535,6 +536,82 @@ var _ = Describe("Profile: Reconciler", func() {
 		Expect(len(clusterSummaryList.Items)).To(BeZero())
 	})
 
+	It("cleanClusterSummaries does not withdraw beyond WithdrawalGuard limits till confirmed", func() {
+		maxPercentage := int32(40)
+		clusterProfile.Spec.WithdrawalGuard = &configv1beta1.WithdrawalGuard{MaxPercentage: &maxPercentage}
+		clusterProfile.Status.MatchingClusterRefs = []corev1.ObjectReference{
+			{
+				Namespace: matchingCluster.Namespace, Name: matchingCluster.Name,
+				Kind: clusterKind, APIVersion: clusterv1.GroupVersion.String(),
+			},
+		}
+
+		initObjects := []client.Object{clusterProfile, matchingCluster, nonMatchingCluster}
+		for _, cluster := range []*clusterv1.Cluster{matchingCluster, nonMatchingCluster} {
+			clusterSummary := &configv1beta1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: controllers.GetClusterSummaryName(configv1beta1.ClusterProfileKind,
+						clusterProfile.Name, cluster.Name, false),
+					Namespace: cluster.Namespace,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: clusterProfile.APIVersion,
+							Kind:       clusterProfile.Kind,
+							Name:       clusterProfile.Name,
+						},
+					},
+				},
+				Spec: configv1beta1.ClusterSummarySpec{
+					ClusterNamespace:   cluster.Namespace,
+					ClusterName:        cluster.Name,
+					ClusterProfileSpec: clusterProfile.Spec,
+					ClusterType:        libsveltosv1beta1.ClusterTypeCapi,
+				},
+			}
+			addLabelsToClusterSummary(clusterSummary, clusterProfile.Name, cluster.Name,
+				libsveltosv1beta1.ClusterTypeCapi)
+			initObjects = append(initObjects, clusterSummary)
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         logger,
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// Withdrawing from 1 out of 2 clusters exceeds 40%
+		Expect(controllers.CleanClusterSummaries(context.TODO(), c, profileScope)).To(Succeed())
+
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.WithdrawalBlockedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Message).To(ContainSubstring(nonMatchingCluster.Name))
+
+		clusterSummaryList := &configv1beta1.ClusterSummaryList{}
+		Expect(c.List(context.TODO(), clusterSummaryList)).To(BeNil())
+		Expect(len(clusterSummaryList.Items)).To(Equal(2))
+
+		// Confirming the withdrawal lets it proceed
+		clusterProfile.Annotations = map[string]string{
+			configv1beta1.ConfirmWithdrawalAnnotation: controllers.GetWithdrawalHash([]string{
+				fmt.Sprintf("%s:%s/%s", libsveltosv1beta1.ClusterTypeCapi, nonMatchingCluster.Namespace, nonMatchingCluster.Name),
+			}),
+		}
+		err = controllers.CleanClusterSummaries(context.TODO(), c, profileScope)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("clusterSummaries still present"))
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions,
+			configv1beta1.WithdrawalBlockedCondition)).To(BeNil())
+		// Confirmation is consumed
+		Expect(clusterProfile.Annotations).ToNot(HaveKey(configv1beta1.ConfirmWithdrawalAnnotation))
+
+		Expect(c.List(context.TODO(), clusterSummaryList)).To(BeNil())
+		Expect(len(clusterSummaryList.Items)).To(Equal(1))
+	})
+
 	It("updateClusterSummarySyncMode updates ClusterSummary SyncMode", func() {
 		clusterSummary := &configv1beta1.ClusterSummary{
 			ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,148 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ProfileSimulationReconciler reconciles a ProfileSimulation object
+type ProfileSimulationReconciler struct {
+	client.Client
+	Scheme               *runtime.Scheme
+	ConcurrentReconciles int
+}
+
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=profilesimulations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=profilesimulations/status,verbs=get;update;patch
+
+func (r *ProfileSimulationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	logger := ctrl.LoggerFrom(ctx)
+	logger.V(logs.LogInfo).Info("Reconciling")
+
+	simulation := &configv1beta1.ProfileSimulation{}
+	if err := r.Get(ctx, req.NamespacedName, simulation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		logger.Error(err, "Failed to fetch ProfileSimulation")
+		return reconcile.Result{}, fmt.Errorf("failed to fetch ProfileSimulation %s: %w", req.NamespacedName, err)
+	}
+
+	if !simulation.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	helper, err := patch.NewHelper(simulation, r.Client)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to init patch helper")
+	}
+	defer func() {
+		if err := helper.Patch(ctx, simulation); err != nil {
+			reterr = err
+		}
+	}()
+
+	status, err := simulateProfileChanges(ctx, r.Client, simulation, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to simulate profile changes: %v", err))
+		failureMessage := err.Error()
+		simulation.Status = configv1beta1.ProfileSimulationStatus{FailureMessage: &failureMessage}
+		if apierrors.IsNotFound(err) {
+			simulation.Status.ObservedGeneration = simulation.Generation
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+
+	status.ObservedGeneration = simulation.Generation
+	simulation.Status = *status
+
+	logger.V(logs.LogInfo).Info("Reconcile success")
+	return reconcile.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProfileSimulationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	_, err := ctrl.NewControllerManagedBy(mgr).
+		For(&configv1beta1.ProfileSimulation{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.ConcurrentReconciles,
+		}).
+		Watches(&configv1beta1.ClusterProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileSimulationForProfile),
+		).
+		Watches(&configv1beta1.Profile{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileSimulationForProfile),
+		).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "error creating controller")
+	}
+
+	return nil
+}
+
+// requeueProfileSimulationForProfile returns all ProfileSimulations referencing a ClusterProfile/Profile
+func (r *ProfileSimulationReconciler) requeueProfileSimulationForProfile(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	logger := ctrl.LoggerFrom(ctx)
+
+	kind := configv1beta1.ProfileKind
+	if _, ok := o.(*configv1beta1.ClusterProfile); ok {
+		kind = configv1beta1.ClusterProfileKind
+	}
+
+	simulations := &configv1beta1.ProfileSimulationList{}
+	if err := r.List(ctx, simulations); err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ProfileSimulations: %v", err))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range simulations.Items {
+		ref := &simulations.Items[i].Spec.ProfileRef
+		if ref.Kind != kind || ref.Name != o.GetName() {
+			continue
+		}
+		if kind == configv1beta1.ProfileKind && ref.Namespace != o.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: simulations.Items[i].Name},
+		})
+	}
+
+	return requests
+}
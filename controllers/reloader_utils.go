@@ -31,8 +31,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
@@ -205,13 +205,13 @@ func getReloaderClient(ctx context.Context, clusterNamespace, clusterName string
 	logger logr.Logger) (client.Client, error) {
 
 	if getAgentInMgmtCluster() {
-		return getManagementClusterClient(), nil
+		return getManagementClusterClient(ctx), nil
 	}
 
 	// ResourceSummary is a Sveltos resource created in managed clusters.
 	// Sveltos resources are always created using cluster-admin so that admin does not need to be
 	// given such permissions.
-	return clusterproxy.GetKubernetesClient(ctx, getManagementClusterClient(),
+	return clustercache.GetManager().GetKubernetesClient(ctx, getManagementClusterClient(ctx),
 		clusterNamespace, clusterName, "", "", clusterType, logger)
 }
 
@@ -0,0 +1,544 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	"github.com/projectsveltos/libsveltos/lib/patcher"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+// RenderOptions contains everything needed to render a ClusterProfile/Profile for a cluster
+// without accessing any cluster
+type RenderOptions struct {
+	// Profile is the ClusterProfile or Profile to render
+	Profile client.Object
+
+	// Cluster is the Cluster or SveltosCluster the profile is rendered for
+	Cluster client.Object
+
+	// Objects stands in for any other resource the profile references in the management cluster:
+	// ConfigMaps, Secrets, TemplateResourceRefs, Cluster infrastructure and control plane.
+	Objects []client.Object
+
+	// Sources maps a Flux Source, in the form Kind/namespace/name, to a local directory
+	// containing its content
+	Sources map[string]string
+
+	// Charts maps a helm release, in the form releaseNamespace/releaseName, to a local chart
+	// (directory or archive). Any other chart is pulled from its repository.
+	Charts map[string]string
+}
+
+// RenderedFeature contains the resources a feature would deploy from a single PolicyRef,
+// KustomizationRef or helm chart
+type RenderedFeature struct {
+	FeatureID configv1beta1.FeatureID
+
+	// Source identifies the PolicyRef, KustomizationRef or helm release resources come from
+	Source string
+
+	// DeploymentType indicates whether resources would be deployed in the management
+	// or in the managed cluster
+	DeploymentType configv1beta1.DeploymentType
+
+	// Objects are the fully rendered resources
+	Objects []*unstructured.Unstructured
+}
+
+// Render instantiates templates, Lua scripts, Kustomize overlays, helm charts and patches of
+// a ClusterProfile/Profile for a given cluster. Only the fixtures in options are used, no
+// cluster is ever contacted.
+func Render(ctx context.Context, options *RenderOptions, logger logr.Logger,
+) ([]RenderedFeature, error) {
+
+	s, err := InitScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	initObjects := append([]client.Object{options.Profile, options.Cluster}, options.Objects...)
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(initObjects...).Build()
+
+	// Any management cluster resource is read from the fixtures
+	ctx = withOfflineManagementCluster(ctx, c)
+
+	clusterSummary, err := getRenderClusterSummary(options.Profile, options.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	mgmtResources, err := collectTemplateResourceRefs(ctx, clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]RenderedFeature, 0)
+
+	helmFeatures, err := renderHelmCharts(ctx, clusterSummary, options, mgmtResources, logger)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, helmFeatures...)
+
+	resourceFeatures, err := renderPolicyRefs(ctx, c, clusterSummary, options, mgmtResources, logger)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, resourceFeatures...)
+
+	kustomizeFeatures, err := renderKustomizationRefs(ctx, c, clusterSummary, options, logger)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, kustomizeFeatures...)
+
+	return result, nil
+}
+
+// getRenderClusterSummary returns the ClusterSummary the ClusterProfile/Profile would create
+// for the cluster
+func getRenderClusterSummary(profile, cluster client.Object) (*configv1beta1.ClusterSummary, error) {
+	var spec *configv1beta1.Spec
+	var profileKind string
+	switch p := profile.(type) {
+	case *configv1beta1.ClusterProfile:
+		spec = &p.Spec
+		profileKind = configv1beta1.ClusterProfileKind
+	case *configv1beta1.Profile:
+		spec = &p.Spec
+		profileKind = configv1beta1.ProfileKind
+	default:
+		return nil, fmt.Errorf("unsupported profile type %T", profile)
+	}
+
+	var clusterRef *corev1.ObjectReference
+	switch cluster.(type) {
+	case *libsveltosv1beta1.SveltosCluster:
+		clusterRef = &corev1.ObjectReference{
+			Namespace: cluster.GetNamespace(), Name: cluster.GetName(),
+			Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+	case *clusterv1.Cluster:
+		clusterRef = &corev1.ObjectReference{
+			Namespace: cluster.GetNamespace(), Name: cluster.GetName(),
+			Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String(),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cluster type %T", cluster)
+	}
+
+	clusterSummary := &configv1beta1.ClusterSummary{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: GetClusterSummaryName(profileKind, profile.GetName(), clusterRef.Name,
+				clusterRef.Kind == libsveltosv1beta1.SveltosClusterKind),
+			Namespace: clusterRef.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: configv1beta1.GroupVersion.String(),
+					Kind:       profileKind,
+					Name:       profile.GetName(),
+					UID:        profile.GetUID(),
+				},
+			},
+			Annotations: profile.GetAnnotations(),
+		},
+		Spec: configv1beta1.ClusterSummarySpec{
+			ClusterNamespace:   clusterRef.Namespace,
+			ClusterName:        clusterRef.Name,
+			ClusterType:        clusterproxy.GetClusterType(clusterRef),
+			ClusterProfileSpec: *spec,
+		},
+	}
+
+	// Labels depend on the profile kind, which fixtures decoded into typed objects might not carry
+	profile = profile.DeepCopyObject().(client.Object)
+	profile.GetObjectKind().SetGroupVersionKind(configv1beta1.GroupVersion.WithKind(profileKind))
+	profileScope := &scope.ProfileScope{Profile: profile}
+	addClusterSummaryLabels(clusterSummary, profileScope, clusterRef)
+
+	return clusterSummary, nil
+}
+
+func renderHelmCharts(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	options *RenderOptions, mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
+) ([]RenderedFeature, error) {
+
+	result := make([]RenderedFeature, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+		currentChart := &clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i]
+
+		instantiatedChart, err := getInstantiatedChart(ctx, clusterSummary, currentChart, mgmtResources, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		l := logger.WithValues("release", fmt.Sprintf("%s/%s",
+			instantiatedChart.ReleaseNamespace, instantiatedChart.ReleaseName))
+
+		objects, err := renderHelmChart(ctx, clusterSummary, instantiatedChart, options, mgmtResources, l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render helm chart %s/%s: %w",
+				instantiatedChart.ReleaseNamespace, instantiatedChart.ReleaseName, err)
+		}
+
+		result = append(result, RenderedFeature{
+			FeatureID:      configv1beta1.FeatureHelm,
+			Source:         fmt.Sprintf("HelmChart %s/%s", instantiatedChart.ReleaseNamespace, instantiatedChart.ReleaseName),
+			DeploymentType: configv1beta1.DeploymentTypeRemote,
+			Objects:        objects,
+		})
+	}
+
+	return result, nil
+}
+
+// renderHelmChart renders a helm chart the way "helm template" does
+func renderHelmChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart, options *RenderOptions,
+	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
+) ([]*unstructured.Unstructured, error) {
+
+	values, err := getInstantiatedValues(ctx, clusterSummary, mgmtResources, requestedChart, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	patches, err := initiatePatches(ctx, clusterSummary, requestedChart.ChartName, mgmtResources, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	registryClient, err := registry.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	installClient := action.NewInstall(&action.Configuration{Log: debugf, RegistryClient: registryClient})
+	installClient.ReleaseName = requestedChart.ReleaseName
+	installClient.Namespace = requestedChart.ReleaseNamespace
+	installClient.Version = requestedChart.ChartVersion
+	installClient.DryRun = true
+	installClient.ClientOnly = true
+	installClient.Replace = true
+	installClient.IncludeCRDs = !getSkipCRDsHelmValue(requestedChart.Options)
+	installClient.DisableHooks = getDisableHooksHelmInstallValue(requestedChart.Options)
+	installClient.SkipSchemaValidation = getSkipSchemaValidation(requestedChart.Options)
+	installClient.Labels = getLabelsValue(requestedChart.Options)
+	installClient.SetRegistryClient(registryClient)
+	if len(patches) > 0 {
+		installClient.PostRenderer = &patcher.CustomPatchPostRenderer{Patches: patches}
+	}
+
+	chartPath, err := locateRenderChart(ctx, clusterSummary, requestedChart, options, installClient)
+	if err != nil {
+		return nil, err
+	}
+
+	chartRequested, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isChartInstallable(chartRequested) {
+		return nil, fmt.Errorf("chart is not installable")
+	}
+
+	r, err := installClient.RunWithContext(ctx, chartRequested, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectHelmContent(r.Manifest, logger)
+}
+
+// locateRenderChart returns the path of the chart to render. Charts are taken, in order, from:
+// options.Charts, the local directory standing in for the referenced Flux Source, the chart repository.
+func locateRenderChart(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
+	requestedChart *configv1beta1.HelmChart, options *RenderOptions, installClient *action.Install,
+) (string, error) {
+
+	if chartPath, ok := options.Charts[fmt.Sprintf("%s/%s", requestedChart.ReleaseNamespace,
+		requestedChart.ReleaseName)]; ok {
+
+		return chartPath, nil
+	}
+
+	if isReferencingFluxSource(requestedChart) {
+		sourceRef, repoPath, err := getReferencedFluxSourceFromURL(requestedChart)
+		if err != nil {
+			return "", err
+		}
+		dir, err := getRenderSourceDir(options, sourceRef.Kind, sourceRef.Namespace, sourceRef.Name)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, repoPath), nil
+	}
+
+	if requestedChart.ChartName == "" {
+		return "", fmt.Errorf("chart name can not be empty")
+	}
+
+	chartName := requestedChart.ChartName
+	if registry.IsOCI(requestedChart.RepositoryURL) {
+		chartName = fmt.Sprintf("%s/%s", requestedChart.RepositoryURL, requestedChart.ChartName)
+	} else {
+		installClient.RepoURL = requestedChart.RepositoryURL
+	}
+
+	return installClient.LocateChart(chartName, getSettings(requestedChart.ReleaseNamespace, &registryClientOptions{}))
+}
+
+func renderPolicyRefs(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	options *RenderOptions, mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
+) ([]RenderedFeature, error) {
+
+	result := make([]RenderedFeature, 0)
+	refs := getResourceRefs(clusterSummary)
+	for i := range refs {
+		ref := &refs[i]
+
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, c,
+			clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, ref.Namespace,
+			clusterSummary.Spec.ClusterType)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := libsveltostemplate.GetReferenceResourceName(ctx, c,
+			clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, ref.Name,
+			clusterSummary.Spec.ClusterType)
+		if err != nil {
+			return nil, err
+		}
+
+		referencedObject, data, err := getRenderPolicyRefContent(ctx, c, clusterSummary, options, ref,
+			namespace, name, logger)
+		if err != nil {
+			return nil, err
+		}
+		if referencedObject == nil {
+			// Optional and not found
+			continue
+		}
+
+		resources, err := collectContent(ctx, clusterSummary, mgmtResources, data,
+			instantiateTemplate(referencedObject, logger), instantiateWithLua(referencedObject, logger), logger)
+		if err != nil {
+			return nil, err
+		}
+
+		resources, err = applyPatches(ctx, clusterSummary, resources, mgmtResources, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, RenderedFeature{
+			FeatureID:      configv1beta1.FeatureResources,
+			Source:         fmt.Sprintf("%s %s/%s", ref.Kind, namespace, name),
+			DeploymentType: ref.DeploymentType,
+			Objects:        sortResourcesForApply(resources),
+		})
+	}
+
+	return result, nil
+}
+
+// getRenderPolicyRefContent returns the object referenced by a PolicyRef and its content.
+// Returns a nil object if an optional referenced resource does not exist.
+func getRenderPolicyRefContent(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	options *RenderOptions, ref *configv1beta1.PolicyRef, namespace, name string, logger logr.Logger,
+) (client.Object, map[string]string, error) {
+
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	switch ref.Kind {
+	case string(libsveltosv1beta1.ConfigMapReferencedResourceKind):
+		configMap, err := getConfigMap(ctx, c, key)
+		if err != nil {
+			return handleRenderReferenceError(err, ref, namespace, name)
+		}
+		return configMap, configMap.Data, nil
+	case string(libsveltosv1beta1.SecretReferencedResourceKind):
+		secret, err := getSecret(ctx, c, key)
+		if err != nil {
+			return handleRenderReferenceError(err, ref, namespace, name)
+		}
+		data := make(map[string]string)
+		for k, v := range secret.Data {
+			data[k] = string(v)
+		}
+		return secret, data, nil
+	}
+
+	dir, err := getRenderSourceDir(options, ref.Kind, namespace, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The Source itself is optional in the fixtures. When present, its annotations
+	// indicate whether content is a template or contains a Lua script.
+	var source client.Object = &unstructured.Unstructured{}
+	if s, err := getSource(ctx, c, namespace, name, ref.Kind); err == nil && s != nil {
+		source = s
+	}
+
+	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(ctx), c,
+		clusterSummary, clusterSummary.GetName(), ref.Path, nil, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := readFiles(filepath.Join(dir, instantiatedPath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return source, data, nil
+}
+
+func handleRenderReferenceError(err error, ref *configv1beta1.PolicyRef, namespace, name string,
+) (client.Object, map[string]string, error) {
+
+	if client.IgnoreNotFound(err) == nil {
+		if ref.Optional {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("referenced resource: %s %s/%s does not exist", ref.Kind, namespace, name)
+	}
+	return nil, nil, err
+}
+
+// getRenderSourceDir returns the local directory standing in for a Flux Source
+func getRenderSourceDir(options *RenderOptions, kind, namespace, name string) (string, error) {
+
+	key := fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+	dir, ok := options.Sources[key]
+	if !ok {
+		return "", fmt.Errorf("no local directory provided for source %s", key)
+	}
+
+	return dir, nil
+}
+
+func renderKustomizationRefs(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	options *RenderOptions, logger logr.Logger) ([]RenderedFeature, error) {
+
+	result := make([]RenderedFeature, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs {
+		kustomizationRef := &clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[i]
+
+		resMap, err := buildRenderKustomization(ctx, c, clusterSummary, options, kustomizationRef, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		local, remote, mgmtResources, err := getKustomizedResources(ctx, c, clusterSummary,
+			kustomizationRef.DeploymentType, resMap, kustomizationRef, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		resources := append(local, remote...)
+		resources, err = applyPatches(ctx, clusterSummary, resources, mgmtResources, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, RenderedFeature{
+			FeatureID: configv1beta1.FeatureKustomize,
+			Source: fmt.Sprintf("%s %s/%s:%s", kustomizationRef.Kind, kustomizationRef.Namespace,
+				kustomizationRef.Name, kustomizationRef.Path),
+			DeploymentType: kustomizationRef.DeploymentType,
+			Objects:        sortResourcesForApply(resources),
+		})
+	}
+
+	return result, nil
+}
+
+func buildRenderKustomization(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	options *RenderOptions, kustomizationRef *configv1beta1.KustomizationRef, logger logr.Logger,
+) (resmap.ResMap, error) {
+
+	var dir string
+	switch kustomizationRef.Kind {
+	case string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+		string(libsveltosv1beta1.SecretReferencedResourceKind):
+
+		tmpDir, err := prepareFileSystem(ctx, c, kustomizationRef, clusterSummary, logger)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmpDir)
+		dir = tmpDir
+	default:
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, c, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, kustomizationRef.Namespace, clusterSummary.Spec.ClusterType)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := libsveltostemplate.GetReferenceResourceName(ctx, c, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, kustomizationRef.Name, clusterSummary.Spec.ClusterType)
+		if err != nil {
+			return nil, err
+		}
+
+		dir, err = getRenderSourceDir(options, kustomizationRef.Kind, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Path can be expressed as a template and instantiate using Cluster fields.
+	instantiatedPath, err := instantiateTemplateValues(ctx, getManagementClusterConfig(ctx), c,
+		clusterSummary, clusterSummary.GetName(), kustomizationRef.Path, nil, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	dirPath := filepath.Join(dir, instantiatedPath)
+	if _, err := os.Stat(dirPath); err != nil {
+		return nil, fmt.Errorf("kustomization path not found: %w", err)
+	}
+
+	return buildKustomization(filesys.MakeFsOnDisk(), dirPath)
+}
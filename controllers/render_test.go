@@ -0,0 +1,134 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Render", func() {
+	It("Render instantiates policyRefs and kustomizationRefs using only local fixtures", func() {
+		namespace := randomString()
+
+		cluster := &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+		}
+
+		info := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			Data:       map[string]string{"region": "eu"},
+		}
+
+		policies := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        randomString(),
+				Annotations: map[string]string{libsveltosv1beta1.PolicyTemplateAnnotation: "ok"},
+			},
+			Data: map[string]string{
+				"namespace.yaml": `apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .Cluster.metadata.name }}-{{ (getResource "Info").data.region }}`,
+			},
+		}
+
+		repoDir, err := os.MkdirTemp("", randomString())
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(repoDir)
+		Expect(os.Mkdir(filepath.Join(repoDir, "overlay"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(repoDir, "overlay", "kustomization.yaml"),
+			[]byte("resources:\n- serviceaccount.yaml\n"), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(repoDir, "overlay", "serviceaccount.yaml"), []byte(`apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+  namespace: web
+  labels:
+    app: web`), 0o600)).To(Succeed())
+
+		repoName := randomString()
+		clusterProfile := &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.Spec{
+				TemplateResourceRefs: []configv1beta1.TemplateResourceRef{
+					{
+						Resource: corev1.ObjectReference{
+							APIVersion: "v1", Kind: "ConfigMap", Namespace: namespace, Name: info.Name,
+						},
+						Identifier: "Info",
+					},
+				},
+				PolicyRefs: []configv1beta1.PolicyRef{
+					{Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind), Namespace: namespace, Name: policies.Name},
+				},
+				KustomizationRefs: []configv1beta1.KustomizationRef{
+					{Kind: sourcev1.GitRepositoryKind, Namespace: namespace, Name: repoName, Path: "overlay"},
+				},
+				Patches: []libsveltosv1beta1.Patch{
+					{
+						Target: &libsveltosv1beta1.PatchSelector{Kind: "ServiceAccount"},
+						Patch: `- op: add
+  path: /metadata/labels/patched
+  value: "true"`,
+					},
+				},
+			},
+		}
+
+		features, err := controllers.Render(context.TODO(), &controllers.RenderOptions{
+			Profile: clusterProfile,
+			Cluster: cluster,
+			Objects: []client.Object{info, policies},
+			Sources: map[string]string{sourcev1.GitRepositoryKind + "/" + namespace + "/" + repoName: repoDir},
+		}, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(len(features)).To(Equal(2))
+
+		Expect(features[0].FeatureID).To(Equal(configv1beta1.FeatureResources))
+		Expect(len(features[0].Objects)).To(Equal(1))
+		Expect(features[0].Objects[0].GetName()).To(Equal(cluster.Name + "-eu"))
+
+		Expect(features[1].FeatureID).To(Equal(configv1beta1.FeatureKustomize))
+		Expect(len(features[1].Objects)).To(Equal(1))
+		Expect(features[1].Objects[0].GetKind()).To(Equal("ServiceAccount"))
+		Expect(features[1].Objects[0].GetLabels()).To(HaveKeyWithValue("patched", "true"))
+
+		// A source without a local directory cannot be rendered
+		_, err = controllers.Render(context.TODO(), &controllers.RenderOptions{
+			Profile: clusterProfile,
+			Cluster: cluster,
+			Objects: []client.Object{info, policies},
+		}, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,229 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Resources are applied following Helm install order: Namespaces, then CRDs, then RBAC, then
+// workloads. Kinds not listed (for instance instances of CRDs) are applied after all listed kinds but
+// before webhook configurations, which are always applied last so that they never intercept resources
+// being deployed by the same bundle. Resources are withdrawn in reverse order.
+// Before an instance of a CRD deployed by the same bundle is applied, CRD is required to be Established.
+
+const (
+	crdKind = "CustomResourceDefinition"
+
+	crdEstablishedTimeout  = time.Minute
+	crdEstablishedInterval = time.Second
+)
+
+var (
+	applyOrder = []string{
+		"PriorityClass",
+		"Namespace",
+		"NetworkPolicy",
+		"ResourceQuota",
+		"LimitRange",
+		"PodSecurityPolicy",
+		"PodDisruptionBudget",
+		"ServiceAccount",
+		"Secret",
+		"SecretList",
+		"ConfigMap",
+		"StorageClass",
+		"PersistentVolume",
+		"PersistentVolumeClaim",
+		crdKind,
+		"ClusterRole",
+		"ClusterRoleList",
+		"ClusterRoleBinding",
+		"ClusterRoleBindingList",
+		"Role",
+		"RoleList",
+		"RoleBinding",
+		"RoleBindingList",
+		"Service",
+		"DaemonSet",
+		"Pod",
+		"ReplicationController",
+		"ReplicaSet",
+		"Deployment",
+		"HorizontalPodAutoscaler",
+		"StatefulSet",
+		"Job",
+		"CronJob",
+		"IngressClass",
+		"Ingress",
+		"APIService",
+	}
+
+	// webhooks are applied after any other kind, including kinds not listed in applyOrder
+	webhookKinds = []string{
+		"MutatingWebhookConfiguration",
+		"ValidatingWebhookConfiguration",
+	}
+)
+
+// getApplyOrder returns the position of a kind within the apply order
+func getApplyOrder(kind string) int {
+	for i := range applyOrder {
+		if applyOrder[i] == kind {
+			return i
+		}
+	}
+
+	for i := range webhookKinds {
+		if webhookKinds[i] == kind {
+			return len(applyOrder) + 1 + i
+		}
+	}
+
+	// Any other kind goes after all listed kinds and before webhooks
+	return len(applyOrder)
+}
+
+// sortResourcesForApply sorts resources following the apply order. Resources of the same kind (or
+// of kinds with same position) keep the order they were defined with.
+func sortResourcesForApply(resources []*unstructured.Unstructured) []*unstructured.Unstructured {
+	sorted := make([]*unstructured.Unstructured, len(resources))
+	copy(sorted, resources)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return getApplyOrder(sorted[i].GetKind()) < getApplyOrder(sorted[j].GetKind())
+	})
+
+	return sorted
+}
+
+// sortGVKsForWithdraw sorts GroupVersionKinds in reverse apply order
+func sortGVKsForWithdraw(gvks []schema.GroupVersionKind) []schema.GroupVersionKind {
+	sorted := make([]schema.GroupVersionKind, len(gvks))
+	copy(sorted, gvks)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return getApplyOrder(sorted[i].Kind) > getApplyOrder(sorted[j].Kind)
+	})
+
+	return sorted
+}
+
+// getCRDGroupKind returns the GroupKind defined by a CustomResourceDefinition
+func getCRDGroupKind(crd *unstructured.Unstructured) (schema.GroupKind, error) {
+	group, _, err := unstructured.NestedString(crd.Object, "spec", "group")
+	if err != nil {
+		return schema.GroupKind{}, err
+	}
+	kind, _, err := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	if err != nil {
+		return schema.GroupKind{}, err
+	}
+
+	return schema.GroupKind{Group: group, Kind: kind}, nil
+}
+
+// appliedCRDs tracks CustomResourceDefinitions applied while deploying a bundle.
+// key: GroupKind defined by the CRD; value: CRD name
+type appliedCRDs map[schema.GroupKind]string
+
+func (a appliedCRDs) record(policy *unstructured.Unstructured) {
+	if policy.GetKind() != crdKind {
+		return
+	}
+
+	gk, err := getCRDGroupKind(policy)
+	if err != nil || gk.Kind == "" {
+		return
+	}
+
+	a[gk] = policy.GetName()
+}
+
+// waitForCRD waits, if the policy is an instance of a CRD applied earlier in the same bundle, for the
+// CRD to be Established. Once established, destClient RESTMapper is reset so the new kind is discovered.
+func (a appliedCRDs) waitForCRD(ctx context.Context, destConfig *rest.Config, destClient client.Client,
+	policy *unstructured.Unstructured, logger logr.Logger) error {
+
+	gk := policy.GroupVersionKind().GroupKind()
+	crdName, ok := a[gk]
+	if !ok {
+		return nil
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("waiting for CustomResourceDefinition %s to be established", crdName))
+	err := waitForCRDEstablished(ctx, destConfig, crdName)
+	if err != nil {
+		return err
+	}
+	delete(a, gk)
+
+	resetRESTMapper(destClient)
+	return nil
+}
+
+func waitForCRDEstablished(ctx context.Context, destConfig *rest.Config, crdName string) error {
+	gvk := schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: crdKind}
+	dr, err := k8s_utils.GetDynamicResourceInterface(destConfig, gvk, "")
+	if err != nil {
+		return err
+	}
+
+	var lastMsg string
+	err = wait.PollUntilContextTimeout(ctx, crdEstablishedInterval, crdEstablishedTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			crd, err := dr.Get(ctx, crdName, metav1.GetOptions{})
+			if err != nil {
+				lastMsg = err.Error()
+				return false, nil
+			}
+			var ready bool
+			ready, lastMsg = isResourceReady(crd)
+			return ready, nil
+		})
+	if err != nil {
+		return fmt.Errorf("CustomResourceDefinition %s is not established: %s", crdName, lastMsg)
+	}
+
+	return nil
+}
+
+// resetRESTMapper drops cached discovery information, if any, so newly established kinds are found
+func resetRESTMapper(c client.Client) {
+	if c == nil {
+		return
+	}
+
+	if mapper, ok := c.RESTMapper().(meta.ResettableRESTMapper); ok {
+		mapper.Reset()
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Resource ordering", func() {
+	newResource := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		return u
+	}
+
+	It("sortResourcesForApply sorts resources by kind keeping definition order within a kind", func() {
+		resources := []*unstructured.Unstructured{
+			newResource("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "webhook"),
+			newResource("example.com/v1", "Foo", "foo"),
+			newResource("apps/v1", "Deployment", "deployment"),
+			newResource("rbac.authorization.k8s.io/v1", "ClusterRole", "role"),
+			newResource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "foos.example.com"),
+			newResource("v1", "ConfigMap", "second"),
+			newResource("v1", "Namespace", "namespace"),
+			newResource("v1", "ConfigMap", "first"),
+		}
+
+		sorted := controllers.SortResourcesForApply(resources)
+		names := make([]string, len(sorted))
+		for i := range sorted {
+			names[i] = sorted[i].GetName()
+		}
+		Expect(names).To(Equal([]string{"namespace", "second", "first", "foos.example.com", "role",
+			"deployment", "foo", "webhook"}))
+
+		// Input is left untouched
+		Expect(resources[0].GetName()).To(Equal("webhook"))
+	})
+
+	It("sortGVKsForWithdraw sorts GVKs in reverse apply order", func() {
+		gvks := []schema.GroupVersionKind{
+			{Version: "v1", Kind: "Namespace"},
+			{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"},
+			{Group: "apps", Version: "v1", Kind: "Deployment"},
+			{Group: "example.com", Version: "v1", Kind: "Foo"},
+			{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"},
+		}
+
+		sorted := controllers.SortGVKsForWithdraw(gvks)
+		kinds := make([]string, len(sorted))
+		for i := range sorted {
+			kinds[i] = sorted[i].Kind
+		}
+		Expect(kinds).To(Equal([]string{"MutatingWebhookConfiguration", "Foo", "Deployment",
+			"CustomResourceDefinition", "Namespace"}))
+	})
+})
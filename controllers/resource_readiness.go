@@ -0,0 +1,236 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Built-in readiness evaluation for resources deployed in managed clusters. Rules follow
+// what kubectl rollout status and kstatus consider "Current":
+// - observedGeneration, when reported, must match generation;
+// - well known kinds (workloads, Jobs, Pods, PVCs, Services, CRDs, APIServices, Namespaces) have specific rules;
+// - any other resource is ready unless it reports a Ready condition which is not True.
+
+const (
+	conditionStatusTrue = "True"
+)
+
+// isResourceReady returns true if resource is ready. When not ready, a message explaining
+// why is returned as well.
+func isResourceReady(u *unstructured.Unstructured) (ready bool, message string) {
+	if observed, found, _ := unstructured.NestedInt64(u.Object, "status", "observedGeneration"); found {
+		if observed < u.GetGeneration() {
+			return false, fmt.Sprintf("%s %s: observedGeneration %d is behind generation %d",
+				u.GetKind(), getResourceReadinessName(u), observed, u.GetGeneration())
+		}
+	}
+
+	var notReady string
+	switch u.GroupVersionKind().GroupKind().String() {
+	case "Deployment.apps":
+		notReady = isDeploymentReady(u)
+	case "StatefulSet.apps":
+		notReady = isStatefulSetReady(u)
+	case "DaemonSet.apps":
+		notReady = isDaemonSetReady(u)
+	case "ReplicaSet.apps":
+		notReady = isReplicaSetReady(u)
+	case "Job.batch":
+		notReady = isJobReady(u)
+	case "Pod":
+		notReady = isPodReady(u)
+	case "PersistentVolumeClaim":
+		notReady = isPersistentVolumeClaimReady(u)
+	case "Service":
+		notReady = isServiceReady(u)
+	case "CustomResourceDefinition.apiextensions.k8s.io":
+		notReady = isCustomResourceDefinitionReady(u)
+	case "APIService.apiregistration.k8s.io":
+		notReady = isAPIServiceReady(u)
+	case "Namespace":
+		notReady = isNamespaceReady(u)
+	default:
+		notReady = isGenericResourceReady(u)
+	}
+
+	if notReady != "" {
+		return false, fmt.Sprintf("%s %s: %s", u.GetKind(), getResourceReadinessName(u), notReady)
+	}
+
+	return true, ""
+}
+
+func getResourceReadinessName(u *unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return u.GetName()
+	}
+	return fmt.Sprintf("%s/%s", u.GetNamespace(), u.GetName())
+}
+
+func getSpecReplicas(u *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+func getStatusInt(u *unstructured.Unstructured, field string) int64 {
+	value, _, _ := unstructured.NestedInt64(u.Object, "status", field)
+	return value
+}
+
+// getConditionStatus returns the status of the condition of the given type. Empty string is
+// returned if condition is not reported.
+func getConditionStatus(u *unstructured.Unstructured, conditionType string) (status, message string) {
+	conditions, found, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if !found {
+		return "", ""
+	}
+
+	for i := range conditions {
+		condition, ok := conditions[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _ := condition["type"].(string); t != conditionType {
+			continue
+		}
+		status, _ = condition["status"].(string)
+		message, _ = condition["message"].(string)
+		return status, message
+	}
+
+	return "", ""
+}
+
+func isDeploymentReady(u *unstructured.Unstructured) string {
+	replicas := getSpecReplicas(u)
+	if updated := getStatusInt(u, "updatedReplicas"); updated < replicas {
+		return fmt.Sprintf("%d out of %d replicas updated", updated, replicas)
+	}
+	if available := getStatusInt(u, "availableReplicas"); available < replicas {
+		return fmt.Sprintf("%d out of %d replicas available", available, replicas)
+	}
+	if status, message := getConditionStatus(u, "Available"); status != "" && status != conditionStatusTrue {
+		return fmt.Sprintf("not available: %s", message)
+	}
+	return ""
+}
+
+func isStatefulSetReady(u *unstructured.Unstructured) string {
+	replicas := getSpecReplicas(u)
+	if ready := getStatusInt(u, "readyReplicas"); ready < replicas {
+		return fmt.Sprintf("%d out of %d replicas ready", ready, replicas)
+	}
+	if updated := getStatusInt(u, "updatedReplicas"); updated < replicas {
+		return fmt.Sprintf("%d out of %d replicas updated", updated, replicas)
+	}
+	return ""
+}
+
+func isDaemonSetReady(u *unstructured.Unstructured) string {
+	desired := getStatusInt(u, "desiredNumberScheduled")
+	if updated := getStatusInt(u, "updatedNumberScheduled"); updated < desired {
+		return fmt.Sprintf("%d out of %d pods updated", updated, desired)
+	}
+	if available := getStatusInt(u, "numberAvailable"); available < desired {
+		return fmt.Sprintf("%d out of %d pods available", available, desired)
+	}
+	return ""
+}
+
+func isReplicaSetReady(u *unstructured.Unstructured) string {
+	replicas := getSpecReplicas(u)
+	if available := getStatusInt(u, "availableReplicas"); available < replicas {
+		return fmt.Sprintf("%d out of %d replicas available", available, replicas)
+	}
+	return ""
+}
+
+func isJobReady(u *unstructured.Unstructured) string {
+	if status, message := getConditionStatus(u, "Failed"); status == conditionStatusTrue {
+		return fmt.Sprintf("failed: %s", message)
+	}
+	if status, _ := getConditionStatus(u, "Complete"); status != conditionStatusTrue {
+		return "not completed yet"
+	}
+	return ""
+}
+
+func isPodReady(u *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+	if phase == "Succeeded" {
+		return ""
+	}
+	if status, _ := getConditionStatus(u, "Ready"); status != conditionStatusTrue {
+		return fmt.Sprintf("not ready (phase %s)", phase)
+	}
+	return ""
+}
+
+func isPersistentVolumeClaimReady(u *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+	if phase != "Bound" {
+		return fmt.Sprintf("not bound (phase %s)", phase)
+	}
+	return ""
+}
+
+func isServiceReady(u *unstructured.Unstructured) string {
+	serviceType, _, _ := unstructured.NestedString(u.Object, "spec", "type")
+	if serviceType != "LoadBalancer" {
+		return ""
+	}
+	ingress, _, _ := unstructured.NestedSlice(u.Object, "status", "loadBalancer", "ingress")
+	if len(ingress) == 0 {
+		return "load balancer ingress not assigned yet"
+	}
+	return ""
+}
+
+func isCustomResourceDefinitionReady(u *unstructured.Unstructured) string {
+	if status, _ := getConditionStatus(u, "Established"); status != conditionStatusTrue {
+		return "not established"
+	}
+	return ""
+}
+
+func isAPIServiceReady(u *unstructured.Unstructured) string {
+	if status, message := getConditionStatus(u, "Available"); status != conditionStatusTrue {
+		return fmt.Sprintf("not available: %s", message)
+	}
+	return ""
+}
+
+func isNamespaceReady(u *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+	if phase != "" && phase != "Active" {
+		return fmt.Sprintf("not active (phase %s)", phase)
+	}
+	return ""
+}
+
+func isGenericResourceReady(u *unstructured.Unstructured) string {
+	if status, message := getConditionStatus(u, "Ready"); status != "" && status != conditionStatusTrue {
+		return fmt.Sprintf("not ready: %s", message)
+	}
+	return ""
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
+)
+
+// PolicyRefs and KustomizationRefs can opt in to wait for all the resources they deployed to be ready.
+// Readiness is evaluated with isResourceReady. While waiting, feature stays Provisioning and
+// FailureMessage lists the resources which are not ready yet.
+
+const (
+	defaultWaitTimeout = 5 * time.Minute
+	waitPollInterval   = 5 * time.Second
+
+	// maximum number of pending resources listed while waiting
+	maxPendingResourcesInMessage = 5
+)
+
+// waitReference is a PolicyRef/KustomizationRef with Wait set
+type waitReference struct {
+	namespace string
+	name      string
+	kind      string
+	timeout   *metav1.Duration
+	// instantiate indicates whether namespace and name recorded as deployed resources owner
+	// are the instantiated ones (PolicyRefs) or the ones as expressed in the reference (KustomizationRefs)
+	instantiate bool
+}
+
+// resourceToWaitFor is a deployed resource which needs to be ready before given deadline
+type resourceToWaitFor struct {
+	resource configv1beta1.Resource
+	deadline time.Time
+	local    bool
+}
+
+func getPolicyRefsToWaitFor(clusterSummary *configv1beta1.ClusterSummary) []waitReference {
+	refs := make([]waitReference, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PolicyRefs {
+		ref := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i]
+		if ref.Wait {
+			refs = append(refs, waitReference{namespace: ref.Namespace, name: ref.Name, kind: ref.Kind,
+				timeout: ref.Timeout, instantiate: true})
+		}
+	}
+	return refs
+}
+
+func getKustomizationRefsToWaitFor(clusterSummary *configv1beta1.ClusterSummary) []waitReference {
+	refs := make([]waitReference, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs {
+		ref := &clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[i]
+		if ref.Wait {
+			refs = append(refs, waitReference{namespace: ref.Namespace, name: ref.Name, kind: ref.Kind,
+				timeout: ref.Timeout})
+		}
+	}
+	return refs
+}
+
+func getWaitTimeout(timeout *metav1.Duration) time.Duration {
+	if timeout == nil || timeout.Duration <= 0 {
+		return defaultWaitTimeout
+	}
+	return timeout.Duration
+}
+
+// getWaitHash returns a string representing wait configuration. Empty if no reference sets Wait,
+// so hash does not change for references not waiting.
+func getWaitHash(refs []waitReference) string {
+	var config string
+	for i := range refs {
+		config += fmt.Sprintf("wait:%s:%s/%s:%s", refs[i].kind, refs[i].namespace, refs[i].name,
+			getWaitTimeout(refs[i].timeout))
+	}
+	return config
+}
+
+// instantiateWaitReferences returns, for each reference with Wait set, the reference as recorded
+// in deployed resource Owner and the wait timeout
+func instantiateWaitReferences(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	refs []waitReference) (map[corev1.ObjectReference]time.Duration, error) {
+
+	result := make(map[corev1.ObjectReference]time.Duration, len(refs))
+	for i := range refs {
+		if !refs[i].instantiate {
+			result[corev1.ObjectReference{Kind: refs[i].kind, Namespace: refs[i].namespace, Name: refs[i].name}] =
+				getWaitTimeout(refs[i].timeout)
+			continue
+		}
+
+		namespace, err := libsveltostemplate.GetReferenceResourceNamespace(ctx, c,
+			clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, refs[i].namespace,
+			clusterSummary.Spec.ClusterType)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := libsveltostemplate.GetReferenceResourceName(ctx, c, clusterSummary.Spec.ClusterNamespace,
+			clusterSummary.Spec.ClusterName, refs[i].name, clusterSummary.Spec.ClusterType)
+		if err != nil {
+			return nil, err
+		}
+
+		result[corev1.ObjectReference{Kind: refs[i].kind, Namespace: namespace, Name: name}] =
+			getWaitTimeout(refs[i].timeout)
+	}
+
+	return result, nil
+}
+
+// selectResourcesToWaitFor returns deployed resources whose owner (the PolicyRef/KustomizationRef) sets Wait
+func selectResourcesToWaitFor(reports []configv1beta1.ResourceReport, waits map[corev1.ObjectReference]time.Duration,
+	local bool, start time.Time) []resourceToWaitFor {
+
+	resources := make([]resourceToWaitFor, 0)
+	for i := range reports {
+		// Resources being withdrawn or not deployed because of a conflict are not waited for
+		if reports[i].Action == string(configv1beta1.DeleteResourceAction) ||
+			reports[i].Action == string(configv1beta1.ConflictResourceAction) {
+
+			continue
+		}
+		owner := reports[i].Resource.Owner
+		timeout, ok := waits[corev1.ObjectReference{Kind: owner.Kind, Namespace: owner.Namespace, Name: owner.Name}]
+		if !ok {
+			continue
+		}
+		resources = append(resources, resourceToWaitFor{
+			resource: reports[i].Resource,
+			deadline: start.Add(timeout),
+			local:    local,
+		})
+	}
+
+	return resources
+}
+
+// waitForDeployedResources waits for all resources deployed because of a reference with Wait set
+// to be ready. An error is returned if any resource is still not ready once its deadline is reached.
+func waitForDeployedResources(ctx context.Context, c client.Client, remoteConfig *rest.Config,
+	clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID, refs []waitReference,
+	localReports, remoteReports []configv1beta1.ResourceReport, logger logr.Logger) error {
+
+	if len(refs) == 0 || clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1beta1.SyncModeDryRun {
+		return nil
+	}
+
+	waits, err := instantiateWaitReferences(ctx, c, clusterSummary, refs)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	resources := selectResourcesToWaitFor(localReports, waits, true, start)
+	resources = append(resources, selectResourcesToWaitFor(remoteReports, waits, false, start)...)
+	if len(resources) == 0 {
+		return nil
+	}
+
+	remoteClient, err := client.New(remoteConfig, client.Options{Scheme: c.Scheme()})
+	if err != nil {
+		return err
+	}
+
+	return waitForResourcesToBeReady(ctx, c, remoteClient, clusterSummary, featureID, resources, logger)
+}
+
+// waitForResourcesToBeReady polls resources till all are ready. An error is returned if any resource is
+// still not ready once its deadline is reached.
+func waitForResourcesToBeReady(ctx context.Context, c, remoteClient client.Client,
+	clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	resources []resourceToWaitFor, logger logr.Logger) error {
+
+	if len(resources) == 0 {
+		return nil
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("waiting for %d resources to be ready", len(resources)))
+	lastReported := ""
+	for {
+		var pending []string
+		var err error
+		pending, resources, err = getPendingResources(ctx, getManagementClusterClient(ctx), remoteClient, resources)
+		if err != nil {
+			return err
+		}
+
+		if len(resources) == 0 {
+			logger.V(logs.LogDebug).Info("all resources are ready")
+			return nil
+		}
+
+		for i := range resources {
+			if time.Now().After(resources[i].deadline) {
+				return fmt.Errorf("timed out waiting for resources to be ready: %s",
+					getPendingResourcesMessage(pending))
+			}
+		}
+
+		msg := fmt.Sprintf("waiting for resources to be ready: %s", getPendingResourcesMessage(pending))
+		if msg != lastReported {
+			logger.V(logs.LogDebug).Info(msg)
+			if err := reportPendingResources(ctx, c, clusterSummary, featureID, msg); err != nil {
+				logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to report pending resources: %v", err))
+			}
+			lastReported = msg
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+// getPendingResources returns the resources which are not ready yet along with a message
+// explaining why each one is not ready.
+func getPendingResources(ctx context.Context, localClient, remoteClient client.Client,
+	resources []resourceToWaitFor) (pending []string, notReady []resourceToWaitFor, err error) {
+
+	for i := range resources {
+		c := remoteClient
+		if resources[i].local {
+			c = localClient
+		}
+
+		resource := &libsveltosv1beta1.Resource{
+			Namespace: resources[i].resource.Namespace,
+			Name:      resources[i].resource.Name,
+			Group:     resources[i].resource.Group,
+			Kind:      resources[i].resource.Kind,
+			Version:   resources[i].resource.Version,
+		}
+		u, err := fetchDeployedResource(ctx, c, resource)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				pending = append(pending, fmt.Sprintf("%s %s/%s: not found", resource.Kind,
+					resource.Namespace, resource.Name))
+				notReady = append(notReady, resources[i])
+				continue
+			}
+			return nil, nil, err
+		}
+
+		if ready, msg := isResourceReady(u); !ready {
+			pending = append(pending, msg)
+			notReady = append(notReady, resources[i])
+		}
+	}
+
+	return pending, notReady, nil
+}
+
+func getPendingResourcesMessage(pending []string) string {
+	message := strings.Join(pending[:min(len(pending), maxPendingResourcesInMessage)], "; ")
+	if len(pending) > maxPendingResourcesInMessage {
+		message += fmt.Sprintf(" (and %d more)", len(pending)-maxPendingResourcesInMessage)
+	}
+	return message
+}
+
+// reportPendingResources sets the feature FailureMessage, while feature is still Provisioning,
+// to list resources not ready yet.
+func reportPendingResources(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, msg string) error {
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		currentClusterSummary := &configv1beta1.ClusterSummary{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		for i := range currentClusterSummary.Status.FeatureSummaries {
+			fs := &currentClusterSummary.Status.FeatureSummaries[i]
+			if fs.FeatureID != featureID || fs.Status != configv1beta1.FeatureStatusProvisioning {
+				continue
+			}
+			fs.FailureMessage = &msg
+			return c.Status().Update(ctx, currentClusterSummary)
+		}
+
+		return nil
+	})
+}
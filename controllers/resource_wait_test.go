@@ -0,0 +1,210 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Wait for deployed resources", func() {
+	var cluster *libsveltosv1beta1.SveltosCluster
+	var clusterSummary *configv1beta1.ClusterSummary
+
+	BeforeEach(func() {
+		cluster = &libsveltosv1beta1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+
+		clusterSummary = &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1beta1.Spec{
+					PolicyRefs: []configv1beta1.PolicyRef{
+						{
+							Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+							Name: "{{ .Cluster.metadata.name }}-wait",
+							Wait: true,
+							Timeout: &metav1.Duration{
+								Duration: time.Minute,
+							},
+						},
+						{
+							Kind: string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+							Name: randomString(),
+						},
+					},
+					KustomizationRefs: []configv1beta1.KustomizationRef{
+						{
+							Kind:      string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+							Namespace: randomString(),
+							Name:      randomString(),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("getPolicyRefsToWaitFor and getWaitHash consider only references with Wait set", func() {
+		Expect(len(controllers.GetPolicyRefsToWaitFor(clusterSummary))).To(Equal(1))
+
+		refs := controllers.GetKustomizationRefsToWaitFor(clusterSummary)
+		Expect(refs).To(BeEmpty())
+		Expect(controllers.GetWaitHash(refs)).To(BeEmpty())
+
+		clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[0].Wait = true
+		refs = controllers.GetKustomizationRefsToWaitFor(clusterSummary)
+		Expect(len(refs)).To(Equal(1))
+		hash := controllers.GetWaitHash(refs)
+		Expect(hash).ToNot(BeEmpty())
+
+		clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs[0].Timeout = &metav1.Duration{Duration: time.Hour}
+		Expect(controllers.GetWaitHash(controllers.GetKustomizationRefsToWaitFor(clusterSummary))).ToNot(Equal(hash))
+	})
+
+	It("selectResourcesToWaitFor returns resources deployed because of references with Wait set", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+
+		waits, err := controllers.InstantiateWaitReferences(context.TODO(), c, clusterSummary,
+			controllers.GetPolicyRefsToWaitFor(clusterSummary))
+		Expect(err).To(BeNil())
+		Expect(len(waits)).To(Equal(1))
+
+		owner := corev1.ObjectReference{
+			Kind:      string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name + "-wait",
+		}
+		Expect(waits[owner]).To(Equal(time.Minute))
+
+		reports := []configv1beta1.ResourceReport{
+			{
+				Resource: configv1beta1.Resource{Kind: "Deployment", Name: randomString(), Owner: owner},
+				Action:   string(configv1beta1.CreateResourceAction),
+			},
+			{
+				// Resources being removed are not waited for
+				Resource: configv1beta1.Resource{Kind: "Deployment", Name: randomString(), Owner: owner},
+				Action:   string(configv1beta1.DeleteResourceAction),
+			},
+			{
+				Resource: configv1beta1.Resource{Kind: "Deployment", Name: randomString(),
+					Owner: corev1.ObjectReference{Kind: owner.Kind, Namespace: owner.Namespace, Name: randomString()}},
+				Action: string(configv1beta1.CreateResourceAction),
+			},
+		}
+
+		resources := controllers.SelectResourcesToWaitFor(reports, waits, false, time.Now())
+		Expect(len(resources)).To(Equal(1))
+	})
+
+	It("getPendingResources returns resources which are not ready", func() {
+		depl := &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Deployment",
+				APIVersion: "apps/v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(1)),
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(depl).WithObjects(depl).Build()
+
+		owner := corev1.ObjectReference{
+			Kind:      string(libsveltosv1beta1.ConfigMapReferencedResourceKind),
+			Namespace: randomString(),
+			Name:      randomString(),
+		}
+		reports := []configv1beta1.ResourceReport{
+			{
+				Resource: configv1beta1.Resource{Kind: "Deployment", Group: "apps", Version: "v1",
+					Namespace: depl.Namespace, Name: depl.Name, Owner: owner},
+			},
+		}
+		resources := controllers.SelectResourcesToWaitFor(reports,
+			map[corev1.ObjectReference]time.Duration{owner: time.Minute}, false, time.Now())
+
+		pending, notReady, err := controllers.GetPendingResources(context.TODO(), c, c, resources)
+		Expect(err).To(BeNil())
+		Expect(len(notReady)).To(Equal(1))
+		Expect(len(pending)).To(Equal(1))
+		Expect(pending[0]).To(ContainSubstring(depl.Name))
+
+		depl.Status = appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
+		}
+		Expect(c.Status().Update(context.TODO(), depl)).To(Succeed())
+
+		pending, notReady, err = controllers.GetPendingResources(context.TODO(), c, c, resources)
+		Expect(err).To(BeNil())
+		Expect(notReady).To(BeEmpty())
+		Expect(pending).To(BeEmpty())
+
+		// Resources not found are pending
+		Expect(c.Delete(context.TODO(), depl)).To(Succeed())
+		_, notReady, err = controllers.GetPendingResources(context.TODO(), c, c, resources)
+		Expect(err).To(BeNil())
+		Expect(len(notReady)).To(Equal(1))
+	})
+
+	It("isResourceReady requires APIServices to be available", func() {
+		apiService := &unstructured.Unstructured{}
+		apiService.SetAPIVersion("apiregistration.k8s.io/v1")
+		apiService.SetKind("APIService")
+		apiService.SetName(randomString())
+
+		ready, _ := controllers.IsResourceReady(apiService)
+		Expect(ready).To(BeFalse())
+
+		Expect(unstructured.SetNestedSlice(apiService.Object, []interface{}{
+			map[string]interface{}{"type": "Available", "status": "True"},
+		}, "status", "conditions")).To(Succeed())
+		ready, _ = controllers.IsResourceReady(apiService)
+		Expect(ready).To(BeTrue())
+	})
+})
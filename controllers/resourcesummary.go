@@ -37,7 +37,6 @@ import (
 	"github.com/projectsveltos/addon-controller/controllers/clustercache"
 	driftdetection "github.com/projectsveltos/addon-controller/pkg/drift-detection"
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/crd"
 	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
 	"github.com/projectsveltos/libsveltos/lib/logsettings"
@@ -79,6 +78,11 @@ func deployDriftDetectionManagerInCluster(ctx context.Context, c client.Client,
 
 	logger = logger.WithValues("clustersummary", applicant)
 	logger = logger.WithValues("cluster", fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName))
+	if getDriftDetectionPolling() {
+		logger.V(logs.LogDebug).Info("drift detection by polling. No drift-detection-manager to deploy")
+		return nil
+	}
+
 	logger.V(logs.LogDebug).Info("deploy drift detection manager: do not send updates mode")
 
 	patches, err := getDriftDetectionManagerPatches(ctx, c, logger)
@@ -95,7 +99,7 @@ func deployDriftDetectionManagerInCluster(ctx context.Context, c client.Client,
 	logger.V(logs.LogDebug).Info("Deploying drift-detection-manager")
 	// Deploy DriftDetectionManager
 	if startInMgmtCluster {
-		restConfig := getManagementClusterConfig()
+		restConfig := getManagementClusterConfig(ctx)
 		return deployDriftDetectionManagerInManagementCluster(ctx, restConfig, clusterNamespace,
 			clusterName, "do-not-send-updates", clusterType, patches, logger)
 	}
@@ -152,7 +156,7 @@ func deployDriftDetectionCRDs(ctx context.Context, clusterNamespace, clusterName
 
 	var err error
 	cacheMgr := clustercache.GetManager()
-	remoteConfig, err := cacheMgr.GetKubernetesRestConfig(ctx, getManagementClusterClient(), clusterNamespace,
+	remoteConfig, err := cacheMgr.GetKubernetesRestConfig(ctx, getManagementClusterClient(ctx), clusterNamespace,
 		clusterName, "", "", clusterType, logger)
 	if err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to get cluster rest config")
@@ -269,7 +273,7 @@ func deployDriftDetectionManagerInManagedCluster(ctx context.Context, clusterNam
 
 	// Sveltos resources are deployed using cluster-admin role.
 	cacheMgr := clustercache.GetManager()
-	remoteRestConfig, err := cacheMgr.GetKubernetesRestConfig(ctx, getManagementClusterClient(),
+	remoteRestConfig, err := cacheMgr.GetKubernetesRestConfig(ctx, getManagementClusterClient(ctx),
 		clusterNamespace, clusterName, "", "", clusterType, logger)
 	if err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to get cluster rest config")
@@ -458,7 +462,37 @@ func deployResourceSummaryInstance(ctx context.Context, clusterClient client.Cli
 	currentResourceSummary.Annotations = annotations
 
 	logger.V(logsettings.LogDebug).Info("resourceSummary instance already present. updating it.")
-	return clusterClient.Update(ctx, currentResourceSummary)
+	err = clusterClient.Update(ctx, currentResourceSummary)
+	if err != nil {
+		return err
+	}
+
+	if getDriftDetectionPolling() {
+		// Resources were just (re)deployed. Forget recorded hashes so the poller records
+		// the new state instead of reporting it as a drift.
+		return resetResourceSummaryHashes(ctx, clusterClient, currentResourceSummary,
+			resources != nil, kustomizeResources != nil, helmResources != nil)
+	}
+
+	return nil
+}
+
+// resetResourceSummaryHashes clears the resource hashes recorded in the ResourceSummary status.
+// Only used when drift detection is done by polling.
+func resetResourceSummaryHashes(ctx context.Context, clusterClient client.Client,
+	rs *libsveltosv1beta1.ResourceSummary, resetResources, resetKustomize, resetHelm bool) error {
+
+	if resetResources {
+		rs.Status.ResourceHashes = nil
+	}
+	if resetKustomize {
+		rs.Status.KustomizeResourceHashes = nil
+	}
+	if resetHelm {
+		rs.Status.HelmResourceHashes = nil
+	}
+
+	return clusterClient.Status().Update(ctx, rs)
 }
 
 // transformDriftExclusionPathsToPatches transforms a DriftExclusion instance to a Patch instance.
@@ -638,7 +672,7 @@ func removeDriftDetectionManagerFromManagementCluster(ctx context.Context,
 	// Addon-controller deploys drift-detection-manager resources for each cluster matching at least
 	// one ClusterProfile with SyncMode set to ContinuousWithDriftDetection.
 	lbls := getDriftDetectionManagerLabels(clusterNamespace, clusterName, clusterType)
-	name, err := getDriftDetectionManagerDeploymentName(ctx, getManagementClusterConfig(), lbls)
+	name, err := getDriftDetectionManagerDeploymentName(ctx, getManagementClusterConfig(ctx), lbls)
 	if err != nil {
 		logger.V(logs.LogInfo).Info(
 			fmt.Sprintf("failed to get name for drift-detection-manager deployment: %v", err))
@@ -647,7 +681,7 @@ func removeDriftDetectionManagerFromManagementCluster(ctx context.Context,
 
 	driftDetectionManagerYAML = strings.ReplaceAll(driftDetectionManagerYAML, "$NAME", name)
 
-	restConfig := getManagementClusterConfig()
+	restConfig := getManagementClusterConfig(ctx)
 
 	elements, err := customSplit(driftDetectionManagerYAML)
 	if err != nil {
@@ -739,24 +773,39 @@ func addTemplateSpecLabels(u *unstructured.Unstructured, lbls map[string]string)
 func getResourceSummaryClient(ctx context.Context, clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType,
 	logger logr.Logger) (client.Client, error) {
 
-	if getAgentInMgmtCluster() {
-		return getManagementClusterClient(), nil
+	if isResourceSummaryInMgmtCluster() {
+		return getManagementClusterClient(ctx), nil
 	}
 
 	// ResourceSummary is a Sveltos resource created in managed clusters.
 	// Sveltos resources are always created using cluster-admin so that admin does not need to be
 	// given such permissions.
-	return clusterproxy.GetKubernetesClient(ctx, getManagementClusterClient(),
+	return clustercache.GetManager().GetKubernetesClient(ctx, getManagementClusterClient(ctx),
 		clusterNamespace, clusterName, "", "", clusterType, logger)
 }
 
+// getResourceSummaryRestConfig returns the restConfig to access the cluster where ResourceSummaries are.
+// Managed cluster restConfig is taken from the clustercache so that it is dropped (and any open watch
+// closed) when the cluster or its kubeconfig change.
+func getResourceSummaryRestConfig(ctx context.Context, clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) (*rest.Config, error) {
+
+	if isResourceSummaryInMgmtCluster() {
+		return getManagementClusterConfig(ctx), nil
+	}
+
+	cacheMgr := clustercache.GetManager()
+	return cacheMgr.GetKubernetesRestConfig(ctx, getManagementClusterClient(ctx), clusterNamespace, clusterName,
+		"", "", clusterType, logger)
+}
+
 // Determines the NamespacedName for the ResourceSummary based on the drift detection component's deployment location.
 // If deployed in the management cluster, it uses the cluster's namespace and a management-specific naming scheme.
 // If deployed in a managed cluster, it uses the projectsveltos namespace and a managed-cluster-specific naming scheme.
 func getResourceSummaryNameInfo(clusterNamespace, clusterSummaryName string) types.NamespacedName {
 	var resourceSummaryNamespace, resourceSummaryName string
 
-	if getAgentInMgmtCluster() {
+	if isResourceSummaryInMgmtCluster() {
 		resourceSummaryNamespace = clusterNamespace
 		resourceSummaryName = getResourceSummaryNameInManagemntCluster(clusterSummaryName)
 	} else {
@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,46 +39,9 @@ import (
 	"github.com/projectsveltos/libsveltos/lib/sveltos_upgrade"
 )
 
-// Periodically collects ResourceSummaries from each CAPI/Sveltos cluster.
-func collectAndProcessResourceSummaries(ctx context.Context, c client.Client, shardkey, version string,
-	logger logr.Logger) {
-
-	const interval = 10 * time.Second
-
-	for {
-		time.Sleep(interval)
-
-		logger.V(logs.LogVerbose).Info("collecting ResourceSummaries")
-		clusterList, err := clusterproxy.GetListOfClustersForShardKey(ctx, c, "", getCAPIOnboardAnnotation(),
-			shardkey, logger)
-		if err != nil {
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get clusters: %v", err))
-			continue
-		}
-
-		clustersWithDD, err := getListOfClusterWithDriftDetectionDeployed(ctx, c)
-		if err != nil {
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to collect clusters with drift detection: %v", err))
-			continue
-		}
-
-		for i := range clusterList {
-			cluster := &clusterList[i]
-			if _, ok := clustersWithDD[*cluster]; !ok {
-				continue
-			}
-
-			err = collectResourceSummariesFromCluster(ctx, c, cluster, version, logger)
-			if err != nil {
-				if !strings.Contains(err.Error(), "unable to retrieve the complete list of server APIs") {
-					logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to collect ResourceSummaries from cluster: %s/%s %v",
-						cluster.Namespace, cluster.Name, err))
-				}
-			}
-		}
-	}
-}
-
+// collectResourceSummariesFromCluster lists ResourceSummaries for a cluster and processes the ones
+// reporting a configuration drift. ResourceSummaries are normally processed as soon as watch events
+// are received. This is only used as a fallback.
 func collectResourceSummariesFromCluster(ctx context.Context, c client.Client, cluster *corev1.ObjectReference,
 	version string, logger logr.Logger) error {
 
@@ -106,66 +70,95 @@ func collectResourceSummariesFromCluster(ctx context.Context, c client.Client, c
 		return err
 	}
 
-	var installed bool
-	installed, err = isResourceSummaryInstalled(ctx, clusterClient)
+	installed, err := canCollectResourceSummaries(ctx, clusterClient, clusterRef, version, logger)
+	if err != nil || !installed {
+		return err
+	}
+
+	logger.V(logs.LogVerbose).Info("collecting ResourceSummaries from cluster")
+	rsList := libsveltosv1beta1.ResourceSummaryList{}
+
+	err = clusterClient.List(ctx, &rsList, getResourceSummaryListOptions(cluster)...)
 	if err != nil {
-		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to verify if ResourceSummary is installed %v", err))
 		return err
 	}
 
+	for i := range rsList.Items {
+		err = handleResourceSummary(ctx, clusterClient, cluster, &rsList.Items[i], logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// canCollectResourceSummaries returns true if ResourceSummary CRD is installed and drift-detection-manager
+// version is compatible. clusterClient points to the cluster where ResourceSummaries are.
+func canCollectResourceSummaries(ctx context.Context, clusterClient client.Client,
+	cluster *corev1.ObjectReference, version string, logger logr.Logger) (bool, error) {
+
+	installed, err := isResourceSummaryInstalled(ctx, clusterClient)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to verify if ResourceSummary is installed %v", err))
+		return false, err
+	}
+
 	if !installed {
-		return nil
+		return false, nil
 	}
 
-	if !sveltos_upgrade.IsDriftDetectionVersionCompatible(ctx, getManagementClusterClient(), version, cluster.Namespace,
-		cluster.Name, clusterproxy.GetClusterType(clusterRef), getAgentInMgmtCluster(), logger) {
+	// When drift detection is done by polling there is no drift-detection-manager
+	// whose version needs to be verified.
+	if !getDriftDetectionPolling() && !sveltos_upgrade.IsDriftDetectionVersionCompatible(ctx,
+		getManagementClusterClient(ctx), version, cluster.Namespace, cluster.Name,
+		clusterproxy.GetClusterType(cluster), getAgentInMgmtCluster(), logger) {
 
 		msg := "compatibility checks failed"
 		logger.V(logs.LogDebug).Info(msg)
-		return errors.New(msg)
+		return false, errors.New(msg)
 	}
 
-	logger.V(logs.LogVerbose).Info("collecting ResourceSummaries from cluster")
-	listOptions := []client.ListOption{}
-	if getAgentInMgmtCluster() {
-		listOptions = []client.ListOption{
+	return true, nil
+}
+
+// getResourceSummaryListOptions returns the options to select ResourceSummaries for a given cluster.
+// When ResourceSummaries are in the management cluster, those are filtered by cluster name label.
+func getResourceSummaryListOptions(cluster *corev1.ObjectReference) []client.ListOption {
+	if isResourceSummaryInMgmtCluster() {
+		return []client.ListOption{
+			client.InNamespace(cluster.Namespace),
 			client.MatchingLabels{
 				sveltos_upgrade.ClusterNameLabel: cluster.Name,
 			},
 		}
 	}
 
-	rsList := libsveltosv1beta1.ResourceSummaryList{}
-
-	err = clusterClient.List(ctx, &rsList, listOptions...)
-	if err != nil {
-		return err
-	}
+	return []client.ListOption{}
+}
 
-	l := logger.WithValues("cluster", fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name))
+// handleResourceSummary processes a ResourceSummary if it reports a configuration drift.
+// clusterClient points to the cluster where ResourceSummary is.
+func handleResourceSummary(ctx context.Context, clusterClient client.Client, cluster *corev1.ObjectReference,
+	rs *libsveltosv1beta1.ResourceSummary, logger logr.Logger) error {
 
-	for i := range rsList.Items {
-		rs := &rsList.Items[i]
+	ns, ok := getClusterSummaryNamespaceFromResourceSummary(rs, logger)
+	if !ok {
+		return nil
+	}
+	if ns != cluster.Namespace {
+		return nil
+	}
 
-		ns, ok := getClusterSummaryNamespaceFromResourceSummary(rs, logger)
-		if !ok {
-			continue
-		}
-		if ns != cluster.Namespace {
-			continue
-		}
+	if !rs.DeletionTimestamp.IsZero() {
+		// ignore deleted resourceSummary
+		return nil
+	}
 
-		if !rs.DeletionTimestamp.IsZero() {
-			// ignore deleted resourceSummary
-			continue
-		}
-		if rs.Status.ResourcesChanged || rs.Status.HelmResourcesChanged || rs.Status.KustomizeResourcesChanged {
-			// process resourceSummary
-			err = processResourceSummary(ctx, clusterClient, rs, l)
-			if err != nil {
-				return err
-			}
-		}
+	if rs.Status.ResourcesChanged || rs.Status.HelmResourcesChanged || rs.Status.KustomizeResourcesChanged {
+		// process resourceSummary
+		return processResourceSummary(ctx, clusterClient, rs,
+			logger.WithValues("resourceSummary", rs.Name))
 	}
 
 	return nil
@@ -263,9 +256,12 @@ func processResourceSummary(ctx context.Context, clusterClient client.Client,
 		return nil
 	}
 
+	var clusterSummary *configv1beta1.ClusterSummary
+	var driftedFeatures []string
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		clusterSummary := &configv1beta1.ClusterSummary{}
-		err := getManagementClusterClient().Get(ctx,
+		clusterSummary = &configv1beta1.ClusterSummary{}
+		driftedFeatures = nil
+		err := getManagementClusterClient(ctx).Get(ctx,
 			types.NamespacedName{Namespace: clusterSummaryNamespace, Name: clusterSummaryName}, clusterSummary)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
@@ -287,29 +283,41 @@ func processResourceSummary(ctx context.Context, clusterClient client.Client,
 					l.V(logs.LogDebug).Info("redeploy helm")
 					clusterSummary.Status.FeatureSummaries[i].Hash = nil
 					clusterSummary.Status.FeatureSummaries[i].Status = configv1beta1.FeatureStatusProvisioning
+					setDriftDetectedTime(&clusterSummary.Status.FeatureSummaries[i])
 					trackDrifts(clusterSummaryNamespace, clusterSummary.Spec.ClusterName, string(clusterSummary.Status.FeatureSummaries[i].FeatureID),
 						string(clusterSummary.Spec.ClusterType), logger)
+					recordDriftDetection(clusterSummary.Namespace, clusterSummary.Name,
+						string(clusterSummary.Status.FeatureSummaries[i].FeatureID))
+					driftedFeatures = append(driftedFeatures, string(clusterSummary.Status.FeatureSummaries[i].FeatureID))
 				}
 			} else if clusterSummary.Status.FeatureSummaries[i].FeatureID == configv1beta1.FeatureResources {
 				if rs.Status.ResourcesChanged {
 					l.V(logs.LogDebug).Info("redeploy resources")
 					clusterSummary.Status.FeatureSummaries[i].Hash = nil
 					clusterSummary.Status.FeatureSummaries[i].Status = configv1beta1.FeatureStatusProvisioning
+					setDriftDetectedTime(&clusterSummary.Status.FeatureSummaries[i])
 					trackDrifts(clusterSummaryNamespace, clusterSummary.Spec.ClusterName, string(clusterSummary.Status.FeatureSummaries[i].FeatureID),
 						string(clusterSummary.Spec.ClusterType), logger)
+					recordDriftDetection(clusterSummary.Namespace, clusterSummary.Name,
+						string(clusterSummary.Status.FeatureSummaries[i].FeatureID))
+					driftedFeatures = append(driftedFeatures, string(clusterSummary.Status.FeatureSummaries[i].FeatureID))
 				}
 			} else if clusterSummary.Status.FeatureSummaries[i].FeatureID == configv1beta1.FeatureKustomize {
 				if rs.Status.KustomizeResourcesChanged {
 					l.V(logs.LogDebug).Info("redeploy kustomization resources")
 					clusterSummary.Status.FeatureSummaries[i].Hash = nil
 					clusterSummary.Status.FeatureSummaries[i].Status = configv1beta1.FeatureStatusProvisioning
+					setDriftDetectedTime(&clusterSummary.Status.FeatureSummaries[i])
 					trackDrifts(clusterSummaryNamespace, clusterSummary.Spec.ClusterName, string(clusterSummary.Status.FeatureSummaries[i].FeatureID),
 						string(clusterSummary.Spec.ClusterType), logger)
+					recordDriftDetection(clusterSummary.Namespace, clusterSummary.Name,
+						string(clusterSummary.Status.FeatureSummaries[i].FeatureID))
+					driftedFeatures = append(driftedFeatures, string(clusterSummary.Status.FeatureSummaries[i].FeatureID))
 				}
 			}
 		}
 
-		err = getManagementClusterClient().Status().Update(ctx, clusterSummary)
+		err = getManagementClusterClient(ctx).Status().Update(ctx, clusterSummary)
 		if err != nil {
 			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update ClusterSummary status: %v", err))
 			return err
@@ -321,6 +329,15 @@ func processResourceSummary(ctx context.Context, clusterClient client.Client,
 		return err
 	}
 
+	if len(driftedFeatures) > 0 {
+		recordWarningEvent(ctx, clusterSummary, eventReasonDriftDetected, "Configuration drift detected in cluster %s for %s",
+			getClusterDescription(clusterSummary), strings.Join(driftedFeatures, ", "))
+		for i := range driftedFeatures {
+			queueNotification(newClusterNotification(clusterSummary, driftedFeatures[i],
+				configv1beta1.NotificationTransitionDriftDetected, "configuration drift detected"), logger)
+		}
+	}
+
 	return resetResourceSummaryStatus(ctx, clusterClient, rs, logger)
 }
 
@@ -394,3 +411,10 @@ func addMatchingClusters(matchingClusterRefs []corev1.ObjectReference,
 
 	return clustersWithDriftDetection
 }
+
+// setDriftDetectedTime records when a configuration drift was first detected for a feature.
+func setDriftDetectedTime(fs *configv1beta1.FeatureSummary) {
+	if fs.DriftDetectedTime == nil {
+		fs.DriftDetectedTime = &metav1.Time{Time: time.Now()}
+	}
+}
@@ -0,0 +1,390 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/projectsveltos/addon-controller/controllers/clustercache"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ResourceSummaries are watched, one watch per cluster, so that configuration drifts are processed
+// as soon as drift-detection-manager reports those. When drift-detection-manager runs in the management
+// cluster, ResourceSummaries are in the management cluster as well and the watch is restricted to the
+// cluster namespace and name.
+// Watches are tied to the clustercache: when cached data for a cluster are removed (cluster deleted or
+// kubeconfig changed) the watch is closed and restarted with fresh credentials.
+// When the API server closes a watch, the watch is resumed from the last seen resourceVersion so existing
+// ResourceSummaries are not processed again. Only when that resourceVersion is too old (410 Gone) is the
+// watch restarted from scratch, replaying one event per existing ResourceSummary.
+// Listing ResourceSummaries from all clusters is still done, but only as a fallback with a much lower
+// frequency.
+
+const (
+	// interval at which set of watched clusters is updated
+	resourceSummaryWatcherSyncInterval = 10 * time.Second
+	// interval at which ResourceSummaries are listed from all clusters, in case a watch event was missed
+	resourceSummaryFallbackInterval = 5 * time.Minute
+)
+
+var (
+	resourceSummaryWatchBackoff = wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    math.MaxInt32,
+		Cap:      5 * time.Minute,
+	}
+)
+
+type resourceSummaryWatchers struct {
+	mux sync.Mutex
+	// key: cluster; value: function to stop the watch
+	watchers map[corev1.ObjectReference]context.CancelFunc
+}
+
+// watchResourceSummaries starts and stops ResourceSummary watches for all clusters matching a
+// ClusterProfile/Profile in SyncModeContinuousWithDriftDetection.
+func watchResourceSummaries(ctx context.Context, c client.Client, shardkey, version string,
+	logger logr.Logger) {
+
+	rsWatchers := &resourceSummaryWatchers{
+		watchers: make(map[corev1.ObjectReference]context.CancelFunc),
+	}
+
+	clustercache.GetManager().RegisterRemoveClusterHandler(
+		func(clusterNamespace, clusterName string, clusterType libsveltosv1beta1.ClusterType) {
+			rsWatchers.stopWatcher(getClusterReference(clusterNamespace, clusterName, clusterType))
+		})
+
+	lastFallback := time.Now()
+	for {
+		time.Sleep(resourceSummaryWatcherSyncInterval)
+
+		logger.V(logs.LogVerbose).Info("updating ResourceSummary watchers")
+		clusterList, err := clusterproxy.GetListOfClustersForShardKey(ctx, c, "", getCAPIOnboardAnnotation(),
+			shardkey, logger)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get clusters: %v", err))
+			continue
+		}
+
+		clustersWithDD, err := getListOfClusterWithDriftDetectionDeployed(ctx, c)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to collect clusters with drift detection: %v", err))
+			continue
+		}
+
+		currentClusters := make(map[corev1.ObjectReference]bool)
+		for i := range clusterList {
+			cluster := getClusterReference(clusterList[i].Namespace, clusterList[i].Name,
+				clusterproxy.GetClusterType(&clusterList[i]))
+			if _, ok := clustersWithDD[clusterList[i]]; !ok {
+				continue
+			}
+			if !isClusterAssignedToThisReplica(cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster)) {
+				continue
+			}
+			currentClusters[*cluster] = true
+			rsWatchers.startWatcher(ctx, c, cluster, version, logger)
+		}
+
+		rsWatchers.stopStaleWatchers(currentClusters)
+
+		if time.Since(lastFallback) < resourceSummaryFallbackInterval {
+			continue
+		}
+
+		lastFallback = time.Now()
+		for i := range clusterList {
+			cluster := &clusterList[i]
+			if _, ok := clustersWithDD[*cluster]; !ok {
+				continue
+			}
+			if !isClusterAssignedToThisReplica(cluster.Namespace, cluster.Name, clusterproxy.GetClusterType(cluster)) {
+				continue
+			}
+
+			err = collectResourceSummariesFromCluster(ctx, c, cluster, version, logger)
+			if err != nil {
+				logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to collect ResourceSummaries from cluster: %s/%s %v",
+					cluster.Namespace, cluster.Name, err))
+			}
+		}
+	}
+}
+
+func (w *resourceSummaryWatchers) startWatcher(ctx context.Context, c client.Client,
+	cluster *corev1.ObjectReference, version string, logger logr.Logger) {
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if _, ok := w.watchers[*cluster]; ok {
+		return
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	w.watchers[*cluster] = cancel
+
+	go watchResourceSummariesInCluster(watchCtx, c, cluster, version,
+		logger.WithValues("cluster", fmt.Sprintf("%s/%s", cluster.Namespace, cluster.Name)))
+}
+
+func (w *resourceSummaryWatchers) stopWatcher(cluster *corev1.ObjectReference) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if cancel, ok := w.watchers[*cluster]; ok {
+		cancel()
+		delete(w.watchers, *cluster)
+	}
+}
+
+func (w *resourceSummaryWatchers) stopStaleWatchers(currentClusters map[corev1.ObjectReference]bool) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	for cluster, cancel := range w.watchers {
+		if _, ok := currentClusters[cluster]; !ok {
+			cancel()
+			delete(w.watchers, cluster)
+		}
+	}
+}
+
+// resourceSummaryWatchOpener opens a watch on ResourceSummaries. It returns the client to use to
+// process those as well. lastErr is the error the previous watch failed with, if any. resourceVersion
+// is the resourceVersion to resume the watch from. When empty, the watch starts from scratch.
+type resourceSummaryWatchOpener func(ctx context.Context, lastErr error,
+	resourceVersion string) (client.Client, watch.Interface, error)
+
+// watchResourceSummariesInCluster keeps a watch on ResourceSummaries for a cluster open till
+// context is canceled. The client used to watch is created once and reused every time the watch
+// is reopened. It is recreated only if the API server rejects its credentials.
+func watchResourceSummariesInCluster(ctx context.Context, c client.Client, cluster *corev1.ObjectReference,
+	version string, logger logr.Logger) {
+
+	var clusterClient client.WithWatch
+	openWatch := func(ctx context.Context, lastErr error, resourceVersion string) (client.Client, watch.Interface, error) {
+		if apierrors.IsUnauthorized(lastErr) {
+			clusterClient = nil
+		}
+
+		if clusterClient == nil {
+			var err error
+			clusterClient, err = getResourceSummaryWatchClient(ctx, c, cluster, logger)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// Verifying ResourceSummaries can be collected is needed only when watch starts from scratch
+		// or previous watch failed. A watch simply closed by the API server is resumed right away.
+		if resourceVersion == "" || lastErr != nil {
+			installed, err := canCollectResourceSummaries(ctx, clusterClient, cluster, version, logger)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !installed {
+				return nil, nil, errors.New("ResourceSummary CRD is not installed yet")
+			}
+		}
+
+		watcher, err := openResourceSummaryWatch(ctx, clusterClient, cluster, resourceVersion)
+		return clusterClient, watcher, err
+	}
+
+	keepResourceSummaryWatch(ctx, cluster, openWatch, logger)
+}
+
+// keepResourceSummaryWatch processes ResourceSummary events till context is canceled. Every time the
+// watch is closed by the API server it is reopened from the last seen resourceVersion. Every time the
+// watch fails, it is reopened with an exponential backoff. If the last seen resourceVersion is too old,
+// watch is restarted from scratch.
+func keepResourceSummaryWatch(ctx context.Context, cluster *corev1.ObjectReference,
+	openWatch resourceSummaryWatchOpener, logger logr.Logger) {
+
+	backoff := resourceSummaryWatchBackoff
+	resourceVersion := ""
+	var err error
+	for {
+		var clusterClient client.Client
+		var watcher watch.Interface
+		clusterClient, watcher, err = openWatch(ctx, err, resourceVersion)
+		if err == nil {
+			resourceVersion, err = processResourceSummaryEvents(ctx, clusterClient, cluster, watcher,
+				resourceVersion, logger)
+		}
+
+		if ctx.Err() != nil {
+			logger.V(logs.LogDebug).Info("stop watching ResourceSummaries")
+			return
+		}
+
+		if err == nil {
+			// Watch was closed by the API server. Reopen it
+			backoff = resourceSummaryWatchBackoff
+			continue
+		}
+
+		if apierrors.IsGone(err) || apierrors.IsResourceExpired(err) {
+			// Last seen resourceVersion is too old. Restart watch from scratch
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("resourceVersion %s is too old: %v", resourceVersion, err))
+			resourceVersion = ""
+			backoff = resourceSummaryWatchBackoff
+			continue
+		}
+
+		delay := backoff.Step()
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("watching ResourceSummaries failed: %v. Retrying in %s",
+			err, delay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// getResourceSummaryWatchClient returns a client to watch ResourceSummaries for a cluster
+func getResourceSummaryWatchClient(ctx context.Context, c client.Client, cluster *corev1.ObjectReference,
+	logger logr.Logger) (client.WithWatch, error) {
+
+	ready, err := clusterproxy.IsClusterReadyToBeConfigured(ctx, c, cluster, logger)
+	if err != nil {
+		return nil, err
+	}
+	if !ready {
+		return nil, errors.New("cluster is not ready yet")
+	}
+
+	clusterType := clusterproxy.GetClusterType(cluster)
+	restConfig, err := getResourceSummaryRestConfig(ctx, cluster.Namespace, cluster.Name, clusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewWithWatch(restConfig, client.Options{Scheme: c.Scheme()})
+}
+
+// openResourceSummaryWatch opens a watch on ResourceSummaries for a cluster starting from resourceVersion.
+// When resourceVersion is empty, initial events (one per existing ResourceSummary) take care of any drift
+// reported while the watch was not running.
+func openResourceSummaryWatch(ctx context.Context, clusterClient client.WithWatch, cluster *corev1.ObjectReference,
+	resourceVersion string) (watch.Interface, error) {
+
+	listOptions := getResourceSummaryListOptions(cluster)
+	listOptions = append(listOptions, &client.ListOptions{
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+
+	rsList := &libsveltosv1beta1.ResourceSummaryList{}
+	return clusterClient.Watch(ctx, rsList, listOptions...)
+}
+
+// processResourceSummaryEvents processes ResourceSummary events till the watch is closed.
+// It returns the last seen resourceVersion, which is resourceVersion if no event was received.
+func processResourceSummaryEvents(ctx context.Context, clusterClient client.Client, cluster *corev1.ObjectReference,
+	watcher watch.Interface, resourceVersion string, logger logr.Logger) (string, error) {
+
+	defer watcher.Stop()
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("watching ResourceSummaries (resourceVersion %q)", resourceVersion))
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+			err := handleResourceSummaryEvent(ctx, clusterClient, cluster, &event, logger)
+			if err != nil {
+				return resourceVersion, err
+			}
+			if accessor, err := meta.Accessor(event.Object); err == nil && accessor.GetResourceVersion() != "" {
+				resourceVersion = accessor.GetResourceVersion()
+			}
+		}
+	}
+}
+
+func handleResourceSummaryEvent(ctx context.Context, clusterClient client.Client, cluster *corev1.ObjectReference,
+	event *watch.Event, logger logr.Logger) error {
+
+	switch event.Type {
+	case watch.Added, watch.Modified:
+		rs, ok := event.Object.(*libsveltosv1beta1.ResourceSummary)
+		if !ok {
+			return nil
+		}
+		err := handleResourceSummary(ctx, clusterClient, cluster, rs, logger)
+		if err != nil {
+			// Failing to process a single ResourceSummary does not require to reopen the watch.
+			// ResourceSummary will be processed again by the fallback collection.
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to process ResourceSummary %s: %v",
+				rs.Name, err))
+		}
+	case watch.Error:
+		return apierrors.FromObject(event.Object)
+	case watch.Deleted, watch.Bookmark:
+	}
+
+	return nil
+}
+
+func getClusterReference(clusterNamespace, clusterName string,
+	clusterType libsveltosv1beta1.ClusterType) *corev1.ObjectReference {
+
+	if clusterType == libsveltosv1beta1.ClusterTypeSveltos {
+		return &corev1.ObjectReference{
+			Namespace:  clusterNamespace,
+			Name:       clusterName,
+			Kind:       libsveltosv1beta1.SveltosClusterKind,
+			APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+	}
+
+	return &corev1.ObjectReference{
+		Namespace:  clusterNamespace,
+		Name:       clusterName,
+		Kind:       clusterv1.ClusterKind,
+		APIVersion: clusterv1.GroupVersion.String(),
+	}
+}
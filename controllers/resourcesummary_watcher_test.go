@@ -0,0 +1,187 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2/textlogger"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("ResourceSummary watcher", func() {
+	It("getClusterReference returns the cluster ObjectReference", func() {
+		namespace := randomString()
+		name := randomString()
+
+		cluster := controllers.GetClusterReference(namespace, name, libsveltosv1beta1.ClusterTypeSveltos)
+		Expect(cluster.Namespace).To(Equal(namespace))
+		Expect(cluster.Name).To(Equal(name))
+		Expect(cluster.Kind).To(Equal(libsveltosv1beta1.SveltosClusterKind))
+		Expect(cluster.APIVersion).To(Equal(libsveltosv1beta1.GroupVersion.String()))
+
+		cluster = controllers.GetClusterReference(namespace, name, libsveltosv1beta1.ClusterTypeCapi)
+		Expect(cluster.Kind).To(Equal(clusterv1.ClusterKind))
+		Expect(cluster.APIVersion).To(Equal(clusterv1.GroupVersion.String()))
+	})
+
+	It("handleResourceSummaryEvent returns an error only when watch reports an error", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		cluster := &corev1.ObjectReference{
+			Namespace:  randomString(),
+			Name:       randomString(),
+			Kind:       libsveltosv1beta1.SveltosClusterKind,
+			APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		// ResourceSummary not reporting any drift is ignored
+		rs := &libsveltosv1beta1.ResourceSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+				Annotations: map[string]string{
+					libsveltosv1beta1.ClusterSummaryNameAnnotation:      randomString(),
+					libsveltosv1beta1.ClusterSummaryNamespaceAnnotation: cluster.Namespace,
+				},
+			},
+		}
+		event := &watch.Event{Type: watch.Modified, Object: rs}
+		Expect(controllers.HandleResourceSummaryEvent(context.TODO(), c, cluster, event, logger)).To(Succeed())
+
+		event = &watch.Event{Type: watch.Deleted, Object: rs}
+		Expect(controllers.HandleResourceSummaryEvent(context.TODO(), c, cluster, event, logger)).To(Succeed())
+
+		event = &watch.Event{
+			Type: watch.Error,
+			Object: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  metav1.StatusReasonExpired,
+				Message: "too old resource version",
+				Code:    410,
+			},
+		}
+		Expect(controllers.HandleResourceSummaryEvent(context.TODO(), c, cluster, event, logger)).ToNot(Succeed())
+	})
+
+	It("keepResourceSummaryWatch processes watch events and reopens the watch once closed", func() {
+		cluster := &corev1.ObjectReference{
+			Namespace:  randomString(),
+			Name:       randomString(),
+			Kind:       libsveltosv1beta1.SveltosClusterKind,
+			APIVersion: libsveltosv1beta1.GroupVersion.String(),
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1beta1.ClusterTypeSveltos,
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{
+						FeatureID: configv1beta1.FeatureResources,
+						Status:    configv1beta1.FeatureStatusProvisioned,
+						Hash:      []byte(randomString()),
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).
+			WithStatusSubresource(clusterSummary).Build()
+		mgmtClient, mgmtConfig := controllers.GetManagementClusterAccess()
+		controllers.SetManagementClusterAccess(c, nil)
+		defer controllers.SetManagementClusterAccess(mgmtClient, mgmtConfig)
+
+		watchers := make(chan *watch.FakeWatcher, 3)
+		resourceVersions := make(chan string, 3)
+		openWatch := func(ctx context.Context, lastErr error, resourceVersion string) (client.Client, watch.Interface, error) {
+			w := watch.NewFakeWithChanSize(1, false)
+			resourceVersions <- resourceVersion
+			watchers <- w
+			return c, w, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		go controllers.KeepResourceSummaryWatch(ctx, cluster, openWatch, textlogger.NewLogger(textlogger.NewConfig()))
+
+		var w *watch.FakeWatcher
+		Eventually(watchers).Should(Receive(&w))
+		Eventually(resourceVersions).Should(Receive(BeEmpty()))
+
+		rs := &libsveltosv1beta1.ResourceSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       cluster.Namespace,
+				Name:            randomString(),
+				ResourceVersion: "5",
+				Annotations: map[string]string{
+					libsveltosv1beta1.ClusterSummaryNameAnnotation:      clusterSummary.Name,
+					libsveltosv1beta1.ClusterSummaryNamespaceAnnotation: clusterSummary.Namespace,
+				},
+			},
+			Status: libsveltosv1beta1.ResourceSummaryStatus{
+				ResourcesChanged: true,
+			},
+		}
+		w.Modify(rs)
+
+		// Drift reported by the ResourceSummary causes the feature to be redeployed
+		Eventually(func() bool {
+			current := &configv1beta1.ClusterSummary{}
+			err := c.Get(context.TODO(),
+				types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}, current)
+			return err == nil && current.Status.FeatureSummaries[0].Hash == nil &&
+				current.Status.FeatureSummaries[0].Status == configv1beta1.FeatureStatusProvisioning
+		}, time.Minute, time.Second).Should(BeTrue())
+
+		// Watch closed by the API server is reopened from last seen resourceVersion
+		w.Stop()
+		Eventually(watchers).Should(Receive(&w))
+		Eventually(resourceVersions).Should(Receive(Equal("5")))
+
+		// When resourceVersion is too old, watch is restarted from scratch
+		w.Error(&metav1.Status{
+			Status: metav1.StatusFailure,
+			Code:   http.StatusGone,
+			Reason: metav1.StatusReasonExpired,
+		})
+		Eventually(watchers).Should(Receive())
+		Eventually(resourceVersions).Should(Receive(BeEmpty()))
+	})
+})
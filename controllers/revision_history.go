@@ -0,0 +1,748 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// Revision history
+// Every time a feature is successfully deployed to a managed cluster, what was rendered (Kubernetes
+// resources after templates, Lua and patches were applied; helm charts along with their instantiated
+// values) is stored as a revision: a compressed Secret in the ClusterSummary namespace, owned by the
+// ClusterSummary. A new revision is stored only when rendered content changes. Only the last
+// revisionHistoryLimit revisions are kept per cluster and feature.
+//
+// A Rollback pins deployments of a ClusterProfile/Profile (optionally only on one cluster and only
+// for some features) to a prior revision: stored content is deployed instead of what the
+// ClusterProfile/Profile currently references. No revision is stored while pinned. Deleting the
+// Rollback releases the pin and the current configuration is deployed again.
+// Rollback Status reports, per cluster and feature, the pinned revision. When no stored revision
+// matches (history disabled or not enough revisions yet) nothing is pinned, Status reports it and
+// the current configuration keeps being deployed.
+//
+// Revisions are recorded (and replayed) by deployer workers. Since a worker never processes the same
+// ClusterSummary feature in parallel, per ClusterSummary feature sessions are kept in memory while a
+// deployment is in progress.
+
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=rollbacks,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=rollbacks/status,verbs=get;update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;delete
+
+const (
+	// DefaultRevisionHistoryLimit is the default number of revisions kept per cluster and feature
+	DefaultRevisionHistoryLimit = 10
+
+	revisionSecretType = corev1.SecretType("addons.projectsveltos.io/revision")
+	revisionDataKey    = "revision"
+
+	revisionFeatureLabel = "projectsveltos.io/feature"
+	revisionNumberLabel  = "projectsveltos.io/revision"
+	revisionHashLabel    = "projectsveltos.io/revision-hash"
+)
+
+var (
+	revisionHistoryLimit = DefaultRevisionHistoryLimit
+)
+
+// SetRevisionHistoryLimit sets the number of revisions kept per cluster and feature.
+// Zero disables the revision history.
+func SetRevisionHistoryLimit(limit int) {
+	revisionHistoryLimit = limit
+}
+
+// revisionUnit contains resources rendered from one referenced ConfigMap/Secret/Source
+// or from one KustomizationRef
+type revisionUnit struct {
+	ManagementCluster bool                   `json:"managementCluster,omitempty"`
+	ReferencedObject  corev1.ObjectReference `json:"referencedObject"`
+	Subresources      []string               `json:"subresources,omitempty"`
+	Objects           []json.RawMessage      `json:"objects"`
+}
+
+// revisionChart contains an instantiated helm chart and its instantiated values
+type revisionChart struct {
+	// Key is the release namespace/name as listed in the ClusterProfile/Profile (before instantiation)
+	Key    string                   `json:"key"`
+	Chart  *configv1beta1.HelmChart `json:"chart,omitempty"`
+	Values string                   `json:"values,omitempty"`
+}
+
+// featureRevision is what is stored for a revision
+type featureRevision struct {
+	Revision  int64                   `json:"revision"`
+	FeatureID configv1beta1.FeatureID `json:"featureID"`
+	Units     []revisionUnit          `json:"units,omitempty"`
+	Charts    []revisionChart         `json:"charts,omitempty"`
+
+	// hash of the rendered content
+	hash string
+}
+
+// revisionSession tracks a feature deployment in progress
+type revisionSession struct {
+	// pinned is the revision being deployed because of a Rollback
+	pinned *featureRevision
+	// rendered collects the rendered content. Nil when not recording.
+	rendered *featureRevision
+	charts   map[string]*revisionChart
+}
+
+var (
+	revisionMux sync.Mutex
+	// key: ClusterSummary namespace/name/featureID
+	revisionSessions = map[string]*revisionSession{}
+)
+
+func getRevisionSessionKey(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) string {
+	return fmt.Sprintf("%s/%s/%s", clusterSummary.Namespace, clusterSummary.Name, featureID)
+}
+
+func getReleaseKey(releaseNamespace, releaseName string) string {
+	return fmt.Sprintf("%s/%s", releaseNamespace, releaseName)
+}
+
+// startRevisionSession is invoked by a worker before deploying a feature. If the feature is pinned
+// to a revision, such revision is loaded. Otherwise rendered content is recorded (not in DryRun mode).
+func startRevisionSession(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, logger logr.Logger) error {
+
+	pinned, err := getPinnedRevision(ctx, c, clusterSummary, featureID, logger)
+	if err != nil {
+		return err
+	}
+
+	session := &revisionSession{pinned: pinned}
+	if pinned == nil && revisionHistoryLimit > 0 &&
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1beta1.SyncModeDryRun {
+
+		session.rendered = &featureRevision{FeatureID: featureID}
+		session.charts = map[string]*revisionChart{}
+	}
+
+	revisionMux.Lock()
+	defer revisionMux.Unlock()
+	revisionSessions[getRevisionSessionKey(clusterSummary, featureID)] = session
+
+	return nil
+}
+
+// endRevisionSession is invoked by a worker once done deploying a feature
+func endRevisionSession(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) {
+	revisionMux.Lock()
+	defer revisionMux.Unlock()
+	delete(revisionSessions, getRevisionSessionKey(clusterSummary, featureID))
+}
+
+func getRevisionSession(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+) *revisionSession {
+
+	return revisionSessions[getRevisionSessionKey(clusterSummary, featureID)]
+}
+
+// getSessionPinnedRevision returns the revision a feature deployment in progress is pinned to, if any
+func getSessionPinnedRevision(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+) *featureRevision {
+
+	revisionMux.Lock()
+	defer revisionMux.Unlock()
+
+	if session := getRevisionSession(clusterSummary, featureID); session != nil {
+		return session.pinned
+	}
+	return nil
+}
+
+// recordRenderedObjects records resources, rendered from referencedObject, about to be deployed
+func recordRenderedObjects(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	deployingToMgmtCluster bool, referencedObject *corev1.ObjectReference, subresources []string,
+	objects []*unstructured.Unstructured) {
+
+	revisionMux.Lock()
+	defer revisionMux.Unlock()
+
+	session := getRevisionSession(clusterSummary, featureID)
+	if session == nil || session.rendered == nil {
+		return
+	}
+
+	unit := revisionUnit{
+		ManagementCluster: deployingToMgmtCluster,
+		ReferencedObject:  *referencedObject,
+		Subresources:      subresources,
+		Objects:           make([]json.RawMessage, len(objects)),
+	}
+	for i := range objects {
+		data, err := objects[i].MarshalJSON()
+		if err != nil {
+			// Revision would be incomplete. Stop recording.
+			session.rendered = nil
+			return
+		}
+		unit.Objects[i] = data
+	}
+	session.rendered.Units = append(session.rendered.Units, unit)
+}
+
+// recordRenderedChart records an instantiated helm chart
+func recordRenderedChart(clusterSummary *configv1beta1.ClusterSummary, currentChart,
+	instantiatedChart *configv1beta1.HelmChart) {
+
+	revisionMux.Lock()
+	defer revisionMux.Unlock()
+
+	session := getRevisionSession(clusterSummary, configv1beta1.FeatureHelm)
+	if session == nil || session.rendered == nil {
+		return
+	}
+
+	key := getReleaseKey(instantiatedChart.ReleaseNamespace, instantiatedChart.ReleaseName)
+	chart, ok := session.charts[key]
+	if !ok {
+		chart = &revisionChart{}
+		session.charts[key] = chart
+	}
+	chart.Key = getReleaseKey(currentChart.ReleaseNamespace, currentChart.ReleaseName)
+	chart.Chart = instantiatedChart.DeepCopy()
+}
+
+// recordRenderedValues records the instantiated values of a helm chart
+func recordRenderedValues(clusterSummary *configv1beta1.ClusterSummary, requestedChart *configv1beta1.HelmChart,
+	values chartutil.Values) {
+
+	revisionMux.Lock()
+	defer revisionMux.Unlock()
+
+	session := getRevisionSession(clusterSummary, configv1beta1.FeatureHelm)
+	if session == nil || session.rendered == nil {
+		return
+	}
+
+	yamlValues, err := values.YAML()
+	if err != nil {
+		return
+	}
+
+	key := getReleaseKey(requestedChart.ReleaseNamespace, requestedChart.ReleaseName)
+	chart, ok := session.charts[key]
+	if !ok {
+		chart = &revisionChart{}
+		session.charts[key] = chart
+	}
+	chart.Values = yamlValues
+}
+
+// deployPinnedRevision deploys resources stored in a revision instead of the ones currently
+// referenced by the ClusterSummary
+func deployPinnedRevision(ctx context.Context, c client.Client, remoteConfig *rest.Config,
+	clusterSummary *configv1beta1.ClusterSummary, revision *featureRevision, logger logr.Logger,
+) (localReports, remoteReports []configv1beta1.ResourceReport, err error) {
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("deploying revision %d", revision.Revision))
+
+	remoteClient, err := client.New(remoteConfig, client.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	localConfig := rest.CopyConfig(getManagementClusterConfig(ctx))
+	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
+	if adminName != "" {
+		localConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", adminNamespace, adminName),
+		}
+	}
+
+	for i := range revision.Units {
+		unit := &revision.Units[i]
+		objects := make([]*unstructured.Unstructured, len(unit.Objects))
+		for j := range unit.Objects {
+			objects[j] = &unstructured.Unstructured{}
+			if err := objects[j].UnmarshalJSON(unit.Objects[j]); err != nil {
+				return localReports, remoteReports, err
+			}
+		}
+
+		var reports []configv1beta1.ResourceReport
+		if unit.ManagementCluster {
+			reports, err = deployRenderedUnstructured(ctx, true, localConfig, c, objects, &unit.ReferencedObject,
+				revision.FeatureID, clusterSummary, unit.Subresources, logger)
+			localReports = append(localReports, reports...)
+		} else {
+			reports, err = deployRenderedUnstructured(ctx, false, remoteConfig, remoteClient, objects,
+				&unit.ReferencedObject, revision.FeatureID, clusterSummary, unit.Subresources, logger)
+			remoteReports = append(remoteReports, reports...)
+		}
+		if err != nil {
+			return localReports, remoteReports, err
+		}
+	}
+
+	return localReports, remoteReports, nil
+}
+
+// getPinnedChart returns, when helm feature is pinned to a revision, the instantiated chart
+// stored in such revision for currentChart. Nil otherwise.
+func getPinnedChart(clusterSummary *configv1beta1.ClusterSummary, currentChart *configv1beta1.HelmChart,
+) *configv1beta1.HelmChart {
+
+	pinned := getSessionPinnedRevision(clusterSummary, configv1beta1.FeatureHelm)
+	if pinned == nil {
+		return nil
+	}
+
+	key := getReleaseKey(currentChart.ReleaseNamespace, currentChart.ReleaseName)
+	for i := range pinned.Charts {
+		if pinned.Charts[i].Key == key && pinned.Charts[i].Chart != nil {
+			return pinned.Charts[i].Chart.DeepCopy()
+		}
+	}
+	return nil
+}
+
+// getPinnedValues returns, when helm feature is pinned to a revision, the instantiated values
+// stored in such revision for requestedChart.
+func getPinnedValues(clusterSummary *configv1beta1.ClusterSummary, requestedChart *configv1beta1.HelmChart,
+) (values chartutil.Values, pinned bool, err error) {
+
+	revision := getSessionPinnedRevision(clusterSummary, configv1beta1.FeatureHelm)
+	if revision == nil {
+		return nil, false, nil
+	}
+
+	key := getReleaseKey(requestedChart.ReleaseNamespace, requestedChart.ReleaseName)
+	for i := range revision.Charts {
+		if revision.Charts[i].Chart == nil {
+			continue
+		}
+		if getReleaseKey(revision.Charts[i].Chart.ReleaseNamespace, revision.Charts[i].Chart.ReleaseName) == key {
+			values, err = chartutil.ReadValues([]byte(revision.Charts[i].Values))
+			return values, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+// getRenderedRevision returns the content rendered during the session
+func (s *revisionSession) getRenderedRevision() (*featureRevision, error) {
+	rendered := &featureRevision{
+		FeatureID: s.rendered.FeatureID,
+		Units:     s.rendered.Units,
+	}
+
+	keys := make([]string, 0, len(s.charts))
+	for k := range s.charts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i := range keys {
+		if s.charts[keys[i]].Chart != nil {
+			rendered.Charts = append(rendered.Charts, *s.charts[keys[i]])
+		}
+	}
+
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return nil, err
+	}
+	rendered.hash = fmt.Sprintf("%x", sha256.Sum256(data))
+	return rendered, nil
+}
+
+// storeRevision stores, as a new revision, the content rendered while deploying a feature.
+// No-op if nothing was recorded or if rendered content is the same as the latest revision.
+// Oldest revisions exceeding revisionHistoryLimit are removed. Recorded content is kept till
+// the revision is stored, so a failed store can be retried.
+func storeRevision(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, logger logr.Logger) error {
+
+	revisionMux.Lock()
+	session := getRevisionSession(clusterSummary, featureID)
+	if session == nil || session.rendered == nil {
+		revisionMux.Unlock()
+		return nil
+	}
+	rendered, err := session.getRenderedRevision()
+	revisionMux.Unlock()
+	if err != nil {
+		return err
+	}
+
+	revisions, err := listRevisionSecrets(ctx, c, clusterSummary, featureID)
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) > 0 && revisions[len(revisions)-1].Labels[revisionHashLabel] == rendered.hash[:63] {
+		logger.V(logs.LogDebug).Info("rendered content matches latest revision")
+		clearRenderedRevision(clusterSummary, featureID)
+		return nil
+	}
+
+	rendered.Revision = 1
+	if len(revisions) > 0 {
+		rendered.Revision = getRevisionNumber(&revisions[len(revisions)-1]) + 1
+	}
+
+	secret, err := getRevisionSecret(clusterSummary, rendered)
+	if err != nil {
+		return err
+	}
+	if err := c.Create(ctx, secret); err != nil {
+		return err
+	}
+	clearRenderedRevision(clusterSummary, featureID)
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("stored revision %d for feature %s", rendered.Revision, featureID))
+
+	revisions = append(revisions, *secret)
+	for i := 0; i < len(revisions)-revisionHistoryLimit; i++ {
+		if err := c.Delete(ctx, &revisions[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clearRenderedRevision stops recording, for the session in progress, once rendered content is stored
+func clearRenderedRevision(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID) {
+	revisionMux.Lock()
+	defer revisionMux.Unlock()
+
+	if session := getRevisionSession(clusterSummary, featureID); session != nil {
+		session.rendered = nil
+	}
+}
+
+// getRevisionOwnerLabelValue returns the value of the label identifying the ClusterSummary
+// revisions belong to. ClusterSummary names often exceed the 63 characters allowed in label
+// values, in which case a hash of the name is used.
+func getRevisionOwnerLabelValue(clusterSummary *configv1beta1.ClusterSummary) string {
+	const maxLabelValueLength = 63
+	if len(clusterSummary.Name) <= maxLabelValueLength {
+		return clusterSummary.Name
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(clusterSummary.Name)))[:maxLabelValueLength]
+}
+
+func getRevisionSecretName(clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID,
+	revision int64) string {
+
+	const maxNameLength = 253
+	name := fmt.Sprintf("%s-%s-rev-%d", clusterSummary.Name, strings.ToLower(string(featureID)), revision)
+	if len(name) > maxNameLength {
+		name = fmt.Sprintf("rev-%x-%d",
+			sha256.Sum256([]byte(clusterSummary.Name+string(featureID))), revision)
+	}
+	return name
+}
+
+func getRevisionSecret(clusterSummary *configv1beta1.ClusterSummary, revision *featureRevision,
+) (*corev1.Secret, error) {
+
+	data, err := json.Marshal(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: clusterSummary.Namespace,
+			Name:      getRevisionSecretName(clusterSummary, revision.FeatureID, revision.Revision),
+			Labels: map[string]string{
+				ClusterSummaryLabelName: getRevisionOwnerLabelValue(clusterSummary),
+				revisionFeatureLabel:    string(revision.FeatureID),
+				revisionNumberLabel:     strconv.FormatInt(revision.Revision, 10),
+				// Label values are limited to 63 characters
+				revisionHashLabel: revision.hash[:63],
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: configv1beta1.GroupVersion.String(),
+					Kind:       configv1beta1.ClusterSummaryKind,
+					Name:       clusterSummary.Name,
+					UID:        clusterSummary.UID,
+				},
+			},
+		},
+		Type: revisionSecretType,
+		Data: map[string][]byte{revisionDataKey: buf.Bytes()},
+	}, nil
+}
+
+// listRevisionSecrets returns revisions stored for a ClusterSummary feature, oldest first
+func listRevisionSecrets(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID) ([]corev1.Secret, error) {
+
+	secrets := &corev1.SecretList{}
+	err := c.List(ctx, secrets, client.InNamespace(clusterSummary.Namespace),
+		client.MatchingLabels{
+			ClusterSummaryLabelName: getRevisionOwnerLabelValue(clusterSummary),
+			revisionFeatureLabel:    string(featureID),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]corev1.Secret, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		if secrets.Items[i].Type == revisionSecretType {
+			revisions = append(revisions, secrets.Items[i])
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return getRevisionNumber(&revisions[i]) < getRevisionNumber(&revisions[j])
+	})
+	return revisions, nil
+}
+
+func getRevisionNumber(secret *corev1.Secret) int64 {
+	revision, err := strconv.ParseInt(secret.Labels[revisionNumberLabel], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+func decodeRevision(secret *corev1.Secret) (*featureRevision, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(secret.Data[revisionDataKey]))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &featureRevision{}
+	if err := json.Unmarshal(data, revision); err != nil {
+		return nil, err
+	}
+	revision.hash = secret.Labels[revisionHashLabel]
+	return revision, nil
+}
+
+// getMatchingRollback returns the Rollback pinning a ClusterSummary feature, if any.
+// A Rollback referencing the cluster takes precedence over one applying to all clusters.
+func getMatchingRollback(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID) (*configv1beta1.Rollback, error) {
+
+	rollbacks := &configv1beta1.RollbackList{}
+	if err := c.List(ctx, rollbacks); err != nil {
+		return nil, err
+	}
+
+	if len(rollbacks.Items) == 0 {
+		return nil, nil
+	}
+
+	profileOwnerRef, err := configv1beta1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(rollbacks.Items, func(i, j int) bool {
+		return rollbacks.Items[i].Name < rollbacks.Items[j].Name
+	})
+
+	var result *configv1beta1.Rollback
+	for i := range rollbacks.Items {
+		rollback := &rollbacks.Items[i]
+		if !isRollbackMatching(rollback, clusterSummary, profileOwnerRef, featureID) {
+			continue
+		}
+		if rollback.Spec.ClusterRef != nil {
+			return rollback, nil
+		}
+		if result == nil {
+			result = rollback
+		}
+	}
+
+	return result, nil
+}
+
+func isRollbackMatching(rollback *configv1beta1.Rollback, clusterSummary *configv1beta1.ClusterSummary,
+	profileOwnerRef *metav1.OwnerReference, featureID configv1beta1.FeatureID) bool {
+
+	profileRef := &rollback.Spec.ProfileRef
+	if profileRef.Kind != profileOwnerRef.Kind || profileRef.Name != profileOwnerRef.Name {
+		return false
+	}
+	if profileRef.Kind == configv1beta1.ProfileKind && profileRef.Namespace != clusterSummary.Namespace {
+		return false
+	}
+
+	if clusterRef := rollback.Spec.ClusterRef; clusterRef != nil {
+		if clusterRef.Namespace != clusterSummary.Spec.ClusterNamespace ||
+			clusterRef.Name != clusterSummary.Spec.ClusterName ||
+			clusterproxy.GetClusterType(clusterRef) != clusterSummary.Spec.ClusterType {
+
+			return false
+		}
+	}
+
+	if len(rollback.Spec.FeatureIDs) == 0 {
+		return true
+	}
+	for i := range rollback.Spec.FeatureIDs {
+		if rollback.Spec.FeatureIDs[i] == featureID {
+			return true
+		}
+	}
+	return false
+}
+
+// selectRevision returns the revision a Rollback pins to among the available ones (oldest first)
+func selectRevision(rollback *configv1beta1.Rollback, revisions []corev1.Secret) *corev1.Secret {
+	switch {
+	case rollback.Spec.Revision != 0:
+		for i := range revisions {
+			if getRevisionNumber(&revisions[i]) == rollback.Spec.Revision {
+				return &revisions[i]
+			}
+		}
+	case rollback.Spec.DeployedBefore != nil:
+		for i := len(revisions) - 1; i >= 0; i-- {
+			if revisions[i].CreationTimestamp.Before(rollback.Spec.DeployedBefore) {
+				return &revisions[i]
+			}
+		}
+	default:
+		if len(revisions) > 1 {
+			return &revisions[len(revisions)-2]
+		}
+	}
+	return nil
+}
+
+// getPinnedRevision returns the revision a ClusterSummary feature is pinned to by a Rollback.
+// Returns nil if feature is not pinned.
+func getPinnedRevision(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary,
+	featureID configv1beta1.FeatureID, logger logr.Logger) (*featureRevision, error) {
+
+	rollback, err := getMatchingRollback(ctx, c, clusterSummary, featureID)
+	if err != nil || rollback == nil {
+		return nil, err
+	}
+
+	revisions, err := listRevisionSecrets(ctx, c, clusterSummary, featureID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := selectRevision(rollback, revisions)
+	if secret == nil {
+		// Not a deployment error. Current configuration is deployed and Rollback Status reports it.
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("Rollback %s: no matching revision", rollback.Name))
+		updateRollbackStatus(ctx, c, rollback, clusterSummary, featureID, 0,
+			"no matching revision stored. Current configuration is deployed", logger)
+		return nil, nil
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("Rollback %s pins feature %s to revision %d",
+		rollback.Name, featureID, getRevisionNumber(secret)))
+	updateRollbackStatus(ctx, c, rollback, clusterSummary, featureID, getRevisionNumber(secret), "", logger)
+	return decodeRevision(secret)
+}
+
+// updateRollbackStatus reports in Rollback Status the revision a ClusterSummary feature is pinned to.
+// Rollback is updated only if what is reported changes. Failing to update it does not prevent
+// deployments, so errors are only logged.
+func updateRollbackStatus(ctx context.Context, c client.Client, rollback *configv1beta1.Rollback,
+	clusterSummary *configv1beta1.ClusterSummary, featureID configv1beta1.FeatureID, revision int64,
+	message string, logger logr.Logger) {
+
+	pinned := configv1beta1.PinnedRevision{
+		ClusterNamespace: clusterSummary.Spec.ClusterNamespace,
+		ClusterName:      clusterSummary.Spec.ClusterName,
+		ClusterType:      clusterSummary.Spec.ClusterType,
+		FeatureID:        featureID,
+		Revision:         revision,
+		Message:          message,
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &configv1beta1.Rollback{}
+		if err := c.Get(ctx, types.NamespacedName{Name: rollback.Name}, current); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		for i := range current.Status.PinnedRevisions {
+			entry := &current.Status.PinnedRevisions[i]
+			if entry.ClusterNamespace == pinned.ClusterNamespace && entry.ClusterName == pinned.ClusterName &&
+				entry.ClusterType == pinned.ClusterType && entry.FeatureID == pinned.FeatureID {
+
+				if *entry == pinned {
+					return nil
+				}
+				*entry = pinned
+				return c.Status().Update(ctx, current)
+			}
+		}
+
+		current.Status.PinnedRevisions = append(current.Status.PinnedRevisions, pinned)
+		return c.Status().Update(ctx, current)
+	})
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update Rollback %s status: %v", rollback.Name, err))
+	}
+}
+
+// getPinnedRevisionHash returns the hash used, in place of the feature hash, for a pinned feature.
+// Pinning and releasing a pin both change the feature hash so the feature is redeployed.
+func getPinnedRevisionHash(revision *featureRevision) []byte {
+	h := sha256.New()
+	h.Write([]byte(fmt.Sprintf("rollback:%d:%s", revision.Revision, revision.hash)))
+	return h.Sum(nil)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Revision history", func() {
+	AfterEach(func() {
+		controllers.SetRevisionHistoryLimit(controllers.DefaultRevisionHistoryLimit)
+	})
+
+	It("stores revisions of rendered content and pins to a prior one", func() {
+		clusterProfileName := randomString()
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1beta1.ClusterProfileKind,
+						Name:       clusterProfileName,
+						APIVersion: configv1beta1.GroupVersion.String(),
+					},
+				},
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&configv1beta1.Rollback{}).
+			WithObjects(clusterSummary).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		ref := &corev1.ObjectReference{Kind: "ConfigMap", Namespace: randomString(), Name: randomString()}
+
+		deploy := func(replicas int64) {
+			Expect(controllers.StartRevisionSession(context.TODO(), c, clusterSummary,
+				configv1beta1.FeatureResources, logger)).To(Succeed())
+			defer controllers.EndRevisionSession(clusterSummary, configv1beta1.FeatureResources)
+
+			deployment := &unstructured.Unstructured{}
+			deployment.SetAPIVersion("apps/v1")
+			deployment.SetKind("Deployment")
+			deployment.SetNamespace("default")
+			deployment.SetName("nginx")
+			Expect(unstructured.SetNestedField(deployment.Object, replicas, "spec", "replicas")).To(Succeed())
+
+			controllers.RecordRenderedObjects(clusterSummary, configv1beta1.FeatureResources, false, ref, nil,
+				[]*unstructured.Unstructured{deployment})
+			Expect(controllers.StoreRevision(context.TODO(), c, clusterSummary,
+				configv1beta1.FeatureResources, logger)).To(Succeed())
+		}
+
+		listRevisions := func() []corev1.Secret {
+			secrets := &corev1.SecretList{}
+			Expect(c.List(context.TODO(), secrets, client.InNamespace(clusterSummary.Namespace))).To(Succeed())
+			return secrets.Items
+		}
+
+		deploy(1)
+		Expect(len(listRevisions())).To(Equal(1))
+
+		// Same rendered content does not create a new revision
+		deploy(1)
+		Expect(len(listRevisions())).To(Equal(1))
+
+		deploy(2)
+		Expect(len(listRevisions())).To(Equal(2))
+
+		// Oldest revisions are removed
+		controllers.SetRevisionHistoryLimit(2)
+		deploy(3)
+		Expect(len(listRevisions())).To(Equal(2))
+
+		revision, err := controllers.GetPinnedRevision(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources, logger)
+		Expect(err).To(BeNil())
+		Expect(revision).To(BeNil())
+
+		rollback := &configv1beta1.Rollback{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Spec: configv1beta1.RollbackSpec{
+				ProfileRef: corev1.ObjectReference{Kind: configv1beta1.ClusterProfileKind, Name: clusterProfileName},
+				Revision:   2,
+			},
+		}
+		Expect(c.Create(context.TODO(), rollback)).To(Succeed())
+
+		revision, err = controllers.GetPinnedRevision(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources, logger)
+		Expect(err).To(BeNil())
+		Expect(revision).ToNot(BeNil())
+		Expect(revision.Revision).To(Equal(int64(2)))
+		Expect(len(revision.Units)).To(Equal(1))
+		Expect(revision.Units[0].ReferencedObject).To(Equal(*ref))
+		deployment := &unstructured.Unstructured{}
+		Expect(deployment.UnmarshalJSON(revision.Units[0].Objects[0])).To(Succeed())
+		replicas, found, err := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		Expect(replicas).To(Equal(int64(2)))
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: rollback.Name}, rollback)).To(Succeed())
+		Expect(rollback.Status.PinnedRevisions).To(HaveLen(1))
+		Expect(rollback.Status.PinnedRevisions[0].ClusterName).To(Equal(clusterSummary.Spec.ClusterName))
+		Expect(rollback.Status.PinnedRevisions[0].FeatureID).To(Equal(configv1beta1.FeatureResources))
+		Expect(rollback.Status.PinnedRevisions[0].Revision).To(Equal(int64(2)))
+
+		// Revision 1 is not available anymore. This is not an error: nothing is pinned, current
+		// configuration keeps being deployed and Rollback Status reports it.
+		rollback.Spec.Revision = 1
+		Expect(c.Update(context.TODO(), rollback)).To(Succeed())
+		revision, err = controllers.GetPinnedRevision(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources, logger)
+		Expect(err).To(BeNil())
+		Expect(revision).To(BeNil())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: rollback.Name}, rollback)).To(Succeed())
+		Expect(rollback.Status.PinnedRevisions).To(HaveLen(1))
+		Expect(rollback.Status.PinnedRevisions[0].Revision).To(BeZero())
+		Expect(rollback.Status.PinnedRevisions[0].Message).To(ContainSubstring("no matching revision"))
+	})
+	It("stores revisions for ClusterSummaries with long names and keeps content when store fails", func() {
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      strings.Repeat("a", 100),
+			},
+			Spec: configv1beta1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1beta1.ClusterTypeCapi,
+			},
+		}
+
+		failCreate := true
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if failCreate {
+						return errors.New("create failed")
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		ref := &corev1.ObjectReference{Kind: "ConfigMap", Namespace: randomString(), Name: randomString()}
+
+		Expect(controllers.StartRevisionSession(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources, logger)).To(Succeed())
+		defer controllers.EndRevisionSession(clusterSummary, configv1beta1.FeatureResources)
+
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetNamespace("default")
+		configMap.SetName(randomString())
+		controllers.RecordRenderedObjects(clusterSummary, configv1beta1.FeatureResources, false, ref, nil,
+			[]*unstructured.Unstructured{configMap})
+
+		Expect(controllers.StoreRevision(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources, logger)).ToNot(Succeed())
+
+		// Rendered content is kept and stored on retry
+		failCreate = false
+		Expect(controllers.StoreRevision(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources, logger)).To(Succeed())
+
+		secrets := &corev1.SecretList{}
+		Expect(c.List(context.TODO(), secrets, client.InNamespace(clusterSummary.Namespace))).To(Succeed())
+		Expect(secrets.Items).To(HaveLen(1))
+		for _, v := range secrets.Items[0].Labels {
+			Expect(len(v)).To(BeNumerically("<=", 63))
+		}
+
+		revisions, err := controllers.ListRevisionSecrets(context.TODO(), c, clusterSummary,
+			configv1beta1.FeatureResources)
+		Expect(err).To(BeNil())
+		Expect(revisions).To(HaveLen(1))
+	})
+})
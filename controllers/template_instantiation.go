@@ -22,11 +22,9 @@ import (
 
 	"github.com/go-logr/logr"
 	"gopkg.in/yaml.v3"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,7 +33,6 @@ import (
 	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
 	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/funcmap"
-	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
@@ -59,14 +56,8 @@ func fetchResource(ctx context.Context, config *rest.Config, namespace, name, ap
 		Version: gv.Version,
 		Kind:    kind,
 	}
-	var dr dynamic.ResourceInterface
-	dr, err = k8s_utils.GetDynamicResourceInterface(config, gvk, namespace)
-	if err != nil {
-		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to fetch %s: %v", kind, err))
-		return nil, err
-	}
 	var resource *unstructured.Unstructured
-	resource, err = dr.Get(ctx, name, metav1.GetOptions{})
+	resource, err = getManagementClusterResource(ctx, config, gvk, namespace, name)
 	if err != nil {
 		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to fetch %s %v", kind, err))
 		return nil, err
@@ -20,11 +20,9 @@ import (
 	"context"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
-	"github.com/projectsveltos/libsveltos/lib/k8s_utils"
 	libsveltostemplate "github.com/projectsveltos/libsveltos/lib/template"
 )
 
@@ -32,7 +30,7 @@ import (
 func getTemplateResourceNamespace(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
 	ref *configv1beta1.TemplateResourceRef) (string, error) {
 
-	return libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(),
+	return libsveltostemplate.GetReferenceResourceNamespace(ctx, getManagementClusterClient(ctx),
 		clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, ref.Resource.Namespace,
 		clusterSummary.Spec.ClusterType)
 }
@@ -42,7 +40,7 @@ func getTemplateResourceNamespace(ctx context.Context, clusterSummary *configv1b
 func getTemplateResourceName(ctx context.Context, clusterSummary *configv1beta1.ClusterSummary,
 	ref *configv1beta1.TemplateResourceRef) (string, error) {
 
-	return libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(),
+	return libsveltostemplate.GetReferenceResourceName(ctx, getManagementClusterClient(ctx),
 		clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, ref.Resource.Name,
 		clusterSummary.Spec.ClusterType)
 }
@@ -56,7 +54,7 @@ func collectTemplateResourceRefs(ctx context.Context, clusterSummary *configv1be
 		return nil, nil
 	}
 
-	restConfig := getManagementClusterConfig()
+	restConfig := getManagementClusterConfig(ctx)
 
 	result := make(map[string]*unstructured.Unstructured)
 	for i := range clusterSummary.Spec.ClusterProfileSpec.TemplateResourceRefs {
@@ -71,13 +69,9 @@ func collectTemplateResourceRefs(ctx context.Context, clusterSummary *configv1be
 			return nil, err
 		}
 
-		dr, err := k8s_utils.GetDynamicResourceInterface(restConfig, ref.Resource.GroupVersionKind(), ref.Resource.Namespace)
-		if err != nil {
-			return nil, err
-		}
-
 		var u *unstructured.Unstructured
-		u, err = dr.Get(ctx, ref.Resource.Name, metav1.GetOptions{})
+		u, err = getManagementClusterResource(ctx, restConfig, ref.Resource.GroupVersionKind(),
+			ref.Resource.Namespace, ref.Resource.Name)
 		if err != nil {
 			if apierrors.IsNotFound(err) && ref.Optional {
 				continue
@@ -260,7 +260,7 @@ func isCluterSummaryProvisioned(clusterSumary *configv1beta1.ClusterSummary) boo
 
 	for i := range clusterSumary.Status.FeatureSummaries {
 		fs := &clusterSumary.Status.FeatureSummaries[i]
-		if fs.Status != configv1beta1.FeatureStatusProvisioned {
+		if !isFeatureProvisionedOrDegraded(fs) {
 			return false
 		}
 		switch fs.FeatureID {
@@ -510,7 +510,7 @@ func removeStaleDriftDetectionResources(ctx context.Context, logger logr.Logger)
 	for {
 		time.Sleep(time.Minute)
 
-		c := getManagementClusterClient()
+		c := getManagementClusterClient(ctx)
 		driftDetectionDeployments := &appsv1.DeploymentList{}
 		err := c.List(ctx, driftDetectionDeployments, listOptions...)
 		if err != nil {
@@ -541,7 +541,7 @@ func removeStaleDriftDetectionResources(ctx context.Context, logger logr.Logger)
 func removeStaleResourceSummary(ctx context.Context, clusterNamespace, clusterName string,
 	clusterType libsveltosv1beta1.ClusterType, logger logr.Logger) error {
 
-	c := getManagementClusterClient()
+	c := getManagementClusterClient(ctx)
 
 	rsListOptions := []client.ListOption{
 		client.MatchingLabels{
@@ -0,0 +1,160 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+// Withdrawal guard
+// A single typo in a ClusterSelector can make most clusters stop matching a ClusterProfile/Profile.
+// With StopMatchingBehavior set to WithdrawPolicies, all add-ons (CNI included) would then be
+// removed from all those clusters. The withdrawal guard blocks withdrawals from more than a number
+// (or a percentage) of clusters at once. When blocked, no ClusterSummary is deleted and the profile
+// WithdrawalBlocked condition lists the affected clusters along with a hash identifying the withdrawal.
+// Setting the ConfirmWithdrawalAnnotation to such hash lets the withdrawal proceed.
+// Only clusters which still exist but stopped matching count. Withdrawing from clusters being deleted
+// (decommissioning a batch of clusters) is never blocked.
+
+const (
+	withdrawalBlockedReason = "TooManyClusters"
+)
+
+var (
+	// Default limits. Zero disables a limit.
+	maxWithdrawalClusters   int32
+	maxWithdrawalPercentage int32
+)
+
+// SetWithdrawalGuard sets the default limits on the number and percentage of clusters a
+// ClusterProfile/Profile can withdraw add-ons from at once. Zero disables a limit.
+func SetWithdrawalGuard(maxClusters, maxPercentage int) {
+	maxWithdrawalClusters = int32(maxClusters)
+	maxWithdrawalPercentage = int32(maxPercentage)
+}
+
+// getWithdrawalLimits returns the limits for a ClusterProfile/Profile: the ones in its Spec
+// when set, the default ones otherwise.
+func getWithdrawalLimits(spec *configv1beta1.Spec) (maxClusters, maxPercentage int32) {
+	maxClusters, maxPercentage = maxWithdrawalClusters, maxWithdrawalPercentage
+	if spec.WithdrawalGuard != nil {
+		if spec.WithdrawalGuard.MaxClusters != nil {
+			maxClusters = *spec.WithdrawalGuard.MaxClusters
+		}
+		if spec.WithdrawalGuard.MaxPercentage != nil {
+			maxPercentage = *spec.WithdrawalGuard.MaxPercentage
+		}
+	}
+	return maxClusters, maxPercentage
+}
+
+// exceedsWithdrawalLimits returns true if withdrawing from withdrawn out of total clusters
+// exceeds the limits
+func exceedsWithdrawalLimits(spec *configv1beta1.Spec, withdrawn, total int) bool {
+	maxClusters, maxPercentage := getWithdrawalLimits(spec)
+	if maxClusters > 0 && withdrawn > int(maxClusters) {
+		return true
+	}
+	return maxPercentage > 0 && total > 0 && withdrawn*100 > int(maxPercentage)*total
+}
+
+// isClusterGone returns true if the cluster a ClusterSummary is for does not exist anymore
+// or is being deleted
+func isClusterGone(ctx context.Context, c client.Client, clusterSummary *configv1beta1.ClusterSummary) (bool, error) {
+	cluster, err := clusterproxy.GetCluster(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return !cluster.GetDeletionTimestamp().IsZero(), nil
+}
+
+// getWithdrawalHash returns the hash identifying the withdrawal from the given clusters
+func getWithdrawalHash(clusters []string) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(clusters, ",")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isWithdrawalBlocked returns true if add-ons cannot be withdrawn from the given clusters
+// (which stopped matching). It updates the WithdrawalBlocked condition accordingly.
+// confirmed is true when the withdrawal exceeds the limits but was confirmed with the
+// ConfirmWithdrawalAnnotation.
+// Withdrawals are never blocked when the profile is being deleted or leaves policies in
+// clusters which stop matching.
+func isWithdrawalBlocked(profileScope *scope.ProfileScope, clusters []string, total int) (blocked, confirmed bool) {
+	status := profileScope.GetStatus()
+	spec := profileScope.GetSpec()
+
+	if !profileScope.Profile.GetDeletionTimestamp().IsZero() ||
+		spec.StopMatchingBehavior == configv1beta1.LeavePolicies ||
+		!exceedsWithdrawalLimits(spec, len(clusters), total) {
+
+		meta.RemoveStatusCondition(&status.Conditions, configv1beta1.WithdrawalBlockedCondition)
+		return false, false
+	}
+
+	sort.Strings(clusters)
+	hash := getWithdrawalHash(clusters)
+
+	annotations := profileScope.Profile.GetAnnotations()
+	if annotations != nil && annotations[configv1beta1.ConfirmWithdrawalAnnotation] == hash {
+		profileScope.Logger.Info(fmt.Sprintf("withdrawal from %d clusters confirmed", len(clusters)))
+		meta.RemoveStatusCondition(&status.Conditions, configv1beta1.WithdrawalBlockedCondition)
+		return false, true
+	}
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:   configv1beta1.WithdrawalBlockedCondition,
+		Status: metav1.ConditionTrue,
+		Reason: withdrawalBlockedReason,
+		Message: fmt.Sprintf("withdrawal from %d out of %d clusters blocked: %s. To proceed, set annotation %s to %s",
+			len(clusters), total, strings.Join(clusters, ", "), configv1beta1.ConfirmWithdrawalAnnotation, hash),
+		ObservedGeneration: profileScope.Profile.GetGeneration(),
+	})
+	return true, false
+}
+
+// consumeWithdrawalConfirmation removes the ConfirmWithdrawalAnnotation once the confirmed
+// withdrawal has been started, so that a later withdrawal from the same clusters needs a
+// new confirmation
+func consumeWithdrawalConfirmation(profileScope *scope.ProfileScope) {
+	annotations := profileScope.Profile.GetAnnotations()
+	if annotations == nil {
+		return
+	}
+	delete(annotations, configv1beta1.ConfirmWithdrawalAnnotation)
+	profileScope.Profile.SetAnnotations(annotations)
+}
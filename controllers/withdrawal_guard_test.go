@@ -0,0 +1,207 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("Withdrawal guard", func() {
+	var clusterProfile *configv1beta1.ClusterProfile
+
+	BeforeEach(func() {
+		clusterProfile = &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+		}
+		Expect(addTypeInformationToObject(scheme, clusterProfile)).To(Succeed())
+	})
+
+	getProfileScope := func() *scope.ProfileScope {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterProfile).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		return profileScope
+	}
+
+	getClusters := func(n int) []string {
+		clusters := make([]string, n)
+		for i := range clusters {
+			clusters[i] = fmt.Sprintf("%s:%s/%s", libsveltosv1beta1.ClusterTypeSveltos, randomString(), randomString())
+		}
+		return clusters
+	}
+
+	It("isWithdrawalBlocked blocks withdrawals exceeding the percentage limit", func() {
+		maxPercentage := int32(50)
+		clusterProfile.Spec.WithdrawalGuard = &configv1beta1.WithdrawalGuard{MaxPercentage: &maxPercentage}
+		profileScope := getProfileScope()
+
+		blocked, confirmed := controllers.IsWithdrawalBlocked(profileScope, getClusters(2), 4)
+		Expect(blocked).To(BeFalse())
+		Expect(confirmed).To(BeFalse())
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions,
+			configv1beta1.WithdrawalBlockedCondition)).To(BeNil())
+
+		clusters := getClusters(3)
+		blocked, confirmed = controllers.IsWithdrawalBlocked(profileScope, clusters, 4)
+		Expect(blocked).To(BeTrue())
+		Expect(confirmed).To(BeFalse())
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.WithdrawalBlockedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Message).To(ContainSubstring("3 out of 4 clusters"))
+		for i := range clusters {
+			Expect(condition.Message).To(ContainSubstring(clusters[i]))
+		}
+	})
+
+	It("isWithdrawalBlocked blocks withdrawals exceeding the absolute limit", func() {
+		maxClusters := int32(2)
+		clusterProfile.Spec.WithdrawalGuard = &configv1beta1.WithdrawalGuard{MaxClusters: &maxClusters}
+		profileScope := getProfileScope()
+
+		blocked, _ := controllers.IsWithdrawalBlocked(profileScope, getClusters(2), 100)
+		Expect(blocked).To(BeFalse())
+
+		blocked, _ = controllers.IsWithdrawalBlocked(profileScope, getClusters(3), 100)
+		Expect(blocked).To(BeTrue())
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions,
+			configv1beta1.WithdrawalBlockedCondition)).ToNot(BeNil())
+
+		// Limits do not apply when policies are left in clusters which stop matching
+		clusterProfile.Spec.StopMatchingBehavior = configv1beta1.LeavePolicies
+		blocked, _ = controllers.IsWithdrawalBlocked(profileScope, getClusters(3), 100)
+		Expect(blocked).To(BeFalse())
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions,
+			configv1beta1.WithdrawalBlockedCondition)).To(BeNil())
+	})
+
+	It("isWithdrawalBlocked keeps blocking when confirmation hash does not match the withdrawal", func() {
+		maxClusters := int32(1)
+		clusterProfile.Spec.WithdrawalGuard = &configv1beta1.WithdrawalGuard{MaxClusters: &maxClusters}
+		clusters := getClusters(2)
+
+		// Confirmation was given for a different set of clusters
+		clusterProfile.Annotations = map[string]string{
+			configv1beta1.ConfirmWithdrawalAnnotation: controllers.GetWithdrawalHash(clusters[:1]),
+		}
+		profileScope := getProfileScope()
+
+		blocked, confirmed := controllers.IsWithdrawalBlocked(profileScope, clusters, 10)
+		Expect(blocked).To(BeTrue())
+		Expect(confirmed).To(BeFalse())
+		condition := meta.FindStatusCondition(clusterProfile.Status.Conditions, configv1beta1.WithdrawalBlockedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Message).To(ContainSubstring(controllers.GetWithdrawalHash(clusters)))
+
+		clusterProfile.Annotations[configv1beta1.ConfirmWithdrawalAnnotation] = controllers.GetWithdrawalHash(clusters)
+		blocked, confirmed = controllers.IsWithdrawalBlocked(profileScope, clusters, 10)
+		Expect(blocked).To(BeFalse())
+		Expect(confirmed).To(BeTrue())
+	})
+
+	It("cleanClusterSummaries does not count deleted clusters towards the limits", func() {
+		maxClusters := int32(1)
+		clusterProfile.Spec.WithdrawalGuard = &configv1beta1.WithdrawalGuard{MaxClusters: &maxClusters}
+
+		namespace := randomString()
+		initObjects := []client.Object{clusterProfile}
+		for i := 0; i < 4; i++ {
+			cluster := &libsveltosv1beta1.SveltosCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+			}
+			if i == 0 {
+				// Only cluster still matching
+				clusterProfile.Status.MatchingClusterRefs = []corev1.ObjectReference{
+					{
+						Namespace: cluster.Namespace, Name: cluster.Name,
+						Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+					},
+				}
+			}
+			if i < 2 {
+				// Other clusters are deleted
+				initObjects = append(initObjects, cluster)
+			}
+
+			clusterSummary := &configv1beta1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name: controllers.GetClusterSummaryName(configv1beta1.ClusterProfileKind,
+						clusterProfile.Name, cluster.Name, true),
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: clusterProfile.APIVersion,
+							Kind:       clusterProfile.Kind,
+							Name:       clusterProfile.Name,
+						},
+					},
+				},
+				Spec: configv1beta1.ClusterSummarySpec{
+					ClusterNamespace:   namespace,
+					ClusterName:        cluster.Name,
+					ClusterProfileSpec: clusterProfile.Spec,
+					ClusterType:        libsveltosv1beta1.ClusterTypeSveltos,
+				},
+			}
+			addLabelsToClusterSummary(clusterSummary, clusterProfile.Name, cluster.Name,
+				libsveltosv1beta1.ClusterTypeSveltos)
+			initObjects = append(initObjects, clusterSummary)
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// Three clusters stopped matching, but only one of those still exists
+		err = controllers.CleanClusterSummaries(context.TODO(), c, profileScope)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("clusterSummaries still present"))
+		Expect(meta.FindStatusCondition(clusterProfile.Status.Conditions,
+			configv1beta1.WithdrawalBlockedCondition)).To(BeNil())
+
+		clusterSummaryList := &configv1beta1.ClusterSummaryList{}
+		Expect(c.List(context.TODO(), clusterSummaryList)).To(Succeed())
+		Expect(len(clusterSummaryList.Items)).To(Equal(1))
+	})
+})
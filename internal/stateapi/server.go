@@ -0,0 +1,392 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stateapi serves a read-only view of the add-ons and applications deployed
+// in each managed cluster.
+package stateapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+const (
+	// BasePath is the path the state API is served at on the diagnostics server
+	BasePath = "/api/v1/"
+
+	defaultLimit = 100
+	maxLimit     = 500
+)
+
+// ClusterRef identifies a managed cluster
+type ClusterRef struct {
+	Namespace string                        `json:"namespace"`
+	Name      string                        `json:"name"`
+	Type      libsveltosv1beta1.ClusterType `json:"type"`
+}
+
+// ProfileRef identifies a ClusterProfile or a Profile
+type ProfileRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// ClusterItem is a managed cluster along with the profiles matching it
+type ClusterItem struct {
+	Cluster  ClusterRef   `json:"cluster"`
+	Profiles []ProfileRef `json:"profiles"`
+}
+
+// ClusterList is a page of managed clusters
+type ClusterList struct {
+	Items    []ClusterItem `json:"items"`
+	Continue string        `json:"continue,omitempty"`
+}
+
+// ProfileState is what a profile deployed in a managed cluster
+type ProfileState struct {
+	Profile          ProfileRef                     `json:"profile"`
+	FeatureSummaries []configv1beta1.FeatureSummary `json:"featureSummaries,omitempty"`
+	HelmReleases     []configv1beta1.Chart          `json:"helmReleases,omitempty"`
+	Resources        []configv1beta1.Resource       `json:"resources,omitempty"`
+}
+
+// ClusterState is a page of the profiles deployed in a managed cluster
+type ClusterState struct {
+	Cluster  ClusterRef     `json:"cluster"`
+	Items    []ProfileState `json:"items"`
+	Continue string         `json:"continue,omitempty"`
+}
+
+// ProfileRollout is the rollout progress of a profile across its matching clusters
+type ProfileRollout struct {
+	Profile ProfileRef `json:"profile"`
+
+	// MatchingClusters is the number of clusters matching the profile
+	MatchingClusters int `json:"matchingClusters"`
+
+	// Statuses is the number of clusters per status. A cluster status is the least
+	// advanced status of all features. Clusters not reporting a status yet are Pending.
+	Statuses map[string]int `json:"statuses"`
+}
+
+// ProfileList is a page of profile rollouts
+type ProfileList struct {
+	Items    []ProfileRollout `json:"items"`
+	Continue string           `json:"continue,omitempty"`
+}
+
+type server struct {
+	client client.Client
+}
+
+// Handler returns the handler serving the state API. All reads go through c, which is expected to be
+// the manager's cached client so requests never reach the API server.
+//   - GET /api/v1/clusters: managed clusters with the profiles matching each;
+//   - GET /api/v1/clusters/{type}/{namespace}/{name}: per profile, helm releases and resources deployed
+//     in the cluster and FeatureSummaries;
+//   - GET /api/v1/profiles: rollout progress of each profile.
+//
+// Lists are paginated: limit sets the page size, continue the token returned by the previous page.
+func Handler(c client.Client) http.Handler {
+	s := &server{client: c}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+BasePath+"clusters", s.listClusters)
+	mux.HandleFunc("GET "+BasePath+"clusters/{type}/{namespace}/{name}", s.getCluster)
+	mux.HandleFunc("GET "+BasePath+"profiles", s.listProfiles)
+	return mux
+}
+
+func (s *server) listClusters(w http.ResponseWriter, r *http.Request) {
+	profiles, err := s.getProfiles(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	clusters := map[ClusterRef]*ClusterItem{}
+	for i := range profiles {
+		for j := range profiles[i].matchingClusters {
+			ref := &profiles[i].matchingClusters[j]
+			cluster := ClusterRef{Namespace: ref.Namespace, Name: ref.Name, Type: clusterproxy.GetClusterType(ref)}
+			if _, ok := clusters[cluster]; !ok {
+				clusters[cluster] = &ClusterItem{Cluster: cluster, Profiles: make([]ProfileRef, 0)}
+			}
+			clusters[cluster].Profiles = append(clusters[cluster].Profiles, profiles[i].ref)
+		}
+	}
+
+	items := make([]ClusterItem, 0, len(clusters))
+	for _, item := range clusters {
+		items = append(items, *item)
+	}
+
+	page, next, err := paginate(r, items, func(item *ClusterItem) string { return clusterKey(&item.Cluster) })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &ClusterList{Items: page, Continue: next})
+}
+
+func (s *server) getCluster(w http.ResponseWriter, r *http.Request) {
+	cluster := ClusterRef{
+		Namespace: r.PathValue("namespace"),
+		Name:      r.PathValue("name"),
+		Type:      libsveltosv1beta1.ClusterType(r.PathValue("type")),
+	}
+	if cluster.Type != libsveltosv1beta1.ClusterTypeCapi && cluster.Type != libsveltosv1beta1.ClusterTypeSveltos {
+		http.Error(w, fmt.Sprintf("unsupported cluster type %q", cluster.Type), http.StatusBadRequest)
+		return
+	}
+
+	listOptions := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{
+			configv1beta1.ClusterNameLabel: cluster.Name,
+			configv1beta1.ClusterTypeLabel: string(cluster.Type),
+		},
+	}
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := s.client.List(r.Context(), clusterSummaries, listOptions...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	clusterConfigurations := &configv1beta1.ClusterConfigurationList{}
+	if err := s.client.List(r.Context(), clusterConfigurations, listOptions...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	states := map[ProfileRef]*ProfileState{}
+	getState := func(ref ProfileRef) *ProfileState {
+		if _, ok := states[ref]; !ok {
+			states[ref] = &ProfileState{Profile: ref}
+		}
+		return states[ref]
+	}
+
+	for i := range clusterSummaries.Items {
+		cs := &clusterSummaries.Items[i]
+		owner, err := configv1beta1.GetProfileOwnerReference(cs)
+		if err != nil {
+			continue
+		}
+		getState(getProfileRef(owner.Kind, cs.Namespace, owner.Name)).FeatureSummaries = cs.Status.FeatureSummaries
+	}
+
+	for i := range clusterConfigurations.Items {
+		cc := &clusterConfigurations.Items[i]
+		for j := range cc.Status.ClusterProfileResources {
+			state := getState(getProfileRef(configv1beta1.ClusterProfileKind, "",
+				cc.Status.ClusterProfileResources[j].ClusterProfileName))
+			addFeatures(state, cc.Status.ClusterProfileResources[j].Features)
+		}
+		for j := range cc.Status.ProfileResources {
+			state := getState(getProfileRef(configv1beta1.ProfileKind, cc.Namespace,
+				cc.Status.ProfileResources[j].ProfileName))
+			addFeatures(state, cc.Status.ProfileResources[j].Features)
+		}
+	}
+
+	if len(states) == 0 {
+		http.Error(w, "no profile is deployed in this cluster", http.StatusNotFound)
+		return
+	}
+
+	items := make([]ProfileState, 0, len(states))
+	for _, state := range states {
+		items = append(items, *state)
+	}
+
+	page, next, err := paginate(r, items, func(item *ProfileState) string { return profileKey(&item.Profile) })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &ClusterState{Cluster: cluster, Items: page, Continue: next})
+}
+
+func (s *server) listProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := s.getProfiles(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	clusterSummaries := &configv1beta1.ClusterSummaryList{}
+	if err := s.client.List(r.Context(), clusterSummaries); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rollouts := map[ProfileRef]*ProfileRollout{}
+	for i := range profiles {
+		rollouts[profiles[i].ref] = &ProfileRollout{
+			Profile:          profiles[i].ref,
+			MatchingClusters: len(profiles[i].matchingClusters),
+			Statuses:         map[string]int{},
+		}
+	}
+
+	for i := range clusterSummaries.Items {
+		cs := &clusterSummaries.Items[i]
+		owner, err := configv1beta1.GetProfileOwnerReference(cs)
+		if err != nil {
+			continue
+		}
+		rollout, ok := rollouts[getProfileRef(owner.Kind, cs.Namespace, owner.Name)]
+		if !ok {
+			continue
+		}
+		if status := configv1beta1.GetClusterSummaryStatus(cs); status != "" {
+			rollout.Statuses[string(status)]++
+		}
+	}
+
+	items := make([]ProfileRollout, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		reported := 0
+		for _, v := range rollout.Statuses {
+			reported += v
+		}
+		if pending := rollout.MatchingClusters - reported; pending > 0 {
+			rollout.Statuses["Pending"] = pending
+		}
+		items = append(items, *rollout)
+	}
+
+	page, next, err := paginate(r, items, func(item *ProfileRollout) string { return profileKey(&item.Profile) })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &ProfileList{Items: page, Continue: next})
+}
+
+type profileInfo struct {
+	ref              ProfileRef
+	matchingClusters []corev1.ObjectReference
+}
+
+func (s *server) getProfiles(r *http.Request) ([]profileInfo, error) {
+	clusterProfiles := &configv1beta1.ClusterProfileList{}
+	if err := s.client.List(r.Context(), clusterProfiles); err != nil {
+		return nil, err
+	}
+
+	profiles := &configv1beta1.ProfileList{}
+	if err := s.client.List(r.Context(), profiles); err != nil {
+		return nil, err
+	}
+
+	result := make([]profileInfo, 0, len(clusterProfiles.Items)+len(profiles.Items))
+	for i := range clusterProfiles.Items {
+		cp := &clusterProfiles.Items[i]
+		result = append(result, profileInfo{
+			ref:              getProfileRef(configv1beta1.ClusterProfileKind, "", cp.Name),
+			matchingClusters: cp.Status.MatchingClusterRefs,
+		})
+	}
+	for i := range profiles.Items {
+		p := &profiles.Items[i]
+		result = append(result, profileInfo{
+			ref:              getProfileRef(configv1beta1.ProfileKind, p.Namespace, p.Name),
+			matchingClusters: p.Status.MatchingClusterRefs,
+		})
+	}
+
+	return result, nil
+}
+
+func addFeatures(state *ProfileState, features []configv1beta1.Feature) {
+	for i := range features {
+		state.HelmReleases = append(state.HelmReleases, features[i].Charts...)
+		state.Resources = append(state.Resources, features[i].Resources...)
+	}
+}
+
+// paginate sorts items by key and returns the page starting after the continue token along with
+// the token for the next page (empty if this is the last page)
+func paginate[T any](r *http.Request, items []T, key func(*T) string) (page []T, next string, err error) {
+	limit := defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return nil, "", fmt.Errorf("invalid limit %q", v)
+		}
+		limit = min(limit, maxLimit)
+	}
+
+	after := ""
+	if v := r.URL.Query().Get("continue"); v != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid continue token")
+		}
+		after = string(decoded)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return key(&items[i]) < key(&items[j]) })
+
+	start := sort.Search(len(items), func(i int) bool { return after == "" || key(&items[i]) > after })
+	end := min(start+limit, len(items))
+
+	page = items[start:end]
+	if end < len(items) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(key(&items[end-1])))
+	}
+
+	return page, next, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func getProfileRef(kind, namespace, name string) ProfileRef {
+	if kind == configv1beta1.ClusterProfileKind {
+		namespace = ""
+	}
+	return ProfileRef{Kind: kind, Namespace: namespace, Name: name}
+}
+
+func profileKey(ref *ProfileRef) string {
+	return fmt.Sprintf("%s/%s/%s", ref.Kind, ref.Namespace, ref.Name)
+}
+
+func clusterKey(ref *ClusterRef) string {
+	return fmt.Sprintf("%s/%s/%s", ref.Type, ref.Namespace, ref.Name)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stateapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1beta1 "github.com/projectsveltos/addon-controller/api/v1beta1"
+	"github.com/projectsveltos/addon-controller/internal/stateapi"
+	libsveltosv1beta1 "github.com/projectsveltos/libsveltos/api/v1beta1"
+)
+
+var _ = Describe("State API", func() {
+	var handler http.Handler
+	var namespace string
+	var clusterProfile *configv1beta1.ClusterProfile
+	var clusterNames []string
+
+	BeforeEach(func() {
+		namespace = randomString()
+		clusterNames = []string{randomString(), randomString(), randomString()}
+
+		matching := make([]corev1.ObjectReference, len(clusterNames))
+		for i := range clusterNames {
+			matching[i] = corev1.ObjectReference{
+				Namespace: namespace, Name: clusterNames[i],
+				Kind: libsveltosv1beta1.SveltosClusterKind, APIVersion: libsveltosv1beta1.GroupVersion.String(),
+			}
+		}
+
+		clusterProfile = &configv1beta1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: randomString()},
+			Status:     configv1beta1.Status{MatchingClusterRefs: matching},
+		}
+
+		clusterSummary := &configv1beta1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Labels: map[string]string{
+					configv1beta1.ClusterNameLabel: clusterNames[0],
+					configv1beta1.ClusterTypeLabel: string(libsveltosv1beta1.ClusterTypeSveltos),
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: configv1beta1.ClusterProfileKind, APIVersion: configv1beta1.GroupVersion.String(),
+						Name: clusterProfile.Name, UID: "uid"},
+				},
+			},
+			Status: configv1beta1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1beta1.FeatureSummary{
+					{FeatureID: configv1beta1.FeatureHelm, Status: configv1beta1.FeatureStatusProvisioned},
+				},
+			},
+		}
+
+		clusterConfiguration := &configv1beta1.ClusterConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Labels: map[string]string{
+					configv1beta1.ClusterNameLabel: clusterNames[0],
+					configv1beta1.ClusterTypeLabel: string(libsveltosv1beta1.ClusterTypeSveltos),
+				},
+			},
+			Status: configv1beta1.ClusterConfigurationStatus{
+				ClusterProfileResources: []configv1beta1.ClusterProfileResource{
+					{
+						ClusterProfileName: clusterProfile.Name,
+						Features: []configv1beta1.Feature{
+							{
+								FeatureID: configv1beta1.FeatureHelm,
+								Charts: []configv1beta1.Chart{
+									{RepoURL: randomString(), ReleaseName: "kyverno", Namespace: "kyverno", ChartVersion: "v3.0.0"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		initObjects := []client.Object{clusterProfile, clusterSummary, clusterConfiguration}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+		handler = stateapi.Handler(c)
+	})
+
+	get := func(path string, v any) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code == http.StatusOK {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), v)).To(Succeed())
+		}
+		return recorder.Code
+	}
+
+	It("lists clusters with matching profiles, paginated", func() {
+		seen := map[string]bool{}
+		path := stateapi.BasePath + "clusters?limit=2"
+
+		clusters := &stateapi.ClusterList{}
+		Expect(get(path, clusters)).To(Equal(http.StatusOK))
+		Expect(len(clusters.Items)).To(Equal(2))
+		Expect(clusters.Continue).ToNot(BeEmpty())
+		for i := range clusters.Items {
+			Expect(clusters.Items[i].Profiles).To(ContainElement(stateapi.ProfileRef{
+				Kind: configv1beta1.ClusterProfileKind, Name: clusterProfile.Name}))
+			seen[clusters.Items[i].Cluster.Name] = true
+		}
+
+		next := &stateapi.ClusterList{}
+		Expect(get(path+"&continue="+url.QueryEscape(clusters.Continue), next)).To(Equal(http.StatusOK))
+		Expect(len(next.Items)).To(Equal(1))
+		Expect(next.Continue).To(BeEmpty())
+		seen[next.Items[0].Cluster.Name] = true
+
+		Expect(len(seen)).To(Equal(len(clusterNames)))
+
+		Expect(get(stateapi.BasePath+"clusters?limit=0", clusters)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns helm releases and feature summaries deployed in a cluster", func() {
+		state := &stateapi.ClusterState{}
+		Expect(get(stateapi.BasePath+"clusters/Sveltos/"+namespace+"/"+clusterNames[0], state)).
+			To(Equal(http.StatusOK))
+		Expect(len(state.Items)).To(Equal(1))
+		Expect(state.Items[0].Profile.Name).To(Equal(clusterProfile.Name))
+		Expect(len(state.Items[0].HelmReleases)).To(Equal(1))
+		Expect(state.Items[0].HelmReleases[0].ReleaseName).To(Equal("kyverno"))
+		Expect(len(state.Items[0].FeatureSummaries)).To(Equal(1))
+
+		Expect(get(stateapi.BasePath+"clusters/Sveltos/"+namespace+"/"+clusterNames[1], state)).
+			To(Equal(http.StatusNotFound))
+		Expect(get(stateapi.BasePath+"clusters/Foo/"+namespace+"/"+clusterNames[0], state)).
+			To(Equal(http.StatusBadRequest))
+	})
+
+	It("reports profile rollout progress", func() {
+		profiles := &stateapi.ProfileList{}
+		Expect(get(stateapi.BasePath+"profiles", profiles)).To(Equal(http.StatusOK))
+		Expect(len(profiles.Items)).To(Equal(1))
+		Expect(profiles.Items[0].MatchingClusters).To(Equal(len(clusterNames)))
+		Expect(profiles.Items[0].Statuses[string(configv1beta1.FeatureStatusProvisioned)]).To(Equal(1))
+		Expect(profiles.Items[0].Statuses["Pending"]).To(Equal(len(clusterNames) - 1))
+	})
+})
@@ -18,6 +18,7 @@ package scope
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -69,6 +70,39 @@ type ClusterSummaryScope struct {
 	Profile        client.Object
 	ClusterSummary *configv1beta1.ClusterSummary
 	controllerName string
+
+	// deploymentDeferred is set when a DeploymentWindow currently does not allow deployments
+	deploymentDeferred    bool
+	nextDeploymentWindow  time.Time
+	deferDriftRemediation bool
+
+	// dependenciesUnblocked is set when dependencies, previously not deployed, are now all deployed
+	dependenciesUnblocked bool
+}
+
+// SetDeploymentDeferred records that deployments must wait for the next deployment window,
+// starting at nextWindow (zero if no window is scheduled). Unless deferDriftRemediation is set,
+// configuration drifts can still be remediated.
+func (s *ClusterSummaryScope) SetDeploymentDeferred(nextWindow time.Time, deferDriftRemediation bool) {
+	s.deploymentDeferred = true
+	s.nextDeploymentWindow = nextWindow
+	s.deferDriftRemediation = deferDriftRemediation
+}
+
+// IsDeploymentDeferred returns whether deployments must wait for the next deployment window,
+// when such window starts and whether drift remediation must wait as well.
+func (s *ClusterSummaryScope) IsDeploymentDeferred() (deferred bool, nextWindow time.Time, deferDriftRemediation bool) {
+	return s.deploymentDeferred, s.nextDeploymentWindow, s.deferDriftRemediation
+}
+
+// SetDependenciesUnblocked records that dependencies, previously not deployed, are now all deployed.
+func (s *ClusterSummaryScope) SetDependenciesUnblocked() {
+	s.dependenciesUnblocked = true
+}
+
+// AreDependenciesUnblocked returns whether dependencies became all deployed in this reconciliation.
+func (s *ClusterSummaryScope) AreDependenciesUnblocked() bool {
+	return s.dependenciesUnblocked
 }
 
 // PatchObject persists the cluster configuration and status.
@@ -150,6 +184,16 @@ func (s *ClusterSummaryScope) ResetConsecutiveFailures(featureID configv1beta1.F
 	}
 }
 
+// ResetDriftDetectedTime clears the time a configuration drift was detected
+func (s *ClusterSummaryScope) ResetDriftDetectedTime(featureID configv1beta1.FeatureID) {
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].DriftDetectedTime = nil
+			return
+		}
+	}
+}
+
 // SetDependenciesMessage sets the dependencies status.
 func (s *ClusterSummaryScope) SetDependenciesMessage(message *string) {
 	s.ClusterSummary.Status.Dependencies = message
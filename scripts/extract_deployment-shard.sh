@@ -39,7 +39,7 @@ done < "$yaml_file"
 
 # Iterate through the split sections and print those with "kind: Deployment"
 for section_file in temp_yaml_sections/*.yaml; do
-    if grep -q "kind: Deployment" "$section_file"; then
+    if grep -qx "kind: Deployment" "$section_file"; then
         cat "$section_file" > $output_file
     fi
 done